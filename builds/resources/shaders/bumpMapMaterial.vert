@@ -10,11 +10,11 @@ uniform sampler2D NormalTextureSampler;
 uniform mat4 model, view, projection;
 uniform vec4 ambient, diffuse, specular, emissive;
 uniform uint colourmode;
-uniform vec4 lightpos;
+uniform vec4 clipplane; // Water plane for the reflection and refraction passes
 
 // Outputs
 out vec4 lightPosition;
-out vec3 lightNormal, lightDirection;
+out vec3 lightNormal;
 out mat3 matrixNormal;
 out vec2 textureCoordinates;
 out vec4 ambientMaterial, diffuseMaterial, specularMaterial, emissiveMaterial;
@@ -25,8 +25,6 @@ void main() {
     specularMaterial    = specular;
     emissiveMaterial    = emissive;
 
-    vec3 lightPosV3 = lightpos.xyz;
-
     // Convert the (x,y,z) position to homogeneous coords (x,y,z,w)
     vec4 positionHomogeneus = vec4(position, 1.0);
 
@@ -34,12 +32,14 @@ void main() {
     mat4 matrixModelView = view * model;
     matrixNormal = transpose(inverse(mat3(matrixModelView)));
 
-    // Calculates the Lights
+    // Calculates the Lights (in view space)
     lightPosition = matrixModelView * positionHomogeneus;
-    lightDirection = lightPosV3 - lightPosition.xyz;
     lightNormal = normalize(matrixNormal *  normal);
 
     // Define the vertex position
+    // Cuts what is on the other side of the water (only when GL_CLIP_DISTANCE0 is enabled)
+    gl_ClipDistance[0] = dot(model * positionHomogeneus, clipplane);
+
     gl_Position = (projection * view * model) * positionHomogeneus;
 
 
@@ -6,10 +6,10 @@ layout(location = 2) in vec3 normal;
 uniform mat4 model, view, projection;
 uniform vec4 ambient, diffuse, specular, emissive;
 uniform uint colourmode;
-uniform vec4 lightpos;
+uniform vec4 clipplane; // Water plane for the reflection and refraction passes
 
 out vec4 lightPosition;
-out vec3 lightNormal, lightDirection;
+out vec3 lightNormal;
 out vec4 colorAmbient, colorDiffuse, colorSpecular, colorEmissive;
 
 void main() {
@@ -18,8 +18,6 @@ void main() {
     colorSpecular = specular;
     colorEmissive = emissive;
 
-    vec3 lightPosV3 = lightpos.xyz;
-
     // Convert the (x,y,z) position to homogeneous coords (x,y,z,w)
     vec4 positionHomogeneus = vec4(position, 1.0);
 
@@ -34,11 +32,13 @@ void main() {
     mat4 matrixModelView = view * model;
     mat3 matrixNormal = transpose(inverse(mat3(matrixModelView)));
 
-    // Calculates the Lights
+    // Calculates the Lights (in view space)
     lightPosition = matrixModelView * positionHomogeneus;
     lightNormal = normalize(matrixNormal * normal);
-    lightDirection = lightPosV3 - lightPosition.xyz;
 
     // Define the vertex position
+    // Cuts what is on the other side of the water (only when GL_CLIP_DISTANCE0 is enabled)
+    gl_ClipDistance[0] = dot(model * positionHomogeneus, clipplane);
+
     gl_Position = (projection * view * model) * positionHomogeneus;
 }
\ No newline at end of file
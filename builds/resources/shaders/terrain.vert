@@ -10,20 +10,18 @@ layout(location = 2) in vec3 normal;
 // Uniform variables are passed in from the application
 uniform mat4 model, view, projection;
 uniform uint colourmode;
-uniform vec4 lightpos;
+uniform vec4 clipplane; // Water plane for the reflection and refraction passes
 uniform vec4 tone;
 
 // Outputs
 out vec4 lightPosition;
-out vec3 lightNormal, lightDirection;
+out vec3 lightNormal;
 out vec4 colorDiffuse;
 
 // Color Constants
 const vec4 toneModifier = vec4(0.662, 0.405, 0.022, 1);
 
 void main() {
-    vec3 lightPosV3 = lightpos.xyz;
-
     // Convert the (x,y,z) position to homogeneous coords (x,y,z,w)
 	vec4 positionHomogeneus = vec4(position, 1.0);
 
@@ -38,12 +36,14 @@ void main() {
 	mat4 matrixModelView = view * model;
 	mat3 matrixNormal = transpose(inverse(mat3(matrixModelView)));
 
-    // Calculates the Lights
+    // Calculates the Lights (in view space)
 	lightPosition = matrixModelView * positionHomogeneus;
 	lightNormal = normalize(matrixNormal * -normal);
-	lightDirection = lightPosV3 - lightPosition.xyz;
 
 	// Define the vertex position
+	// Cuts what is on the other side of the water (only when GL_CLIP_DISTANCE0 is enabled)
+	gl_ClipDistance[0] = dot(model * positionHomogeneus, clipplane);
+
 	gl_Position = (projection * view * model) * positionHomogeneus;
 }
 
@@ -5,7 +5,7 @@ uniform sampler2D NormalTextureSampler;
 uniform sampler2D SpecularTextureSampler;
 
 in vec4 lightPosition;
-in vec3 lightNormal, lightDirection;
+in vec3 lightNormal;
 in vec2 fragTexCoord;
 
 out vec4 outputColor;
@@ -14,39 +14,71 @@ out vec4 outputColor;
 const vec4 colorAmbientGlobal   = vec4(0.05, 0.05, 0.05, 0.0);
 const vec4 colorEmissive        = vec4(0.2, 0.2, 0.2, 0.0);
 const int  shininess            = 9000;
-const float radius              = 90.5;
+
+// Lights of the scene, in view space (the light manager fills the block)
+const int maxLights = 8;
+
+struct Light {
+    vec4 position;  // w is 0 for the directional lights
+    vec4 direction; // Direction the light shines to
+    vec4 colour;    // Colour times the intensity (a is the range)
+    vec4 cone;      // Cosines of the inner and outer half angles (z is 1 for the spot lights)
+};
+
+layout(std140) uniform Lights {
+    ivec4 lightcount; // x is the number of lights
+    Light lights[maxLights];
+};
+
+// Adds up the diffuse and specular light that every light gives to a point (in view space)
+// Copy of the one in colorMaterial.frag (the canonical copy), change that one first and copy it here
+void accumulateLights(vec3 P, vec3 N, float shininess, out vec3 diffuseLight, out vec3 specularLight) {
+    diffuseLight = vec3(0.0);
+    specularLight = vec3(0.0);
+    vec3 V = normalize(-P);
+
+    for (int i = 0; i < min(lightcount.x, maxLights); i++) {
+        vec3 L = -lights[i].direction.xyz;
+        float attenuation = 1.0;
+
+        // The point and spot lights fade out at their range. Attenuation formula from:
+        // http://gamedev.stackexchange.com/questions/56897/glsl-light-attenuation-color-and-intensity-formula
+        if (lights[i].position.w != 0.0) {
+            L = lights[i].position.xyz - P;
+            float range = lights[i].colour.a;
+            attenuation = clamp(1.0 - dot(L, L) / (range * range), 0.0, 1.0);
+            attenuation *= attenuation;
+        }
+        L = normalize(L);
+
+        // The spot lights fade out between the inner and the outer cone
+        if (lights[i].cone.z != 0.0) {
+            attenuation *= smoothstep(lights[i].cone.y, lights[i].cone.x, dot(-L, lights[i].direction.xyz));
+        }
+
+        // Diffuse and Phong specular reflection
+        vec3 R = reflect(-L, N);
+        diffuseLight += attenuation * max(dot(N, L), 0.0) * lights[i].colour.rgb;
+        specularLight += attenuation * pow(max(dot(R, V), 0.0), shininess) * lights[i].colour.rgb;
+    }
+}
 
 void main() {
     vec4 colorDiffuse = texture(DiffuseTextureSampler, fragTexCoord);
     vec4 colorAmbient = vec4(colorDiffuse.xyz * 0.2, 0.0);
     vec4 colorSpecular =  vec4(1.0, 1.0, 0.5, 0.0);
 
-    float lightDistance = length(lightDirection);
-
     // Normalise interpolated vectors
-    vec3 L = normalize(lightDirection);
     vec3 N = normalize(lightNormal);
 
-    // Calculate the diffuse component
-    vec4 diffuse = max(dot(N, L), 0.0) * colorDiffuse;
-
-    // Calculate the specular component using Phong specular reflection
-    vec3 V = normalize(-lightPosition.xyz);
-    vec3 R = reflect(-L, N);
-    vec4 specular = pow(max(dot(R, V), 0.0), shininess) * colorSpecular;
-
-    // Attenuation formula from:
-    // http://gamedev.stackexchange.com/questions/56897/glsl-light-attenuation-color-and-intensity-formula
-
-    // Calculate the attenuation factor;
-    float attenuation = clamp(1.0 - lightDistance * lightDistance / (radius * radius), 0.0, 1.0);
-    attenuation *= attenuation;
+    // Adds up the light of every light of the scene
+    vec3 diffuseLight, specularLight;
+    accumulateLights(lightPosition.xyz, N, float(shininess), diffuseLight, specularLight);
 
-    // simple hack to make the light brighter, it would be better to change the attenuation equation!
-    // attenuation *= 3.5;
+    vec3 diffuse = diffuseLight * colorDiffuse.rgb;
+    vec3 specular = specularLight * colorSpecular.rgb;
 
-    // Calculate the output colour, includung attenuation on the diffuse and specular components
-    // Note that you may want to exclude the ambient form the attenuation factor so objects
-    // are always visible, or include a global ambient
-    outputColor = colorAmbient + (attenuation * (diffuse + specular)) + colorEmissive + colorAmbientGlobal;
+    // Calculate the output colour (the lights are attenuated already, the ambient and emissive colours keep
+    // the objects visible), with the transparency of the texture
+    outputColor = vec4(colorAmbient.rgb + diffuse + specular + colorEmissive.rgb + colorAmbientGlobal.rgb, colorDiffuse.a);
 }
\ No newline at end of file
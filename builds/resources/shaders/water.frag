@@ -0,0 +1,123 @@
+// Water fragment shader
+
+#version 330
+
+in vec4 clipSpace;
+in vec3 worldNormal;
+in vec3 viewPosition, viewNormal;
+
+out vec4 outputColor;
+
+uniform sampler2D reflection, refraction, depthmap;
+uniform uint fallback;          // 1 when there are no reflection and refraction textures
+uniform vec4 tone, deepcolour;  // Shallow and deep colours
+uniform float maxdepth;         // Depth where the water gets the deep colour
+uniform float shorefade;        // Depth where the water stops being transparent
+uniform float fresnelpower, distortion;
+uniform float near, far;
+
+const vec3 colorSpecular = vec3(1.0, 1.0, 0.9);
+const float shininess    = 120.0;
+
+// Lights of the scene, in view space (the light manager fills the block)
+const int maxLights = 8;
+
+struct Light {
+	vec4 position;  // w is 0 for the directional lights
+	vec4 direction; // Direction the light shines to
+	vec4 colour;    // Colour times the intensity (a is the range)
+	vec4 cone;      // Cosines of the inner and outer half angles (z is 1 for the spot lights)
+};
+
+layout(std140) uniform Lights {
+	ivec4 lightcount; // x is the number of lights
+	Light lights[maxLights];
+};
+
+// Adds up the diffuse and specular light that every light gives to a point (in view space)
+// Copy of the one in colorMaterial.frag (the canonical copy), change that one first and copy it here
+void accumulateLights(vec3 P, vec3 N, float shininess, out vec3 diffuseLight, out vec3 specularLight) {
+	diffuseLight = vec3(0.0);
+	specularLight = vec3(0.0);
+	vec3 V = normalize(-P);
+
+	for (int i = 0; i < min(lightcount.x, maxLights); i++) {
+		vec3 L = -lights[i].direction.xyz;
+		float attenuation = 1.0;
+
+		// The point and spot lights fade out at their range. Attenuation formula from:
+		// http://gamedev.stackexchange.com/questions/56897/glsl-light-attenuation-color-and-intensity-formula
+		if (lights[i].position.w != 0.0) {
+			L = lights[i].position.xyz - P;
+			float range = lights[i].colour.a;
+			attenuation = clamp(1.0 - dot(L, L) / (range * range), 0.0, 1.0);
+			attenuation *= attenuation;
+		}
+		L = normalize(L);
+
+		// The spot lights fade out between the inner and the outer cone
+		if (lights[i].cone.z != 0.0) {
+			attenuation *= smoothstep(lights[i].cone.y, lights[i].cone.x, dot(-L, lights[i].direction.xyz));
+		}
+
+		// Diffuse and Phong specular reflection
+		vec3 R = reflect(-L, N);
+		diffuseLight += attenuation * max(dot(N, L), 0.0) * lights[i].colour.rgb;
+		specularLight += attenuation * pow(max(dot(R, V), 0.0), shininess) * lights[i].colour.rgb;
+	}
+}
+
+// Converts a value of the depth buffer to a distance from the camera
+float linearDepth(float depth) {
+	float z = depth * 2.0 - 1.0;
+	return 2.0 * near * far / (far + near - z * (far - near));
+}
+
+void main() {
+	vec3 N = normalize(worldNormal);
+	vec3 lightNormal = normalize(viewNormal);
+	vec3 V = normalize(-viewPosition);
+
+	// Looking from below the water flips the normal
+	if (dot(lightNormal, V) < 0.0) {
+		N = -N;
+		lightNormal = -lightNormal;
+	}
+
+	// Adds up the light of every light of the scene
+	vec3 diffuseLight, specularLight;
+	accumulateLights(viewPosition, lightNormal, shininess, diffuseLight, specularLight);
+	vec3 specular = specularLight * colorSpecular;
+
+	if (fallback == uint(1)) {
+		// Without textures it's a lit transparent surface
+		outputColor = vec4(tone.rgb * (0.3 + 0.7 * diffuseLight) + specular, tone.a);
+		return;
+	}
+
+	// Screen position of the fragment, bent by the waves
+	vec2 screen = (clipSpace.xy / clipSpace.w) * 0.5 + 0.5;
+	vec2 offset = N.xz * distortion;
+	vec2 reflectionCoords = clamp(screen + offset, 0.001, 0.999);
+	vec2 refractionCoords = clamp(screen + offset, 0.001, 0.999);
+
+	// Depth of the water below this fragment
+	float floorDistance = linearDepth(texture(depthmap, screen).r);
+	float waterDistance = linearDepth(gl_FragCoord.z);
+	float waterDepth = max(floorDistance - waterDistance, 0.0);
+
+	// Deep water hides the floor
+	float depthFactor = clamp(waterDepth / maxdepth, 0.0, 1.0);
+	vec3 waterColour = mix(tone.rgb, deepcolour.rgb, depthFactor);
+	vec3 refractionColour = mix(texture(refraction, refractionCoords).rgb, waterColour, depthFactor);
+	vec3 reflectionColour = texture(reflection, reflectionCoords).rgb;
+
+	// Looking down shows the refraction, looking along the surface shows the reflection
+	float fresnel = pow(1.0 - max(dot(N, V), 0.0), fresnelpower);
+	vec3 colour = mix(refractionColour, reflectionColour, fresnel);
+
+	// Soft edge where the water meets the shore
+	float alpha = clamp(waterDepth / shorefade, 0.0, 1.0);
+
+	outputColor = vec4(colour + specular * alpha, alpha);
+}
@@ -15,19 +15,65 @@ out vec4 fcolour;
 // These are the uniforms that are defined in the application
 uniform mat4 model, view, projection;
 uniform uint colourmode, emitmode;
-uniform vec4 lightpos;
+uniform vec4 clipplane; // Water plane for the reflection and refraction passes
 
 // Global constants (for this vertex shader)
 vec3 specular_albedo = vec3(1.0, 0.8, 0.6);
 vec3 global_ambient = vec3(0.1, 0.08, 0.08);
 int  shininess = 15;
 
+// Lights of the scene, in view space (the light manager fills the block)
+const int maxLights = 8;
+
+struct Light {
+	vec4 position;  // w is 0 for the directional lights
+	vec4 direction; // Direction the light shines to
+	vec4 colour;    // Colour times the intensity (a is the range)
+	vec4 cone;      // Cosines of the inner and outer half angles (z is 1 for the spot lights)
+};
+
+layout(std140) uniform Lights {
+	ivec4 lightcount; // x is the number of lights
+	Light lights[maxLights];
+};
+
+// Adds up the diffuse and specular light that every light gives to a point (in view space)
+void accumulateLights(vec3 P, vec3 N, float shininess, out vec3 diffuseLight, out vec3 specularLight) {
+	diffuseLight = vec3(0.0);
+	specularLight = vec3(0.0);
+	vec3 V = normalize(-P);
+
+	for (int i = 0; i < min(lightcount.x, maxLights); i++) {
+		vec3 L = -lights[i].direction.xyz;
+		float attenuation = 1.0;
+
+		// The point and spot lights fade out at their range. Attenuation formula from:
+		// http://gamedev.stackexchange.com/questions/56897/glsl-light-attenuation-color-and-intensity-formula
+		if (lights[i].position.w != 0.0) {
+			L = lights[i].position.xyz - P;
+			float range = lights[i].colour.a;
+			attenuation = clamp(1.0 - dot(L, L) / (range * range), 0.0, 1.0);
+			attenuation *= attenuation;
+		}
+		L = normalize(L);
+
+		// The spot lights fade out between the inner and the outer cone
+		if (lights[i].cone.z != 0.0) {
+			attenuation *= smoothstep(lights[i].cone.y, lights[i].cone.x, dot(-L, lights[i].direction.xyz));
+		}
+
+		// Diffuse and Phong specular reflection
+		vec3 R = reflect(-L, N);
+		diffuseLight += attenuation * max(dot(N, L), 0.0) * lights[i].colour.rgb;
+		specularLight += attenuation * pow(max(dot(R, V), 0.0), shininess) * lights[i].colour.rgb;
+	}
+}
+
 void main()
 {
 	vec3 emissive = vec3(0);				// Create a vec3(0, 0, 0) for our emmissive light
 	vec4 position_h = vec4(position, 1.0);	// Convert the (x,y,z) position to homogeneous coords (x,y,z,w)
 	vec4 diffuse_albedo;					// This is the vertex colour, used to handle the colourmode change
-	vec3 light_pos3 = lightpos.xyz;
 
 	// Switch the vertex colour based on the colourmode
 	if (colourmode == uint(1))
@@ -35,7 +81,7 @@ void main()
 	else
 		diffuse_albedo = vec4(1.0, 0, 0, 1.0);
 
-	vec3 ambient = diffuse_albedo.xyz * 2.0;
+	vec3 ambient = diffuse_albedo.xyz * 0.2;
 
 	// Define our vectors to calculate diffuse and specular lighting
 	mat4 mv_matrix = view * model;		            // Calculate the model-view transformation
@@ -43,29 +89,25 @@ void main()
 
 	vec4 P = mv_matrix * position_h;	            // Modify the vertex position (x, y, z, w) by the model-view transformation
 	vec3 N = normalize(normalmatrix * normal);		// Modify the normals by the normal-matrix (i.e. to model-view (or eye) coordinates )
-	vec3 L = light_pos3 - P.xyz;		            // Calculate the vector from the light position to the vertex in eye space
-	float distanceToLight = length(L);	            // For attenuation
-	L = normalize(L);					            // Normalise our light vector
-
-	// Calculate the diffuse component
-	vec3 diffuse = max(dot(N, L), 0.0) * diffuse_albedo.xyz;
 
-	// Calculate the specular component using Phong specular reflection
-	vec3 V = normalize(-P.xyz);
-	vec3 R = reflect(-L, N);
-	vec3 specular = pow(max(dot(R, V), 0.0), shininess) * specular_albedo;
+	// Adds up the diffuse and specular light of every light of the scene (already attenuated)
+	vec3 diffuse_light, specular_light;
+	accumulateLights(P.xyz, N, float(shininess), diffuse_light, specular_light);
 
-	// Calculate the attenuation factor;
-	float attenuation_k = 2.0;
-    float attenuation = 1.0 / (1.0 + attenuation_k * pow(distanceToLight, 2));
+	vec3 diffuse = diffuse_light * diffuse_albedo.xyz;
+	vec3 specular = specular_light * specular_albedo;
 
 	// If emitmode is 1 then we enable emmissive lighting
 	if (emitmode == uint(1)) emissive = vec3(0.994, 0.803, 0.019);
 
-	// Calculate the output colour, includung attenuation on the diffuse and specular components
-	// Note that you may want to exclude the ambient form the attenuation factor so objects
-	// are always visible, or include a global ambient
-	fcolour = vec4(attenuation*(ambient + diffuse + specular) + emissive + global_ambient, 1.0);
+	// Calculate the output colour (the ambient is not attenuated, so the objects are always visible)
+	fcolour = vec4(ambient + diffuse + specular + emissive + global_ambient, 1.0);
+
+	// The bright mode (used by the light gizmos) shows the colour of the vertex without lighting
+	if (emitmode == uint(0)) fcolour = vec4(colour.rgb, 1.0);
+
+	// Cuts what is on the other side of the water (only when GL_CLIP_DISTANCE0 is enabled)
+	gl_ClipDistance[0] = dot(model * position_h, clipplane);
 
 	gl_Position = (projection * view * model) * position_h;
 }
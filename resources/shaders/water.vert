@@ -0,0 +1,30 @@
+// Water vertex shader
+
+#version 330
+
+// These are the vertex attributes
+layout(location = 0) in vec3 position;
+layout(location = 1) in vec4 colour;
+layout(location = 2) in vec3 normal;
+
+// Uniform variables are passed in from the application
+uniform mat4 model, view, projection;
+
+// Outputs
+out vec4 clipSpace;
+out vec3 worldNormal;
+out vec3 viewPosition, viewNormal; // The lights are in view space
+
+void main() {
+	vec4 positionHomogeneus = vec4(position, 1.0);
+
+	// The reflection and refraction are read in screen space
+	vec4 world = model * positionHomogeneus;
+	clipSpace = projection * view * world;
+
+	worldNormal = normalize(transpose(inverse(mat3(model))) * normal);
+	viewPosition = (view * world).xyz;
+	viewNormal = normalize(transpose(inverse(mat3(view * model))) * normal);
+
+	gl_Position = clipSpace;
+}
@@ -1,10 +1,15 @@
 package main
 import (
+	"flag"
 	"fmt"
 	"runtime"
+	"strings"
 
 	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
     "github.com/yagocarballo/Go-GL-Assignment-2/models"
+	"github.com/yagocarballo/Go-GL-Assignment-2/collision"
+	"github.com/yagocarballo/Go-GL-Assignment-2/scene"
+	"github.com/yagocarballo/Go-GL-Assignment-2/input"
 
 	"github.com/go-gl/gl/all-core/gl"
 	"github.com/go-gl/glfw/v3.1/glfw"
@@ -20,21 +25,44 @@ const windowWidth = 1024
 const windowHeight = 768
 const windowFPS = 60
 
+// Updates per second of the animations (they don't depend on the frame rate)
+const updateRate = 60
+
+// Scene file with the models, lights and camera (F5 saves the current state to savedSceneFile)
+const sceneFile = "./resources/scenes/default.json"
+const savedSceneFile = "./resources/scenes/saved.json"
+
+// Bindings of the Input Actions (keys, mouse buttons and gamepad)
+const bindingsFile = "./resources/config/bindings.json"
+
+// Command line flags (the screenshot mode draws offscreen, writes one frame and exits)
+var screenshotFile = flag.String("screenshot", "", "renders the scene offscreen into this PNG file and exits")
+var screenshotFrames = flag.Int("frames", 1, "updates to run before taking the screenshot")
+var animationSeed = flag.Int64("seed", 0, "seed of the fish animation (0 uses the time, or 1 with -screenshot)")
+var recordEncoder = flag.String("encoder", "", "command that gets the recorded frames as PNG images on its standard input (Example: \"ffmpeg -y -f image2pipe -framerate 30 -i - clip.mp4\")")
+
+// Screenshots and recordings (F12 / F11), the screenshots are drawn twice as large and scaled down
+const capturesFolder = "./captures"
+const screenshotScale = 2
+const recordingFrameRate = 30
+
 // The Window Wrapper
 var glw *wrapper.Glw
 
 // Vertex array (Container) object.
 var vertexArrayObject uint32
 
-// Position and view globals
+// Movement speed of the selected model (10 is the normal speed)
 var speed float64 = 10
 
 // Animation progress
-var animationProgress float32 = 0
 var fishAnimationProgress []float32 = []float32{}
 
-// Aspect ratio of the window defined in the reshape callback
-var aspect_ratio float32
+// Draws the moving models between the last two updates
+var interpolation *models.Interpolation
+
+// Seconds until the frame stats on the title of the window are refreshed
+var titleRefresh float64
 
 // Shader Manager
 var shaderManager *wrapper.ShaderManager
@@ -42,12 +70,78 @@ var shaderManager *wrapper.ShaderManager
 // Selection
 var selected_model models.Model
 
+// Models that can be selected, and the actions that select them
+var selectable []models.Model
+var selectActions []string
+
+// Model followed by the follow camera and orbited by the orbit camera (the last selected model)
+var cameraTarget models.Model
+
+// Translates the keys, mouse buttons and gamepad into Input Actions
+var actionMap *input.ActionMap
+
+// Input Actions (the bindings come from the bindings file, the help is generated from them)
+var actionList = []input.Action{
+	{ Name: "quit", Group: "General", Description: "Releases the cursor, or closes the app" },
+	{ Name: "show-help", Group: "General", Description: "Prints the input bindings" },
+	{ Name: "regenerate-water", Group: "General", Description: "Regenerates the water waves with the next seed" },
+	{ Name: "reload-shaders", Group: "General", Description: "Reloads the shaders" },
+	{ Name: "save-scene", Group: "General", Description: "Saves the scene to " + savedSceneFile },
+
+	{ Name: "select-next", Group: "Selection", Description: "Selects the next model" },
+	{ Name: "select-previous", Group: "Selection", Description: "Selects the previous model" },
+	{ Name: "select-gopher", Group: "Selection", Description: "Selects the Gopher" },
+	{ Name: "select-gingerbread-house", Group: "Selection", Description: "Selects the Gingerbread House" },
+	{ Name: "select-dragon", Group: "Selection", Description: "Selects the Dragon" },
+	{ Name: "select-car", Group: "Selection", Description: "Selects the Car" },
+	{ Name: "select-wall", Group: "Selection", Description: "Selects the Wall" },
+	{ Name: "select-terrain", Group: "Selection", Description: "Selects the Terrain" },
+	{ Name: "select-camera", Group: "Selection", Description: "Selects the Camera" },
+	{ Name: "select-light", Group: "Selection", Description: "Selects the Light Point" },
+
+	{ Name: "move-up", Group: "Movement", Description: "Moves the selected model up" },
+	{ Name: "move-down", Group: "Movement", Description: "Moves the selected model down" },
+	{ Name: "move-left", Group: "Movement", Description: "Moves the selected model left" },
+	{ Name: "move-right", Group: "Movement", Description: "Moves the selected model right" },
+	{ Name: "move-back", Group: "Movement", Description: "Moves the selected model back" },
+	{ Name: "move-forward", Group: "Movement", Description: "Moves the selected model forward" },
+	{ Name: "speed-down", Group: "Movement", Description: "Slows down the movement" },
+	{ Name: "speed-up", Group: "Movement", Description: "Speeds up the movement" },
+
+	{ Name: "rotate-x+", Group: "Rotation", Description: "Rotates around x (the Camera looks up)" },
+	{ Name: "rotate-x-", Group: "Rotation", Description: "Rotates around x (the Camera looks down)" },
+	{ Name: "rotate-y+", Group: "Rotation", Description: "Rotates around y (the Camera looks left)" },
+	{ Name: "rotate-y-", Group: "Rotation", Description: "Rotates around y (the Camera looks right)" },
+	{ Name: "rotate-z+", Group: "Rotation", Description: "Rotates around z" },
+	{ Name: "rotate-z-", Group: "Rotation", Description: "Rotates around z" },
+	{ Name: "zoom-out", Group: "Rotation", Description: "Shrinks the selected model (the Camera zooms out)" },
+	{ Name: "zoom-in", Group: "Rotation", Description: "Grows the selected model (the Camera zooms in)" },
+
+	{ Name: "camera-fly", Group: "Camera", Description: "Switches to the Fly Camera" },
+	{ Name: "camera-orbit", Group: "Camera", Description: "Orbits around the last selected model" },
+	{ Name: "camera-follow", Group: "Camera", Description: "Follows the last selected model" },
+	{ Name: "camera-look", Group: "Camera", Description: "Turns the selected Camera while held (dragging the mouse)" },
+	{ Name: "capture-cursor", Group: "Camera", Description: "Captures the cursor to turn the selected Camera" },
+
+	{ Name: "screenshot", Group: "Capture", Description: "Saves a screenshot to " + capturesFolder },
+	{ Name: "toggle-recording", Group: "Capture", Description: "Starts or stops recording the frames to " + capturesFolder },
+
+	{ Name: "toggle-color-mode", Group: "Display", Description: "Switches between solid and per side colours" },
+	{ Name: "cycle-draw-mode", Group: "Display", Description: "Cycles the selected model between points, lines and polygons" },
+}
+
+// The Scene built from the Scene File
+var activeScene				*scene.Scene
+
 // Models
 var view					*models.Camera
-var lightPoint				*models.Sphere
+var lightPoint				*models.Light
 
 var terrain 				*models.Terrain
-var water 					*models.Terrain
+var terrainShape			*collision.Heightfield
+var water 					*models.Water
+var waterPass				*models.WaterPass
+var waterSeed				int64 // Seed of the waves, the regenerate-water action moves it to the next one
 var gopher 					*models.WavefrontObject
 var gingerbreadHouse        *models.WavefrontObject
 var dragon       		 	*models.WavefrontObject
@@ -68,6 +162,7 @@ var shaderList = []string{
 	"bumpMapMaterial",
 	"terrain",
 	"colorMaterial",
+	"water",
 }
 
 
@@ -99,26 +194,41 @@ func main() {
 		defer profile.Start(&cfg).Stop()
 	}
 
+	flag.Parse()
+
 	// Creates the Window Wrapper
 	glw = wrapper.NewWrapper(windowWidth, windowHeight, "Lab4")
 	glw.SetFPS(windowFPS)
+	glw.SetUpdateRate(updateRate)
+	glw.SetHeadless(*screenshotFile != "")
 
 	// Creates the Window
 	glw.CreateWindow()
 
 	// Sets the Event Callbacks
+	glw.SetUpdateCallback(updateLoop)
 	glw.SetRenderCallback(drawLoop)
 	glw.SetKeyCallBack(keyCallback)
 	glw.SetReshapeCallback(reshape)
+	glw.AddMouseButtonListener(mouseButtonListener)
+	glw.AddScrollListener(scrollListener)
+	glw.AddGamepadListener(gamepadListener)
+	glw.AddGamepadButtonListener(gamepadButtonListener)
 
 	// Initializes the App
 	InitApp(glw)
 
+	// Renders the Screenshot and exits (without the keyboard instructions)
+	if glw.IsHeadless() {
+		renderScreenshot(*screenshotFile, *screenshotFrames)
+		return
+	}
+
 	// Prints the Keyboard Instructions
 	printKeyboardMappings()
 
 	// Sets the Viewport (Important !!, this has to run after the loop!!)
-	defer gl.Viewport(0, 0, windowWidth, windowHeight)
+	defer glw.Device.Viewport(0, 0, windowWidth, windowHeight)
 
 	// Starts the Rendering Loop
 	glw.StartLoop()
@@ -130,7 +240,7 @@ func main() {
 //
 func InitShaders () {
 	// Creates the Shader Program
-	shaderManager = wrapper.NewShaderManager()
+	shaderManager = wrapper.NewShaderManager(glw.Device)
 
 	// Loads In the list of shaders
 	for _, shaderName := range shaderList {
@@ -151,11 +261,11 @@ func InitShaders () {
 		shaderManager.CreateUniform(name, "model")
 		shaderManager.CreateUniform(name, "view")
 		shaderManager.CreateUniform(name, "projection")
-		shaderManager.CreateUniform(name, "lightpos")
 
 		shaderManager.CreateUniform(name, "colourmode")
 		shaderManager.CreateUniform(name, "emitmode")
 		shaderManager.CreateUniform(name, "tone")
+		shaderManager.CreateUniform(name, "clipplane")
 	}
 }
 
@@ -167,95 +277,79 @@ func InitShaders () {
 //
 func InitApp(glw *wrapper.Glw) {
 	/* Set the object transformation controls to their initial values */
-	aspect_ratio = 1.3333
 	colorMode = models.COLOR_SOLID
 	emitMode = models.EMIT_COLORED
 
 	// Generate index (name) for one vertex array object
-	gl.GenVertexArrays(1, &vertexArrayObject);
+	vertexArrayObject = glw.Device.GenVertexArray()
 
 	// Create the vertex array object and make it current
-	gl.BindVertexArray(vertexArrayObject);
+	glw.Device.BindVertexArray(vertexArrayObject)
 
 	InitShaders();
 
-	// Create the Camera / View
-	view = models.NewCamera("View", mgl32.LookAtV(
-		mgl32.Vec3{0, -20, 6},	// Camera is at (0,0,4), in World Space
-		mgl32.Vec3{0, 0, 0},	// and looks at the origin
-		mgl32.Vec3{0, 1, 0},	// Head is up (set to 0,-1,0 to look upside-down)
-	))
-
-    // Creates the Lightpoint indicator
-    lightPoint = models.NewSphere(
-		"Light Point", 	// Name
-		20, 			// Latitude
-		20, 			// Longitude
-		shaderManager,	// A pointer to the ShaderManager
-	)
-    lightPoint.MakeSphereVBO() // Creates the Light Point Buffer Object
-
-	// Creates the Terrain
-	terrain = models.NewTerrainWithSeed(
-//		time.Now().UnixNano(),
-		999999,
-		4.0,
-		5.0,
-		mgl32.Vec4{ 0.662, 0.405, 0.022, 1 },
-	)
-//	terrain.CreateTerrain(200, 200, 150.0, 150.0)
-	terrain.CreateTerrain(200, 200, 350.0, 350.0)
-//	terrain.CreateTerrain(300, 300, 150.0, 150.0)
-
-	// Creates the Water
-	water = models.NewTerrainWithSeed(
-		incSeed,
-		4.0,
-		50.0,
-		mgl32.Vec4{ 0, 0.618, 1, 0.9 },
-	)
-	water.CreateTerrain(250, 250, 350.0, 350.0)
-
-	// Creates the Gopher
-	gopher = models.NewObjectLoader()
-	gopher.LoadObject("./resources/models/gopher/gopher.obj")
-	gopher.CreateObject()
-
-	// Creates the Gingerbread House
-	gingerbreadHouse = models.NewObjectLoader()
-	gingerbreadHouse.LoadObject("./resources/models/gingebreadHouse/gingebreadHouse.obj")
-	gingerbreadHouse.CreateObject()
-
-	// Creates the Dragon
-	dragon = models.NewObjectLoader()
-	dragon.LoadObject("./resources/models/dragon/dragon.obj")
-	dragon.CreateObject()
-
-	// Creates the Tree
-	wall = models.NewObjectLoader()
-	wall.LoadObject("./resources/models/wall/wall.obj")
-	wall.CreateObject()
-
-
-	// Creates the Car
-	car = models.NewObjectLoader()
-	car.LoadObject("./resources/models/car/car.obj")
-	car.CreateObject()
-
-	// Creates Sea Creatures
-	seed := rand.NewSource(time.Now().UnixNano())
+	// Defines the Input Actions and loads their Bindings
+	actionMap = input.NewActionMap()
+	for _, action := range actionList {
+		actionMap.Define(action.Name, action.Group, action.Description)
+	}
+	if err := actionMap.LoadBindings(bindingsFile); err != nil {
+		log.Fatalf("Could not load the bindings '%s':\n%s", bindingsFile, err)
+	}
+
+	// Builds the Camera, Light, Terrain, Water and Objects from the Scene File
+	var err error
+	activeScene, err = scene.Load(sceneFile, shaderManager)
+	if err != nil {
+		log.Fatalf("Could not load the scene '%s':\n%s", sceneFile, err)
+	}
+
+	view = activeScene.View
+	lightPoint = activeScene.Light("Light Point")
+	terrain = activeScene.Terrain("Terrain")
+	water = activeScene.Water
+	if lightPoint == nil || terrain == nil || water == nil {
+		log.Fatalf("The scene '%s' needs a 'Light Point' light, a 'Terrain' terrain and water", sceneFile)
+	}
+	terrainShape = terrain.CollisionShape()
+
+	// The Camera starts as a Fly Camera (it only gets the input while it's selected)
+	framebufferWidth, framebufferHeight := glw.GetFramebufferSize()
+	view.SetViewport(framebufferWidth, framebufferHeight)
+	view.SetController(models.NewFlyController())
+
+	// Creates the Reflection and Refraction of the Water
+	waterPass = models.NewWaterPass(glw.Device, water, int32(framebufferWidth), int32(framebufferHeight), view.Near, view.Far)
+
+	// Picks the Models that can be selected with the keyboard
+	gopher = sceneObject("Gopher")
+	gingerbreadHouse = sceneObject("Gingerbread House")
+	dragon = sceneObject("Dragon")
+	wall = sceneObject("Wall")
+	car = sceneObject("Car")
+	cameraTarget = gopher
+
+	selectable = []models.Model{ gopher, gingerbreadHouse, dragon, car, wall, terrain, view, lightPoint }
+	selectActions = []string{ "select-gopher", "select-gingerbread-house", "select-dragon", "select-car", "select-wall", "select-terrain", "select-camera", "select-light" }
+
+	// Sea Creatures (alternates the clown fish and the fish, so they swim in opposite directions)
+	clownFish, fish := activeScene.Object("Clown Fish"), activeScene.Object("Fish")
+	// The screenshots always use the same seed, so they can be compared
+	seedValue := *animationSeed
+	if seedValue == 0 && glw.IsHeadless() {
+		seedValue = 1
+	} else if seedValue == 0 {
+		seedValue = time.Now().UnixNano()
+	}
+	seed := rand.NewSource(seedValue)
 	random := rand.New(seed)
-	for i:=0; i<30; i++ {
-		creature := models.NewObjectLoader()
-		if (i % 2) == 0 {
-			creature.LoadObject("./resources/models/clownFish/clownFish.obj")
-		} else {
-			creature.LoadObject("./resources/models/fish/fish.obj")
+	for i := 0; i < len(clownFish) || i < len(fish); i++ {
+		for _, creatures := range [][]*models.WavefrontObject{ clownFish, fish } {
+			if i < len(creatures) {
+				seaCreatures = append(seaCreatures, creatures[i])
+				fishAnimationProgress = append(fishAnimationProgress, (random.Float32() * 10.0))
+			}
 		}
-
-		creature.CreateObject()
-		seaCreatures = append(seaCreatures, creature)
-		fishAnimationProgress = append(fishAnimationProgress, (random.Float32() * 10.0))
 	}
 
 	// Applies Initial Transforms to the Models
@@ -265,7 +359,19 @@ func InitApp(glw *wrapper.Glw) {
 	selected_model = lightPoint
 
 	// Changes the Title of the Window to display the Selected Model
-	glw.Window.SetTitle(fmt.Sprintf("Selected Model --> %s", selected_model.GetName()))
+	updateTitle()
+
+	// The Models that move on the updates are drawn between the last two updates
+	nodes := []*models.SceneNode{ view.Node }
+	for _, model := range selectable {
+		if model != view {
+			nodes = append(nodes, model.GetNode())
+		}
+	}
+	for _, creature := range seaCreatures {
+		nodes = append(nodes, creature.Node)
+	}
+	interpolation = models.NewInterpolation(nodes...)
 }
 
 //
@@ -273,105 +379,82 @@ func InitApp(glw *wrapper.Glw) {
 // Applies the Initial transforms for the Models
 //
 func InitialModelTransforms () {
-	// Define the model transformations for the LightPoint
-	lightPoint.ResetModel()
-	lightPoint.Translate(-10.0, -15.0, -20.0)
-	lightPoint.Scale(1, 1, 1)
-
-	// Apply rotations to the view position
-	view.Rotate(0.7, mgl32.Vec3{1, 0, 0}) //rotating in clockwise direction around x-axis
-	view.Rotate(0, mgl32.Vec3{0, 1, 0}) //rotating in clockwise direction around y-axis
-	view.Rotate(0, mgl32.Vec3{0, 0, 1})
-	view.Scale(0.7, 0.7, 0.7)
-
-	// Apply the initial terrain Position
-	terrain.ResetModel()
-	terrain.Translate(0.0, 10.0, -20.0)
-	terrain.Scale(0.5, 0.5, 0.5)
-	terrain.Rotate(180, mgl32.Vec3{0, 1, 0})
-
-	// Apply the initial terrain Position
-	water.ResetModel()
-	water.Translate(0.0, 10.0, -20.0)
-	water.Scale(0.5, 0.5, 0.5)
-	water.Rotate(180, mgl32.Vec3{0, 1, 0})
-
-	// Apply the initial Gopher Position
-	gopher.ResetModel()
-	gopher.Translate(0.0, 1.8, -30.0)
-	gopher.Scale(3.0, 3.0, 3.0)
-	gopher.Rotate(1.7, mgl32.Vec3{1, 0, 0})
-	gopher.Rotate(1.5, mgl32.Vec3{0, 1, 0})
-	gopher.Rotate(1.5, mgl32.Vec3{0, 0, 1})
-
-	// Moves the House
-	gingerbreadHouse.ResetModel()
-	gingerbreadHouse.Translate(10.5, 1.0, 20.0)
-	gingerbreadHouse.Scale(3.0, 3.0, 3.0)
-	gingerbreadHouse.Rotate(3.5, mgl32.Vec3{1, 0, 0})
-	gingerbreadHouse.Rotate(-0.15, mgl32.Vec3{0, 0, 1})
-
-	seed := rand.NewSource(time.Now().UnixNano())
-	random := rand.New(seed)
-	for _, creature := range seaCreatures {
-		creature.ResetModel()
-		creature.Translate((random.Float32() * 50.0), 12, (random.Float32() * 50.0))
-		creature.Scale(0.2, 0.2, 0.2)
-		creature.Rotate(180, mgl32.Vec3{1, 0, 0})
-	}
-
-	// Moves the dragon
-	dragon.ResetModel()
-	dragon.Translate(-18.0, -3.0, -30.0)
-	dragon.Scale(3.0, 3.0, 3.0)
-	dragon.Rotate(1.7, mgl32.Vec3{1, 0, 0})
-	dragon.Rotate(1.5, mgl32.Vec3{0, 1, 0})
-	dragon.Rotate(1.5, mgl32.Vec3{0, 0, 1})
-
-	// Moves the tree
-	wall.ResetModel()
-	wall.Translate(-5.0, 4.0, 0.0)
-//	tree.Scale(3.0, 3.0, 3.0)
-	wall.Rotate(1.7, mgl32.Vec3{1, 0, 0})
-	wall.Rotate(1.5, mgl32.Vec3{0, 1, 0})
-	wall.Rotate(1.5, mgl32.Vec3{0, 0, 1})
-
-	// Moves the car
-	car.ResetModel()
-	car.Translate(0.0, 6.0, -10.0)
-	car.Scale(3.0, 3.0, 3.0)
-	car.Rotate(1.5, mgl32.Vec3{1, 0, 0})
-	car.Rotate(1.5, mgl32.Vec3{0, 1, 0})
-	car.Rotate(1.5, mgl32.Vec3{0, 0, 1})
+	activeScene.ApplyTransforms()
+}
+
+//
+// sceneObject
+// Finds the first instance of an object of the scene (it stops the app if it's missing)
+//
+// @param name (string) the name of the object
+//
+// @return object (*models.WavefrontObject) the object
+//
+func sceneObject (name string) *models.WavefrontObject {
+	instances := activeScene.Object(name)
+	if len(instances) == 0 {
+		log.Fatalf("The scene '%s' has no object called '%s'", sceneFile, name)
+	}
+
+	return instances[0]
 }
 
 /////////////////////////////////////////////////////////////////////////////////////
 ///////////////////////////////////// Callbacks /////////////////////////////////////
 /////////////////////////////////////////////////////////////////////////////////////
 
+//
+// Update Loop Function
+// This function gets called a fixed number of times per second (it moves the models).
+//
+// @param glw (*wrapper.Glw) the window wrapper
+// @param dt (float64) the time step in seconds
+//
+func updateLoop(glw *wrapper.Glw, dt float64) {
+	// Remembers where the Models were, to draw the frames in between
+	interpolation.Store()
+
+	// Applies the Animations
+	applyAnimations(float32(dt))
+}
+
 //
 // Draw Loop Function
-// This function gets called on every update.
+// This function gets called on every frame.
+//
+// @param glw (*wrapper.Glw) the window wrapper
+// @param alpha (float64) how far the frame is from the last update to the next one (0 to 1)
 //
-func drawLoop(glw *wrapper.Glw, delta float64) {
+func drawLoop(glw *wrapper.Glw, alpha float64) {
 	// Sets the Clear Color (Background Color)
-	gl.ClearColor(0.028, 0.156, 0.348, 1)
+	glw.Device.ClearColor(0.028, 0.156, 0.348, 1)
 
 	// Clears the Window
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	glw.Device.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
 	// Enables Depth
-	gl.Enable(gl.DEPTH_TEST)
+	glw.Device.Enable(gl.DEPTH_TEST)
 
-	// Applies the Animations
-	applyAnimations(delta)
+	// Turns the selected Camera with the cursor movement of the frame (while dragging or captured)
+	if selected_model == view && view.Controller != nil && (actionMap.IsHeld("camera-look") || glw.IsCursorCaptured()) {
+		view.Controller.Look(float32(glw.Mouse.DeltaX), float32(glw.Mouse.DeltaY))
+	}
 
-	// Fov / Aspect / Near / Far
-	// Projection matrix : 30° Field of View, 4:3 ratio, display range : 0.1 unit <-> 100 units
-	var Projection mgl32.Mat4 = mgl32.Perspective(30.0, aspect_ratio, 0.1, 100.0)
+	// Shows the frame stats about once a second
+	titleRefresh -= glw.GetStats().FrameTime()
+	if titleRefresh <= 0 {
+		titleRefresh = 1
+		updateTitle()
+	}
+
+	// Moves the Models between the last two updates (and back after drawing)
+	interpolation.Apply(float32(alpha))
+	defer interpolation.Restore()
 
-    // Define the light position and transform by the view matrix
-	var lightpos mgl32.Vec4 = view.Model.Mul4x1(mgl32.Vec4{lightPoint.Position.X(), lightPoint.Position.Y(), lightPoint.Position.Z(), 1.0})
+	// The Projection comes from the lens of the Camera (Fov / Aspect / Near / Far)
+	var Projection mgl32.Mat4 = view.Projection()
+	var View mgl32.Mat4 = view.View()
+	waterPass.Near, waterPass.Far = view.Near, view.Far
 
 	for name, _ := range shaderManager.Shaders {
 		// Sets the Shader program to Use
@@ -380,94 +463,75 @@ func drawLoop(glw *wrapper.Glw, delta float64) {
 		// Send our uniforms variables to the shader
 		shaderManager.SetUniform1ui(name, "colourmode", uint32(colorMode))
 		shaderManager.SetUniform1ui(name, "emitmode", emitMode.AsUint32())
-		shaderManager.SetUniformMatrix4fv(name, "view", 1, false, &view.Model[0])
-		shaderManager.SetUniformMatrix4fv(name, "projection", 1, false, &Projection[0])
-		shaderManager.SetUniform4f(name, "lightpos", lightpos.X(), lightpos.Y(), lightpos.Z(), lightpos.W())
-	}
-
-	// Sets the Shader program to Use
-	for name, _ := range shaderManager.Shaders {
-		// Sets the Shader program to Use
-		shaderManager.EnableShader(name)
-		shaderManager.SetUniform4f(name, "tone", terrain.ColorTone.X(), terrain.ColorTone.Y(), terrain.ColorTone.Z(), terrain.ColorTone.W())
-	}
-	shaderManager.EnableShader("terrain")
-	terrain.DrawObject(shaderManager.Shaders["terrain"].Shader)
-
-	// Sets the Shader program to Use
-	for name, _ := range shaderManager.Shaders {
-		// Sets the Shader program to Use
-		shaderManager.EnableShader(name)
-		shaderManager.SetUniform4f(name, "tone", water.ColorTone.X(), water.ColorTone.Y(), water.ColorTone.Z(), water.ColorTone.W())
-	}
-
-	shaderManager.EnableShader("bumpMapMaterial")
-	gingerbreadHouse.DrawObject(shaderManager.CurrentShader())
-	wall.DrawObject(shaderManager.CurrentShader())
-
-	for _, creature := range seaCreatures {
-		creature.DrawObject(shaderManager.CurrentShader())
+		shaderManager.SetUniformMatrix4fv(name, "projection", 1, false, Projection[:])
 	}
 
-	shaderManager.EnableShader("textureMaterial")
-	dragon.DrawObject(shaderManager.CurrentShader())
-
-	shaderManager.EnableShader("colorMaterial")
-	gopher.DrawObject(shaderManager.CurrentShader())
-	car.DrawObject(shaderManager.CurrentShader())
-
-	// Enables Transparencies
-	gl.Enable(gl.BLEND)
-//		gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
-	//	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-
+	// Renders the Reflection and Refraction of the Water
+	waterPass.Render(View, drawScene)
 
-	gl.BlendFunc(gl.SRC_COLOR, gl.ONE)
+	// Draws the Scene from the Camera
+	drawScene(View, mgl32.Vec4{ 0, 0, 0, 0 })
 
-	shaderManager.EnableShader("terrain")
-	water.DrawObject(shaderManager.CurrentShader())
+	// Draws the Water (it blends with what is behind it)
+	shaderManager.EnableShader("water")
+	waterPass.DrawObject(shaderManager.CurrentShader())
 
-	// Disables transparencies
-	gl.Disable(gl.BLEND)
-
-
-    // Draw our light Position sphere
+    // Draw the gizmos of the lights
     emitMode = models.EMIT_BRIGHT
 	shaderManager.EnableShader("basic")
 	shaderManager.SetUniform1ui(shaderManager.ActiveShader, "emitmode", emitMode.AsUint32())
 
-    lightPoint.Draw() // Draws the Light Point
+    activeScene.Lights.Draw() // Draws the Light Point and the other lights
 
-	gl.DisableVertexAttribArray(0);
+	glw.Device.DisableVertexAttribArray(0)
 	shaderManager.DisableShader()
     emitMode = models.EMIT_COLORED
 }
 
-var incSeed int64
+//
+// drawScene
+// Draws everything but the water and the light gizmos (also used for the water reflection and refraction).
+//
+// @param viewMatrix (mgl32.Mat4) the view matrix
+// @param clipPlane (mgl32.Vec4) the clip plane (only used when GL_CLIP_DISTANCE0 is enabled)
+//
+func drawScene(viewMatrix mgl32.Mat4, clipPlane mgl32.Vec4) {
+    // Sends the lights to the shaders in the space of this view
+	activeScene.Lights.Upload(viewMatrix)
+
+	for name, _ := range shaderManager.Shaders {
+		// Sets the Shader program to Use
+		shaderManager.EnableShader(name)
+
+		// Send our uniforms variables to the shader
+		shaderManager.SetUniformMatrix4fv(name, "view", 1, false, viewMatrix[:])
+		shaderManager.SetUniform4f(name, "clipplane", clipPlane.X(), clipPlane.Y(), clipPlane.Z(), clipPlane.W())
+	}
+
+	// Draws the Terrains and the Objects with the shaders of the Scene File
+	activeScene.Draw(shaderManager)
+}
 
 //
 // applyAnimations
-// Applies animations (called once every loop)
-//
-// @param delta (float64) delta time of the update
-//
-func applyAnimations (delta float64) {
-	animationProgress += float32(delta) * float32(1000)
-	if animationProgress > 10 {
-		model := water.Model
-		water = models.NewTerrainWithSeed(
-			incSeed,
-			4.0,
-			50.0,
-			mgl32.Vec4{ 0, 0.618, 1, 0.9 },
-		)
-		water.CreateTerrain(250, 250, 350.0, 350.0)
-		water.Model = model
-		animationProgress = 0.0
-		incSeed++
-	}
+// Applies animations (called once every update)
+//
+// @param seconds (float32) the time step in seconds
+//
+func applyAnimations (seconds float32) {
+	// Moves the waves
+	water.Update(seconds)
+
+	// Moves the selected Model or Camera with the gamepad axes
+	applyGamepadAxes(seconds)
 
-	incStep := (float32(delta) * 20.0)
+	// Moves the Camera with its Controller
+	view.Update(seconds)
+
+	incStep := seconds * 1.2
+
+	// The terrain can be moved with the keyboard
+	terrainShape.Model = terrain.Node.World()
 
 	// Animate the Planets
 	for index, creature := range seaCreatures {
@@ -484,16 +548,20 @@ func applyAnimations (delta float64) {
 		step := (360.0 / 1000.0) * float64(len(seaCreatures) - index) * float64(fishAnimationProgress[index])
 
 		// Calculates the Next position
-		var x float32 = (float32(index) * 3.0) * float32(math.Cos(step * float64(models.DEG_TO_RADIANS)))
-		var y float32 = 12
+		var x float32 = -(float32(index) * 3.0) * float32(math.Cos(step * float64(models.DEG_TO_RADIANS)))
+		var y float32 = -12
 		var z float32 = (float32(index) * 3.0) * float32(math.Sin(step * float64(models.DEG_TO_RADIANS)))
 
+		// Keeps the fish above the ground
+		position, _ := collision.Resolve(terrainShape, collision.Sphere{ Center: mgl32.Vec3{ x, y, z }, Radius: 1.0 })
+
 		// Resets model and applies transformations
 		creature.ResetModel()
-		creature.Translate(x, y, z)
+		creature.Translate(position.X(), position.Y(), position.Z())
+		creature.RotateDegrees(180, mgl32.Vec3{0, 0, 1}) // The turn of the scene (the original one was upside down)
 		creature.Scale(0.2, 0.2, 0.2)
-		creature.Rotate(180, mgl32.Vec3{1, 0, 0})
-		creature.Rotate(-90, mgl32.Vec3{0, 0, pair})
+		creature.RotateRadians(180, mgl32.Vec3{1, 0, 0}) // Radians, like the original animation
+		creature.RotateRadians(-90, mgl32.Vec3{0, 0, pair})
 	}
 }
 
@@ -508,177 +576,470 @@ func applyAnimations (delta float64) {
 // @param mods (glfw.ModifierKey) the pressed modified keys.
 //
 func keyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-	var keySpeed float32 = 0.05
-	var moveSpeed float32 = 0.5
-	var position mgl32.Vec4 = mgl32.Vec4{0, 0, 0, 0}
-	var rotation mgl32.Vec4 = mgl32.Vec4{0, 0, 0, 0}
-	var zoom float32 = 0.0
+	if event, ok := actionMap.Key(key, action); ok {
+		handleAction(event)
+	}
+}
 
-	// Increases the Speed of the Light Point
-	if selected_model.GetName() != "Terrain" && selected_model.GetName() != "View" {
-		keySpeed = 0.5
+//
+// mouseButtonListener
+// This function gets called when a mouse button is pressed
+//
+// @param glw (*wrapper.Glw) the window wrapper
+// @param button (glfw.MouseButton) the button
+// @param action (glfw.Action) the state of the button
+// @param mods (glfw.ModifierKey) the pressed modified keys.
+//
+func mouseButtonListener(glw *wrapper.Glw, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	if event, ok := actionMap.MouseButton(button, action); ok {
+		handleAction(event)
 	}
+}
+
+//
+// scrollListener
+// Zooms the selected Camera
+//
+// @param glw (*wrapper.Glw) the window wrapper
+// @param xoff (float64) the horizontal scroll
+// @param yoff (float64) the vertical scroll
+//
+func scrollListener(glw *wrapper.Glw, xoff, yoff float64) {
+	if selected_model == view && view.Controller != nil {
+		view.Controller.Zoom(float32(yoff))
+	}
+}
+
+//
+// gamepadListener
+// This function gets called when a gamepad is connected or disconnected
+//
+// @param glw (*wrapper.Glw) the window wrapper
+// @param gamepad (*wrapper.Gamepad) the gamepad
+// @param connected (bool) true if it was connected
+//
+func gamepadListener(glw *wrapper.Glw, gamepad *wrapper.Gamepad, connected bool) {
+	if connected {
+		fmt.Printf("Gamepad connected: %s (%d axes, %d buttons) \n", gamepad.Name, len(glfw.GetJoystickAxes(gamepad.Joystick)), len(glfw.GetJoystickButtons(gamepad.Joystick)))
+	} else {
+		fmt.Printf("Gamepad disconnected: %s \n", gamepad.Name)
+	}
+}
 
-	switch key {
-	// If the Key Excape is pressed, it closes the App
-	case glfw.KeyEscape:
-		if action == glfw.Press {
-			window.SetShouldClose(true)
+//
+// gamepadButtonListener
+// This function gets called when a gamepad button is pressed
+//
+// @param glw (*wrapper.Glw) the window wrapper
+// @param gamepad (*wrapper.Gamepad) the gamepad
+// @param button (int) the index of the button
+// @param action (glfw.Action) the state of the button
+//
+func gamepadButtonListener(glw *wrapper.Glw, gamepad *wrapper.Gamepad, button int, action glfw.Action) {
+	if event, ok := actionMap.GamepadButton(button, action); ok {
+		handleAction(event)
+	}
+}
+
+//
+// applyGamepadAxes
+// Moves, rotates and scales the selected model (or the Camera) with the gamepad axes,
+// continuously and scaled by the elapsed time
+//
+// @param seconds (float32) the elapsed time in seconds
+//
+func applyGamepadAxes(seconds float32) {
+	var moveRate float32 = 10.0 * float32(speed / 10.0) // Units per second
+	var rotateRate float32 = 2.0                         // Radians per second
+	var zoomRate float32 = 1.0                           // Scale change per second
+	var lookRate float32 = 600.0                         // Pixels of cursor movement per second (for the Camera)
+	var zoomSteps float32 = 5.0                          // Scroll steps per second (for the Camera)
+
+	// Uses the axis pushed the furthest when there are several gamepads
+	actionMap.ResetAxes()
+	axes := []float32{}
+	for _, gamepad := range glw.GetGamepads() {
+		for axis, value := range gamepad.Axes {
+			if axis >= len(axes) {
+				axes = append(axes, 0)
+			}
+			if math.Abs(float64(value)) > math.Abs(float64(axes[axis])) {
+				axes[axis] = value
+			}
 		}
+	}
+	for axis, value := range axes {
+		actionMap.GamepadAxis(axis, value)
+	}
+
+	axis := func(positive, negative string) float32 {
+		return actionMap.AxisValue(positive) - actionMap.AxisValue(negative)
+	}
+	move := mgl32.Vec3{ axis("move-right", "move-left"), axis("move-up", "move-down"), axis("move-back", "move-forward") }
+	turn := mgl32.Vec3{ axis("rotate-x+", "rotate-x-"), axis("rotate-y+", "rotate-y-"), axis("rotate-z+", "rotate-z-") }
+	zoom := axis("zoom-in", "zoom-out")
+
+	// The Camera gets the axes through its Controller
+	if selected_model == view {
+		if view.Controller != nil {
+			view.Controller.Steer(move)
+			view.Controller.Look(-turn.Y() * lookRate * seconds, -turn.X() * lookRate * seconds)
+			view.Controller.Zoom(zoom * zoomSteps * seconds)
+		}
+		return
+	}
+
+	// Same directions as the keys
+	if move.Len() > 0 {
+		selected_model.Translate(move.X() * moveRate * seconds, move.Y() * moveRate * seconds, move.Z() * moveRate * seconds)
+	}
+	if turn.Len() > 0 {
+		selected_model.RotateRadians(turn.Len() * rotateRate * seconds, turn)
+	}
+	if zoom != 0 {
+		scaleAmount := 1.0 + zoom * zoomRate * seconds
+		selected_model.Scale(scaleAmount, scaleAmount, scaleAmount)
+	}
+}
+
+//
+// handleAction
+// Reacts to an Input Action
+//
+// @param event (input.Event) the action and the state of its input
+//
+func handleAction(event input.Event) {
+	// The selected Camera moves with its Controller
+	if selected_model == view && cameraAction(event) {
+		return
+	}
+
+	// The rest of the actions react when the input is pressed (the transformations also when it repeats)
+	if event.State == input.RELEASED {
+		return
+	}
+
+	if selected_model != view {
+		transformAction(event.Action)
+	}
+
+	if event.State != input.PRESSED {
+		return
+	}
 
 	// Changes the Selected Model
-	case glfw.Key1:
-		selected_model = gopher
+	for index, name := range selectActions {
+		if event.Action == name {
+			selectModel(selectable[index])
+			return
+		}
+	}
 
-	case glfw.Key2:
-		selected_model = gingerbreadHouse
+	switch event.Action {
+	// Releases the cursor, or closes the App
+	case "quit":
+		if glw.IsCursorCaptured() {
+			glw.SetCursorCaptured(false)
+		} else {
+			glw.GetWindow().SetShouldClose(true)
+		}
 
-	case glfw.Key3:
-		selected_model = dragon
+	case "select-next", "select-previous":
+		step := 1
+		if event.Action == "select-previous" {
+			step = len(selectable) - 1
+		}
+		for index, model := range selectable {
+			if model == selected_model {
+				selectModel(selectable[(index + step) % len(selectable)])
+				return
+			}
+		}
+		selectModel(selectable[0])
 
-	case glfw.Key4:
-		selected_model = car
+	// Speed Up / Down
+	case "speed-down":
+		if speed > 1 {
+			speed -= 1
+		}
+		fmt.Printf("Speed: %v \n", speed)
 
-	case glfw.Key5:
-		selected_model = wall
+	case "speed-up":
+		speed += 1
+		fmt.Printf("Speed: %v \n", speed)
 
-	case glfw.Key6:
-		selected_model = lightPoint
+	case "toggle-color-mode":
+		if colorMode == models.COLOR_PER_SIDE {
+			colorMode = models.COLOR_SOLID
+		} else {
+			colorMode = models.COLOR_PER_SIDE
+		}
+		fmt.Printf("Color Mode: %s \n", colorMode)
 
-	case glfw.Key7:
-		selected_model = lightPoint
+	// Cycle between drawing vertices, mesh and filled polygons
+	case "cycle-draw-mode":
+		selected_model.SetDrawMode(selected_model.GetDrawMode() + 1)
+		if selected_model.GetDrawMode() > models.DRAW_POLYGONS {
+			selected_model.SetDrawMode(models.DRAW_POINTS)
+		}
+		fmt.Printf("%s Draw Mode: %s \n", selected_model.GetName(), selected_model.GetDrawMode())
 
-	case glfw.Key8:
-		selected_model = terrain
+	// Prints the Input Bindings
+	case "show-help":
+		printKeyboardMappings()
 
-	case glfw.Key9:
-		selected_model = view
+	case "reload-shaders":
+		// Loads In the list of shaders
+		for _, shaderName := range shaderList {
+			var err error; err = shaderManager.LoadShader(
+				shaderName,
+				fmt.Sprintf("./resources/shaders/%s.vert", shaderName),
+				fmt.Sprintf("./resources/shaders/%s.frag", shaderName),
+			)
 
-	case glfw.Key0:
-		selected_model = lightPoint
+			// If there is any error loading the shaders, it panics
+			if err != nil {
+				log.Println(err)
+			}
+		}
 
-	// Applies Movement
-	case glfw.KeyQ:
-		position = mgl32.Vec4{0, 0, moveSpeed, 0}
+	case "regenerate-water":
+		waterSeed++
+		water.Waves = models.RandomWaves(waterSeed, len(water.Waves))
+		fmt.Printf("Water Seed: %d \n", waterSeed)
 
-	case glfw.KeyW:
-		position = mgl32.Vec4{0, -moveSpeed, 0, 0}
+	// Screenshots and Recordings
+	case "screenshot":
+		glw.Screenshot(fmt.Sprintf("%s/screenshot-%s.png", capturesFolder, time.Now().Format("20060102-150405")), screenshotScale)
 
-	case glfw.KeyE:
-		position = mgl32.Vec4{0, 0, -moveSpeed, 0}
+	case "toggle-recording":
+		toggleRecording()
 
-	case glfw.KeyA:
-		position = mgl32.Vec4{moveSpeed, 0, 0, 0}
+	// Camera Controllers
+	case "camera-fly":
+		view.SetController(models.NewFlyController())
+		fmt.Println("Camera: Fly")
+
+	case "camera-orbit":
+		view.SetController(models.NewOrbitController(view, cameraTarget.GetNode().WorldPosition()))
+		fmt.Printf("Camera: Orbit around %s \n", cameraTarget.GetName())
 
-	case glfw.KeyS:
+	case "camera-follow":
+		view.SetController(models.NewFollowController(cameraTarget))
+		fmt.Printf("Camera: Follow %s \n", cameraTarget.GetName())
+
+	case "capture-cursor":
+		if selected_model == view {
+			glw.SetCursorCaptured(!glw.IsCursorCaptured())
+		}
+
+	// Saves the current state of the Scene
+	case "save-scene":
+		if err := activeScene.Save(savedSceneFile); err != nil {
+			log.Println(err)
+		} else {
+			fmt.Printf("Scene saved to '%s' \n", savedSceneFile)
+		}
+	}
+}
+
+//
+// transformAction
+// Moves, rotates and scales the selected model
+//
+// @param action (string) the name of the action
+//
+func transformAction(action string) {
+	var keySpeed float32 = 0.05
+	var moveSpeed float32 = 0.5 * float32(speed / 10.0)
+	var position mgl32.Vec4 = mgl32.Vec4{0, 0, 0, 0}
+	var rotation mgl32.Vec4 = mgl32.Vec4{0, 0, 0, 0}
+	var zoom float32 = 0.0
+
+	// Increases the Speed of the Light Point
+	if selected_model.GetName() != "Terrain" && selected_model.GetName() != "View" {
+		keySpeed = 0.5
+	}
+
+	switch action {
+	// Applies Movement
+	case "move-back":
+		position = mgl32.Vec4{0, 0, moveSpeed, 0}
+
+	case "move-up":
 		position = mgl32.Vec4{0, moveSpeed, 0, 0}
 
-	case glfw.KeyD:
+	case "move-forward":
+		position = mgl32.Vec4{0, 0, -moveSpeed, 0}
+
+	case "move-left":
 		position = mgl32.Vec4{-moveSpeed, 0, 0, 0}
 
-	case glfw.KeyTab:
-//		position = mgl32.Vec4{0, 0, 0, speed}
+	case "move-down":
+		position = mgl32.Vec4{0, -moveSpeed, 0, 0}
 
-	case glfw.KeyR:
-//		position = mgl32.Vec4{0, 0, 0, -speed}
+	case "move-right":
+		position = mgl32.Vec4{moveSpeed, 0, 0, 0}
 
 	// Rotates
-	case glfw.KeyI:
+	case "rotate-x+":
 		rotation = mgl32.Vec4{1, 0, 0, 0}
 
-	case glfw.KeyK:
+	case "rotate-x-":
 		rotation = mgl32.Vec4{-1, 0, 0, 0}
 
-	case glfw.KeyJ:
+	case "rotate-y+":
 		rotation = mgl32.Vec4{0, 1, 0, 0}
 
-	case glfw.KeyL:
+	case "rotate-y-":
 		rotation = mgl32.Vec4{0, -1, 0, 0}
 
-	case glfw.KeyU:
+	case "rotate-z+":
 		rotation = mgl32.Vec4{0, 0, 1, 0}
 
-	case glfw.KeyO:
+	case "rotate-z-":
 		rotation = mgl32.Vec4{0, 0, -1, 0}
 
-	case glfw.KeyY:
-//		rotation = mgl32.Vec4{0, 0, 0, 1}
-
-	case glfw.KeyP:
-//		rotation = mgl32.Vec4{0, 0, 0, -1}
-
 	// Zooms In / Out
-	case glfw.KeyZ:
+	case "zoom-out":
 		zoom = -0.02
 
-	case glfw.KeyX:
+	case "zoom-in":
 		zoom = 0.02
+	}
 
-	// Speed Up / Down
-	case glfw.KeyC:
-		speed -= 1
+	// Applies the Transformations to the Selected Model
+	ApplyTransformations(selected_model, rotation, position, zoom, keySpeed)
+}
 
-	case glfw.KeyV:
-		speed += 1
+//
+// cameraAction
+// Sends the movement, rotation and zoom actions to the Camera Controller
+// (the movement is held, the rotation and zoom repeat)
+//
+// @param event (input.Event) the action and the state of its input
+//
+// @return handled (bool) true if the action was for the Camera Controller
+//
+func cameraAction(event input.Event) bool {
+	if view.Controller == nil {
+		return false
 	}
 
-	// Changes the Title of the Window to display the Selected Model
-	glw.Window.SetTitle(fmt.Sprintf("Selected Model --> %s", selected_model.GetName()))
+	var lookStep float32 = 10.0 // Pixels of cursor movement per key
+	var direction mgl32.Vec3
+	var look mgl32.Vec2
+	var zoom float32
+
+	switch event.Action {
+	// Movement along the axes of the Camera
+	case "move-up":
+		direction = mgl32.Vec3{0, 1, 0}
+	case "move-down":
+		direction = mgl32.Vec3{0, -1, 0}
+	case "move-left":
+		direction = mgl32.Vec3{-1, 0, 0}
+	case "move-right":
+		direction = mgl32.Vec3{1, 0, 0}
+	case "move-back":
+		direction = mgl32.Vec3{0, 0, 1}
+	case "move-forward":
+		direction = mgl32.Vec3{0, 0, -1}
+
+	// Rotation, as if the cursor moved
+	case "rotate-x+":
+		look = mgl32.Vec2{0, -lookStep}
+	case "rotate-x-":
+		look = mgl32.Vec2{0, lookStep}
+	case "rotate-y+":
+		look = mgl32.Vec2{-lookStep, 0}
+	case "rotate-y-":
+		look = mgl32.Vec2{lookStep, 0}
 
-	// Applies the Transformations to the Selected Model
-	ApplyTransformations(selected_model, rotation, position, zoom, keySpeed)
+	// Zooms In / Out
+	case "zoom-out":
+		zoom = -1
+	case "zoom-in":
+		zoom = 1
 
-	// React only if the key was just pressed
-	if action != glfw.Press {
-		return;
+	default:
+		return false
 	}
 
-	switch key {
-	case glfw.KeyM:
-		if colorMode == models.COLOR_PER_SIDE {
-			colorMode = models.COLOR_SOLID
-		} else {
-			colorMode = models.COLOR_PER_SIDE
+	if direction.Len() > 0 {
+		if event.State != input.REPEATED {
+			view.Controller.Move(direction, event.State == input.PRESSED)
 		}
-		fmt.Printf("Color Mode: %s \n", colorMode)
+	} else if event.State != input.RELEASED {
+		view.Controller.Look(look.X(), look.Y())
+		view.Controller.Zoom(zoom)
+	}
 
-	// Cycle between drawing vertices, mesh and filled polygons
-	case glfw.KeyN:
-		selected_model.SetDrawMode(selected_model.GetDrawMode() + 1)
-		if selected_model.GetDrawMode() > models.DRAW_POLYGONS {
-			selected_model.SetDrawMode(models.DRAW_POINTS)
-		}
-		fmt.Printf("%s Draw Mode: %s \n", selected_model.GetName(), selected_model.GetDrawMode())
+	return true
+}
 
-	// Prints the Keyboard Mappings
-	case glfw.KeyB:
-		printKeyboardMappings()
+//
+// selectModel
+// Changes the Selected Model (and displays its name on the title of the window)
+//
+// @param model (models.Model) the model
+//
+func selectModel(model models.Model) {
+	selected_model = model
+	updateTitle()
 
-	case glfw.KeyEnter:
-		// Loads In the list of shaders
-		for _, shaderName := range shaderList {
-			var err error; err = shaderManager.LoadShader(
-				shaderName,
-				fmt.Sprintf("./resources/shaders/%s.vert", shaderName),
-				fmt.Sprintf("./resources/shaders/%s.frag", shaderName),
-			)
+	if model == view {
+		return
+	}
 
-			// If there is any error loading the shaders, it panics
-			if err != nil {
-				log.Println(err)
-			}
+	// Remembers the Model for the Follow and Orbit Cameras, and stops the Camera
+	cameraTarget = model
+	if view.Controller != nil {
+		view.Controller.Stop()
+	}
+	if glw.IsCursorCaptured() {
+		glw.SetCursorCaptured(false)
+	}
+}
+
+//
+// toggleRecording
+// Starts recording the frames to a new folder (and to the encoder of the -encoder flag), or stops the recording
+//
+func toggleRecording() {
+	if glw.IsRecording() {
+		if err := glw.StopRecording(); err != nil {
+			log.Println("The recording failed:", err)
 		}
-	case glfw.KeySpace:
-		model := water.Model
-		water = models.NewTerrainWithSeed(
-			incSeed,
-			4.0,
-			50.0,
-			mgl32.Vec4{ 0, 0.618, 1, 0.9 },
-		)
-		water.CreateTerrain(250, 250, 350.0, 350.0)
-		water.Model = model
+		updateTitle()
+		return
+	}
+
+	options := wrapper.RecordOptions{
+		Directory: fmt.Sprintf("%s/recording-%s", capturesFolder, time.Now().Format("20060102-150405")),
+		FrameRate: recordingFrameRate,
+		Scale:     1,
+		Encoder:   strings.Fields(*recordEncoder),
+	}
+	if err := glw.StartRecording(options); err != nil {
+		log.Println("Couldn't start the recording:", err)
+		return
 	}
+	fmt.Printf("Recording to %s\n", options.Directory)
+	updateTitle()
+}
+
+//
+// updateTitle
+// Displays the Selected Model and the frame stats on the title of the window
+//
+func updateTitle() {
+	recording := ""
+	if glw.IsRecording() {
+		recording = " | Recording"
+	}
+
+	glw.Window.SetTitle(fmt.Sprintf("Selected Model --> %s | %s%s", selected_model.GetName(), glw.GetStats(), recording))
 }
 
 //
@@ -693,7 +1054,7 @@ func keyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Ac
 //
 func ApplyTransformations (model models.Model, rotation mgl32.Vec4, position mgl32.Vec4, zoom float32, speed float32) {
 	if rotation.X() != 0 || rotation.Y() != 0 || rotation.Z() != 0 || rotation.W() != 0 {
-		model.Rotate(speed, mgl32.Vec3{rotation.X(), rotation.Y(), rotation.Z()})
+		model.RotateRadians(speed, mgl32.Vec3{rotation.X(), rotation.Y(), rotation.Z()})
 	}
 	if position.X() != 0 || position.Y() != 0 || position.Z() != 0 || rotation.W() != 0 {
 		model.Translate(position.X(), position.Y(), position.Z())
@@ -704,6 +1065,35 @@ func ApplyTransformations (model models.Model, rotation mgl32.Vec4, position mgl
 	}
 }
 
+//
+// renderScreenshot
+// Runs the loop offscreen with a clock that moves one update per frame (so the frame is always the same),
+// writes the last frame to a PNG file and closes the window
+//
+// @param path (string) the path to the PNG file
+// @param frames (int) the number of updates before the screenshot (at least 1)
+//
+func renderScreenshot(path string, frames int) {
+	clock := &wrapper.ManualClock{}
+	glw.SetClock(clock)
+	glw.SetFPS(0)
+
+	// The first frame runs one update without moving the clock
+	glw.RunFrames(1)
+	for frame := 1; frame < frames; frame++ {
+		clock.Advance(1.0 / updateRate)
+		glw.RunFrames(1)
+	}
+
+	if err := wrapper.SavePNG(path, glw.ReadPixels()); err != nil {
+		glw.Terminate()
+		log.Fatalf("Couldn't save the screenshot: %s", err)
+	}
+	fmt.Printf("Saved the screenshot to %s\n", path)
+
+	glw.Terminate()
+}
+
 //
 // Reshape
 // This gets called when the window changes its size
@@ -713,88 +1103,34 @@ func ApplyTransformations (model models.Model, rotation mgl32.Vec4, position mgl
 // @param height (int) the height of the window
 //
 func reshape(window *glfw.Window, width, height int) {
-	gl.Viewport(0, 0, int32(width), int32(height));
-	aspect_ratio = (float32(width) / 640.0 * 4.0) / (float32(height) / 480.0 * 3.0);
+	glw.Device.Viewport(0, 0, int32(width), int32(height))
+
+	// The window can be resized before the scene is loaded
+	if waterPass != nil {
+		waterPass.Resize(int32(width), int32(height))
+	}
+	if view != nil {
+		view.SetViewport(width, height)
+	}
 }
 
 //
 // printKeyboardMappings
-// Prints the Keyboard instructions to console
+// Prints the Input Bindings to console (generated from the bindings file)
 //
 func printKeyboardMappings () {
-	fmt.Println(`
+	fmt.Printf(`
 
-Keyboard Instructions
----------------------
+Input Instructions (%s)
+------------------
 
-Select A Model with a number
 - The name of the selected model is displayed on the title of the window.
-- Once a model is selected, it can be moved using the Keys Below
-- Move and Rotate work like a cross: ( Example )
-
-| W   \ S 	-->  Up  \ Down	 |
-| A   \ D 	--> Left \ Right |
-| Q   \ E 	--> Back \ Front |
-| Tab \ R 	-->   -W \ +W	 |
-
-------------------------------------
- |-----||-----||-----||-----||-----|
- |  1  ||  2  ||  3  ||  4  ||  5  |
- |-----||-----||-----||-----||-----|
- |-----||-----||-----||-----||-----|
- |  6  ||  7  ||  8  ||  9  ||  0  |
- |-----||-----||-----||-----||-----|
-
-1. Gopher				| 6.
-2. Gingerbread House	| 7.
-3. Dragon				| 8. Terrain
-4. Car					| 9. Camera / View
-5. Wall					| 0. Light Point
-
-
-		  Position (Move)
--------------------------------------
-
-|-------||-----||-----||-----||-----|
-|  Tab  ||  Q  ||  W  ||  E  ||  R  |
-|-------||-----||-----||-----||-----|
-		 |-----||-----||-----|
-		 |  A  ||  S  ||  D  |
-		 |-----||-----||-----|
-
-		 Rotation (Rotate)
------------------------------------
-|-----||-----||-----||-----||-----|
-|  Y  ||  U  ||  I  ||  O  ||  P  |
-|-----||-----||-----||-----||-----|
- 	   |-----||-----||-----|
- 	   |  J  ||  K  ||  L  |
- 	   |-----||-----||-----|
-
-
- Zoom (-/+)  | Speed Up/Down
-----------------------------
-|-----||-----||-----||-----|
-|  Z  ||  X  ||  Z  ||  X  |
-|-----||-----||-----||-----|
-
-
-
-Instructions / Draw Mode / Color
-	---------------------
-	|-----||-----||-----|
-	|  B  ||  N  ||  M  |
-	|-----||-----||-----|
-
-----------------------------
-
-DEBUG Options
-
-- The Enter Key will reload the Shaders.
-- The Space Key will print the current selected model matrix.
+- Once a model is selected, it can be moved, rotated and scaled with the actions below.
+- The Camera moves with its Controller (Fly, Orbit or Follow), the mouse wheel zooms it.
 
+%s
 -------------------------------------------------------------
 
 
-	`)
+`, bindingsFile, actionMap.Help())
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/input"
+)
+
+// The tests run in the folder of the package, the resources are in the root of the repository
+var testResources = filepath.Join("..", "..", "..", "..")
+
+// testActionMap defines the actions of the app and loads the bindings file
+func testActionMap(t *testing.T, path string) *input.ActionMap {
+	testMap := input.NewActionMap()
+	for _, action := range actionList {
+		if err := testMap.Define(action.Name, action.Group, action.Description); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testMap.LoadBindings(path); err != nil {
+		t.Fatalf("could not load %s:\n%s", path, err)
+	}
+
+	return testMap
+}
+
+func TestBindingsFile(t *testing.T) {
+	for _, folder := range []string{ testResources, filepath.Join(testResources, "builds") } {
+		path := filepath.Join(folder, bindingsFile)
+		testMap := testActionMap(t, path)
+
+		// Every action has an input
+		for _, action := range testMap.Actions() {
+			if len(action.Bindings) == 0 {
+				t.Errorf("%s: %q is not bound", path, action.Name)
+			}
+		}
+
+		// The water is regenerated with the space (it was the key of print-selected)
+		space, _ := input.ParseBinding("key:Space")
+		if bindings := testMap.Action("regenerate-water").Bindings; len(bindings) != 1 || bindings[0] != space {
+			t.Errorf("%s: expected regenerate-water on the space, found %v", path, bindings)
+		}
+		if testMap.Action("print-selected") != nil {
+			t.Errorf("%s: print-selected is still an action", path)
+		}
+
+		// The help has every group, and a line for every action with its inputs
+		help := testMap.Help()
+		for _, group := range []string{ "General", "Selection", "Movement", "Rotation", "Camera", "Capture", "Display" } {
+			if !strings.Contains(help, group + "\n" + strings.Repeat("-", len(group)) + "\n") {
+				t.Errorf("%s: the help has no %s section", path, group)
+			}
+		}
+		for _, line := range []string{ "Space", "regenerate-water", "Regenerates the water waves" } {
+			if !strings.Contains(lineOf(help, "regenerate-water"), line) {
+				t.Errorf("%s: expected %q in the line of regenerate-water:\n%s", path, line, help)
+			}
+		}
+		if !strings.Contains(lineOf(help, "move-forward"), "E, Gamepad Axis 1 -") {
+			t.Errorf("%s: expected the key and the axis of move-forward:\n%s", path, help)
+		}
+		if strings.Contains(help, "(unbound)") {
+			t.Errorf("%s: the help has unbound actions:\n%s", path, help)
+		}
+	}
+}
+
+// lineOf finds the line of the help with an action
+func lineOf(help, action string) string {
+	for _, line := range strings.Split(help, "\n") {
+		if strings.Contains(line, "  " + action + "  ") {
+			return line
+		}
+	}
+
+	return ""
+}
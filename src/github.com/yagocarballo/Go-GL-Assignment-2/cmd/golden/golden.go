@@ -0,0 +1,344 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"runtime"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/generation"
+	"github.com/yagocarballo/Go-GL-Assignment-2/golden"
+	"github.com/yagocarballo/Go-GL-Assignment-2/models"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Command line flags
+var goldenDir = flag.String("goldens", "./resources/goldens", "folder with the golden images")
+var outputDir = flag.String("output", "./golden-output", "folder for the renders and diff images of the failed cases")
+var update = flag.Bool("update", false, "replaces the golden images with the renders")
+var threshold = flag.Float64("threshold", golden.DefaultTolerance().Threshold, "perceptual difference a pixel can have before it counts as different (0 to 1)")
+var maxDiff = flag.Float64("max-diff", golden.DefaultTolerance().MaxDiffRatio, "part of the pixels that can be different (0 to 1)")
+var runFilter = flag.String("run", "", "only runs the cases whose name matches this regular expression")
+var width = flag.Int("width", 320, "width of the renders")
+var height = flag.Int("height", 240, "height of the renders")
+
+// The Window Wrapper (offscreen) and the Shaders
+var glw *wrapper.Glw
+var shaderManager *wrapper.ShaderManager
+var lights *models.LightManager
+
+// Shaders used by the cases
+var shaderList = []string{
+	"basic",
+	"terrain",
+	"colorMaterial",
+}
+
+// Names of the draw modes in the case names
+var drawModeNames = map[models.DrawMode]string{
+	models.DRAW_POINTS:   "points",
+	models.DRAW_LINES:    "lines",
+	models.DRAW_POLYGONS: "polygons",
+}
+
+//
+// primitive
+// A model with its own vertex buffers that draws with the active shader
+//
+type primitive interface {
+	models.Model
+	Draw()
+}
+
+//
+// init
+// This function is called by go as soon as this class is opened
+//
+func init() {
+	// Locks the Execution in the main Thread as OpenGL is not thread Safe
+	runtime.LockOSThread()
+}
+
+//
+// main
+// Renders the cases offscreen and compares them with the golden images (run it from the root of the project).
+// It exits with 1 if any case fails
+//
+func main() {
+	flag.Parse()
+
+	reports, err := runCases()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	failed := 0
+	for _, report := range reports {
+		fmt.Println(report)
+		if !report.Passed() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d of %d cases failed (the renders and diff images are in %s)\n", failed, len(reports), *outputDir)
+		os.Exit(1)
+	}
+	fmt.Printf("%d cases passed\n", len(reports))
+}
+
+//
+// runCases
+// Opens the offscreen window, renders the cases that match -run and compares them with the golden images (or updates them)
+//
+// @return reports ([]golden.Report) a report per case
+// @return error (error) the error (if the -run expression is not valid)
+//
+func runCases() ([]golden.Report, error) {
+	filter, err := regexp.Compile(*runFilter)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid -run expression: %s", err)
+	}
+
+	// Creates the offscreen Window
+	glw = wrapper.NewWrapper(*width, *height, "Golden Images")
+	glw.SetHeadless(true)
+	glw.CreateWindow()
+
+	// The core profile can't draw without a vertex array object
+	glw.Device.BindVertexArray(glw.Device.GenVertexArray())
+	loadShaders()
+
+	cases := []golden.Case{}
+	for _, testCase := range buildCases() {
+		if filter.MatchString(testCase.Name) {
+			cases = append(cases, testCase)
+		}
+	}
+
+	reports := golden.Run(cases, golden.Options{
+		GoldenDir: *goldenDir,
+		OutputDir: *outputDir,
+		Update:    *update,
+		Tolerance: golden.Tolerance{ Threshold: *threshold, MaxDiffRatio: *maxDiff },
+	})
+	glw.Terminate()
+
+	return reports, nil
+}
+
+//
+// buildCases
+// Lists the cases: a Wavefront object, a terrain with a fixed seed, and the primitives in every draw mode.
+// The models are only created when a case runs, so -run skips the rest
+//
+// @return cases ([]golden.Case) the cases
+//
+func buildCases() []golden.Case {
+	cases := []golden.Case{
+		{ Name: "object-gopher", Render: renderObject("./resources/models/gopher/gopher.obj", "colorMaterial") },
+		{ Name: "terrain-seed-42", Render: renderTerrain(42) },
+	}
+
+	primitives := []struct {
+		name   string
+		create func() primitive
+	}{
+		{ "sphere", func() primitive {
+			sphere := models.NewSphere(glw.Device, "Sphere", 20, 20, shaderManager)
+			sphere.Seed = 42
+			sphere.MakeSphereVBO()
+			return sphere
+		} },
+		{ "cylinder", func() primitive {
+			cylinder := models.NewCylinder(glw.Device, "Cylinder", 40, 1.5, 1, shaderManager)
+			cylinder.MakeCylinderVBO()
+			return cylinder
+		} },
+		{ "cog", func() primitive {
+			cog := models.NewCog(glw.Device, "Cog", 40, 0.5, 1, 0.2, shaderManager)
+			cog.MakeCogVBO()
+			return cog
+		} },
+	}
+
+	for _, drawMode := range []models.DrawMode{ models.DRAW_POINTS, models.DRAW_LINES, models.DRAW_POLYGONS } {
+		for _, shape := range primitives {
+			cases = append(cases, golden.Case{
+				Name:   fmt.Sprintf("%s-%s", shape.name, drawModeNames[drawMode]),
+				Render: renderPrimitive(shape.create, drawMode),
+			})
+		}
+	}
+
+	return cases
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+/////////////////////////////////////// Cases ///////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+// renderPrimitive draws a primitive turned a bit, so the sides are visible
+func renderPrimitive(create func() primitive, drawMode models.DrawMode) func() (image.Image, error) {
+	return func() (image.Image, error) {
+		shape := create()
+		shape.SetDrawMode(drawMode)
+		shape.RotateDegrees(30, mgl32.Vec3{ 1, 0, 0 })
+		shape.RotateDegrees(-30, mgl32.Vec3{ 0, 1, 0 })
+
+		return renderFrame("basic", mgl32.Vec3{ 0, 0, 5 }, mgl32.Vec3{}, shape.Draw)
+	}
+}
+
+// renderTerrain draws a terrain made from a seed, seen from above
+// (with the midpoint displacement heights, which only depend on the seed and not on the noise library)
+func renderTerrain(seed int64) func() (image.Image, error) {
+	return func() (image.Image, error) {
+		source := generation.NewRandomMid(seed, 6, 0.7, 1.0, 1.0)
+		terrain := models.NewTerrainWithSource(glw.Device, source, mgl32.Vec4{ 0.662, 0.405, 0.022, 1 })
+		terrain.CreateTerrain(100, 100, 50, 50)
+
+		return renderFrame("terrain", mgl32.Vec3{ 0, 30, 45 }, mgl32.Vec3{}, func() {
+			shaderManager.SetUniform4f("terrain", "tone", terrain.ColorTone.X(), terrain.ColorTone.Y(), terrain.ColorTone.Z(), terrain.ColorTone.W())
+			terrain.DrawObject(shaderManager.CurrentShader())
+		})
+	}
+}
+
+// renderObject draws a Wavefront object, with the camera far enough to see all of it
+func renderObject(path, shader string) func() (image.Image, error) {
+	return func() (image.Image, error) {
+		object := models.NewObjectLoader(glw.Device)
+		object.LoadObject(path)
+		if len(object.Objects) == 0 {
+			return nil, fmt.Errorf("couldn't load %s", path)
+		}
+		object.CreateObject()
+
+		center, radius := objectBounds(object)
+		eye := center.Add(mgl32.Vec3{ 0, radius * 0.5, radius * 2.5 })
+
+		return renderFrame(shader, eye, center, func() {
+			object.DrawObject(shaderManager.CurrentShader())
+		})
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////// Helpers //////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// renderFrame
+// Clears the offscreen target, draws with a shader from a camera and reads the frame back
+//
+// @param shader (string) the name of the shader
+// @param eye (mgl32.Vec3) the position of the camera
+// @param center (mgl32.Vec3) the point the camera looks at
+// @param draw (func()) draws the model (the shader is active)
+//
+// @return image (image.Image) the frame
+// @return error (error) the OpenGL error (if any)
+//
+func renderFrame(shader string, eye, center mgl32.Vec3, draw func()) (image.Image, error) {
+	camera := models.NewCamera("Golden", eye, center, mgl32.Vec3{ 0, 1, 0 })
+	camera.SetViewport(glw.GetFramebufferSize())
+	camera.Far = 500
+
+	projection, view := camera.Projection(), camera.View()
+
+	glw.Device.ClearColor(0.028, 0.156, 0.348, 1)
+	glw.Device.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	glw.Device.Enable(gl.DEPTH_TEST)
+
+	lights.Upload(view)
+
+	shaderManager.EnableShader(shader)
+	shaderManager.SetUniformMatrix4fv(shader, "projection", 1, false, projection[:])
+	shaderManager.SetUniformMatrix4fv(shader, "view", 1, false, view[:])
+	shaderManager.SetUniform4f(shader, "clipplane", 0, 0, 0, 0)
+	shaderManager.SetUniform1ui(shader, "colourmode", uint32(models.COLOR_SOLID))
+	shaderManager.SetUniform1ui(shader, "emitmode", models.EMIT_COLORED.AsUint32())
+
+	draw()
+
+	shaderManager.DisableShader()
+
+	if code := glw.Device.GetError(); code != gl.NO_ERROR {
+		return nil, fmt.Errorf("OpenGL error 0x%x", code)
+	}
+
+	return glw.ReadPixels(), nil
+}
+
+//
+// objectBounds
+// Finds the centre and radius of the sphere around the vertices of a Wavefront object
+//
+// @param object (*models.WavefrontObject) the object
+//
+// @return center (mgl32.Vec3) the centre of the bounding box
+// @return radius (float32) half the diagonal of the bounding box (1 if the object is empty)
+//
+func objectBounds(object *models.WavefrontObject) (mgl32.Vec3, float32) {
+	inf := float32(math.Inf(1))
+	min, max := mgl32.Vec3{ inf, inf, inf }, mgl32.Vec3{ -inf, -inf, -inf }
+	for _, data := range object.Objects {
+		for index := 0; index + 2 < len(data.Vertex); index += 3 {
+			for axis := 0; axis < 3; axis++ {
+				min[axis] = float32(math.Min(float64(min[axis]), float64(data.Vertex[index + axis])))
+				max[axis] = float32(math.Max(float64(max[axis]), float64(data.Vertex[index + axis])))
+			}
+		}
+	}
+
+	if min.X() > max.X() {
+		return mgl32.Vec3{}, 1
+	}
+
+	radius := max.Sub(min).Len() / 2
+	if radius == 0 {
+		radius = 1
+	}
+
+	return min.Add(max).Mul(0.5), radius
+}
+
+//
+// loadShaders
+// Loads the Shaders of the cases, their uniforms and the light
+//
+func loadShaders() {
+	shaderManager = wrapper.NewShaderManager(glw.Device)
+
+	for _, shaderName := range shaderList {
+		err := shaderManager.LoadShader(
+			shaderName,
+			fmt.Sprintf("./resources/shaders/%s.vert", shaderName),
+			fmt.Sprintf("./resources/shaders/%s.frag", shaderName),
+		)
+		if err != nil {
+			log.Fatalf("Couldn't load the shader %s: %s", shaderName, err)
+		}
+	}
+
+	for name := range shaderManager.Shaders {
+		for _, uniform := range []string{ "model", "view", "projection", "colourmode", "emitmode", "tone", "clipplane" } {
+			shaderManager.CreateUniform(name, uniform)
+		}
+	}
+
+	// Every case is lit by the same white point light
+	lights = models.NewLightManager(glw.Device)
+	light := models.NewLight(glw.Device, "Light", models.LIGHT_POINT, shaderManager)
+	light.Translate(5, 10, 10)
+	lights.Add(light)
+	lights.BindShaders(shaderManager)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+//
+// TestGoldenImages
+// Renders the cases and compares them with resources/goldens. It takes the same flags as the command
+// (Example: go test ./cmd/golden -args -update) and it's skipped when there is no display to open the offscreen window
+//
+func TestGoldenImages(t *testing.T) {
+	// The cases load the resources from the root of the project
+	if err := os.Chdir(filepath.Join("..", "..", "..", "..", "..", "..")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := glfw.Init(); err != nil {
+		t.Skipf("there is no display to render the golden images: %s", err)
+	}
+
+	reports, err := runCases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) == 0 {
+		t.Fatalf("no case matches -run %q", *runFilter)
+	}
+
+	for _, report := range reports {
+		if report.Passed() {
+			t.Log(report)
+		} else {
+			t.Errorf("%s (the render and the diff image are in %s)", report, *outputDir)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package collision
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Triangles per leaf of the tree
+const bvhLeafSize = 4
+
+//
+// BVH
+// A bounding volume hierarchy of triangles, for meshes that are not a regular grid
+// (loaded objects, terrains with skirts...)
+//
+type BVH struct {
+	Triangles []Triangle  // Local triangles, sorted so every node owns a contiguous range
+	Model     mgl32.Mat4  // From the local space to the world space
+
+	nodes     []bvhNode
+}
+
+type bvhNode struct {
+	minimum, maximum mgl32.Vec3
+	left, right      int // Children (-1 on leaves)
+	first, count     int // Range of triangles (only on leaves)
+}
+
+//
+// NewBVH
+// Builds the tree, splitting the longest axis on the median triangle
+//
+// @param triangles ([]Triangle) the local triangles
+// @param model (mgl32.Mat4) the model matrix of the mesh
+//
+// @return bvh (*BVH) a pointer to the tree
+//
+func NewBVH(triangles []Triangle, model mgl32.Mat4) *BVH {
+	bvh := &BVH{ triangles, model, []bvhNode{} }
+	if len(triangles) > 0 {
+		bvh.build(0, len(triangles))
+	}
+
+	return bvh
+}
+
+//
+// NewBVHFromIndices
+// Builds the tree from a list of vertices and triangle indices (3 per triangle)
+//
+// @param vertices ([]mgl32.Vec3) the local vertices
+// @param indices ([]uint32) the triangle indices
+// @param model (mgl32.Mat4) the model matrix of the mesh
+//
+// @return bvh (*BVH) a pointer to the tree
+//
+func NewBVHFromIndices(vertices []mgl32.Vec3, indices []uint32, model mgl32.Mat4) *BVH {
+	triangles := make([]Triangle, 0, len(indices) / 3)
+	for i := 0; i + 2 < len(indices); i += 3 {
+		triangles = append(triangles, Triangle{ vertices[indices[i]], vertices[indices[i + 1]], vertices[indices[i + 2]] })
+	}
+
+	return NewBVH(triangles, model)
+}
+
+func (bvh *BVH) SphereContact(sphere Sphere) (Contact, bool) {
+	return bvh.CapsuleContact(Capsule{ sphere.Center, sphere.Center, sphere.Radius })
+}
+
+func (bvh *BVH) CapsuleContact(capsule Capsule) (Contact, bool) {
+	if len(bvh.nodes) == 0 {
+		return Contact{}, false
+	}
+
+	s := newSpace(bvh.Model)
+	local := Capsule{ s.pointToLocal(capsule.A), s.pointToLocal(capsule.B), capsule.Radius / s.scale }
+
+	minimum := mgl32.Vec3{
+		minFloat32(local.A.X(), local.B.X()) - local.Radius,
+		minFloat32(local.A.Y(), local.B.Y()) - local.Radius,
+		minFloat32(local.A.Z(), local.B.Z()) - local.Radius,
+	}
+	maximum := mgl32.Vec3{
+		maxFloat32(local.A.X(), local.B.X()) + local.Radius,
+		maxFloat32(local.A.Y(), local.B.Y()) + local.Radius,
+		maxFloat32(local.A.Z(), local.B.Z()) + local.Radius,
+	}
+
+	var best Contact
+	found := false
+
+	stack := []int{ 0 }
+	for len(stack) > 0 {
+		node := bvh.nodes[stack[len(stack) - 1]]
+		stack = stack[:len(stack) - 1]
+
+		if !overlaps(node.minimum, node.maximum, minimum, maximum) {
+			continue
+		}
+
+		if node.left < 0 {
+			for _, triangle := range bvh.Triangles[node.first:node.first + node.count] {
+				if contact, touches := triangle.CapsuleContact(local); touches {
+					best, found = deeper(best, found, contact)
+				}
+			}
+			continue
+		}
+
+		stack = append(stack, node.left, node.right)
+	}
+
+	if !found {
+		return Contact{}, false
+	}
+
+	return s.contactToWorld(best), true
+}
+
+func (bvh *BVH) Raycast(ray Ray, maxDistance float32) (RayHit, bool) {
+	if len(bvh.nodes) == 0 {
+		return RayHit{}, false
+	}
+
+	s := newSpace(bvh.Model)
+	origin, direction := s.pointToLocal(ray.Origin), s.vectorToLocal(ray.Direction)
+
+	var best RayHit
+	found := false
+	closest := maxDistance
+
+	stack := []int{ 0 }
+	for len(stack) > 0 {
+		node := bvh.nodes[stack[len(stack) - 1]]
+		stack = stack[:len(stack) - 1]
+
+		if _, _, ok := intersectBox(origin, direction, node.minimum, node.maximum, closest); !ok {
+			continue
+		}
+
+		if node.left < 0 {
+			for _, triangle := range bvh.Triangles[node.first:node.first + node.count] {
+				if t, touches := triangle.Raycast(origin, direction); touches && t <= closest {
+					normal := triangle.Normal()
+					if normal.Dot(direction) > 0 {
+						normal = normal.Mul(-1)
+					}
+
+					best, found, closest = RayHit{ origin.Add(direction.Mul(t)), normal, t }, true, t
+				}
+			}
+			continue
+		}
+
+		stack = append(stack, node.left, node.right)
+	}
+
+	if !found {
+		return RayHit{}, false
+	}
+
+	return s.hitToWorld(best), true
+}
+
+// build creates the node for the triangles [first, first + count) and returns its index
+func (bvh *BVH) build(first, count int) int {
+	index := len(bvh.nodes)
+	minimum, maximum := bounds(bvh.Triangles[first:first + count])
+	bvh.nodes = append(bvh.nodes, bvhNode{ minimum, maximum, -1, -1, first, count })
+
+	if count <= bvhLeafSize {
+		return index
+	}
+
+	// Splits along the longest axis
+	size := maximum.Sub(minimum)
+	axis := 0
+	if size.Y() > size[axis] {
+		axis = 1
+	}
+	if size.Z() > size[axis] {
+		axis = 2
+	}
+
+	triangles := bvh.Triangles[first:first + count]
+	sort.Sort(byAxis{ triangles, axis })
+
+	half := count / 2
+	left := bvh.build(first, half)
+	right := bvh.build(first + half, count - half)
+
+	bvh.nodes[index].left, bvh.nodes[index].right = left, right
+	bvh.nodes[index].first, bvh.nodes[index].count = 0, 0
+
+	return index
+}
+
+// byAxis sorts the triangles by the position of their centroid on one axis
+type byAxis struct {
+	triangles []Triangle
+	axis      int
+}
+
+func (sorter byAxis) Len() int {
+	return len(sorter.triangles)
+}
+
+func (sorter byAxis) Less(i, j int) bool {
+	return centroid(sorter.triangles[i])[sorter.axis] < centroid(sorter.triangles[j])[sorter.axis]
+}
+
+func (sorter byAxis) Swap(i, j int) {
+	sorter.triangles[i], sorter.triangles[j] = sorter.triangles[j], sorter.triangles[i]
+}
+
+func bounds(triangles []Triangle) (mgl32.Vec3, mgl32.Vec3) {
+	inf := float32(math.Inf(1))
+	minimum := mgl32.Vec3{ inf, inf, inf }
+	maximum := mgl32.Vec3{ -inf, -inf, -inf }
+
+	for _, triangle := range triangles {
+		for _, vertex := range [3]mgl32.Vec3{ triangle.A, triangle.B, triangle.C } {
+			for axis := 0; axis < 3; axis++ {
+				minimum[axis] = minFloat32(minimum[axis], vertex[axis])
+				maximum[axis] = maxFloat32(maximum[axis], vertex[axis])
+			}
+		}
+	}
+
+	return minimum, maximum
+}
+
+func centroid(triangle Triangle) mgl32.Vec3 {
+	return triangle.A.Add(triangle.B).Add(triangle.C).Mul(1.0 / 3.0)
+}
+
+func overlaps(minimumA, maximumA, minimumB, maximumB mgl32.Vec3) bool {
+	for axis := 0; axis < 3; axis++ {
+		if maximumA[axis] < minimumB[axis] || maximumB[axis] < minimumA[axis] {
+			return false
+		}
+	}
+
+	return true
+}
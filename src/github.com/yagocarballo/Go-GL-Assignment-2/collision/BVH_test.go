@@ -0,0 +1,154 @@
+package collision
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// randomTriangles returns small triangles scattered inside a 20 units box
+func randomTriangles(random *rand.Rand, count int) []Triangle {
+	point := func(center mgl32.Vec3, size float32) mgl32.Vec3 {
+		return center.Add(mgl32.Vec3{ (random.Float32() - 0.5) * size, (random.Float32() - 0.5) * size, (random.Float32() - 0.5) * size })
+	}
+
+	triangles := make([]Triangle, count)
+	for i := range triangles {
+		center := point(mgl32.Vec3{}, 20)
+		triangles[i] = Triangle{ point(center, 3), point(center, 3), point(center, 3) }
+	}
+
+	return triangles
+}
+
+// worldTriangles moves the triangles to world space
+func worldTriangles(triangles []Triangle, model mgl32.Mat4) []Triangle {
+	world := make([]Triangle, len(triangles))
+	for i, triangle := range triangles {
+		world[i] = Triangle{
+			model.Mul4x1(triangle.A.Vec4(1)).Vec3(),
+			model.Mul4x1(triangle.B.Vec4(1)).Vec3(),
+			model.Mul4x1(triangle.C.Vec4(1)).Vec3(),
+		}
+	}
+
+	return world
+}
+
+// bruteRaycast tries every triangle
+func bruteRaycast(triangles []Triangle, ray Ray, maxDistance float32) (float32, bool) {
+	closest, found := maxDistance, false
+	for _, triangle := range triangles {
+		if t, ok := triangle.Raycast(ray.Origin, ray.Direction); ok && t <= closest {
+			closest, found = t, true
+		}
+	}
+
+	return closest, found
+}
+
+// bruteContact tries every triangle
+func bruteContact(triangles []Triangle, capsule Capsule) (Contact, bool) {
+	var best Contact
+	found := false
+	for _, triangle := range triangles {
+		if contact, ok := triangle.CapsuleContact(capsule); ok {
+			best, found = deeper(best, found, contact)
+		}
+	}
+
+	return best, found
+}
+
+// testModel rotates, scales (uniformly) and moves the shapes
+func testModel() mgl32.Mat4 {
+	return mgl32.Translate3D(5, -2, 8).Mul4(mgl32.HomogRotate3D(0.7, mgl32.Vec3{ 1, 2, 0.5 }.Normalize())).Mul4(mgl32.Scale3D(1.5, 1.5, 1.5))
+}
+
+func TestBVHRaycastMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(7))
+	triangles := randomTriangles(random, 300)
+	model := testModel()
+
+	bvh := NewBVH(append([]Triangle{}, triangles...), model)
+	world := worldTriangles(triangles, model)
+
+	hits := 0
+	for i := 0; i < 500; i++ {
+		origin := model.Mul4x1(mgl32.Vec4{ (random.Float32() - 0.5) * 40, (random.Float32() - 0.5) * 40, (random.Float32() - 0.5) * 40, 1 }).Vec3()
+		target := model.Mul4x1(mgl32.Vec4{ (random.Float32() - 0.5) * 20, (random.Float32() - 0.5) * 20, (random.Float32() - 0.5) * 20, 1 }).Vec3()
+		ray := Ray{ origin, target.Sub(origin).Normalize() }
+		maxDistance := float32(20 + random.Intn(60))
+
+		expected, expectedOk := bruteRaycast(world, ray, maxDistance)
+		hit, ok := bvh.Raycast(ray, maxDistance)
+		if ok != expectedOk || (ok && mgl32.Abs(hit.Distance - expected) > 1e-3) {
+			t.Fatalf("ray %d: the BVH found (%v, %f), the brute force (%v, %f)", i, ok, hit.Distance, expectedOk, expected)
+		}
+
+		if ok {
+			hits++
+			if point := ray.Origin.Add(ray.Direction.Mul(hit.Distance)); !point.ApproxEqualThreshold(hit.Point, 1e-3) {
+				t.Fatalf("ray %d: the hit point %v is not at the distance %f (%v)", i, hit.Point, hit.Distance, point)
+			}
+			if hit.Normal.Dot(ray.Direction) > 0 {
+				t.Fatalf("ray %d: the normal %v doesn't face the ray", i, hit.Normal)
+			}
+		}
+	}
+
+	if hits == 0 {
+		t.Error("no ray touched the triangles")
+	}
+}
+
+func TestBVHContactMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(11))
+	triangles := randomTriangles(random, 300)
+	model := testModel()
+
+	bvh := NewBVH(append([]Triangle{}, triangles...), model)
+	world := worldTriangles(triangles, model)
+
+	touches := 0
+	for i := 0; i < 500; i++ {
+		a := model.Mul4x1(mgl32.Vec4{ (random.Float32() - 0.5) * 24, (random.Float32() - 0.5) * 24, (random.Float32() - 0.5) * 24, 1 }).Vec3()
+		b := a
+		if i % 2 == 1 {
+			b = a.Add(mgl32.Vec3{ random.Float32() * 3, random.Float32() * 3, random.Float32() * 3 })
+		}
+		capsule := Capsule{ a, b, 0.5 + random.Float32() * 2 }
+
+		expected, expectedOk := bruteContact(world, capsule)
+		contact, ok := bvh.CapsuleContact(capsule)
+		if ok != expectedOk || (ok && mgl32.Abs(contact.Depth - expected.Depth) > 1e-3) {
+			t.Fatalf("capsule %d: the BVH found (%v, %f), the brute force (%v, %f)", i, ok, contact.Depth, expectedOk, expected.Depth)
+		}
+
+		if ok {
+			touches++
+		}
+	}
+
+	if touches == 0 {
+		t.Error("no capsule touched the triangles")
+	}
+}
+
+func TestEmptyBVH(t *testing.T) {
+	bvh := NewBVHFromIndices(nil, nil, mgl32.Ident4())
+	if _, ok := bvh.Raycast(Ray{ mgl32.Vec3{}, mgl32.Vec3{ 0, -1, 0 } }, 100); ok {
+		t.Error("an empty tree can't be hit")
+	}
+	if _, ok := bvh.SphereContact(Sphere{ mgl32.Vec3{}, 10 }); ok {
+		t.Error("an empty tree can't be touched")
+	}
+
+	// Indices that don't complete a triangle are ignored
+	vertices := []mgl32.Vec3{ { 0, 0, 0 }, { 1, 0, 0 }, { 0, 0, 1 } }
+	bvh = NewBVHFromIndices(vertices, []uint32{ 0, 1, 2, 0 }, mgl32.Ident4())
+	if len(bvh.Triangles) != 1 {
+		t.Errorf("expected 1 triangle, found %d", len(bvh.Triangles))
+	}
+}
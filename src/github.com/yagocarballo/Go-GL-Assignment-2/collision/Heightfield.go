@@ -0,0 +1,315 @@
+package collision
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//
+// Heightfield
+// A regular grid of heights, everything below the surface is solid.
+// The cells are split in two triangles the same way as the terrain triangle strips
+//
+type Heightfield struct {
+	XSize, ZSize uint32
+	Heights      []float32 // Local heights, stored as [x * ZSize + z]
+
+	MinX, MinZ   float32   // Local position of the first vertex
+	StepX, StepZ float32   // Distance between two vertices
+
+	MinY, MaxY   float32   // Lowest and highest heights
+
+	Model        mgl32.Mat4 // From the local space to the world space
+}
+
+//
+// NewHeightfield
+// Creates a heightfield from the vertices of a regular grid
+//
+// @param vertices ([]mgl32.Vec3) the vertices, stored as [x * zSize + z]
+// @param xSize (uint32) number of vertices along x
+// @param zSize (uint32) number of vertices along z
+// @param model (mgl32.Mat4) the model matrix of the grid
+//
+// @return heightfield (*Heightfield) a pointer to the heightfield
+//
+func NewHeightfield(vertices []mgl32.Vec3, xSize, zSize uint32, model mgl32.Mat4) *Heightfield {
+	heightfield := &Heightfield{
+		xSize, zSize,
+		make([]float32, xSize * zSize),
+		0, 0,
+		1, 1,
+		float32(math.Inf(1)), float32(math.Inf(-1)),
+		model,
+	}
+
+	if xSize < 2 || zSize < 2 || len(vertices) < int(xSize * zSize) {
+		return heightfield
+	}
+
+	heightfield.MinX, heightfield.MinZ = vertices[0].X(), vertices[0].Z()
+	heightfield.StepX = (vertices[(xSize - 1) * zSize].X() - vertices[0].X()) / float32(xSize - 1)
+	heightfield.StepZ = (vertices[zSize - 1].Z() - vertices[0].Z()) / float32(zSize - 1)
+
+	for i := range heightfield.Heights {
+		height := vertices[i].Y()
+		heightfield.Heights[i] = height
+		heightfield.MinY = minFloat32(heightfield.MinY, height)
+		heightfield.MaxY = maxFloat32(heightfield.MaxY, height)
+	}
+
+	return heightfield
+}
+
+//
+// HeightAt
+// Returns the local height of the surface (interpolated inside the triangle)
+//
+// @param x (float32) the local x position
+// @param z (float32) the local z position
+//
+// @return height (float32) the local height
+// @return normal (mgl32.Vec3) the local normal of the triangle
+// @return ok (bool) false if the position is outside of the grid
+//
+func (heightfield *Heightfield) HeightAt(x, z float32) (float32, mgl32.Vec3, bool) {
+	cellX, cellZ, fx, fz, ok := heightfield.cell(x, z)
+	if !ok {
+		return 0, mgl32.Vec3{}, false
+	}
+
+	first, second := heightfield.cellTriangles(cellX, cellZ)
+	triangle := first
+	if fx + fz > 1 {
+		triangle = second
+	}
+
+	normal := triangle.Normal()
+	if normal.Y() < 0 {
+		normal = normal.Mul(-1)
+	}
+
+	// The height where the vertical line meets the plane of the triangle
+	height := triangle.A.Y()
+	if normal.Y() > epsilon {
+		height -= (normal.X() * (x - triangle.A.X()) + normal.Z() * (z - triangle.A.Z())) / normal.Y()
+	}
+
+	return height, normal, true
+}
+
+func (heightfield *Heightfield) SphereContact(sphere Sphere) (Contact, bool) {
+	return heightfield.CapsuleContact(Capsule{ sphere.Center, sphere.Center, sphere.Radius })
+}
+
+func (heightfield *Heightfield) CapsuleContact(capsule Capsule) (Contact, bool) {
+	s := newSpace(heightfield.Model)
+	local := Capsule{ s.pointToLocal(capsule.A), s.pointToLocal(capsule.B), capsule.Radius / s.scale }
+
+	minimum := mgl32.Vec3{
+		minFloat32(local.A.X(), local.B.X()) - local.Radius,
+		minFloat32(local.A.Y(), local.B.Y()) - local.Radius,
+		minFloat32(local.A.Z(), local.B.Z()) - local.Radius,
+	}
+	maximum := mgl32.Vec3{
+		maxFloat32(local.A.X(), local.B.X()) + local.Radius,
+		maxFloat32(local.A.Y(), local.B.Y()) + local.Radius,
+		maxFloat32(local.A.Z(), local.B.Z()) + local.Radius,
+	}
+
+	// Completely above the highest point
+	if minimum.Y() > heightfield.MaxY {
+		return Contact{}, false
+	}
+
+	var best Contact
+	found := false
+
+	// The ends of the capsule that are under the surface
+	for _, end := range []mgl32.Vec3{ local.A, local.B } {
+		height, normal, ok := heightfield.HeightAt(end.X(), end.Z())
+		if ok && end.Y() < height {
+			best, found = deeper(best, found, Contact{
+				mgl32.Vec3{ end.X(), height, end.Z() },
+				normal,
+				(height - end.Y()) * normal.Y() + local.Radius,
+			})
+		}
+	}
+
+	// The triangles under the bounding box
+	fromX, fromZ, toX, toZ, ok := heightfield.cellRange(minimum.X(), minimum.Z(), maximum.X(), maximum.Z())
+	if ok {
+		for x := fromX; x <= toX; x++ {
+			for z := fromZ; z <= toZ; z++ {
+				first, second := heightfield.cellTriangles(x, z)
+				for _, triangle := range [2]Triangle{ first, second } {
+					if contact, touches := triangle.CapsuleContact(local); touches {
+						// The solid is below the surface, so the contacts always push up
+						if contact.Normal.Y() < 0 {
+							contact.Normal = contact.Normal.Mul(-1)
+						}
+						best, found = deeper(best, found, contact)
+					}
+				}
+			}
+		}
+	}
+
+	if !found {
+		return Contact{}, false
+	}
+
+	return s.contactToWorld(best), true
+}
+
+func (heightfield *Heightfield) Raycast(ray Ray, maxDistance float32) (RayHit, bool) {
+	if heightfield.XSize < 2 || heightfield.ZSize < 2 {
+		return RayHit{}, false
+	}
+
+	s := newSpace(heightfield.Model)
+	origin, direction := s.pointToLocal(ray.Origin), s.vectorToLocal(ray.Direction)
+
+	maxX := heightfield.MinX + heightfield.StepX * float32(heightfield.XSize - 1)
+	maxZ := heightfield.MinZ + heightfield.StepZ * float32(heightfield.ZSize - 1)
+	minimum := mgl32.Vec3{ minFloat32(heightfield.MinX, maxX), heightfield.MinY, minFloat32(heightfield.MinZ, maxZ) }
+	maximum := mgl32.Vec3{ maxFloat32(heightfield.MinX, maxX), heightfield.MaxY, maxFloat32(heightfield.MinZ, maxZ) }
+
+	start, end, ok := intersectBox(origin, direction, minimum, maximum, maxDistance)
+	if !ok {
+		return RayHit{}, false
+	}
+
+	// Walks through the cells under the ray (Amanatides & Woo), from the closest to the furthest
+	entry := origin.Add(direction.Mul(start))
+	cellX, cellZ, _, _, _ := heightfield.cell(clampToGrid(entry.X(), heightfield.MinX, maxX), clampToGrid(entry.Z(), heightfield.MinZ, maxZ))
+
+	stepX, tDeltaX, tMaxX := walkAxis(origin.X(), direction.X(), heightfield.MinX, heightfield.StepX, cellX)
+	stepZ, tDeltaZ, tMaxZ := walkAxis(origin.Z(), direction.Z(), heightfield.MinZ, heightfield.StepZ, cellZ)
+
+	for {
+		if cellX < 0 || cellZ < 0 || cellX >= int(heightfield.XSize) - 1 || cellZ >= int(heightfield.ZSize) - 1 {
+			return RayHit{}, false
+		}
+
+		first, second := heightfield.cellTriangles(cellX, cellZ)
+		var best RayHit
+		hit := false
+		for _, triangle := range [2]Triangle{ first, second } {
+			if t, touches := triangle.Raycast(origin, direction); touches && t >= start && t <= end && (!hit || t < best.Distance) {
+				normal := triangle.Normal()
+				if normal.Dot(direction) > 0 {
+					normal = normal.Mul(-1)
+				}
+				best, hit = RayHit{ origin.Add(direction.Mul(t)), normal, t }, true
+			}
+		}
+
+		if hit {
+			return s.hitToWorld(best), true
+		}
+
+		if tMaxX < tMaxZ {
+			if tMaxX > end {
+				return RayHit{}, false
+			}
+			cellX += stepX
+			tMaxX += tDeltaX
+		} else {
+			if tMaxZ > end {
+				return RayHit{}, false
+			}
+			cellZ += stepZ
+			tMaxZ += tDeltaZ
+		}
+	}
+}
+
+// cell returns the cell that contains the local position and where it is inside the cell
+func (heightfield *Heightfield) cell(x, z float32) (int, int, float32, float32, bool) {
+	if heightfield.XSize < 2 || heightfield.ZSize < 2 || heightfield.StepX == 0 || heightfield.StepZ == 0 {
+		return 0, 0, 0, 0, false
+	}
+
+	gridX := (x - heightfield.MinX) / heightfield.StepX
+	gridZ := (z - heightfield.MinZ) / heightfield.StepZ
+	if gridX < 0 || gridZ < 0 || gridX > float32(heightfield.XSize - 1) || gridZ > float32(heightfield.ZSize - 1) {
+		return 0, 0, 0, 0, false
+	}
+
+	cellX := int(math.Min(float64(gridX), float64(heightfield.XSize - 2)))
+	cellZ := int(math.Min(float64(gridZ), float64(heightfield.ZSize - 2)))
+
+	return cellX, cellZ, gridX - float32(cellX), gridZ - float32(cellZ), true
+}
+
+// cellRange returns the cells that overlap a local rectangle (clamped to the grid)
+func (heightfield *Heightfield) cellRange(minX, minZ, maxX, maxZ float32) (int, int, int, int, bool) {
+	if heightfield.XSize < 2 || heightfield.ZSize < 2 || heightfield.StepX == 0 || heightfield.StepZ == 0 {
+		return 0, 0, 0, 0, false
+	}
+
+	fromX := (minX - heightfield.MinX) / heightfield.StepX
+	toX := (maxX - heightfield.MinX) / heightfield.StepX
+	fromZ := (minZ - heightfield.MinZ) / heightfield.StepZ
+	toZ := (maxZ - heightfield.MinZ) / heightfield.StepZ
+
+	// Negative steps flip the range
+	if fromX > toX {
+		fromX, toX = toX, fromX
+	}
+	if fromZ > toZ {
+		fromZ, toZ = toZ, fromZ
+	}
+
+	lastX, lastZ := float64(heightfield.XSize - 2), float64(heightfield.ZSize - 2)
+	if toX < 0 || toZ < 0 || float64(fromX) > lastX + 1 || float64(fromZ) > lastZ + 1 {
+		return 0, 0, 0, 0, false
+	}
+
+	return int(math.Max(0, math.Floor(float64(fromX)))),
+		int(math.Max(0, math.Floor(float64(fromZ)))),
+		int(math.Min(lastX, math.Floor(float64(toX)))),
+		int(math.Min(lastZ, math.Floor(float64(toZ)))),
+		true
+}
+
+// cellTriangles returns the two triangles of a cell
+func (heightfield *Heightfield) cellTriangles(cellX, cellZ int) (Triangle, Triangle) {
+	vertex := func(x, z int) mgl32.Vec3 {
+		return mgl32.Vec3{
+			heightfield.MinX + float32(x) * heightfield.StepX,
+			heightfield.Heights[x * int(heightfield.ZSize) + z],
+			heightfield.MinZ + float32(z) * heightfield.StepZ,
+		}
+	}
+
+	v00, v10 := vertex(cellX, cellZ), vertex(cellX + 1, cellZ)
+	v01, v11 := vertex(cellX, cellZ + 1), vertex(cellX + 1, cellZ + 1)
+
+	return Triangle{ v00, v10, v01 }, Triangle{ v11, v01, v10 }
+}
+
+// walkAxis prepares the grid walk along one axis
+func walkAxis(origin, direction, minimum, step float32, cell int) (int, float32, float32) {
+	if direction == 0 {
+		return 0, float32(math.Inf(1)), float32(math.Inf(1))
+	}
+
+	// Steps in grid units, so it also works with negative steps
+	gridDirection := direction / step
+	gridOrigin := (origin - minimum) / step
+
+	if gridDirection > 0 {
+		return 1, 1 / gridDirection, (float32(cell + 1) - gridOrigin) / gridDirection
+	}
+
+	return -1, -1 / gridDirection, (float32(cell) - gridOrigin) / gridDirection
+}
+
+// clampToGrid keeps a value inside the grid (the ends can be in any order)
+func clampToGrid(value, a, b float32) float32 {
+	return mgl32.Clamp(value, minFloat32(a, b), maxFloat32(a, b))
+}
@@ -0,0 +1,147 @@
+package collision
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// hills returns the vertices of a (size * size) grid of bumps, stored as [x * size + z].
+// Negative steps build the grid backwards
+func hills(size uint32, stepX, stepZ float32) []mgl32.Vec3 {
+	vertices := make([]mgl32.Vec3, size * size)
+	for x := uint32(0); x < size; x++ {
+		for z := uint32(0); z < size; z++ {
+			px, pz := float32(x) * stepX, float32(z) * stepZ
+			height := float32(math.Sin(float64(px) * 0.7) * math.Cos(float64(pz) * 0.4) * 2)
+			vertices[x * size + z] = mgl32.Vec3{ px, height, pz }
+		}
+	}
+
+	return vertices
+}
+
+// gridTriangles returns the triangles of every cell, in world space
+func gridTriangles(heightfield *Heightfield) []Triangle {
+	triangles := []Triangle{}
+	for x := 0; x < int(heightfield.XSize) - 1; x++ {
+		for z := 0; z < int(heightfield.ZSize) - 1; z++ {
+			first, second := heightfield.cellTriangles(x, z)
+			triangles = append(triangles, first, second)
+		}
+	}
+
+	return worldTriangles(triangles, heightfield.Model)
+}
+
+func TestHeightfieldHeightAt(t *testing.T) {
+	vertices := hills(9, 1, 1)
+	heightfield := NewHeightfield(vertices, 9, 9, mgl32.Ident4())
+
+	for _, vertex := range vertices {
+		height, _, ok := heightfield.HeightAt(vertex.X(), vertex.Z())
+		if !ok || mgl32.Abs(height - vertex.Y()) > 1e-4 {
+			t.Fatalf("vertex %v: found %f (%v)", vertex, height, ok)
+		}
+	}
+
+	// Inside a cell it follows the plane of the triangle, with the normal pointing up
+	first, _ := heightfield.cellTriangles(2, 3)
+	point := first.A.Mul(0.5).Add(first.B.Mul(0.3)).Add(first.C.Mul(0.2))
+	height, normal, ok := heightfield.HeightAt(point.X(), point.Z())
+	if !ok || mgl32.Abs(height - point.Y()) > 1e-4 || normal.Y() <= 0 {
+		t.Errorf("expected the height %f with an upwards normal, found %f and %v", point.Y(), height, normal)
+	}
+
+	for _, position := range [][2]float32{ { -0.1, 2 }, { 2, 8.1 }, { 20, 20 } } {
+		if _, _, ok := heightfield.HeightAt(position[0], position[1]); ok {
+			t.Errorf("%v is outside of the grid", position)
+		}
+	}
+}
+
+func TestHeightfieldRaycastMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(3))
+
+	grids := []struct {
+		name         string
+		stepX, stepZ float32
+		model        mgl32.Mat4
+	}{
+		{ "plain", 1, 1, mgl32.Ident4() },
+		{ "backwards", -1.5, -0.5, mgl32.Ident4() },
+		{ "moved", 0.75, 1.25, testModel() },
+	}
+
+	for _, grid := range grids {
+		heightfield := NewHeightfield(hills(17, grid.stepX, grid.stepZ), 17, 17, grid.model)
+		triangles := gridTriangles(heightfield)
+
+		// The rays start around the grid and point to random places on it
+		extentX, extentZ := 16 * grid.stepX, 16 * grid.stepZ
+		hits := 0
+		for i := 0; i < 400; i++ {
+			local := mgl32.Vec3{ (random.Float32() * 1.6 - 0.3) * extentX, (random.Float32() - 0.5) * 12, (random.Float32() * 1.6 - 0.3) * extentZ }
+			target := mgl32.Vec3{ random.Float32() * extentX, (random.Float32() - 0.5) * 4, random.Float32() * extentZ }
+
+			// Some rays go straight down and some go along an axis, so the walk crosses only one kind of cell border
+			switch i % 8 {
+			case 0: target = mgl32.Vec3{ local.X(), target.Y(), local.Z() }
+			case 1: target = mgl32.Vec3{ target.X(), target.Y(), local.Z() }
+			case 2: target = mgl32.Vec3{ local.X(), target.Y(), target.Z() }
+			}
+			if target.Sub(local).Len() < 1e-3 {
+				continue
+			}
+
+			origin := grid.model.Mul4x1(local.Vec4(1)).Vec3()
+			direction := grid.model.Mul4x1(target.Sub(local).Vec4(0)).Vec3()
+			ray := Ray{ origin, direction }
+
+			expected, expectedOk := bruteRaycast(triangles, ray, 100)
+			hit, ok := heightfield.Raycast(ray, 100)
+			if ok != expectedOk || (ok && mgl32.Abs(hit.Distance - expected) > 1e-3) {
+				t.Fatalf("%s, ray %d (%v %v): the grid walk found (%v, %f), the brute force (%v, %f)", grid.name, i, ray.Origin, ray.Direction, ok, hit.Distance, expectedOk, expected)
+			}
+
+			if ok {
+				hits++
+			}
+		}
+
+		if hits == 0 {
+			t.Errorf("%s: no ray touched the grid", grid.name)
+		}
+	}
+}
+
+func TestHeightfieldContact(t *testing.T) {
+	heightfield := NewHeightfield(hills(17, 1, 1), 17, 17, testModel())
+	triangles := gridTriangles(heightfield)
+	random := rand.New(rand.NewSource(5))
+
+	for i := 0; i < 300; i++ {
+		local := mgl32.Vec3{ random.Float32() * 18 - 1, (random.Float32() - 0.5) * 6, random.Float32() * 18 - 1 }
+		sphere := Sphere{ heightfield.Model.Mul4x1(local.Vec4(1)).Vec3(), 0.3 + random.Float32() }
+
+		expected, expectedOk := bruteContact(triangles, Capsule{ sphere.Center, sphere.Center, sphere.Radius })
+		contact, ok := heightfield.SphereContact(sphere)
+
+		// The heightfield is solid below the surface, so it can find contacts the triangles alone don't
+		if expectedOk && (!ok || contact.Depth < expected.Depth - 1e-3) {
+			t.Fatalf("sphere %d: expected a contact at least %f deep, found (%v, %f)", i, expected.Depth, ok, contact.Depth)
+		}
+	}
+
+	// Resolve pushes a sphere out of the ground
+	center := heightfield.Model.Mul4x1(mgl32.Vec4{ 8, -1, 8, 1 }).Vec3()
+	moved, ok := Resolve(heightfield, Sphere{ center, 0.5 })
+	if !ok {
+		t.Fatal("the sphere was inside the ground")
+	}
+	if _, touches := heightfield.SphereContact(Sphere{ moved, 0.5 - 1e-3 }); touches {
+		t.Errorf("the sphere is still inside the ground at %v", moved)
+	}
+}
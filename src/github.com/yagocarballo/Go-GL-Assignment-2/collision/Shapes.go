@@ -0,0 +1,230 @@
+package collision
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//
+// Contact
+// Where a query shape touches a collision shape (in world space)
+//
+type Contact struct {
+	Point  mgl32.Vec3 // The closest point on the collision shape
+	Normal mgl32.Vec3 // The direction to move the query shape out of the collision shape
+	Depth  float32    // How far the query shape has to move along the normal
+}
+
+//
+// RayHit
+// Where a ray touches a collision shape (in world space)
+//
+type RayHit struct {
+	Point    mgl32.Vec3
+	Normal   mgl32.Vec3
+	Distance float32 // Distance from the origin of the ray (in units of the direction length)
+}
+
+//
+// Sphere
+// A query sphere
+//
+type Sphere struct {
+	Center mgl32.Vec3
+	Radius float32
+}
+
+//
+// Capsule
+// A query capsule: every point closer than Radius to the segment (A, B)
+//
+type Capsule struct {
+	A, B   mgl32.Vec3
+	Radius float32
+}
+
+//
+// Ray
+// A query ray
+//
+type Ray struct {
+	Origin    mgl32.Vec3
+	Direction mgl32.Vec3
+}
+
+//
+// Shape
+// A collision shape that can be asked for contacts
+//
+type Shape interface {
+	//
+	// SphereContact
+	// Finds the deepest contact between the shape and a sphere
+	//
+	// @param sphere (Sphere) the sphere in world space
+	//
+	// @return contact (Contact) the contact
+	// @return ok (bool) false if they don't touch
+	//
+	SphereContact(sphere Sphere) (Contact, bool)
+
+	//
+	// CapsuleContact
+	// Finds the deepest contact between the shape and a capsule
+	//
+	// @param capsule (Capsule) the capsule in world space
+	//
+	// @return contact (Contact) the contact
+	// @return ok (bool) false if they don't touch
+	//
+	CapsuleContact(capsule Capsule) (Contact, bool)
+
+	//
+	// Raycast
+	// Finds the first point where a ray touches the shape
+	//
+	// @param ray (Ray) the ray in world space
+	// @param maxDistance (float32) the furthest hit accepted (in units of the direction length)
+	//
+	// @return hit (RayHit) the hit
+	// @return ok (bool) false if the ray doesn't touch the shape
+	//
+	Raycast(ray Ray, maxDistance float32) (RayHit, bool)
+}
+
+//
+// Resolve
+// Moves a sphere out of a shape (useful to keep animated objects above the ground)
+//
+// @param shape (Shape) the collision shape
+// @param sphere (Sphere) the sphere in world space
+//
+// @return center (mgl32.Vec3) the new centre of the sphere
+// @return ok (bool) true if the sphere had to be moved
+//
+func Resolve(shape Shape, sphere Sphere) (mgl32.Vec3, bool) {
+	moved := false
+
+	// Pushing out of one triangle can push into another one (in valleys), so it tries a few times
+	for i := 0; i < resolveIterations; i++ {
+		contact, ok := shape.SphereContact(sphere)
+		if !ok || contact.Depth <= epsilon {
+			break
+		}
+
+		sphere.Center = sphere.Center.Add(contact.Normal.Mul(contact.Depth))
+		moved = true
+	}
+
+	return sphere.Center, moved
+}
+
+// Maximum number of pushes done by Resolve
+const resolveIterations = 8
+
+// space converts the queries between world space and the local space of a shape.
+// It supports rotations, translations and uniform scales
+type space struct {
+	model, inverse, normals mgl32.Mat4
+	scale                   float32
+}
+
+func newSpace(model mgl32.Mat4) space {
+	inverse := model.Inv()
+
+	// Average length of the axes (they are the same with uniform scales)
+	scale := (model.Col(0).Vec3().Len() + model.Col(1).Vec3().Len() + model.Col(2).Vec3().Len()) / 3.0
+	if scale == 0 {
+		scale = 1
+	}
+
+	return space{ model, inverse, inverse.Transpose(), scale }
+}
+
+func (s space) pointToLocal(point mgl32.Vec3) mgl32.Vec3 {
+	return s.inverse.Mul4x1(point.Vec4(1)).Vec3()
+}
+
+func (s space) vectorToLocal(vector mgl32.Vec3) mgl32.Vec3 {
+	return s.inverse.Mul4x1(vector.Vec4(0)).Vec3()
+}
+
+func (s space) pointToWorld(point mgl32.Vec3) mgl32.Vec3 {
+	return s.model.Mul4x1(point.Vec4(1)).Vec3()
+}
+
+func (s space) normalToWorld(normal mgl32.Vec3) mgl32.Vec3 {
+	return normalize(s.normals.Mul4x1(normal.Vec4(0)).Vec3())
+}
+
+func (s space) contactToWorld(contact Contact) Contact {
+	return Contact{
+		s.pointToWorld(contact.Point),
+		s.normalToWorld(contact.Normal),
+		contact.Depth * s.scale,
+	}
+}
+
+func (s space) hitToWorld(hit RayHit) RayHit {
+	return RayHit{
+		s.pointToWorld(hit.Point),
+		s.normalToWorld(hit.Normal),
+		hit.Distance,
+	}
+}
+
+// normalize returns a zero vector instead of NaNs for zero length vectors
+func normalize(v mgl32.Vec3) mgl32.Vec3 {
+	length := v.Len()
+	if length == 0 {
+		return v
+	}
+
+	return v.Mul(1 / length)
+}
+
+func minFloat32(a, b float32) float32 {
+	return float32(math.Min(float64(a), float64(b)))
+}
+
+func maxFloat32(a, b float32) float32 {
+	return float32(math.Max(float64(a), float64(b)))
+}
+
+// deeper keeps the contact that goes further into the shape
+func deeper(best Contact, found bool, contact Contact) (Contact, bool) {
+	if !found || contact.Depth > best.Depth {
+		return contact, true
+	}
+
+	return best, found
+}
+
+// intersectBox returns the part of the ray (t >= 0) inside an axis aligned box (slab method)
+func intersectBox(origin, direction, minimum, maximum mgl32.Vec3, maxDistance float32) (float32, float32, bool) {
+	start, end := float32(0), maxDistance
+
+	for axis := 0; axis < 3; axis++ {
+		if direction[axis] == 0 {
+			if origin[axis] < minimum[axis] || origin[axis] > maximum[axis] {
+				return 0, 0, false
+			}
+			continue
+		}
+
+		near := (minimum[axis] - origin[axis]) / direction[axis]
+		far := (maximum[axis] - origin[axis]) / direction[axis]
+		if near > far {
+			near, far = far, near
+		}
+
+		start = maxFloat32(start, near)
+		end = minFloat32(end, far)
+		if start > end {
+			return 0, 0, false
+		}
+	}
+
+	return start, end, true
+}
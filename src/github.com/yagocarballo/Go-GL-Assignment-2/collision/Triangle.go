@@ -0,0 +1,213 @@
+package collision
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Distances smaller than this are treated as touching
+const epsilon = 1e-6
+
+//
+// Triangle
+// A triangle of a collision mesh (both sides collide)
+//
+type Triangle struct {
+	A, B, C mgl32.Vec3
+}
+
+//
+// Normal
+// The normal of the triangle (counter clockwise winding)
+//
+func (triangle Triangle) Normal() mgl32.Vec3 {
+	return normalize(triangle.B.Sub(triangle.A).Cross(triangle.C.Sub(triangle.A)))
+}
+
+//
+// ClosestPoint
+// Returns the point of the triangle closest to p
+// (Real-Time Collision Detection, Christer Ericson, 5.1.5)
+//
+// @param p (mgl32.Vec3) the point
+//
+// @return closest (mgl32.Vec3) the closest point on the triangle
+//
+func (triangle Triangle) ClosestPoint(p mgl32.Vec3) mgl32.Vec3 {
+	a, b, c := triangle.A, triangle.B, triangle.C
+	ab, ac, ap := b.Sub(a), c.Sub(a), p.Sub(a)
+
+	d1, d2 := ab.Dot(ap), ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return a
+	}
+
+	bp := p.Sub(b)
+	d3, d4 := ab.Dot(bp), ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return b
+	}
+
+	vc := d1 * d4 - d3 * d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return a.Add(ab.Mul(d1 / (d1 - d3)))
+	}
+
+	cp := p.Sub(c)
+	d5, d6 := ab.Dot(cp), ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return c
+	}
+
+	vb := d5 * d2 - d1 * d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return a.Add(ac.Mul(d2 / (d2 - d6)))
+	}
+
+	va := d3 * d6 - d5 * d4
+	if va <= 0 && (d4 - d3) >= 0 && (d5 - d6) >= 0 {
+		return b.Add(c.Sub(b).Mul((d4 - d3) / ((d4 - d3) + (d5 - d6))))
+	}
+
+	denominator := 1 / (va + vb + vc)
+	return a.Add(ab.Mul(vb * denominator)).Add(ac.Mul(vc * denominator))
+}
+
+//
+// Raycast
+// Intersects a ray with the triangle (Möller–Trumbore)
+//
+// @param origin (mgl32.Vec3) the start of the ray
+// @param direction (mgl32.Vec3) the direction of the ray
+//
+// @return t (float32) the hit is at origin + direction * t
+// @return ok (bool) false if the ray misses the triangle
+//
+func (triangle Triangle) Raycast(origin, direction mgl32.Vec3) (float32, bool) {
+	edge1 := triangle.B.Sub(triangle.A)
+	edge2 := triangle.C.Sub(triangle.A)
+
+	h := direction.Cross(edge2)
+	determinant := edge1.Dot(h)
+	if determinant > -epsilon && determinant < epsilon {
+		return 0, false
+	}
+
+	inverse := 1 / determinant
+	s := origin.Sub(triangle.A)
+	u := inverse * s.Dot(h)
+	if u < 0 || u > 1 {
+		return 0, false
+	}
+
+	q := s.Cross(edge1)
+	v := inverse * direction.Dot(q)
+	if v < 0 || u + v > 1 {
+		return 0, false
+	}
+
+	t := inverse * edge2.Dot(q)
+	return t, t >= 0
+}
+
+//
+// CapsuleContact
+// Finds the contact between the triangle and a capsule (a sphere is a capsule with A == B)
+//
+// @param capsule (Capsule) the capsule, in the same space as the triangle
+//
+// @return contact (Contact) the contact
+// @return ok (bool) false if they don't touch
+//
+func (triangle Triangle) CapsuleContact(capsule Capsule) (Contact, bool) {
+	onSegment, onTriangle, crosses := triangle.closestToSegment(capsule.A, capsule.B)
+
+	if !crosses {
+		offset := onSegment.Sub(onTriangle)
+		distance := offset.Len()
+		if distance > capsule.Radius {
+			return Contact{}, false
+		}
+
+		if distance > epsilon {
+			return Contact{ onTriangle, offset.Mul(1 / distance), capsule.Radius - distance }, true
+		}
+	}
+
+	// The segment touches the triangle: it's pushed out along the normal,
+	// to the side where most of the segment is
+	normal := triangle.Normal()
+	distanceA := capsule.A.Sub(triangle.A).Dot(normal)
+	distanceB := capsule.B.Sub(triangle.A).Dot(normal)
+	if distanceA + distanceB < 0 {
+		normal = normal.Mul(-1)
+		distanceA, distanceB = -distanceA, -distanceB
+	}
+
+	return Contact{ onTriangle, normal, capsule.Radius - minFloat32(minFloat32(distanceA, distanceB), 0) }, true
+}
+
+// closestToSegment returns the closest points between the segment (a, b) and the triangle,
+// and true if the segment goes through the triangle
+func (triangle Triangle) closestToSegment(a, b mgl32.Vec3) (mgl32.Vec3, mgl32.Vec3, bool) {
+	direction := b.Sub(a)
+	if direction.Dot(direction) > epsilon * epsilon {
+		if t, ok := triangle.Raycast(a, direction); ok && t <= 1 {
+			point := a.Add(direction.Mul(t))
+			return point, point, true
+		}
+	}
+
+	// Otherwise the closest points are on the ends of the segment or the edges of the triangle
+	bestSegment, bestTriangle := a, triangle.ClosestPoint(a)
+	best := bestSegment.Sub(bestTriangle).Len()
+
+	candidates := [][2]mgl32.Vec3{ { b, triangle.ClosestPoint(b) } }
+	for _, edge := range [3][2]mgl32.Vec3{ { triangle.A, triangle.B }, { triangle.B, triangle.C }, { triangle.C, triangle.A } } {
+		onSegment, onEdge := closestSegmentSegment(a, b, edge[0], edge[1])
+		candidates = append(candidates, [2]mgl32.Vec3{ onSegment, onEdge })
+	}
+
+	for _, candidate := range candidates {
+		if distance := candidate[0].Sub(candidate[1]).Len(); distance < best {
+			best, bestSegment, bestTriangle = distance, candidate[0], candidate[1]
+		}
+	}
+
+	return bestSegment, bestTriangle, false
+}
+
+// closestSegmentSegment returns the closest points between the segments (p1, q1) and (p2, q2)
+// (Real-Time Collision Detection, Christer Ericson, 5.1.9)
+func closestSegmentSegment(p1, q1, p2, q2 mgl32.Vec3) (mgl32.Vec3, mgl32.Vec3) {
+	d1, d2 := q1.Sub(p1), q2.Sub(p2)
+	r := p1.Sub(p2)
+	a, e, f := d1.Dot(d1), d2.Dot(d2), d2.Dot(r)
+
+	var s, t float32
+	switch {
+	case a <= epsilon && e <= epsilon:
+		return p1, p2
+	case a <= epsilon:
+		t = mgl32.Clamp(f / e, 0, 1)
+	default:
+		c := d1.Dot(r)
+		if e <= epsilon {
+			s = mgl32.Clamp(-c / a, 0, 1)
+		} else {
+			b := d1.Dot(d2)
+			denominator := a * e - b * b
+			if denominator != 0 {
+				s = mgl32.Clamp((b * f - c * e) / denominator, 0, 1)
+			}
+
+			t = (b * s + f) / e
+			if t < 0 {
+				t, s = 0, mgl32.Clamp(-c / a, 0, 1)
+			} else if t > 1 {
+				t, s = 1, mgl32.Clamp((b - c) / a, 0, 1)
+			}
+		}
+	}
+
+	return p1.Add(d1.Mul(s)), p2.Add(d2.Mul(t))
+}
@@ -0,0 +1,100 @@
+package collision
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var unitTriangle = Triangle{ mgl32.Vec3{ 0, 0, 0 }, mgl32.Vec3{ 1, 0, 0 }, mgl32.Vec3{ 0, 1, 0 } }
+
+func TestClosestPointRegions(t *testing.T) {
+	cases := []struct {
+		point, expected mgl32.Vec3
+	}{
+		{ mgl32.Vec3{ -1, -1, 0 }, mgl32.Vec3{ 0, 0, 0 } },       // Vertex A
+		{ mgl32.Vec3{ 2, -1, 1 }, mgl32.Vec3{ 1, 0, 0 } },        // Vertex B
+		{ mgl32.Vec3{ -1, 2, -1 }, mgl32.Vec3{ 0, 1, 0 } },       // Vertex C
+		{ mgl32.Vec3{ 0.5, -1, 3 }, mgl32.Vec3{ 0.5, 0, 0 } },    // Edge AB
+		{ mgl32.Vec3{ -1, 0.5, 0 }, mgl32.Vec3{ 0, 0.5, 0 } },    // Edge AC
+		{ mgl32.Vec3{ 1, 1, 0 }, mgl32.Vec3{ 0.5, 0.5, 0 } },     // Edge BC
+		{ mgl32.Vec3{ 0.25, 0.25, 5 }, mgl32.Vec3{ 0.25, 0.25, 0 } }, // Inside, above
+		{ mgl32.Vec3{ 0.1, 0.2, -2 }, mgl32.Vec3{ 0.1, 0.2, 0 } },    // Inside, below
+	}
+
+	for _, c := range cases {
+		if closest := unitTriangle.ClosestPoint(c.point); !closest.ApproxEqualThreshold(c.expected, 1e-6) {
+			t.Errorf("%v: expected %v, found %v", c.point, c.expected, closest)
+		}
+	}
+}
+
+func TestClosestPointIsTheClosest(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	point := func(scale float32) mgl32.Vec3 {
+		return mgl32.Vec3{ (random.Float32() - 0.5) * scale, (random.Float32() - 0.5) * scale, (random.Float32() - 0.5) * scale }
+	}
+
+	for i := 0; i < 200; i++ {
+		triangle := Triangle{ point(4), point(4), point(4) }
+		p := point(8)
+		closest := triangle.ClosestPoint(p)
+		distance := closest.Sub(p).Len()
+
+		// No point of the triangle (sampled with barycentric coordinates) is closer
+		for u := float32(0); u <= 1; u += 0.05 {
+			for v := float32(0); u + v <= 1; v += 0.05 {
+				sample := triangle.A.Mul(1 - u - v).Add(triangle.B.Mul(u)).Add(triangle.C.Mul(v))
+				if sample.Sub(p).Len() < distance - 1e-4 {
+					t.Fatalf("triangle %v, point %v: %v is closer than %v", triangle, p, sample, closest)
+				}
+			}
+		}
+	}
+}
+
+func TestTriangleRaycast(t *testing.T) {
+	cases := []struct {
+		origin, direction mgl32.Vec3
+		hit               bool
+		t                 float32
+	}{
+		{ mgl32.Vec3{ 0.25, 0.25, 2 }, mgl32.Vec3{ 0, 0, -1 }, true, 2 },    // Front side
+		{ mgl32.Vec3{ 0.25, 0.25, -4 }, mgl32.Vec3{ 0, 0, 2 }, true, 2 },    // Back side, longer direction
+		{ mgl32.Vec3{ 0, 0, 1 }, mgl32.Vec3{ 0, 0, -1 }, true, 1 },          // On a vertex
+		{ mgl32.Vec3{ 0.75, 0.75, 1 }, mgl32.Vec3{ 0, 0, -1 }, false, 0 },   // Outside of the edge BC
+		{ mgl32.Vec3{ -0.1, 0.5, 1 }, mgl32.Vec3{ 0, 0, -1 }, false, 0 },    // Outside of the edge AC
+		{ mgl32.Vec3{ 0.25, 0.25, 2 }, mgl32.Vec3{ 0, 0, 1 }, false, 0 },    // Pointing away
+		{ mgl32.Vec3{ -1, 0.25, 0 }, mgl32.Vec3{ 1, 0, 0 }, false, 0 },      // Parallel
+	}
+
+	for _, c := range cases {
+		distance, hit := unitTriangle.Raycast(c.origin, c.direction)
+		if hit != c.hit || (hit && mgl32.Abs(distance - c.t) > 1e-6) {
+			t.Errorf("ray %v %v: expected (%v, %f), found (%v, %f)", c.origin, c.direction, c.hit, c.t, hit, distance)
+		}
+	}
+
+	if normal := unitTriangle.Normal(); !normal.ApproxEqual(mgl32.Vec3{ 0, 0, 1 }) {
+		t.Errorf("expected the normal (0, 0, 1), found %v", normal)
+	}
+}
+
+func TestTriangleCapsuleContact(t *testing.T) {
+	// A sphere 0.3 over the triangle with a radius of 0.5 goes 0.2 into it
+	contact, ok := unitTriangle.CapsuleContact(Capsule{ mgl32.Vec3{ 0.25, 0.25, 0.3 }, mgl32.Vec3{ 0.25, 0.25, 0.3 }, 0.5 })
+	if !ok || mgl32.Abs(contact.Depth - 0.2) > 1e-5 || !contact.Normal.ApproxEqual(mgl32.Vec3{ 0, 0, 1 }) {
+		t.Errorf("unexpected contact %+v (%v)", contact, ok)
+	}
+
+	// Below the triangle it pushes the other way
+	contact, ok = unitTriangle.CapsuleContact(Capsule{ mgl32.Vec3{ 0.25, 0.25, -0.3 }, mgl32.Vec3{ 0.25, 0.25, -0.3 }, 0.5 })
+	if !ok || !contact.Normal.ApproxEqual(mgl32.Vec3{ 0, 0, -1 }) {
+		t.Errorf("unexpected contact %+v (%v)", contact, ok)
+	}
+
+	if _, ok := unitTriangle.CapsuleContact(Capsule{ mgl32.Vec3{ 0.25, 0.25, 0.6 }, mgl32.Vec3{ 0.25, 0.25, 2 }, 0.5 }); ok {
+		t.Error("the capsule is above the triangle")
+	}
+}
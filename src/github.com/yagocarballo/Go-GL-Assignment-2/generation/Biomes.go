@@ -0,0 +1,230 @@
+package generation
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//
+// BiomeRule
+// A material (sand, grass, rock, snow...) and where it appears.
+// Heights and moisture go from 0 to 1 and slopes are in degrees (0 is flat, 90 is a wall)
+//
+type BiomeRule struct {
+	Name                     string
+	Color                    mgl32.Vec3
+
+	MinHeight, MaxHeight     float32
+	MinSlope, MaxSlope       float32
+	MinMoisture, MaxMoisture float32
+
+	Blend                    float32 // Width of the soft edge around the ranges (in height / moisture units, slopes use Blend * 90)
+}
+
+//
+// Biomes
+// The rule set used to give a colour and splat weights to every vertex of a terrain
+//
+type Biomes struct {
+	Rules            []BiomeRule
+
+	MoistureSeed     int64
+	MoistureSettings *NoiseSettings // If nil, the moisture is 0.5 everywhere
+	MoistureScale    float64        // Noise units per world unit
+}
+
+//
+// NewBiomes
+// Creates the default rule set: sand, grass, rock and snow
+//
+// @return biomes (*Biomes) a pointer to the rule set
+//
+func NewBiomes() *Biomes {
+	return &Biomes{
+		[]BiomeRule{
+			{ "Sand",  mgl32.Vec3{ 0.76, 0.70, 0.50 }, 0.00, 0.40, 0, 90, 0, 1, 0.05 },
+			{ "Grass", mgl32.Vec3{ 0.30, 0.50, 0.18 }, 0.40, 0.70, 0, 35, 0, 1, 0.05 },
+			{ "Rock",  mgl32.Vec3{ 0.45, 0.42, 0.40 }, 0.40, 1.00, 35, 90, 0, 1, 0.05 },
+			{ "Snow",  mgl32.Vec3{ 0.95, 0.95, 0.97 }, 0.70, 1.00, 0, 35, 0, 1, 0.05 },
+		},
+		0,
+		nil,
+		1.0 / 350.0,
+	}
+}
+
+//
+// Weights
+// Calculates how much of every rule there is on a point, the weights add up to 1
+//
+// @param height (float32) the height (0 to 1)
+// @param slope (float32) the slope in degrees
+// @param moisture (float32) the moisture (0 to 1)
+//
+// @return weights ([]float32) one weight per rule
+//
+func (biomes *Biomes) Weights(height, slope, moisture float32) []float32 {
+	weights := make([]float32, len(biomes.Rules))
+	total := float32(0)
+
+	for i, rule := range biomes.Rules {
+		weights[i] = band(height, rule.MinHeight, rule.MaxHeight, rule.Blend) *
+			band(slope, rule.MinSlope, rule.MaxSlope, rule.Blend * 90.0) *
+			band(moisture, rule.MinMoisture, rule.MaxMoisture, rule.Blend)
+		total += weights[i]
+	}
+
+	// If no rule matches, it falls back to the first one
+	if total == 0 {
+		if len(weights) > 0 {
+			weights[0] = 1
+		}
+		return weights
+	}
+
+	for i := range weights {
+		weights[i] /= total
+	}
+
+	return weights
+}
+
+//
+// Color
+// Mixes the colours of the rules with the given weights
+//
+// @param weights ([]float32) one weight per rule
+//
+// @return color (mgl32.Vec3) the mixed colour
+//
+func (biomes *Biomes) Color(weights []float32) mgl32.Vec3 {
+	mixed := mgl32.Vec3{}
+	for i, rule := range biomes.Rules {
+		if i < len(weights) {
+			mixed = mixed.Add(rule.Color.Mul(weights[i]))
+		}
+	}
+
+	return mixed
+}
+
+//
+// Moisture
+// Generates the moisture for a (xSize * zSize) grid of points in world coordinates,
+// so two grids that share an edge get the same moisture on it
+//
+// @param startCol (int64) the first column, in (spacing) steps from the world origin
+// @param startRow (int64) the first row, in (spacing) steps from the world origin
+// @param xSize (uint32) number of columns
+// @param zSize (uint32) number of rows
+// @param spacing (float64) distance between two points, in world units
+//
+// @return moisture ([]float32) the moisture, stored as [row * xSize + col]
+//
+func (biomes *Biomes) Moisture(startCol, startRow int64, xSize, zSize uint32, spacing float64) []float32 {
+	if biomes.MoistureSettings == nil {
+		moisture := make([]float32, xSize * zSize)
+		for i := range moisture {
+			moisture[i] = 0.5
+		}
+		return moisture
+	}
+
+	var source RegionSource = NewSimplexSource(biomes.MoistureSeed, *biomes.MoistureSettings)
+	return source.GenerateRegion(startCol, startRow, xSize, zSize, spacing * biomes.MoistureScale)
+}
+
+//
+// SlopeFromNormal
+// The angle between a normal and the up vector, in degrees
+//
+// @param normal (mgl32.Vec3) the normal (doesn't need to be normalised)
+//
+// @return slope (float32) the slope in degrees
+//
+func SlopeFromNormal(normal mgl32.Vec3) float32 {
+	length := normal.Len()
+	if length == 0 {
+		return 0
+	}
+
+	cosine := math.Abs(float64(normal.Y() / length))
+	if cosine > 1 {
+		cosine = 1
+	}
+
+	return float32(math.Acos(cosine) * 180.0 / math.Pi)
+}
+
+//
+// SplatMap
+// Stores the weights of the first 4 rules in the RGBA channels of an image
+//
+// @param weights ([][]float32) the weights of every point, stored as [row * width + col]
+// @param width (int) the width of the image
+// @param height (int) the height of the image
+//
+// @return image (*image.RGBA) the splat map
+//
+func SplatMap(weights [][]float32, width, height int) *image.RGBA {
+	splat := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			var channels [4]uint8
+			for c := 0; c < 4 && c < len(weights[row * width + col]); c++ {
+				channels[c] = uint8(clampFloat32(weights[row * width + col][c], 0, 1) * 255.0 + 0.5)
+			}
+
+			splat.SetRGBA(col, row, color.RGBA{ channels[0], channels[1], channels[2], channels[3] })
+		}
+	}
+
+	return splat
+}
+
+//
+// SavePNG
+// Saves an image as a PNG file
+//
+// @param path (string) the path of the file
+// @param img (image.Image) the image
+//
+// @return error (error) the error (if any)
+//
+func SavePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// band is 1 inside [min, max] and goes down to 0 over (blend) units outside of it
+func band(value, min, max, blend float32) float32 {
+	if value >= min && value <= max {
+		return 1
+	}
+
+	if blend <= 0 {
+		return 0
+	}
+
+	distance := min - value
+	if value > max {
+		distance = value - max
+	}
+
+	return clampFloat32(1 - distance / blend, 0, 1)
+}
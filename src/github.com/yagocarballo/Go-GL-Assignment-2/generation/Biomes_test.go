@@ -0,0 +1,184 @@
+package generation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// weightOf returns the weight of the rule with that name
+func weightOf(biomes *Biomes, weights []float32, name string) float32 {
+	for i, rule := range biomes.Rules {
+		if rule.Name == name {
+			return weights[i]
+		}
+	}
+
+	return -1
+}
+
+func TestBiomeWeightsOnSyntheticPoints(t *testing.T) {
+	biomes := NewBiomes()
+
+	cases := []struct {
+		height, slope float32
+		expected      string
+	}{
+		{ 0.10, 0, "Sand" },   // A flat beach
+		{ 0.10, 60, "Sand" },  // Sand has no slope limit
+		{ 0.55, 10, "Grass" }, // A gentle hill
+		{ 0.55, 60, "Rock" },  // A cliff
+		{ 0.90, 10, "Snow" },  // A flat mountain top
+		{ 0.90, 70, "Rock" },  // A steep mountain side
+	}
+
+	for _, c := range cases {
+		weights := biomes.Weights(c.height, c.slope, 0.5)
+		if weight := weightOf(biomes, weights, c.expected); weight != 1 {
+			t.Errorf("height %.2f, slope %.0f: expected only %s, found %v", c.height, c.slope, c.expected, weights)
+		}
+	}
+}
+
+func TestBiomeWeightsBlendAndAddUpToOne(t *testing.T) {
+	biomes := NewBiomes()
+
+	for height := float32(0); height <= 1; height += 0.01 {
+		for slope := float32(0); slope <= 90; slope += 2.5 {
+			weights := biomes.Weights(height, slope, 0.5)
+
+			sum := float32(0)
+			for _, weight := range weights {
+				if weight < 0 || weight > 1 {
+					t.Fatalf("height %.2f, slope %.1f: weight %f is outside of [0, 1]", height, slope, weight)
+				}
+				sum += weight
+			}
+
+			if math.Abs(float64(sum - 1)) > 1e-5 {
+				t.Fatalf("height %.2f, slope %.1f: the weights add up to %f", height, slope, sum)
+			}
+		}
+	}
+
+	// On the edge between grass and snow both are mixed
+	weights := biomes.Weights(0.72, 10, 0.5)
+	grass, snow := weightOf(biomes, weights, "Grass"), weightOf(biomes, weights, "Snow")
+	if grass <= 0 || snow <= grass {
+		t.Errorf("expected a blend of grass and (mostly) snow, found %v", weights)
+	}
+
+	// The slope blends over (Blend * 90) degrees
+	weights = biomes.Weights(0.55, 37, 0.5)
+	if weightOf(biomes, weights, "Grass") <= 0 || weightOf(biomes, weights, "Rock") <= 0 {
+		t.Errorf("expected a blend of grass and rock, found %v", weights)
+	}
+}
+
+func TestBiomeWeightsWithMoistureAndFallback(t *testing.T) {
+	biomes := &Biomes{
+		[]BiomeRule{
+			{ "Desert", mgl32.Vec3{ 1, 1, 0 }, 0, 1, 0, 90, 0.0, 0.3, 0 },
+			{ "Forest", mgl32.Vec3{ 0, 1, 0 }, 0, 1, 0, 45, 0.6, 1.0, 0 },
+		},
+		0,
+		nil,
+		1.0,
+	}
+
+	if weights := biomes.Weights(0.5, 10, 0.1); weights[0] != 1 || weights[1] != 0 {
+		t.Errorf("dry: expected only desert, found %v", weights)
+	}
+	if weights := biomes.Weights(0.5, 10, 0.9); weights[0] != 0 || weights[1] != 1 {
+		t.Errorf("wet: expected only forest, found %v", weights)
+	}
+
+	// Nothing matches a steep and wet point, so it uses the first rule
+	if weights := biomes.Weights(0.5, 80, 0.9); weights[0] != 1 || weights[1] != 0 {
+		t.Errorf("expected the fallback to the first rule, found %v", weights)
+	}
+
+	if color := biomes.Color([]float32{ 0.5, 0.5 }); !color.ApproxEqual(mgl32.Vec3{ 0.5, 1, 0 }) {
+		t.Errorf("expected a mixed colour, found %v", color)
+	}
+
+	empty := &Biomes{}
+	if weights := empty.Weights(0.5, 10, 0.5); len(weights) != 0 {
+		t.Errorf("expected no weights, found %v", weights)
+	}
+}
+
+func TestSlopeFromNormal(t *testing.T) {
+	cases := []struct {
+		normal   mgl32.Vec3
+		expected float32
+	}{
+		{ mgl32.Vec3{ 0, 1, 0 }, 0 },
+		{ mgl32.Vec3{ 0, -3, 0 }, 0 },
+		{ mgl32.Vec3{ 1, 1, 0 }, 45 },
+		{ mgl32.Vec3{ 0, 1, -1 }, 45 },
+		{ mgl32.Vec3{ 1, 0, 0 }, 90 },
+		{ mgl32.Vec3{ 0, 1, float32(math.Sqrt(3)) }, 60 },
+		{ mgl32.Vec3{ 0, 0, 0 }, 0 },
+	}
+
+	for _, c := range cases {
+		if slope := SlopeFromNormal(c.normal); math.Abs(float64(slope - c.expected)) > 1e-3 {
+			t.Errorf("%v: expected %f degrees, found %f", c.normal, c.expected, slope)
+		}
+	}
+}
+
+func TestMoistureIsContinuousAcrossRegions(t *testing.T) {
+	settings := NewNoiseSettings(4.0, 5.0)
+	biomes := NewBiomes()
+	biomes.MoistureSeed = 5
+	biomes.MoistureSettings = &settings
+
+	// Two 9x9 grids, the second one starts on the last column of the first one
+	spacing := 50.0 / 8.0
+	left := biomes.Moisture(-4, 12, 9, 9, spacing)
+	right := biomes.Moisture(4, 12, 9, 9, spacing)
+
+	for row := 0; row < 9; row++ {
+		if left[row * 9 + 8] != right[row * 9] {
+			t.Fatalf("row %d: the moisture jumps from %f to %f", row, left[row * 9 + 8], right[row * 9])
+		}
+	}
+
+	// The moisture is sampled in world units: half the spacing is every other point
+	fine := biomes.Moisture(-8, 24, 17, 17, spacing / 2.0)
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if math.Abs(float64(fine[(row * 2) * 17 + col * 2] - left[row * 9 + col])) > 1e-6 {
+				t.Fatalf("(%d, %d): %f != %f", col, row, fine[(row * 2) * 17 + col * 2], left[row * 9 + col])
+			}
+		}
+	}
+
+	biomes.MoistureSettings = nil
+	for i, moisture := range biomes.Moisture(0, 0, 3, 3, spacing) {
+		if moisture != 0.5 {
+			t.Fatalf("moisture %d: expected 0.5 without settings, found %f", i, moisture)
+		}
+	}
+}
+
+func TestSplatMapChannels(t *testing.T) {
+	weights := [][]float32{
+		{ 1, 0, 0, 0 },
+		{ 0, 0.5, 0.5, 0 },
+		{ 0, 0, 0, 1, 1 }, // The fifth rule doesn't fit in the image
+		{ 2, -1 },
+	}
+
+	splat := SplatMap(weights, 2, 2)
+	expected := [][4]uint8{ { 255, 0, 0, 0 }, { 0, 128, 128, 0 }, { 0, 0, 0, 255 }, { 255, 0, 0, 0 } }
+	for i, channels := range expected {
+		pixel := splat.RGBAAt(i % 2, i / 2)
+		if pixel.R != channels[0] || pixel.G != channels[1] || pixel.B != channels[2] || pixel.A != channels[3] {
+			t.Errorf("pixel %d: expected %v, found %v", i, channels, pixel)
+		}
+	}
+}
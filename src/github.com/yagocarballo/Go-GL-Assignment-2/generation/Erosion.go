@@ -0,0 +1,345 @@
+package generation
+
+import (
+	"math"
+	"math/rand"
+)
+
+//
+// HydraulicSettings
+// Parameters for the particle (droplet) based hydraulic erosion
+//
+type HydraulicSettings struct {
+	Seed                int64   // Seed for the droplet start positions
+	Droplets            int     // Number of droplets simulated (0 disables hydraulic erosion)
+	MaxLifetime         int     // Maximum number of steps of a droplet
+
+	Inertia             float32 // How much a droplet keeps its direction (0 to 1)
+	SedimentCapacity    float32 // Multiplier for how much sediment a droplet can carry
+	MinSedimentCapacity float32 // Prevents the capacity from falling to 0 on flat ground
+	ErodeSpeed          float32 // Fraction of the free capacity eroded every step
+	DepositSpeed        float32 // Fraction of the surplus sediment deposited every step
+	EvaporateSpeed      float32 // Fraction of the water evaporated every step
+	Gravity             float32 // Acceleration of the droplets going down hill
+
+	InitialWater        float32
+	InitialSpeed        float32
+}
+
+//
+// ThermalSettings
+// Parameters for the thermal erosion (material slumps down slopes steeper than the talus angle)
+//
+type ThermalSettings struct {
+	Iterations          int     // Number of passes over the whole height field (0 disables thermal erosion)
+	TalusAngle          float32 // Steepest stable slope, in degrees
+	CellSize            float32 // Horizontal distance between two cells, in height units
+	Rate                float32 // Fraction of the unstable material moved every pass (0 to 1)
+}
+
+//
+// ErosionSettings
+// Groups the hydraulic and thermal erosion, hydraulic runs first
+//
+type ErosionSettings struct {
+	Hydraulic HydraulicSettings
+	Thermal   ThermalSettings
+}
+
+//
+// Erosion
+// Erodes a height field in place and keeps track of where the material went
+//
+type Erosion struct {
+	Width, Depth uint32
+	Heights      []float32 // The height field, stored as [row * Width + col]
+	Sediment     []float32 // Material deposited on every cell
+	Flow         []float32 // Water that went through every cell
+	Lost         float32   // Sediment the droplets still carried when they left the map or evaporated
+}
+
+//
+// NewHydraulicSettings
+// Creates hydraulic erosion settings with sensible defaults for heights between 0 and 1
+//
+// @param seed (int64) the seed for the droplets
+// @param droplets (int) the number of droplets
+//
+// @return settings (HydraulicSettings) the settings
+//
+func NewHydraulicSettings(seed int64, droplets int) HydraulicSettings {
+	return HydraulicSettings{
+		seed,		// Seed
+		droplets,	// Droplets
+		30,			// MaxLifetime
+
+		0.05,		// Inertia
+		4.0,		// SedimentCapacity
+		0.0001,		// MinSedimentCapacity
+		0.3,		// ErodeSpeed
+		0.3,		// DepositSpeed
+		0.01,		// EvaporateSpeed
+		4.0,		// Gravity
+
+		1.0,		// InitialWater
+		1.0,		// InitialSpeed
+	}
+}
+
+//
+// NewThermalSettings
+// Creates thermal erosion settings
+//
+// @param iterations (int) number of passes
+// @param talusAngle (float32) the steepest stable slope in degrees
+// @param cellSize (float32) distance between two cells in height units
+//
+// @return settings (ThermalSettings) the settings
+//
+func NewThermalSettings(iterations int, talusAngle, cellSize float32) ThermalSettings {
+	return ThermalSettings{ iterations, talusAngle, cellSize, 0.5 }
+}
+
+//
+// NewErosion
+// Creates an Erosion over the given heights (the heights are modified in place)
+//
+// @param heights ([]float32) the height field, stored as [row * width + col]
+// @param width (uint32) number of columns
+// @param depth (uint32) number of rows
+//
+// @return erosion (*Erosion) a pointer to the Erosion
+//
+func NewErosion(heights []float32, width, depth uint32) *Erosion {
+	return &Erosion{
+		width,
+		depth,
+		heights,
+		make([]float32, width * depth),
+		make([]float32, width * depth),
+		0,
+	}
+}
+
+//
+// Erode
+// Runs the hydraulic erosion and then the thermal erosion
+//
+// @param settings (ErosionSettings) the erosion settings
+//
+func (erosion *Erosion) Erode(settings ErosionSettings) {
+	erosion.Hydraulic(settings.Hydraulic)
+	erosion.Thermal(settings.Thermal)
+}
+
+//
+// Hydraulic
+// Simulates droplets that run down hill, picking up sediment where they speed up
+// and dropping it where they slow down or go up hill
+//
+// @param settings (HydraulicSettings) the hydraulic erosion settings
+//
+func (erosion *Erosion) Hydraulic(settings HydraulicSettings) {
+	if erosion.Width < 2 || erosion.Depth < 2 {
+		return
+	}
+
+	random := rand.New(rand.NewSource(settings.Seed))
+	maxX := float32(erosion.Width - 1)
+	maxZ := float32(erosion.Depth - 1)
+
+	for droplet := 0; droplet < settings.Droplets; droplet++ {
+		x := random.Float32() * maxX
+		z := random.Float32() * maxZ
+		dirX, dirZ := float32(0), float32(0)
+		speed := settings.InitialSpeed
+		water := settings.InitialWater
+		sediment := float32(0)
+
+		for step := 0; step < settings.MaxLifetime; step++ {
+			height, gradientX, gradientZ := erosion.heightAndGradient(x, z)
+
+			// The droplet keeps part of its direction and the rest follows the slope
+			dirX = dirX * settings.Inertia - gradientX * (1 - settings.Inertia)
+			dirZ = dirZ * settings.Inertia - gradientZ * (1 - settings.Inertia)
+
+			length := float32(math.Sqrt(float64(dirX * dirX + dirZ * dirZ)))
+			if length == 0 {
+				break
+			}
+			dirX /= length
+			dirZ /= length
+
+			oldX, oldZ := x, z
+			x += dirX
+			z += dirZ
+
+			// Stop when the droplet leaves the map
+			if x < 0 || z < 0 || x >= maxX || z >= maxZ {
+				break
+			}
+
+			erosion.Flow[erosion.index(oldX, oldZ)] += water
+
+			newHeight, _, _ := erosion.heightAndGradient(x, z)
+			deltaHeight := newHeight - height
+
+			capacity := -deltaHeight * speed * water * settings.SedimentCapacity
+			if capacity < settings.MinSedimentCapacity {
+				capacity = settings.MinSedimentCapacity
+			}
+
+			if sediment > capacity || deltaHeight > 0 {
+				// Going up hill fills the hole behind, otherwise drops the surplus
+				var amount float32
+				if deltaHeight > 0 {
+					amount = float32(math.Min(float64(deltaHeight), float64(sediment)))
+				} else {
+					amount = (sediment - capacity) * settings.DepositSpeed
+				}
+
+				sediment -= amount
+				erosion.spread(oldX, oldZ, amount)
+			} else {
+				// Never erodes more than the height difference, so it doesn't dig holes
+				amount := float32(math.Min(float64((capacity - sediment) * settings.ErodeSpeed), float64(-deltaHeight)))
+
+				sediment += amount
+				erosion.spread(oldX, oldZ, -amount)
+			}
+
+			speedSquared := speed * speed - deltaHeight * settings.Gravity
+			if speedSquared < 0 {
+				speedSquared = 0
+			}
+			speed = float32(math.Sqrt(float64(speedSquared)))
+			water *= (1 - settings.EvaporateSpeed)
+		}
+
+		// Whatever the droplet carries is not put back on the map
+		erosion.Lost += sediment
+	}
+}
+
+//
+// Thermal
+// Moves material from every cell to its lower neighbours when the slope
+// between them is steeper than the talus angle
+//
+// @param settings (ThermalSettings) the thermal erosion settings
+//
+func (erosion *Erosion) Thermal(settings ThermalSettings) {
+	if settings.Iterations <= 0 {
+		return
+	}
+
+	cellSize := settings.CellSize
+	if cellSize <= 0 {
+		cellSize = 1.0
+	}
+
+	talus := float32(math.Tan(float64(settings.TalusAngle) * math.Pi / 180.0)) * cellSize
+	width, depth := int(erosion.Width), int(erosion.Depth)
+	delta := make([]float32, len(erosion.Heights))
+	neighbours := [][2]int{ {-1, 0}, {1, 0}, {0, -1}, {0, 1} }
+
+	for iteration := 0; iteration < settings.Iterations; iteration++ {
+		for i := range delta {
+			delta[i] = 0
+		}
+
+		// The changes are collected first, so the result doesn't depend on the loop order
+		for row := 0; row < depth; row++ {
+			for col := 0; col < width; col++ {
+				current := erosion.Heights[row * width + col]
+				maxDifference := float32(0)
+				totalDifference := float32(0)
+
+				for _, n := range neighbours {
+					nCol, nRow := col + n[0], row + n[1]
+					if nCol < 0 || nRow < 0 || nCol >= width || nRow >= depth {
+						continue
+					}
+
+					difference := current - erosion.Heights[nRow * width + nCol]
+					if difference > talus {
+						totalDifference += difference
+						if difference > maxDifference {
+							maxDifference = difference
+						}
+					}
+				}
+
+				if totalDifference == 0 {
+					continue
+				}
+
+				moved := settings.Rate * (maxDifference - talus) / 2.0
+				delta[row * width + col] -= moved
+
+				for _, n := range neighbours {
+					nCol, nRow := col + n[0], row + n[1]
+					if nCol < 0 || nRow < 0 || nCol >= width || nRow >= depth {
+						continue
+					}
+
+					difference := current - erosion.Heights[nRow * width + nCol]
+					if difference > talus {
+						delta[nRow * width + nCol] += moved * (difference / totalDifference)
+					}
+				}
+			}
+		}
+
+		for i := range delta {
+			erosion.Heights[i] += delta[i]
+			if delta[i] > 0 {
+				erosion.Sediment[i] += delta[i]
+			}
+		}
+	}
+}
+
+// index returns the position of the cell that contains (x, z)
+func (erosion *Erosion) index(x, z float32) int {
+	return int(z) * int(erosion.Width) + int(x)
+}
+
+// heightAndGradient interpolates the height and the slope at (x, z)
+func (erosion *Erosion) heightAndGradient(x, z float32) (float32, float32, float32) {
+	col, row := int(x), int(z)
+	fx, fz := x - float32(col), z - float32(row)
+	i := row * int(erosion.Width) + col
+
+	heightNW := erosion.Heights[i]
+	heightNE := erosion.Heights[i + 1]
+	heightSW := erosion.Heights[i + int(erosion.Width)]
+	heightSE := erosion.Heights[i + int(erosion.Width) + 1]
+
+	gradientX := (heightNE - heightNW) * (1 - fz) + (heightSE - heightSW) * fz
+	gradientZ := (heightSW - heightNW) * (1 - fx) + (heightSE - heightNE) * fx
+
+	height := heightNW * (1 - fx) * (1 - fz) +
+		heightNE * fx * (1 - fz) +
+		heightSW * (1 - fx) * fz +
+		heightSE * fx * fz
+
+	return height, gradientX, gradientZ
+}
+
+// spread adds (or removes, if negative) an amount of material to the 4 cells around (x, z)
+func (erosion *Erosion) spread(x, z, amount float32) {
+	col, row := int(x), int(z)
+	fx, fz := x - float32(col), z - float32(row)
+	i := row * int(erosion.Width) + col
+
+	cells := [4]int{ i, i + 1, i + int(erosion.Width), i + int(erosion.Width) + 1 }
+	weights := [4]float32{ (1 - fx) * (1 - fz), fx * (1 - fz), (1 - fx) * fz, fx * fz }
+
+	for c, cell := range cells {
+		erosion.Heights[cell] += amount * weights[c]
+		if amount > 0 {
+			erosion.Sediment[cell] += amount * weights[c]
+		}
+	}
+}
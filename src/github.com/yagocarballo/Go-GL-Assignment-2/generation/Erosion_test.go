@@ -0,0 +1,166 @@
+package generation
+
+import (
+	"math"
+	"testing"
+)
+
+// cone returns a (size * size) height field with a peak in the middle
+func cone(size uint32) []float32 {
+	heights := make([]float32, size * size)
+	center := float64(size - 1) / 2.0
+	for row := uint32(0); row < size; row++ {
+		for col := uint32(0); col < size; col++ {
+			distance := math.Hypot(float64(col) - center, float64(row) - center)
+			heights[row * size + col] = float32(math.Max(0, 1 - distance / center))
+		}
+	}
+
+	return heights
+}
+
+// step returns a (size * size) height field with a cliff in the middle
+func step(size uint32) []float32 {
+	heights := make([]float32, size * size)
+	for row := uint32(0); row < size; row++ {
+		for col := size / 2; col < size; col++ {
+			heights[row * size + col] = 1
+		}
+	}
+
+	return heights
+}
+
+func total(heights []float32) float64 {
+	sum := 0.0
+	for _, height := range heights {
+		sum += float64(height)
+	}
+
+	return sum
+}
+
+// roughness adds up the squared differences between the neighbours
+func roughness(heights []float32, size uint32) (float64, float32) {
+	sum, steepest := 0.0, float32(0)
+	for row := uint32(0); row < size; row++ {
+		for col := uint32(0); col < size; col++ {
+			i := row * size + col
+			if col + 1 < size {
+				difference := heights[i + 1] - heights[i]
+				sum += float64(difference * difference)
+				steepest = float32(math.Max(float64(steepest), math.Abs(float64(difference))))
+			}
+			if row + 1 < size {
+				difference := heights[i + size] - heights[i]
+				sum += float64(difference * difference)
+				steepest = float32(math.Max(float64(steepest), math.Abs(float64(difference))))
+			}
+		}
+	}
+
+	return sum, steepest
+}
+
+func TestThermalConservesMass(t *testing.T) {
+	heights := step(32)
+	before := total(heights)
+
+	erosion := NewErosion(heights, 32, 32)
+	erosion.Thermal(NewThermalSettings(50, 30, 1.0 / 31.0))
+
+	if after := total(heights); math.Abs(after - before) > 1e-3 {
+		t.Errorf("thermal erosion changed the mass from %f to %f", before, after)
+	}
+}
+
+func TestThermalSmoothsACliff(t *testing.T) {
+	heights := step(32)
+	roughBefore, steepBefore := roughness(heights, 32)
+
+	erosion := NewErosion(heights, 32, 32)
+	settings := NewThermalSettings(200, 30, 1.0 / 31.0)
+	erosion.Thermal(settings)
+
+	roughAfter, steepAfter := roughness(heights, 32)
+	if roughAfter >= roughBefore || steepAfter >= steepBefore {
+		t.Errorf("expected a smoother field: roughness %f -> %f, steepest %f -> %f", roughBefore, roughAfter, steepBefore, steepAfter)
+	}
+
+	// The material slides to the bottom of the cliff
+	if heights[16 * 32 + 15] <= 0 {
+		t.Error("nothing was deposited at the bottom of the cliff")
+	}
+	if erosion.Sediment[16 * 32 + 15] <= 0 {
+		t.Error("the sediment map is empty at the bottom of the cliff")
+	}
+}
+
+func TestThermalKeepsStableSlopes(t *testing.T) {
+	// A 45 degree ramp is stable with a 60 degree talus angle
+	heights := make([]float32, 16 * 16)
+	for row := 0; row < 16; row++ {
+		for col := 0; col < 16; col++ {
+			heights[row * 16 + col] = float32(col)
+		}
+	}
+	original := append([]float32{}, heights...)
+
+	NewErosion(heights, 16, 16).Thermal(NewThermalSettings(20, 60, 1))
+	for i := range heights {
+		if heights[i] != original[i] {
+			t.Fatalf("height %d moved from %f to %f", i, original[i], heights[i])
+		}
+	}
+}
+
+func TestHydraulicConservesMass(t *testing.T) {
+	heights := cone(48)
+	before := total(heights)
+
+	erosion := NewErosion(heights, 48, 48)
+	erosion.Hydraulic(NewHydraulicSettings(5, 2000))
+
+	// The material is either still on the map or carried away by the droplets
+	after := total(heights) + float64(erosion.Lost)
+	if math.Abs(after - before) > 1e-3 {
+		t.Errorf("hydraulic erosion changed the mass from %f to %f (%f lost)", before, after, erosion.Lost)
+	}
+	if total(heights) >= before {
+		t.Error("the droplets didn't erode anything")
+	}
+}
+
+func TestHydraulicIsDeterministic(t *testing.T) {
+	first, second := cone(32), cone(32)
+
+	one := NewErosion(first, 32, 32)
+	one.Hydraulic(NewHydraulicSettings(11, 500))
+	two := NewErosion(second, 32, 32)
+	two.Hydraulic(NewHydraulicSettings(11, 500))
+
+	flow := float32(0)
+	for i := range first {
+		if first[i] != second[i] || one.Flow[i] != two.Flow[i] {
+			t.Fatalf("cell %d changed between two runs with the same seed", i)
+		}
+		if math.IsNaN(float64(first[i])) {
+			t.Fatalf("cell %d is NaN", i)
+		}
+		flow += one.Flow[i]
+	}
+
+	if flow == 0 {
+		t.Error("no water went through the map")
+	}
+}
+
+func TestErosionOnATinyField(t *testing.T) {
+	heights := []float32{ 1 }
+	erosion := NewErosion(heights, 1, 1)
+	erosion.Erode(ErosionSettings{ NewHydraulicSettings(1, 10), NewThermalSettings(5, 30, 1) })
+
+	if heights[0] != 1 {
+		t.Errorf("a single cell can't erode, found %f", heights[0])
+	}
+}
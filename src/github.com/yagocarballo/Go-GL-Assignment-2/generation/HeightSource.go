@@ -0,0 +1,177 @@
+package generation
+
+import (
+	"github.com/ojrac/opensimplex-go"
+)
+
+//
+// HeightSource
+// Anything that can generate a height field for a Terrain
+//
+type HeightSource interface {
+	//
+	// Generate
+	// Generates (xSize * zSize) heights, normally between 0 and 1
+	//
+	// @param xSize (uint32) number of columns
+	// @param zSize (uint32) number of rows
+	//
+	// @return heights ([]float32) the heights, stored as [row * xSize + col]
+	//
+	Generate(xSize, zSize uint32) []float32
+}
+
+//
+// RegionSource
+// A HeightSource without borders, any part of it can be generated and
+// two regions that share an edge get exactly the same heights on it
+//
+type RegionSource interface {
+	HeightSource
+
+	//
+	// GenerateRegion
+	// Generates (xSize * zSize) heights starting on the sample (startCol, startRow)
+	//
+	// @param startCol (int64) the first column, in samples
+	// @param startRow (int64) the first row, in samples
+	// @param xSize (uint32) number of columns
+	// @param zSize (uint32) number of rows
+	// @param step (float64) distance between two samples, in noise units
+	//
+	// @return heights ([]float32) the heights, stored as [row * xSize + col]
+	//
+	GenerateRegion(startCol, startRow int64, xSize, zSize uint32, step float64) []float32
+}
+
+//
+// Region
+// Turns a part of a RegionSource into a HeightSource (used by the terrain chunks)
+//
+type Region struct {
+	Source             RegionSource
+	StartCol, StartRow int64
+	Step               float64
+}
+
+func NewRegion(source RegionSource, startCol, startRow int64, step float64) *Region {
+	return &Region{ source, startCol, startRow, step }
+}
+
+func (region *Region) Generate(xSize, zSize uint32) []float32 {
+	return region.Source.GenerateRegion(region.StartCol, region.StartRow, xSize, zSize, region.Step)
+}
+
+//
+// SimplexSource
+// Generates the heights with fractal OpenSimplex noise
+//
+type SimplexSource struct {
+	Seed     int64
+	Settings NoiseSettings
+}
+
+func NewSimplexSource(seed int64, settings NoiseSettings) *SimplexSource {
+	return &SimplexSource{ seed, settings }
+}
+
+// Uses code adapted from OpenGL Shading Language Cookbook: Chapter 8
+func (source *SimplexSource) Generate(xSize, zSize uint32) []float32 {
+	heights := make([]float32, xSize * zSize)
+	octaves := make([]float32, source.Settings.OctaveCount())
+
+	xFactor := sampleStep(xSize)
+	zFactor := sampleStep(zSize)
+
+	noiseGenerator := opensimplex.NewWithSeed(source.Seed)
+
+	for row := uint32(0); row < zSize; row++ {
+		for col := uint32(0); col < xSize; col++ {
+			x := xFactor * float32(col)
+			z := zFactor * float32(row)
+
+			// The last octave holds the sum of all the octaves
+			source.Settings.Sample(noiseGenerator, x, z, octaves)
+			heights[row * xSize + col] = octaves[len(octaves) - 1]
+		}
+	}
+
+	return heights
+}
+
+func (source *SimplexSource) GenerateRegion(startCol, startRow int64, xSize, zSize uint32, step float64) []float32 {
+	heights := make([]float32, xSize * zSize)
+	octaves := make([]float32, source.Settings.OctaveCount())
+
+	noiseGenerator := opensimplex.NewWithSeed(source.Seed)
+
+	for row := uint32(0); row < zSize; row++ {
+		for col := uint32(0); col < xSize; col++ {
+			// Uses the global sample index, so the shared edges get the same coordinates
+			x := float32(float64(startCol + int64(col)) * step)
+			z := float32(float64(startRow + int64(row)) * step)
+
+			source.Settings.Sample(noiseGenerator, x, z, octaves)
+			heights[row * xSize + col] = octaves[len(octaves) - 1]
+		}
+	}
+
+	return heights
+}
+
+//
+// Resample
+// Reads a square grid of heights with bilinear interpolation into a (xSize * zSize) grid
+//
+// @param grid ([][]float32) the source heights, indexed as [x][z]
+// @param xSize (uint32) number of columns
+// @param zSize (uint32) number of rows
+//
+// @return heights ([]float32) the heights, stored as [row * xSize + col]
+//
+func Resample(grid [][]float32, xSize, zSize uint32) []float32 {
+	heights := make([]float32, xSize * zSize)
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return heights
+	}
+
+	maxX := float32(len(grid) - 1)
+	maxZ := float32(len(grid[0]) - 1)
+	xFactor := maxX * sampleStep(xSize)
+	zFactor := maxZ * sampleStep(zSize)
+
+	for row := uint32(0); row < zSize; row++ {
+		for col := uint32(0); col < xSize; col++ {
+			x := xFactor * float32(col)
+			z := zFactor * float32(row)
+
+			x0, z0 := int(x), int(z)
+			x1, z1 := x0 + 1, z0 + 1
+			if x1 > int(maxX) {
+				x1 = int(maxX)
+			}
+			if z1 > int(maxZ) {
+				z1 = int(maxZ)
+			}
+
+			fx := x - float32(x0)
+			fz := z - float32(z0)
+
+			top := grid[x0][z0] * (1 - fx) + grid[x1][z0] * fx
+			bottom := grid[x0][z1] * (1 - fx) + grid[x1][z1] * fx
+			heights[row * xSize + col] = top * (1 - fz) + bottom * fz
+		}
+	}
+
+	return heights
+}
+
+// sampleStep is the distance between two of (size) samples that go from 0 to 1
+// (a single sample stays on 0, instead of dividing by zero)
+func sampleStep(size uint32) float32 {
+	if size < 2 {
+		return 0
+	}
+
+	return 1.0 / float32(size - 1)
+}
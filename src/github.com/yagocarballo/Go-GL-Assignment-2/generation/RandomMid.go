@@ -1,11 +1,14 @@
-package wrapper
+package generation
 
 import (
-	"time"
 	"math"
 	rng "github.com/leesper/go_rng"
 )
 
+//
+// RandomMid
+// Midpoint displacement (diamond-square) height field generator
+//
 type RandomMid struct {
 	Height       [][]float32
 	Magnitude    int32
@@ -13,21 +16,65 @@ type RandomMid struct {
 	Fractal      float32
 	HeightFactor float32
 	Scale        float32
+	Additional   bool		// Uses CreateLandscapeAdditional instead of CreateLandscape
+	Seed         int64
+	Min, Max     float32	// Filled by SetMinMax
 	grng         *rng.GaussianGenerator
 }
 
-func NewRandomMid (height [][]float32, magnitude int32, fractal, heightFactor, scale float32) *RandomMid {
+//
+// NewRandomMid
+// Creates a seeded midpoint displacement generator with a (2^magnitude + 1) square grid
+//
+// @param seed (int64) the seed for the gaussian random numbers
+// @param magnitude (int32) number of subdivisions (the grid has 2^magnitude + 1 points per side)
+// @param fractal (float32) the fractal dimension (how rough the landscape is)
+// @param heightFactor (float32) multiplies the random displacements
+// @param scale (float32) scales the random displacements
+//
+// @return randomMid (*RandomMid) a pointer to the generator
+//
+func NewRandomMid (seed int64, magnitude int32, fractal, heightFactor, scale float32) *RandomMid {
+	landSize := int32(math.Pow(2.0, float64(magnitude))) + 1
+
+	height := make([][]float32, landSize)
+	for x := range height {
+		height[x] = make([]float32, landSize)
+	}
+
 	return &RandomMid{
 		height,
 		magnitude,
-		int32(math.Pow(2.0, float64(magnitude))) + 1,
+		landSize,
 		fractal,
 		heightFactor,
 		scale,
-		rng.NewGaussianGenerator(time.Now().UnixNano()),
+		false,
+		seed,
+		0, 0,
+		rng.NewGaussianGenerator(seed),
 	}
 }
 
+//
+// Generate
+// Implements HeightSource, creates the landscape from the seed,
+// normalises it between 0 and 1 and resamples it into the requested size
+//
+func (data *RandomMid) Generate (xSize, zSize uint32) []float32 {
+	// Restarts the random numbers, so the same seed always gives the same landscape
+	data.grng = rng.NewGaussianGenerator(data.Seed)
+
+	if data.Additional {
+		data.CreateLandscapeAdditional()
+	} else {
+		data.CreateLandscape()
+	}
+
+	data.Normalize()
+	return Resample(data.Height, xSize, zSize)
+}
+
 func (data *RandomMid) CreateLandscape () {
 	landMax := data.LandSize - 1
 	scaleFactor	:= float32(1/(math.Pow(2.0, float64(data.Fractal / 2.0))))
@@ -248,19 +295,42 @@ func (data *RandomMid) CreateLandscapeAdditional () {
 	}	// End of main iteration loop
 }
 
+//
+// SetMinMax
+// Finds the lowest and highest points of the landscape and stores them in Min and Max
+//
 func (data *RandomMid) SetMinMax () {
 	var x, y int32
-	var max, min float32 = 0.0, 0.0
+	data.Min, data.Max = data.Height[0][0], data.Height[0][0]
 
 	for y=0; y<data.LandSize; y++ {
-		for x=0; x<data.LandSize; y++ {
-			if data.Height[x][y] > max {
-				max = data.Height[x][y]
+		for x=0; x<data.LandSize; x++ {
+			if data.Height[x][y] > data.Max {
+				data.Max = data.Height[x][y]
 			}
 
-			if data.Height[x][y] < min {
-				min = data.Height[x][y]
+			if data.Height[x][y] < data.Min {
+				data.Min = data.Height[x][y]
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+
+//
+// Normalize
+// Moves every height into the [0, 1] range (a flat landscape stays at 0)
+//
+func (data *RandomMid) Normalize () {
+	data.SetMinMax()
+
+	heightRange := data.Max - data.Min
+	for x := range data.Height {
+		for y := range data.Height[x] {
+			if heightRange == 0 {
+				data.Height[x][y] = 0
+			} else {
+				data.Height[x][y] = (data.Height[x][y] - data.Min) / heightRange
+			}
+		}
+	}
+}
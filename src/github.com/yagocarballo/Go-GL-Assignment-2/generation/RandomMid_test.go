@@ -0,0 +1,137 @@
+package generation
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRandomMidGolden(t *testing.T) {
+	goldens := map[bool][]float32{
+		false: { 0.15374212, 0.92642355, 0.15374212, 0.78409165, 1, 0, 0.15374212, 0.13579735, 0.15374212 },
+		true:  { 0.6541202, 1, 0.7795022, 0.30031204, 0.18351528, 0.9035415, 0.584221, 0.7723128, 0 },
+	}
+
+	for additional, golden := range goldens {
+		generator := NewRandomMid(999999, 2, 0.7, 1.0, 1.0)
+		generator.Additional = additional
+
+		// A 5x5 landscape read every other point
+		heights := generator.Generate(3, 3)
+		for i := range golden {
+			if math.Abs(float64(heights[i] - golden[i])) > 1e-5 {
+				t.Errorf("additional %v, height %d: expected %f, found %f", additional, i, golden[i], heights[i])
+			}
+		}
+	}
+}
+
+func TestRandomMidIsDeterministic(t *testing.T) {
+	for _, additional := range []bool{ false, true } {
+		generator := NewRandomMid(42, 5, 0.7, 1.0, 1.0)
+		generator.Additional = additional
+		first := generator.Generate(33, 33)
+
+		// Generating again restarts the random numbers
+		again := generator.Generate(33, 33)
+
+		other := NewRandomMid(42, 5, 0.7, 1.0, 1.0)
+		other.Additional = additional
+		second := other.Generate(33, 33)
+
+		differentSeed := NewRandomMid(43, 5, 0.7, 1.0, 1.0)
+		differentSeed.Additional = additional
+		third := differentSeed.Generate(33, 33)
+
+		different := false
+		for i := range first {
+			if first[i] != again[i] || first[i] != second[i] {
+				t.Fatalf("additional %v, height %d changed with the same seed: %f, %f, %f", additional, i, first[i], again[i], second[i])
+			}
+			different = different || first[i] != third[i]
+		}
+
+		if !different {
+			t.Errorf("additional %v: two different seeds generated the same landscape", additional)
+		}
+	}
+}
+
+func TestRandomMidRange(t *testing.T) {
+	for _, additional := range []bool{ false, true } {
+		generator := NewRandomMid(7, 6, 0.7, 1.0, 1.0)
+		generator.Additional = additional
+		generator.Generate(65, 65)
+
+		// Normalize leaves the lowest point on 0 and the highest on 1
+		minimum, maximum := float32(math.Inf(1)), float32(math.Inf(-1))
+		for x := range generator.Height {
+			for _, height := range generator.Height[x] {
+				if height < 0 || height > 1 || math.IsNaN(float64(height)) {
+					t.Fatalf("additional %v: height %f is outside of [0, 1]", additional, height)
+				}
+				minimum = float32(math.Min(float64(minimum), float64(height)))
+				maximum = float32(math.Max(float64(maximum), float64(height)))
+			}
+		}
+
+		if minimum != 0 || maximum != 1 {
+			t.Errorf("additional %v: expected the range [0, 1], found [%f, %f]", additional, minimum, maximum)
+		}
+	}
+}
+
+func TestRandomMidFlatLandscape(t *testing.T) {
+	generator := NewRandomMid(1, 2, 0.7, 0, 1.0)
+	for i, height := range generator.Generate(5, 5) {
+		if height != 0 {
+			t.Fatalf("height %d: a flat landscape should stay on 0, found %f", i, height)
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	grid := [][]float32{
+		{ 0, 1 },
+		{ 2, 3 },
+	}
+
+	// Indexed as [x][z], stored as [row * xSize + col]
+	heights := Resample(grid, 3, 2)
+	expected := []float32{ 0, 1, 2, 1, 2, 3 }
+	for i := range expected {
+		if heights[i] != expected[i] {
+			t.Errorf("height %d: expected %f, found %f", i, expected[i], heights[i])
+		}
+	}
+}
+
+func TestResampleSingleSample(t *testing.T) {
+	grid := [][]float32{
+		{ 0.25, 1 },
+		{ 0.5, 0.75 },
+	}
+
+	for _, size := range [][2]uint32{ { 1, 1 }, { 1, 4 }, { 4, 1 } } {
+		heights := Resample(grid, size[0], size[1])
+		if len(heights) != int(size[0] * size[1]) {
+			t.Fatalf("%v: expected %d heights, found %d", size, size[0] * size[1], len(heights))
+		}
+
+		for i, height := range heights {
+			if math.IsNaN(float64(height)) || math.IsInf(float64(height), 0) {
+				t.Fatalf("%v: height %d is %f", size, i, height)
+			}
+		}
+
+		// A single sample stays on the first point of the grid
+		if heights[0] != 0.25 {
+			t.Errorf("%v: expected the first height to be 0.25, found %f", size, heights[0])
+		}
+	}
+
+	for i, height := range NewSimplexSource(1, NewNoiseSettings(4, 5)).Generate(1, 1) {
+		if math.IsNaN(float64(height)) {
+			t.Fatalf("simplex height %d is NaN", i)
+		}
+	}
+}
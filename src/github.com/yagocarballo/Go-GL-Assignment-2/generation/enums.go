@@ -0,0 +1,39 @@
+package generation
+
+import "fmt"
+
+type NoiseMode int32
+type NoiseDimensions int32
+
+const (
+	NOISE_FBM NoiseMode = iota
+	NOISE_RIDGED
+	NOISE_BILLOW
+	NOISE_TURBULENCE
+)
+
+const (
+	_ = iota // ignore first value by assigning to blank identifier
+	NOISE_2D NoiseDimensions = 1 + iota
+	NOISE_3D
+	NOISE_4D
+)
+
+var noiseModeNames = [...]string{
+	"[ fBm ]",
+	"[ Ridged Multifractal ]",
+	"[ Billow ]",
+	"[ Turbulence ]",
+}
+
+func (noiseMode NoiseMode) String() string {
+	if noiseMode < 0 || int(noiseMode) >= len(noiseModeNames) {
+		return fmt.Sprintf("[ Unknown Noise Mode %d ]", noiseMode)
+	}
+
+	return noiseModeNames[noiseMode]
+}
+
+func (dimensions NoiseDimensions) String() string {
+	return fmt.Sprintf("[ %dD Noise ]", dimensions)
+}
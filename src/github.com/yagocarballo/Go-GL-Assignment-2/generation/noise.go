@@ -0,0 +1,174 @@
+package generation
+
+import (
+	"fmt"
+	"math"
+)
+
+//
+// NoiseGenerator
+// Seeded gradient noise read by the fractal (*opensimplex.Noise is the one the terrain uses)
+//
+type NoiseGenerator interface {
+	Eval2(x, y float64) float64
+	Eval3(x, y, z float64) float64
+	Eval4(x, y, z, w float64) float64
+}
+
+//
+// NoiseSettings
+// Describes how the fractal noise of a Terrain is generated
+//
+type NoiseSettings struct {
+	Mode         NoiseMode       // fBm, Ridged Multifractal, Billow or Turbulence
+	Dimensions   NoiseDimensions // Uses Eval2, Eval3 or Eval4 from OpenSimplex
+
+	Octaves      uint32          // Number of octaves added together (at least 1 is always added)
+	Frequency    float32         // Frequency of the first octave
+	Lacunarity   float32         // Frequency multiplier between octaves
+	Amplitude    float32         // Amplitude of the first octave
+	Gain         float32         // Amplitude multiplier between octaves (persistence)
+	Offset       float32         // Ridge offset for the Ridged Multifractal mode
+
+	Slice        float32         // Z coordinate used for 3D and 4D noise
+	Time         float32         // W coordinate used for 4D noise
+
+	WarpStrength  float32        // How far the domain gets displaced (0 disables domain warping)
+	WarpFrequency float32        // Frequency of the noise that displaces the domain
+}
+
+//
+// NewNoiseSettings
+// Creates the noise settings used by the original terrain generator
+// (4 octaves of 2D fBm, doubling the frequency and dividing by scale every octave)
+//
+// @param frequency (float32) the frequency of the first octave
+// @param scale (float32) divides the amplitude of every octave
+//
+// @return settings (NoiseSettings) the noise settings
+//
+func NewNoiseSettings(frequency, scale float32) NoiseSettings {
+	return NoiseSettings{
+		NOISE_FBM,	// Mode
+		NOISE_2D,	// Dimensions
+
+		4,			// Octaves
+		frequency,	// Frequency
+		2.0,		// Lacunarity
+		1.0 / scale,// Amplitude
+		1.0 / scale,// Gain
+		1.0,		// Offset
+
+		0,			// Slice
+		0,			// Time
+
+		0,			// WarpStrength
+		1.0,		// WarpFrequency
+	}
+}
+
+//
+// Sample
+// Calculates the noise of every octave for the point (x, z)
+//
+// @param generator (NoiseGenerator) the seeded noise generator
+// @param x (float32) the x coordinate (normally between 0 and 1)
+// @param z (float32) the z coordinate (normally between 0 and 1)
+// @param octaves ([]float32) receives the accumulated value after each octave (len >= OctaveCount())
+//
+func (settings NoiseSettings) Sample(generator NoiseGenerator, x, z float32, octaves []float32) {
+	px, pz := float64(x), float64(z)
+
+	// Displaces the coordinates with another noise lookup (Domain Warping)
+	if settings.WarpStrength != 0 {
+		warp := float64(settings.WarpFrequency)
+		strength := float64(settings.WarpStrength)
+		qx := settings.eval(generator, px * warp, pz * warp)
+		qz := settings.eval(generator, px * warp + 5.2, pz * warp + 1.3)
+		px += strength * qx
+		pz += strength * qz
+	}
+
+	sum := float32(0.0)
+	weight := float32(1.0)
+	amplitude := settings.Amplitude
+	frequency := float64(settings.Frequency)
+
+	for oct := uint32(0); oct < settings.OctaveCount(); oct++ {
+		p := float32(settings.eval(generator, px * frequency, pz * frequency))
+
+		switch settings.Mode {
+		case NOISE_RIDGED:
+			// Sharp ridges where the noise crosses zero, each octave weighted by the previous one
+			signal := settings.Offset - float32(math.Abs(float64(p)))
+			signal *= signal * weight
+			weight = clampFloat32(signal * 2.0, 0.0, 1.0)
+			sum += signal * amplitude
+		case NOISE_BILLOW:
+			// Rounded bumps, the absolute value moved back to the [-1, 1] range
+			sum += (float32(math.Abs(float64(p))) * 2.0 - 1.0) * amplitude
+		case NOISE_TURBULENCE:
+			sum += float32(math.Abs(float64(p))) * amplitude
+		default:
+			sum += p * amplitude
+		}
+
+		// fBm and Billow are signed, so they get moved into the [0, 1] range
+		if settings.Mode == NOISE_FBM || settings.Mode == NOISE_BILLOW {
+			octaves[oct] = (sum + 1.0) / 2.0
+		} else {
+			octaves[oct] = sum
+		}
+
+		// Move to the next frequency and amplitude
+		frequency *= float64(settings.Lacunarity)
+		amplitude *= settings.Gain
+	}
+}
+
+//
+// OctaveCount
+// Returns the number of octaves that Sample adds (Octaves, but never less than 1,
+// so there is always a last octave to read the height from)
+//
+// @return octaves (uint32) the number of octaves
+//
+func (settings NoiseSettings) OctaveCount() uint32 {
+	if settings.Octaves < 1 {
+		return 1
+	}
+
+	return settings.Octaves
+}
+
+// eval reads the generator with the configured number of dimensions
+func (settings NoiseSettings) eval(generator NoiseGenerator, x, z float64) float64 {
+	switch settings.Dimensions {
+	case NOISE_3D:
+		return generator.Eval3(x, z, float64(settings.Slice))
+	case NOISE_4D:
+		return generator.Eval4(x, z, float64(settings.Slice), float64(settings.Time))
+	default:
+		return generator.Eval2(x, z)
+	}
+}
+
+func clampFloat32(value, min, max float32) float32 {
+	if value < min {
+		return min
+	}
+
+	if value > max {
+		return max
+	}
+
+	return value
+}
+
+func (settings NoiseSettings) String() string {
+	return fmt.Sprintf(
+		"%s %s Octaves: %d, Frequency: %f, Lacunarity: %f, Amplitude: %f, Gain: %f, Offset: %f, Warp: %f",
+		settings.Mode, settings.Dimensions, settings.Octaves, settings.Frequency, settings.Lacunarity,
+		settings.Amplitude, settings.Gain, settings.Offset, settings.WarpStrength,
+	)
+}
@@ -0,0 +1,178 @@
+package generation
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ojrac/opensimplex-go"
+)
+
+// referenceFBM adds the octaves of the original terrain generator one by one
+func referenceFBM(generator *opensimplex.Noise, x, z, frequency, scale float32) float32 {
+	sum := float32(0)
+	amplitude := 1.0 / scale
+	currentFrequency := float64(frequency)
+	for oct := 0; oct < 4; oct++ {
+		sum += float32(generator.Eval2(float64(x) * currentFrequency, float64(z) * currentFrequency)) * amplitude
+		currentFrequency *= 2.0
+		amplitude *= 1.0 / scale
+	}
+
+	return (sum + 1.0) / 2.0
+}
+
+func TestSampleMatchesTheOriginalGenerator(t *testing.T) {
+	for _, seed := range []int64{ 0, 999999, -42 } {
+		generator := opensimplex.NewWithSeed(seed)
+		settings := NewNoiseSettings(4.0, 5.0)
+		octaves := make([]float32, settings.OctaveCount())
+
+		for _, point := range [][2]float32{ { 0, 0 }, { 0.25, 0.75 }, { 1, 0.5 }, { 0.125, 1 } } {
+			settings.Sample(generator, point[0], point[1], octaves)
+
+			expected := referenceFBM(generator, point[0], point[1], 4.0, 5.0)
+			if math.Abs(float64(octaves[3] - expected)) > 1e-6 {
+				t.Errorf("seed %d at %v: expected %f, found %f", seed, point, expected, octaves[3])
+			}
+		}
+	}
+}
+
+// fixedNoise is a smooth noise without a seed, so the heights of the fractal can be written down
+// (the fake generators of the build machines don't give the values of opensimplex)
+type fixedNoise struct{}
+
+func (fixedNoise) Eval2(x, y float64) float64 {
+	return math.Sin(x * 2.1 + 0.3) * math.Cos(y * 1.7 - 0.2)
+}
+
+func (fixedNoise) Eval3(x, y, z float64) float64 {
+	return math.Sin(x * 2.1 + z * 0.9 + 0.3) * math.Cos(y * 1.7 - z * 0.4 - 0.2)
+}
+
+func (fixedNoise) Eval4(x, y, z, w float64) float64 {
+	return math.Sin(x * 2.1 + z * 0.9 + w * 0.6 + 0.3) * math.Cos(y * 1.7 - z * 0.4 + w * 0.8 - 0.2)
+}
+
+func TestSampleGoldenHeights(t *testing.T) {
+	points := [][2]float32{ { 0.25, 0.75 }, { 0.6, 0.1 }, { 1, 1 } }
+	cases := []struct {
+		mode       NoiseMode
+		dimensions NoiseDimensions
+		warp       float32
+		heights    [3]float32
+	}{
+		{ NOISE_FBM, NOISE_2D, 0, [3]float32{ 0.3476925, 0.6475338, 0.545868 } },
+		{ NOISE_FBM, NOISE_2D, 0.4, [3]float32{ 0.4199629, 0.4544101, 0.5027621 } },
+		{ NOISE_FBM, NOISE_3D, 0, [3]float32{ 0.3713166, 0.5772579, 0.5632935 } },
+		{ NOISE_FBM, NOISE_3D, 0.4, [3]float32{ 0.4394763, 0.3772896, 0.4182001 } },
+		{ NOISE_FBM, NOISE_4D, 0, [3]float32{ 0.3363312, 0.468281, 0.8399782 } },
+		{ NOISE_FBM, NOISE_4D, 0.4, [3]float32{ 0.2018314, 0.3299333, 0.516271 } },
+		{ NOISE_RIDGED, NOISE_2D, 0, [3]float32{ 0.3960505, 0.01857845, 0.6500562 } },
+		{ NOISE_RIDGED, NOISE_2D, 0.4, [3]float32{ 0.1753841, 0.6421637, 0.7873353 } },
+		{ NOISE_RIDGED, NOISE_3D, 0, [3]float32{ 0.5206684, 0.08348717, 0.4539997 } },
+		{ NOISE_RIDGED, NOISE_3D, 0.4, [3]float32{ 0.4181995, 0.500668, 0.5214096 } },
+		{ NOISE_RIDGED, NOISE_4D, 0, [3]float32{ 0.1007499, 0.6690271, 0.0006839832 } },
+		{ NOISE_RIDGED, NOISE_4D, 0.4, [3]float32{ 0.005029379, 0.3307317, 0.2455759 } },
+		{ NOISE_BILLOW, NOISE_2D, 0, [3]float32{ 0.335865, 0.719844, 0.1916797 } },
+		{ NOISE_BILLOW, NOISE_2D, 0.4, [3]float32{ 0.4625223, 0.1962171, 0.1102404 } },
+		{ NOISE_BILLOW, NOISE_3D, 0, [3]float32{ 0.2886167, 0.6539448, 0.2947817 } },
+		{ NOISE_BILLOW, NOISE_3D, 0.4, [3]float32{ 0.3188021, 0.3014271, 0.297821 } },
+		{ NOISE_BILLOW, NOISE_4D, 0, [3]float32{ 0.5109081, 0.191765, 0.8042512 } },
+		{ NOISE_BILLOW, NOISE_4D, 0.4, [3]float32{ 0.6275871, 0.3713835, 0.4081512 } },
+		{ NOISE_TURBULENCE, NOISE_2D, 0, [3]float32{ 0.304615, 0.688594, 0.1604297 } },
+		{ NOISE_TURBULENCE, NOISE_2D, 0.4, [3]float32{ 0.4312723, 0.1649671, 0.0789904 } },
+		{ NOISE_TURBULENCE, NOISE_3D, 0, [3]float32{ 0.2573667, 0.6226948, 0.2635318 } },
+		{ NOISE_TURBULENCE, NOISE_3D, 0.4, [3]float32{ 0.2875521, 0.2701771, 0.266571 } },
+		{ NOISE_TURBULENCE, NOISE_4D, 0, [3]float32{ 0.4796581, 0.1605151, 0.7730012 } },
+		{ NOISE_TURBULENCE, NOISE_4D, 0.4, [3]float32{ 0.5963371, 0.3401335, 0.3769012 } },
+	}
+
+	for _, c := range cases {
+		settings := NewNoiseSettings(1.5, 2)
+		settings.Mode, settings.Dimensions = c.mode, c.dimensions
+		settings.Slice, settings.Time = 0.35, 1.5
+		settings.WarpStrength, settings.WarpFrequency = c.warp, 1.3
+		octaves := make([]float32, settings.OctaveCount())
+
+		for i, point := range points {
+			settings.Sample(fixedNoise{}, point[0], point[1], octaves)
+			if math.Abs(float64(octaves[3] - c.heights[i])) > 1e-5 {
+				t.Errorf("%s %s warp %.1f at %v: expected %.7g, found %.7g", c.mode, c.dimensions, c.warp, point, c.heights[i], octaves[3])
+			}
+		}
+	}
+}
+
+func TestSimplexSourceIsDeterministic(t *testing.T) {
+	settings := NewNoiseSettings(4.0, 5.0)
+	first := NewSimplexSource(999999, settings).Generate(17, 9)
+	second := NewSimplexSource(999999, settings).Generate(17, 9)
+	other := NewSimplexSource(1, settings).Generate(17, 9)
+
+	if len(first) != 17 * 9 {
+		t.Fatalf("expected %d heights, found %d", 17 * 9, len(first))
+	}
+
+	different := false
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("height %d changed between two runs with the same seed: %f != %f", i, first[i], second[i])
+		}
+		different = different || first[i] != other[i]
+	}
+
+	if !different {
+		t.Error("two different seeds generated the same heights")
+	}
+}
+
+func TestSimplexSourceRange(t *testing.T) {
+	for _, mode := range []NoiseMode{ NOISE_FBM, NOISE_BILLOW } {
+		settings := NewNoiseSettings(4.0, 5.0)
+		settings.Mode = mode
+
+		for i, height := range NewSimplexSource(7, settings).Generate(33, 33) {
+			if height < 0 || height > 1 || math.IsNaN(float64(height)) {
+				t.Fatalf("%s: height %d is outside of [0, 1]: %f", mode, i, height)
+			}
+		}
+	}
+}
+
+func TestZeroOctavesAddOneOctave(t *testing.T) {
+	settings := NewNoiseSettings(4.0, 5.0)
+	settings.Octaves = 0
+	if settings.OctaveCount() != 1 {
+		t.Fatalf("expected 1 octave, found %d", settings.OctaveCount())
+	}
+
+	single := NewNoiseSettings(4.0, 5.0)
+	single.Octaves = 1
+
+	empty := NewSimplexSource(3, settings).Generate(5, 5)
+	expected := NewSimplexSource(3, single).Generate(5, 5)
+	for i := range expected {
+		if empty[i] != expected[i] {
+			t.Fatalf("height %d: expected %f, found %f", i, expected[i], empty[i])
+		}
+	}
+
+	region := NewSimplexSource(3, settings).GenerateRegion(-2, 4, 5, 5, 0.1)
+	if len(region) != 25 {
+		t.Fatalf("expected 25 heights, found %d", len(region))
+	}
+}
+
+func TestNoiseModeString(t *testing.T) {
+	if NOISE_RIDGED.String() != "[ Ridged Multifractal ]" {
+		t.Errorf("unexpected name %q", NOISE_RIDGED.String())
+	}
+
+	for _, mode := range []NoiseMode{ -1, 4, 100 } {
+		if !strings.Contains(mode.String(), "Unknown") {
+			t.Errorf("mode %d: unexpected name %q", mode, mode.String())
+		}
+	}
+}
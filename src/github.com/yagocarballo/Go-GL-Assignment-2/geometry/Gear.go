@@ -0,0 +1,343 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//
+// GearSettings
+// Describes an involute gear. The module and the pressure angle are measured across the teeth (normal),
+// like the cutters do, so a helical gear is a bit bigger than a spur gear with the same settings
+//
+type GearSettings struct {
+	Module        float32 // Size of the teeth (the pitch diameter of a spur gear is Module * Teeth)
+	Teeth         uint32  // Number of teeth (5 at least)
+	PressureAngle float32 // Angle of the force between two teeth, in degrees (usually 20)
+	FaceWidth     float32 // Thickness of the gear along y
+	Bore          float32 // Diameter of the hole in the middle (0 for none)
+	HelixAngle    float32 // Angle of the teeth across the face, in degrees (0 for a spur gear, the mate uses the opposite angle)
+
+	FlankSegments uint32  // Number of segments on every side of a tooth (1 at least)
+	Slices        uint32  // Number of bands along the face width (1 at least, a helical gear needs more to twist)
+}
+
+//
+// NewGearSettings
+// Creates the settings of a spur gear with a 20 degree pressure angle and no bore
+//
+// @param module (float32) the size of the teeth
+// @param teeth (uint32) the number of teeth
+// @param faceWidth (float32) the thickness of the gear
+//
+// @return settings (GearSettings) the settings
+//
+func NewGearSettings(module float32, teeth uint32, faceWidth float32) GearSettings {
+	return GearSettings{
+		module,		// Module
+		teeth,		// Teeth
+		20,			// PressureAngle
+		faceWidth,	// FaceWidth
+		0,			// Bore
+		0,			// HelixAngle
+
+		6,			// FlankSegments
+		1,			// Slices
+	}
+}
+
+// TeethCount returns the number of teeth (5 at least, fewer can't be cut without the teeth turning into points)
+func (settings GearSettings) TeethCount() uint32 {
+	return atLeast(settings.Teeth, 5)
+}
+
+// TransverseModule returns the module across the axis (the normal module for a spur gear)
+func (settings GearSettings) TransverseModule() float64 {
+	return float64(settings.Module) / math.Cos(float64(settings.HelixAngle) * math.Pi / 180)
+}
+
+// TransversePressureAngle returns the pressure angle across the axis, in radians
+func (settings GearSettings) TransversePressureAngle() float64 {
+	pressure := float64(settings.PressureAngle) * math.Pi / 180
+	return math.Atan(math.Tan(pressure) / math.Cos(float64(settings.HelixAngle) * math.Pi / 180))
+}
+
+// PitchRadius returns the radius of the pitch circle (two gears mesh with their pitch circles touching)
+func (settings GearSettings) PitchRadius() float64 {
+	return settings.TransverseModule() * float64(settings.TeethCount()) / 2
+}
+
+// BaseRadius returns the radius of the circle the involutes unwind from
+func (settings GearSettings) BaseRadius() float64 {
+	return settings.PitchRadius() * math.Cos(settings.TransversePressureAngle())
+}
+
+// RootRadius returns the radius of the bottom of the gaps (1.25 modules below the pitch circle)
+func (settings GearSettings) RootRadius() float64 {
+	return settings.PitchRadius() - 1.25 * float64(settings.Module)
+}
+
+// TipRadius returns the radius of the top of the teeth: 1 module above the pitch circle,
+// or lower if the teeth would turn into points before that
+func (settings GearSettings) TipRadius() float64 {
+	tip := settings.PitchRadius() + float64(settings.Module)
+	if settings.ToothHalfAngle(tip) > 0 {
+		return tip
+	}
+
+	// Finds where the flanks meet and stops a bit before
+	low, high := settings.PitchRadius(), tip
+	for step := 0; step < 50; step++ {
+		middle := (low + high) / 2
+		if settings.ToothHalfAngle(middle) > 0.1 * math.Pi / float64(settings.TeethCount()) {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+
+	return low
+}
+
+//
+// ToothHalfAngle
+// Calculates half of the angle a tooth covers at a radius (the tooth is centred on the angle 0).
+// The flanks are involutes, so it's π / 2z on the pitch circle, plus inv(pressure angle) - inv(angle at the radius).
+// Below the base circle the flanks go straight to the root, so it stays at its value on the base circle
+//
+// @param radius (float64) the distance from the centre
+//
+// @return angle (float64) the half angle in radians
+//
+func (settings GearSettings) ToothHalfAngle(radius float64) float64 {
+	base := settings.BaseRadius()
+	if radius < base {
+		radius = base
+	}
+
+	return math.Pi / (2 * float64(settings.TeethCount())) + InvoluteFunction(settings.TransversePressureAngle()) - InvoluteFunction(math.Acos(base / radius))
+}
+
+//
+// InvoluteFunction
+// The angle the involute turns (seen from the centre) by the time its pressure angle is alpha: tan(alpha) - alpha
+//
+// @param alpha (float64) the pressure angle in radians
+//
+// @return angle (float64) the angle in radians
+//
+func InvoluteFunction(alpha float64) float64 {
+	return math.Tan(alpha) - alpha
+}
+
+//
+// InvolutePoint
+// The point of the involute of a circle (the path of the end of a string unwound from it), starting on the x axis
+// and unwinding counter-clockwise
+//
+// @param baseRadius (float64) the radius of the circle
+// @param roll (float64) the angle unwound, in radians
+//
+// @return point (mgl32.Vec2) the point
+//
+func InvolutePoint(baseRadius, roll float64) mgl32.Vec2 {
+	return mgl32.Vec2{
+		float32(baseRadius * (math.Cos(roll) + roll * math.Sin(roll))),
+		float32(baseRadius * (math.Sin(roll) - roll * math.Cos(roll))),
+	}
+}
+
+//
+// Profile
+// Calculates the outline of the gear across its axis, counter-clockwise from the x axis towards the y axis.
+// The first tooth is centred on the x axis, every tooth goes up its right flank, along the tip, down its left flank
+// and along the root to the next tooth
+//
+// @return outline ([]mgl32.Vec2) the points of the outline (without repeating the first one at the end)
+//
+func (settings GearSettings) Profile() []mgl32.Vec2 {
+	teeth := settings.TeethCount()
+	flankSegments := atLeast(settings.FlankSegments, 1)
+	base, root, tip := settings.BaseRadius(), settings.RootRadius(), settings.TipRadius()
+	pitch := 2 * math.Pi / float64(teeth)
+
+	// The flank is an involute from the base circle (or from the root, if the root is above it),
+	// and a straight line from the root to the base circle
+	radii := []float64{}
+	start := math.Max(root, base)
+	if root < base {
+		radii = append(radii, root)
+	}
+	for segment := uint32(0); segment <= flankSegments; segment++ {
+		radii = append(radii, start + (tip - start) * float64(segment) / float64(flankSegments))
+	}
+
+	point := func(radius, angle float64) mgl32.Vec2 {
+		return mgl32.Vec2{ float32(radius * math.Cos(angle)), float32(radius * math.Sin(angle)) }
+	}
+
+	// The arcs on the tip and the root have about the same length as a flank segment
+	arc := func(radius, from, to float64) []mgl32.Vec2 {
+		segments := int(math.Ceil(radius * (to - from) / ((tip - start) / float64(flankSegments))))
+		points := []mgl32.Vec2{}
+		for segment := 1; segment < segments; segment++ {
+			points = append(points, point(radius, from + (to - from) * float64(segment) / float64(segments)))
+		}
+		return points
+	}
+
+	outline := []mgl32.Vec2{}
+	for tooth := uint32(0); tooth < teeth; tooth++ {
+		center := pitch * float64(tooth)
+
+		for _, radius := range radii {
+			outline = append(outline, point(radius, center - settings.ToothHalfAngle(radius)))
+		}
+		outline = append(outline, arc(tip, center - settings.ToothHalfAngle(tip), center + settings.ToothHalfAngle(tip))...)
+		for index := len(radii) - 1; index >= 0; index-- {
+			outline = append(outline, point(radii[index], center + settings.ToothHalfAngle(radii[index])))
+		}
+		outline = append(outline, arc(root, center + settings.ToothHalfAngle(root), center + pitch - settings.ToothHalfAngle(root))...)
+	}
+
+	return outline
+}
+
+//
+// Gear
+// Creates an involute gear on the y axis, centred on the origin, with the first tooth on the x axis
+// (the x axis goes towards the z axis as the profile goes counter-clockwise).
+// A helical gear twists its profile along the face width, around the middle of it.
+// The sides are flat shaded, every side has a slice of the texture (u) from the top to the bottom (v),
+// and the faces have the texture seen from outside
+//
+// @param settings (GearSettings) the shape of the gear
+//
+// @return mesh (*Mesh) the gear
+//
+func Gear(settings GearSettings) *Mesh {
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+	outline := settings.Profile()
+	count := uint32(len(outline))
+	slices := atLeast(settings.Slices, 1)
+	width := settings.FaceWidth
+	tip := float32(settings.TipRadius())
+
+	// The bore can't reach the root of the teeth
+	bore := float64(settings.Bore) / 2
+	if bore > settings.RootRadius() * 0.9 {
+		bore = settings.RootRadius() * 0.9
+	}
+
+	// Angle the profile turns per unit along y
+	twist := math.Tan(float64(settings.HelixAngle) * math.Pi / 180) / settings.PitchRadius()
+
+	// The slices go from the top to the bottom
+	height := func(slice uint32) float32 {
+		return width * (0.5 - float32(slice) / float32(slices))
+	}
+	position := func(point mgl32.Vec2, y float32) mgl32.Vec3 {
+		angle := float64(y) * twist
+		cos, sin := float32(math.Cos(angle)), float32(math.Sin(angle))
+		return mgl32.Vec3{ point.X() * cos - point.Y() * sin, y, point.X() * sin + point.Y() * cos }
+	}
+	inner := func(index uint32) mgl32.Vec2 {
+		point := outline[index % count]
+		return point.Normalize().Mul(float32(bore))
+	}
+
+	// Sides
+	for slice := uint32(0); slice < slices; slice++ {
+		top, bottom := height(slice), height(slice + 1)
+		vTop, vBottom := 1 - float32(slice) / float32(slices), 1 - float32(slice + 1) / float32(slices)
+
+		for index := uint32(0); index < count; index++ {
+			left, right := outline[index], outline[(index + 1) % count]
+			uLeft, uRight := around(index, count), around(index + 1, count)
+			mesh.addFlatQuad(
+				[4]mgl32.Vec3{ position(left, top), position(right, top), position(right, bottom), position(left, bottom) },
+				[4]mgl32.Vec2{ { uLeft, vTop }, { uRight, vTop }, { uRight, vBottom }, { uLeft, vBottom } },
+			)
+
+			// The bore faces in, so its quads (and the texture) go the other way
+			if bore > 0 && !sameDirection(left, right) {
+				a, b := position(inner(index), top), position(inner(index + 1), top)
+				c, d := position(inner(index + 1), bottom), position(inner(index), bottom)
+				first := uint32(len(mesh.Positions))
+				for corner, point := range []mgl32.Vec3{ a, b, c, d } {
+					normal := mgl32.Vec3{ -point.X(), 0, -point.Z() }.Normalize()
+					u := []float32{ 1 - uLeft, 1 - uRight, 1 - uRight, 1 - uLeft }[corner]
+					v := []float32{ vTop, vTop, vBottom, vBottom }[corner]
+					mesh.addVertex(point, normal, mgl32.Vec2{ u, v })
+				}
+				mesh.addQuad(first, first + 3, first + 2, first + 1)
+			}
+		}
+	}
+
+	// Faces: a strip between the bore and the outline (a fan from the centre without a bore)
+	for _, up := range []bool{ true, false } {
+		y, normal, flip := width * 0.5, mgl32.Vec3{ 0, 1, 0 }, float32(-1)
+		if !up {
+			y, normal, flip = width * -0.5, mgl32.Vec3{ 0, -1, 0 }, 1
+		}
+		uv := func(point mgl32.Vec3) mgl32.Vec2 {
+			return mgl32.Vec2{ 0.5 + point.X() / (2 * tip), 0.5 + flip * point.Z() / (2 * tip) }
+		}
+
+		outer := uint32(len(mesh.Positions))
+		for index := uint32(0); index < count; index++ {
+			point := position(outline[index], y)
+			mesh.addVertex(point, normal, uv(point))
+		}
+
+		if bore == 0 {
+			center := mesh.addVertex(mgl32.Vec3{ 0, y, 0 }, normal, mgl32.Vec2{ 0.5, 0.5 })
+			for index := uint32(0); index < count; index++ {
+				mesh.addFacing(up, center, outer + (index + 1) % count, outer + index)
+			}
+			continue
+		}
+
+		holes := uint32(len(mesh.Positions))
+		for index := uint32(0); index < count; index++ {
+			point := position(inner(index), y)
+			mesh.addVertex(point, normal, uv(point))
+		}
+		for index := uint32(0); index < count; index++ {
+			next := (index + 1) % count
+			mesh.addFacing(up, holes + index, outer + next, outer + index)
+			if !sameDirection(outline[index], outline[next]) {
+				mesh.addFacing(up, holes + index, holes + next, outer + next)
+			}
+		}
+	}
+
+	return mesh
+}
+
+// sameDirection checks if two points of the outline are on the same line from the centre (the straight part of the flanks)
+func sameDirection(a, b mgl32.Vec2) bool {
+	return a.Normalize().Sub(b.Normalize()).Len() < 1e-5
+}
+
+// addFlatQuad adds a quad with its own vertices and the normal of its diagonals (it can be a bit twisted)
+func (mesh *Mesh) addFlatQuad(corners [4]mgl32.Vec3, uvs [4]mgl32.Vec2) {
+	normal := corners[2].Sub(corners[0]).Cross(corners[3].Sub(corners[1])).Normalize()
+
+	first := uint32(len(mesh.Positions))
+	for corner := range corners {
+		mesh.addVertex(corners[corner], normal, uvs[corner])
+	}
+	mesh.addQuad(first, first + 1, first + 2, first + 3)
+}
+
+// addFacing adds a triangle of a face that looks up, or the same triangle the other way round if it looks down
+func (mesh *Mesh) addFacing(up bool, a, b, c uint32) {
+	if up {
+		mesh.addTriangle(a, b, c)
+	} else {
+		mesh.addTriangle(a, c, b)
+	}
+}
@@ -0,0 +1,168 @@
+package geometry
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// rotate turns a point counter-clockwise around the origin
+func rotate(point mgl32.Vec2, angle float64) mgl32.Vec2 {
+	cos, sin := float32(math.Cos(angle)), float32(math.Sin(angle))
+	return mgl32.Vec2{ point.X() * cos - point.Y() * sin, point.X() * sin + point.Y() * cos }
+}
+
+func TestInvolutePoint(t *testing.T) {
+	for _, base := range []float64{ 1, 2.5 } {
+		for roll := 0.0; roll <= 2; roll += 0.125 {
+			point := InvolutePoint(base, roll)
+
+			// The string leaves the circle at the angle unwound, it's as long as the arc and it's tangent to the circle
+			touch := mgl32.Vec2{ float32(base * math.Cos(roll)), float32(base * math.Sin(roll)) }
+			string_ := point.Sub(touch)
+			if math.Abs(float64(string_.Len()) - base * roll) > 1e-5 || math.Abs(float64(string_.Dot(touch))) > 1e-5 {
+				t.Errorf("base %f, roll %f: the string %v is not tangent or not %f long", base, roll, string_, base * roll)
+			}
+
+			// So the point is at sqrt(1 + roll²) base radii, behind the touching point by atan(roll)
+			if radius := float64(point.Len()); math.Abs(radius - base * math.Sqrt(1 + roll * roll)) > 1e-5 {
+				t.Errorf("base %f, roll %f: expected the radius %f, found %f", base, roll, base * math.Sqrt(1 + roll * roll), radius)
+			}
+			if angle := math.Atan2(float64(point.Y()), float64(point.X())); math.Abs(angle - (roll - math.Atan(roll))) > 1e-6 {
+				t.Errorf("base %f, roll %f: expected the angle %f, found %f", base, roll, roll - math.Atan(roll), angle)
+			}
+		}
+	}
+}
+
+func TestInvoluteFunction(t *testing.T) {
+	if InvoluteFunction(0) != 0 {
+		t.Errorf("expected inv(0) = 0, found %f", InvoluteFunction(0))
+	}
+
+	// The value of the tables for 20 degrees
+	if value := InvoluteFunction(20 * math.Pi / 180); math.Abs(value - 0.014904383867336446) > 1e-12 {
+		t.Errorf("expected inv(20°) = 0.0149044, found %f", value)
+	}
+
+	// It's the angle of the involute where the pressure angle is alpha (the roll is tan alpha)
+	for degrees := 5.0; degrees < 60; degrees += 5 {
+		alpha := degrees * math.Pi / 180
+		point := InvolutePoint(1, math.Tan(alpha))
+		if angle := math.Atan2(float64(point.Y()), float64(point.X())); math.Abs(angle - InvoluteFunction(alpha)) > 1e-6 {
+			t.Errorf("%.0f°: expected %f, found %f", degrees, InvoluteFunction(alpha), angle)
+		}
+	}
+}
+
+func TestGearRadii(t *testing.T) {
+	settings := NewGearSettings(2, 20, 1)
+	pitch := 20.0
+	radii := []struct {
+		name             string
+		found, expected  float64
+	}{
+		{ "pitch", settings.PitchRadius(), pitch },
+		{ "base", settings.BaseRadius(), pitch * math.Cos(20 * math.Pi / 180) },
+		{ "root", settings.RootRadius(), pitch - 2.5 },
+		{ "tip", settings.TipRadius(), pitch + 2 },
+
+		// The tooth is as wide as the gap on the pitch circle
+		{ "half tooth on the pitch circle", settings.ToothHalfAngle(pitch), math.Pi / 40 },
+		{ "half tooth on the base circle", settings.ToothHalfAngle(settings.BaseRadius()), math.Pi / 40 + InvoluteFunction(20 * math.Pi / 180) },
+		{ "half tooth below the base circle", settings.ToothHalfAngle(settings.RootRadius()), math.Pi / 40 + InvoluteFunction(20 * math.Pi / 180) },
+	}
+
+	// A helical gear is measured across the teeth, so it's bigger across the axis
+	helical := settings
+	helical.HelixAngle = 30
+	radii = append(radii, struct {
+		name             string
+		found, expected  float64
+	}{ "helical pitch", helical.PitchRadius(), pitch / math.Cos(30 * math.Pi / 180) })
+
+	for _, radius := range radii {
+		if math.Abs(radius.found - radius.expected) > 1e-9 {
+			t.Errorf("%s: expected %f, found %f", radius.name, radius.expected, radius.found)
+		}
+	}
+
+	// A few teeth turn into points before the full height: the tip is lowered to keep some land on top
+	small := NewGearSettings(2, 5, 1)
+	small.PressureAngle = 35
+	if tip := small.TipRadius(); tip >= small.PitchRadius() + 2 || small.ToothHalfAngle(tip) <= 0 {
+		t.Errorf("expected the tip of 5 teeth to be lowered, found %f (half angle %f)", tip, small.ToothHalfAngle(tip))
+	}
+	if small := NewGearSettings(2, 1, 1); small.TeethCount() != 5 {
+		t.Errorf("expected 5 teeth at least, found %d", small.TeethCount())
+	}
+}
+
+func TestProfileFlanks(t *testing.T) {
+	for _, settings := range []GearSettings{ NewGearSettings(2, 20, 1), NewGearSettings(1, 9, 1), NewGearSettings(0.5, 60, 1) } {
+		settings.FlankSegments = 8
+		name := fmt.Sprintf("%d teeth", settings.Teeth)
+		base, root, tip := settings.BaseRadius(), settings.RootRadius(), settings.TipRadius()
+		pitch := 2 * math.Pi / float64(settings.Teeth)
+
+		// The right flank of the tooth at 0 is the involute unwound from the base circle at -(π / 2z + inv(pressure angle)),
+		// the left flank is its mirror
+		start := -(math.Pi / (2 * float64(settings.Teeth)) + InvoluteFunction(settings.TransversePressureAngle()))
+
+		flanks := 0
+		for index, point := range settings.Profile() {
+			radius := float64(point.Len())
+			// The arcs on the root and the tip are not on the flanks
+			if radius < base - 1e-6 || radius < root + 1e-4 || radius > tip - 1e-4 {
+				continue
+			}
+
+			// Brings the point to the tooth at 0
+			center := math.Floor(math.Atan2(float64(point.Y()), float64(point.X())) / pitch + 0.5) * pitch
+			local := rotate(point, -center)
+
+			roll := math.Sqrt(math.Max(0, radius * radius / (base * base) - 1))
+			expected := rotate(InvolutePoint(base, roll), start)
+			if local.Y() > 0 {
+				expected[1] = -expected[1]
+			}
+
+			if distance := local.Sub(expected).Len(); distance > 1e-4 * settings.Module {
+				t.Fatalf("%s: point %d %v is %f away from the involute (%v)", name, index, local, distance, expected)
+			}
+			flanks++
+		}
+
+		// Every flank has its segments above the base circle (without the root and the tip)
+		if expected := 2 * int(settings.Teeth) * (int(settings.FlankSegments) - 1); flanks < expected {
+			t.Errorf("%s: expected %d points on the flanks at least, found %d", name, expected, flanks)
+		}
+	}
+}
+
+func TestGearMesh(t *testing.T) {
+	spur := NewGearSettings(1, 12, 0.5)
+	helical := NewGearSettings(1, 20, 2)
+	helical.HelixAngle, helical.Slices = 20, 6
+	bored := NewGearSettings(1, 30, 1)
+	bored.Bore = 10
+	wide := NewGearSettings(1, 8, 1)
+	wide.Bore = 100
+
+	for name, settings := range map[string]GearSettings{ "spur": spur, "helical": helical, "bored": bored, "bore too wide": wide } {
+		mesh := Gear(settings)
+		checkNormalsAndUVs(t, name, mesh)
+		if err := mesh.Validate(); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+		if !mesh.IsClosed() {
+			t.Errorf("%s: is not closed", name)
+		}
+
+		// The gear fits in the tip circle and the face width
+		tip := float32(settings.TipRadius())
+		checkBounds(t, name, mesh, mgl32.Vec3{ -tip, -settings.FaceWidth / 2, -tip }, mgl32.Vec3{ tip, settings.FaceWidth / 2, tip })
+	}
+}
@@ -0,0 +1,216 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// How close to the seam (or the poles) a vertex has to be to be on it
+const seamTolerance = 1e-6
+
+//
+// Icosphere
+// Creates a sphere of radius 1 with its poles on the z axis by splitting the faces of an icosahedron,
+// so the triangles have about the same size everywhere (unlike the UV sphere, that bunches them at the poles).
+// The texture is mapped the same way as the UV sphere: the triangles that cross the seam are cut along it,
+// and the vertices on the seam and the poles are repeated, so every triangle maps to its own part of the texture
+//
+// @param subdivisions (uint32) the number of times every triangle is split in 4 (0 is the icosahedron)
+//
+// @return mesh (*Mesh) the icosphere
+//
+func Icosphere(subdivisions uint32) *Mesh {
+	positions, triangles := icosahedron()
+	for level := uint32(0); level < subdivisions; level++ {
+		positions, triangles = subdivide(positions, triangles)
+	}
+
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+	vertices := make(map[[5]float32]uint32)
+
+	for _, triangle := range triangles {
+		for _, piece := range splitAtSeam([3]mgl32.Vec3{ positions[triangle[0]], positions[triangle[1]], positions[triangle[2]] }) {
+			uvs := sphereUVs(piece)
+
+			indices := [3]uint32{}
+			for corner, position := range piece {
+				// The vertices are shared when they have the same position and UV
+				key := [5]float32{ position.X(), position.Y(), position.Z(), uvs[corner].X(), uvs[corner].Y() }
+				index, ok := vertices[key]
+				if !ok {
+					index = mesh.addVertex(position, position.Normalize(), uvs[corner])
+					vertices[key] = index
+				}
+				indices[corner] = index
+			}
+			mesh.addTriangle(indices[0], indices[1], indices[2])
+		}
+	}
+
+	return mesh
+}
+
+// icosahedron returns the corners and faces of an icosahedron with a corner on each pole (counter-clockwise seen from outside).
+// The rings are turned so no corner is on the seam
+func icosahedron() ([]mgl32.Vec3, [][3]uint32) {
+	positions := []mgl32.Vec3{ { 0, 0, 1 } }
+
+	// Two rings of 5 corners, the bottom one half a step around from the top one
+	height := 1 / math.Sqrt(5)
+	radius := 2 / math.Sqrt(5)
+	for _, ring := range []struct{ z, offset float64 }{ { height, 0 }, { -height, 36 } } {
+		for index := 0; index < 5; index++ {
+			angle := (18 + ring.offset + 72 * float64(index)) * math.Pi / 180
+			positions = append(positions, mgl32.Vec3{ float32(radius * math.Cos(angle)), float32(radius * math.Sin(angle)), float32(ring.z) })
+		}
+	}
+	positions = append(positions, mgl32.Vec3{ 0, 0, -1 })
+
+	north, south := uint32(0), uint32(11)
+	upper := func(index int) uint32 { return 1 + uint32(index % 5) }
+	lower := func(index int) uint32 { return 6 + uint32(index % 5) }
+
+	triangles := [][3]uint32{}
+	for index := 0; index < 5; index++ {
+		triangles = append(triangles,
+			[3]uint32{ north, upper(index), upper(index + 1) },
+			[3]uint32{ upper(index), lower(index), upper(index + 1) },
+			[3]uint32{ lower(index), lower(index + 1), upper(index + 1) },
+			[3]uint32{ south, lower(index + 1), lower(index) },
+		)
+	}
+
+	return positions, triangles
+}
+
+// subdivide splits every triangle in 4, with the new corners pushed out to the sphere (shared by the neighbours)
+func subdivide(positions []mgl32.Vec3, triangles [][3]uint32) ([]mgl32.Vec3, [][3]uint32) {
+	middles := make(map[[2]uint32]uint32)
+	middle := func(a, b uint32) uint32 {
+		if a > b {
+			a, b = b, a
+		}
+		if index, ok := middles[[2]uint32{ a, b }]; ok {
+			return index
+		}
+
+		positions = append(positions, positions[a].Add(positions[b]).Normalize())
+		index := uint32(len(positions) - 1)
+		middles[[2]uint32{ a, b }] = index
+
+		return index
+	}
+
+	split := make([][3]uint32, 0, len(triangles) * 4)
+	for _, triangle := range triangles {
+		a, b, c := triangle[0], triangle[1], triangle[2]
+		ab, bc, ca := middle(a, b), middle(b, c), middle(c, a)
+		split = append(split, [3]uint32{ a, ab, ca }, [3]uint32{ ab, b, bc }, [3]uint32{ ca, bc, c }, [3]uint32{ ab, bc, ca })
+	}
+
+	return positions, split
+}
+
+// seamSide returns 1 or -1 for the side of the seam (the half plane y = 0, x < 0) a position is on,
+// and 0 if it's on the seam or a pole
+func seamSide(position mgl32.Vec3) int {
+	switch {
+	case math.Abs(float64(position.Y())) < seamTolerance && position.X() < seamTolerance:
+		return 0
+	case position.Y() > 0:
+		return 1
+	}
+
+	return -1
+}
+
+// seamCrossing returns the point where the edge crosses y = 0, pushed out to the sphere
+// (the same for both directions, so the neighbour that shares the edge gets the same point)
+func seamCrossing(a, b mgl32.Vec3) mgl32.Vec3 {
+	if a.Y() < b.Y() {
+		a, b = b, a
+	}
+
+	crossing := a.Add(b.Sub(a).Mul(a.Y() / (a.Y() - b.Y())))
+	crossing[1] = 0
+
+	return crossing.Normalize()
+}
+
+// splitAtSeam cuts a triangle that crosses the seam into triangles on either side (keeping the winding)
+func splitAtSeam(triangle [3]mgl32.Vec3) [][3]mgl32.Vec3 {
+	sides := [3]int{ seamSide(triangle[0]), seamSide(triangle[1]), seamSide(triangle[2]) }
+
+	// Turns the corners so the first one is on the seam, or alone on its side
+	rotate := func(first int) (mgl32.Vec3, mgl32.Vec3, mgl32.Vec3) {
+		return triangle[first], triangle[(first + 1) % 3], triangle[(first + 2) % 3]
+	}
+
+	for corner := 0; corner < 3; corner++ {
+		next, last := (corner + 1) % 3, (corner + 2) % 3
+
+		// A corner on the seam and the other two on different sides: cut from that corner
+		if sides[corner] == 0 && sides[next] * sides[last] < 0 {
+			a, b, c := rotate(corner)
+			crossing := seamCrossing(b, c)
+			if crossing.X() > 0 {
+				break
+			}
+
+			return [][3]mgl32.Vec3{ { a, b, crossing }, { a, crossing, c } }
+		}
+
+		// A corner alone on its side: cut the two edges that leave it
+		if sides[corner] != 0 && sides[corner] == -sides[next] && sides[corner] == -sides[last] {
+			a, b, c := rotate(corner)
+			ab, ac := seamCrossing(a, b), seamCrossing(a, c)
+			if ab.X() > 0 || ac.X() > 0 {
+				break
+			}
+
+			return [][3]mgl32.Vec3{ { a, ab, ac }, { ab, b, c }, { ab, c, ac } }
+		}
+	}
+
+	return [][3]mgl32.Vec3{ triangle }
+}
+
+// sphereUVs maps the corners of a triangle that doesn't cross the seam. The corners on the seam take the u of the side
+// of the triangle (0 or 1), and the poles the middle of the other two corners
+func sphereUVs(triangle [3]mgl32.Vec3) [3]mgl32.Vec2 {
+	side := float32(0)
+	for _, position := range triangle {
+		side += position.Y()
+	}
+
+	uvs := [3]mgl32.Vec2{}
+	poles := []int{}
+	for corner, position := range triangle {
+		v := float32(math.Asin(math.Max(-1, math.Min(1, float64(position.Z())))) / math.Pi + 0.5)
+
+		switch {
+		case math.Abs(float64(position.X())) < seamTolerance && math.Abs(float64(position.Y())) < seamTolerance:
+			poles = append(poles, corner)
+		case seamSide(position) == 0 && side > 0:
+			uvs[corner][0] = 1
+		case seamSide(position) == 0:
+			uvs[corner][0] = 0
+		default:
+			uvs[corner][0] = float32(math.Atan2(float64(position.Y()), float64(position.X())) / (2 * math.Pi) + 0.5)
+		}
+		uvs[corner][1] = v
+	}
+
+	for _, pole := range poles {
+		sum := float32(0)
+		for corner := range triangle {
+			if corner != pole {
+				sum += uvs[corner].X()
+			}
+		}
+		uvs[pole][0] = sum / 2
+	}
+
+	return uvs
+}
@@ -0,0 +1,47 @@
+package geometry
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestIcosphere(t *testing.T) {
+	for subdivisions := uint32(0); subdivisions <= 4; subdivisions++ {
+		name := fmt.Sprintf("icosphere %d", subdivisions)
+		mesh := Icosphere(subdivisions)
+		checkNormalsAndUVs(t, name, mesh)
+
+		if err := mesh.Validate(); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+
+		// Cutting the triangles at the seam leaves no holes
+		if !mesh.IsClosed() {
+			t.Errorf("%s: is not closed", name)
+		}
+
+		// Every split makes 4 triangles of one, and the seam adds some more
+		if triangles := 20 * int(math.Pow(4, float64(subdivisions))); mesh.TriangleCount() < triangles {
+			t.Errorf("%s: expected %d triangles at least, found %d", name, triangles, mesh.TriangleCount())
+		}
+
+		for index, position := range mesh.Positions {
+			if math.Abs(float64(position.Len()) - 1) > 1e-5 || !position.ApproxEqualThreshold(mesh.Normals[index], 1e-5) {
+				t.Fatalf("%s: vertex %d %v is not on the sphere with its normal out (%v)", name, index, position, mesh.Normals[index])
+			}
+		}
+
+		// No triangle wraps around the texture: the seam is only crossed by the repeated vertices
+		for triangle := 0; triangle < len(mesh.Indices); triangle += 3 {
+			min, max := float32(1), float32(0)
+			for corner := 0; corner < 3; corner++ {
+				u := mesh.UVs[mesh.Indices[triangle + corner]].X()
+				min, max = float32(math.Min(float64(min), float64(u))), float32(math.Max(float64(max), float64(u)))
+			}
+			if max - min > 0.5 {
+				t.Fatalf("%s: triangle %d goes from u %f to %f", name, triangle / 3, min, max)
+			}
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package geometry
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// How the indices of a mesh are read
+type Topology int32
+
+const (
+	TOPOLOGY_TRIANGLES Topology = iota // Every 3 indices are a triangle (counter-clockwise seen from outside)
+	TOPOLOGY_LINES                     // Every 2 indices are a line
+	TOPOLOGY_POINTS                    // Every index is a point
+)
+
+// How far from 1 the length of a normal can be
+const NORMAL_TOLERANCE = 1e-4
+
+//
+// Mesh
+// The vertices and indices of a shape, ready to be uploaded (it doesn't know about OpenGL).
+// Normals, Colors and UVs are either empty or have one value per position
+//
+type Mesh struct {
+	Positions []mgl32.Vec3
+	Normals   []mgl32.Vec3
+	Colors    []mgl32.Vec4
+	UVs       []mgl32.Vec2
+	Indices   []uint32
+	Topology  Topology
+}
+
+//
+// NewMesh
+// Creates an empty mesh
+//
+// @param topology (Topology) how the indices are read
+//
+// @return mesh (*Mesh) a pointer to the mesh
+//
+func NewMesh(topology Topology) *Mesh {
+	return &Mesh{ []mgl32.Vec3{}, []mgl32.Vec3{}, nil, []mgl32.Vec2{}, []uint32{}, topology }
+}
+
+func (mesh *Mesh) String() string {
+	return fmt.Sprintf("Mesh: %d vertices, %d indices", mesh.VertexCount(), len(mesh.Indices))
+}
+
+// VertexCount returns the number of vertices
+func (mesh *Mesh) VertexCount() int {
+	return len(mesh.Positions)
+}
+
+// TriangleCount returns the number of triangles (0 if the mesh isn't made of triangles)
+func (mesh *Mesh) TriangleCount() int {
+	if mesh.Topology != TOPOLOGY_TRIANGLES {
+		return 0
+	}
+
+	return len(mesh.Indices) / 3
+}
+
+// addVertex adds a vertex and returns its index
+func (mesh *Mesh) addVertex(position, normal mgl32.Vec3, uv mgl32.Vec2) uint32 {
+	mesh.Positions = append(mesh.Positions, position)
+	mesh.Normals = append(mesh.Normals, normal)
+	mesh.UVs = append(mesh.UVs, uv)
+
+	return uint32(len(mesh.Positions) - 1)
+}
+
+// addTriangle adds a triangle (counter-clockwise seen from outside)
+func (mesh *Mesh) addTriangle(a, b, c uint32) {
+	mesh.Indices = append(mesh.Indices, a, b, c)
+}
+
+// addQuad adds two triangles for the quad a b c d (counter-clockwise seen from outside)
+func (mesh *Mesh) addQuad(a, b, c, d uint32) {
+	mesh.Indices = append(mesh.Indices, a, b, c, a, c, d)
+}
+
+//
+// SetColor
+// Gives every vertex the same colour
+//
+// @param color (mgl32.Vec4) the colour
+//
+func (mesh *Mesh) SetColor(color mgl32.Vec4) {
+	mesh.Paint(func(position, normal mgl32.Vec3) mgl32.Vec4 {
+		return color
+	})
+}
+
+//
+// Paint
+// Gives every vertex a colour from its position and normal
+//
+// @param paint (func(position, normal mgl32.Vec3) mgl32.Vec4) returns the colour of a vertex
+//
+func (mesh *Mesh) Paint(paint func(position, normal mgl32.Vec3) mgl32.Vec4) {
+	mesh.Colors = make([]mgl32.Vec4, len(mesh.Positions))
+	for index, position := range mesh.Positions {
+		normal := mgl32.Vec3{}
+		if index < len(mesh.Normals) {
+			normal = mesh.Normals[index]
+		}
+		mesh.Colors[index] = paint(position, normal)
+	}
+}
+
+//
+// Validate
+// Checks that the mesh can be uploaded and drawn: one normal, colour and UV per position (or none),
+// the indices inside the vertices and complete primitives, normals of length 1 and UVs inside [0, 1]
+//
+// @return error (error) the first problem (nil if the mesh is valid)
+//
+func (mesh *Mesh) Validate() error {
+	count := len(mesh.Positions)
+	if len(mesh.Normals) != 0 && len(mesh.Normals) != count {
+		return fmt.Errorf("%d normals for %d positions", len(mesh.Normals), count)
+	}
+	if len(mesh.Colors) != 0 && len(mesh.Colors) != count {
+		return fmt.Errorf("%d colours for %d positions", len(mesh.Colors), count)
+	}
+	if len(mesh.UVs) != 0 && len(mesh.UVs) != count {
+		return fmt.Errorf("%d UVs for %d positions", len(mesh.UVs), count)
+	}
+
+	switch mesh.Topology {
+	case TOPOLOGY_TRIANGLES:
+		if len(mesh.Indices) % 3 != 0 {
+			return fmt.Errorf("%d indices don't make whole triangles", len(mesh.Indices))
+		}
+	case TOPOLOGY_LINES:
+		if len(mesh.Indices) % 2 != 0 {
+			return fmt.Errorf("%d indices don't make whole lines", len(mesh.Indices))
+		}
+	}
+
+	for position, index := range mesh.Indices {
+		if int(index) >= count {
+			return fmt.Errorf("index %d (at %d) is outside the %d vertices", index, position, count)
+		}
+	}
+
+	for index, normal := range mesh.Normals {
+		if length := normal.Len(); math.Abs(float64(length) - 1) > NORMAL_TOLERANCE {
+			return fmt.Errorf("normal %d has length %f", index, length)
+		}
+	}
+
+	// The textures are clamped to the edge, so the coordinates outside of the texture would smear it
+	for index, uv := range mesh.UVs {
+		if !(uv.X() >= 0 && uv.X() <= 1 && uv.Y() >= 0 && uv.Y() <= 1) {
+			return fmt.Errorf("UV %d (%f, %f) is outside the texture", index, uv.X(), uv.Y())
+		}
+	}
+
+	return nil
+}
+
+//
+// IsClosed
+// Checks if the triangles enclose a volume: every edge is shared by exactly two triangles that go along it
+// in opposite directions (so they all face out or all face in). The vertices are matched by position, so the
+// seams and flat shaded edges (the same position with different normals) still count as joined.
+// The degenerate triangles (two corners in the same place, like the ones at the poles of a sphere) are skipped
+//
+// @return closed (bool) true if the surface is closed
+//
+func (mesh *Mesh) IsClosed() bool {
+	if mesh.Topology != TOPOLOGY_TRIANGLES || len(mesh.Indices) == 0 {
+		return false
+	}
+
+	welded := mesh.weldedIndices()
+	edges := make(map[[2]uint32]int)
+	for triangle := 0; triangle + 2 < len(mesh.Indices); triangle += 3 {
+		a, b, c := welded[mesh.Indices[triangle]], welded[mesh.Indices[triangle + 1]], welded[mesh.Indices[triangle + 2]]
+		if a == b || b == c || c == a {
+			continue
+		}
+
+		edges[[2]uint32{ a, b }]++
+		edges[[2]uint32{ b, c }]++
+		edges[[2]uint32{ c, a }]++
+	}
+
+	for edge, count := range edges {
+		if count != 1 || edges[[2]uint32{ edge[1], edge[0] }] != 1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// weldedIndices returns, for every vertex, the first vertex at the same position (to 1e-5)
+func (mesh *Mesh) weldedIndices() []uint32 {
+	const precision = 1e5
+
+	first := make(map[[3]int64]uint32)
+	welded := make([]uint32, len(mesh.Positions))
+	for index, position := range mesh.Positions {
+		key := [3]int64{
+			int64(math.Floor(float64(position.X()) * precision + 0.5)),
+			int64(math.Floor(float64(position.Y()) * precision + 0.5)),
+			int64(math.Floor(float64(position.Z()) * precision + 0.5)),
+		}
+		if existing, ok := first[key]; ok {
+			welded[index] = existing
+		} else {
+			first[key] = uint32(index)
+			welded[index] = uint32(index)
+		}
+	}
+
+	return welded
+}
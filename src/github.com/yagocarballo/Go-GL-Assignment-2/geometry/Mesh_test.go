@@ -0,0 +1,88 @@
+package geometry
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// tetrahedron returns the smallest closed mesh (the triangles go counter-clockwise seen from outside)
+func tetrahedron() *Mesh {
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+	for _, position := range []mgl32.Vec3{ { 0, 0, 0 }, { 1, 0, 0 }, { 0, 1, 0 }, { 0, 0, 1 } } {
+		mesh.addVertex(position, position.Sub(mgl32.Vec3{ 0.25, 0.25, 0.25 }).Normalize(), mgl32.Vec2{})
+	}
+	mesh.addTriangle(0, 2, 1)
+	mesh.addTriangle(0, 1, 3)
+	mesh.addTriangle(0, 3, 2)
+	mesh.addTriangle(1, 2, 3)
+
+	return mesh
+}
+
+func TestValidate(t *testing.T) {
+	if err := tetrahedron().Validate(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	cases := []struct {
+		name  string
+		break_ func(mesh *Mesh)
+	}{
+		{ "missing normal", func(mesh *Mesh) { mesh.Normals = mesh.Normals[1:] } },
+		{ "missing colour", func(mesh *Mesh) { mesh.Colors = []mgl32.Vec4{ { 1, 1, 1, 1 } } } },
+		{ "missing UV", func(mesh *Mesh) { mesh.UVs = mesh.UVs[1:] } },
+		{ "half a triangle", func(mesh *Mesh) { mesh.Indices = append(mesh.Indices, 0) } },
+		{ "index outside", func(mesh *Mesh) { mesh.Indices[4] = 4 } },
+		{ "long normal", func(mesh *Mesh) { mesh.Normals[2] = mgl32.Vec3{ 0, 2, 0 } } },
+		{ "zero normal", func(mesh *Mesh) { mesh.Normals[2] = mgl32.Vec3{} } },
+		{ "UV outside", func(mesh *Mesh) { mesh.UVs[3] = mgl32.Vec2{ 0.5, 1.01 } } },
+		{ "UV not a number", func(mesh *Mesh) { mesh.UVs[3] = mgl32.Vec2{ float32(nan()), 0 } } },
+	}
+
+	for _, c := range cases {
+		mesh := tetrahedron()
+		c.break_(mesh)
+		if err := mesh.Validate(); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+
+	// The attributes are optional, and the lines only need pairs
+	lines := &Mesh{ Positions: []mgl32.Vec3{ {}, { 1, 0, 0 } }, Indices: []uint32{ 0, 1 }, Topology: TOPOLOGY_LINES }
+	if err := lines.Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestIsClosed(t *testing.T) {
+	if !tetrahedron().IsClosed() {
+		t.Error("the tetrahedron is closed")
+	}
+
+	// A missing face leaves a hole
+	open := tetrahedron()
+	open.Indices = open.Indices[:9]
+	if open.IsClosed() {
+		t.Error("the tetrahedron without a face is open")
+	}
+
+	// A face turned the other way doesn't join its edges in opposite directions
+	flipped := tetrahedron()
+	flipped.Indices[1], flipped.Indices[2] = flipped.Indices[2], flipped.Indices[1]
+	if flipped.IsClosed() {
+		t.Error("a flipped face can't close the surface")
+	}
+
+	// The same position with other normals (a flat shaded edge) is still joined
+	split := tetrahedron()
+	split.addVertex(mgl32.Vec3{ 1, 0, 0 }, mgl32.Vec3{ 1, 0, 0 }, mgl32.Vec2{})
+	split.Indices[len(split.Indices) - 3] = 4
+	if !split.IsClosed() {
+		t.Error("the vertices are matched by position")
+	}
+
+	if NewMesh(TOPOLOGY_TRIANGLES).IsClosed() || (&Mesh{ Topology: TOPOLOGY_LINES, Indices: []uint32{ 0, 1 } }).IsClosed() {
+		t.Error("only triangles can be closed")
+	}
+}
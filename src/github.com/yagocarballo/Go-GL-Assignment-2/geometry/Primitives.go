@@ -0,0 +1,233 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//
+// Box
+// Creates a box centred on the origin, with a flat normal on every face.
+// Every face is a grid with the whole texture on it (seen from outside, u goes right and v goes up)
+//
+// @param width (float32) the size along x
+// @param height (float32) the size along y
+// @param depth (float32) the size along z
+// @param xSegments (uint32) the number of cells along x (1 at least)
+// @param ySegments (uint32) the number of cells along y (1 at least)
+// @param zSegments (uint32) the number of cells along z (1 at least)
+//
+// @return mesh (*Mesh) the box
+//
+func Box(width, height, depth float32, xSegments, ySegments, zSegments uint32) *Mesh {
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+	size := mgl32.Vec3{ width, height, depth }
+	segments := [3]uint32{ atLeast(xSegments, 1), atLeast(ySegments, 1), atLeast(zSegments, 1) }
+
+	// Every face: its normal and the axes of u and v (u x v = normal, so the cells go counter-clockwise)
+	faces := [][3]int{
+		{ 0, 2, 1 }, { 0, 2, 1 }, // +x and -x: u along z, v along y
+		{ 1, 0, 2 }, { 1, 0, 2 }, // +y and -y: u along x, v along z
+		{ 2, 0, 1 }, { 2, 0, 1 }, // +z and -z: u along x, v along y
+	}
+
+	for index, face := range faces {
+		sign := float32(1)
+		if index % 2 == 1 {
+			sign = -1
+		}
+
+		normal, u, v := mgl32.Vec3{}, mgl32.Vec3{}, mgl32.Vec3{}
+		normal[face[0]] = sign
+		u[face[1]] = 1
+		v[face[2]] = 1
+
+		// Flips u when the axes go clockwise around the normal
+		if u.Cross(v).Dot(normal) < 0 {
+			u = u.Mul(-1)
+		}
+
+		columns, rows := segments[face[1]], segments[face[2]]
+		center := normal.Mul(size[face[0]] * 0.5)
+		first := uint32(len(mesh.Positions))
+
+		for row := uint32(0); row <= rows; row++ {
+			for column := uint32(0); column <= columns; column++ {
+				s, t := float32(column) / float32(columns), float32(row) / float32(rows)
+				position := center.Add(u.Mul((s - 0.5) * size[face[1]])).Add(v.Mul((t - 0.5) * size[face[2]]))
+				mesh.addVertex(position, normal, mgl32.Vec2{ s, t })
+			}
+		}
+
+		vertex := func(column, row uint32) uint32 {
+			return first + row * (columns + 1) + column
+		}
+		for row := uint32(0); row < rows; row++ {
+			for column := uint32(0); column < columns; column++ {
+				mesh.addQuad(vertex(column, row), vertex(column + 1, row), vertex(column + 1, row + 1), vertex(column, row + 1))
+			}
+		}
+	}
+
+	return mesh
+}
+
+//
+// Cube
+// Creates a cube centred on the origin, with a flat normal and the whole texture on every face
+//
+// @param size (float32) the length of the sides
+//
+// @return mesh (*Mesh) the cube
+//
+func Cube(size float32) *Mesh {
+	return Box(size, size, size, 1, 1, 1)
+}
+
+//
+// Sphere
+// Creates a UV sphere of radius 1 with its poles on the z axis. Every ring has an extra vertex on the seam
+// and every pole one vertex per segment, so the texture wraps once around (u) and goes from the south to the north (v)
+//
+// @param latitudes (uint32) the number of bands from pole to pole (2 at least)
+// @param longitudes (uint32) the number of segments around (3 at least)
+//
+// @return mesh (*Mesh) the sphere
+//
+func Sphere(latitudes, longitudes uint32) *Mesh {
+	latitudes, longitudes = atLeast(latitudes, 2), atLeast(longitudes, 3)
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+
+	// The rings go from the north to the south (whole steps, so the float error can't add or miss a ring)
+	for ring := uint32(0); ring <= latitudes; ring++ {
+		latitude := math.Pi / 2 - float64(ring) * math.Pi / float64(latitudes)
+		for segment := uint32(0); segment <= longitudes; segment++ {
+			longitude := -math.Pi + float64(segment) * 2 * math.Pi / float64(longitudes)
+			position := mgl32.Vec3{
+				float32(math.Cos(latitude) * math.Cos(longitude)),
+				float32(math.Cos(latitude) * math.Sin(longitude)),
+				float32(math.Sin(latitude)),
+			}
+
+			// cos(pi / 2) isn't exactly 0
+			if ring == 0 || ring == latitudes {
+				position = mgl32.Vec3{ 0, 0, position.Z() }
+			}
+
+			uv := mgl32.Vec2{ float32(segment) / float32(longitudes), 1 - float32(ring) / float32(latitudes) }
+			mesh.addVertex(position, position.Normalize(), uv)
+		}
+	}
+
+	vertex := func(ring, segment uint32) uint32 {
+		return ring * (longitudes + 1) + segment
+	}
+
+	for ring := uint32(0); ring < latitudes; ring++ {
+		for segment := uint32(0); segment < longitudes; segment++ {
+			a, b := vertex(ring, segment), vertex(ring + 1, segment)
+			c, d := vertex(ring + 1, segment + 1), vertex(ring, segment + 1)
+
+			// The bands at the poles are triangles (the other half of the quad has no area)
+			if ring != 0 {
+				mesh.addTriangle(a, c, d)
+			}
+			if ring != latitudes - 1 {
+				mesh.addTriangle(a, b, c)
+			}
+		}
+	}
+
+	return mesh
+}
+
+//
+// Plane
+// Creates a flat grid on the xz plane, centred on the origin and facing up.
+// Seen from above (with -z up), u goes right and v goes up
+//
+// @param width (float32) the size along x
+// @param depth (float32) the size along z
+// @param xSegments (uint32) the number of cells along x (1 at least)
+// @param zSegments (uint32) the number of cells along z (1 at least)
+//
+// @return mesh (*Mesh) the plane
+//
+func Plane(width, depth float32, xSegments, zSegments uint32) *Mesh {
+	xSegments, zSegments = atLeast(xSegments, 1), atLeast(zSegments, 1)
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+	up := mgl32.Vec3{ 0, 1, 0 }
+
+	for x := uint32(0); x <= xSegments; x++ {
+		for z := uint32(0); z <= zSegments; z++ {
+			s, t := float32(x) / float32(xSegments), float32(z) / float32(zSegments)
+			mesh.addVertex(mgl32.Vec3{ width * (s - 0.5), 0, depth * (t - 0.5) }, up, mgl32.Vec2{ s, 1 - t })
+		}
+	}
+
+	vertex := func(x, z uint32) uint32 {
+		return x * (zSegments + 1) + z
+	}
+	for x := uint32(0); x < xSegments; x++ {
+		for z := uint32(0); z < zSegments; z++ {
+			mesh.addQuad(vertex(x, z), vertex(x, z + 1), vertex(x + 1, z + 1), vertex(x + 1, z))
+		}
+	}
+
+	return mesh
+}
+
+//
+// Torus
+// Creates a ring around the y axis, centred on the origin.
+// The texture wraps once around the y axis (u) and once around the tube (v, from the outer edge going up)
+//
+// @param majorRadius (float32) the distance from the centre to the middle of the tube
+// @param minorRadius (float32) the radius of the tube
+// @param rings (uint32) the number of segments around the y axis (3 at least)
+// @param sides (uint32) the number of segments around the tube (3 at least)
+//
+// @return mesh (*Mesh) the torus
+//
+func Torus(majorRadius, minorRadius float32, rings, sides uint32) *Mesh {
+	rings, sides = atLeast(rings, 3), atLeast(sides, 3)
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+
+	// The first ring and side are repeated at the end, with u or v at 1
+	for ring := uint32(0); ring <= rings; ring++ {
+		around := 2 * math.Pi * float64(ring % rings) / float64(rings)
+		center := mgl32.Vec3{ majorRadius * float32(math.Cos(around)), 0, majorRadius * float32(math.Sin(around)) }
+
+		for side := uint32(0); side <= sides; side++ {
+			tube := 2 * math.Pi * float64(side % sides) / float64(sides)
+			normal := mgl32.Vec3{
+				float32(math.Cos(tube) * math.Cos(around)),
+				float32(math.Sin(tube)),
+				float32(math.Cos(tube) * math.Sin(around)),
+			}
+			uv := mgl32.Vec2{ 1 - float32(ring) / float32(rings), float32(side) / float32(sides) }
+			mesh.addVertex(center.Add(normal.Mul(minorRadius)), normal.Normalize(), uv)
+		}
+	}
+
+	vertex := func(ring, side uint32) uint32 {
+		return ring * (sides + 1) + side
+	}
+	for ring := uint32(0); ring < rings; ring++ {
+		for side := uint32(0); side < sides; side++ {
+			mesh.addQuad(vertex(ring, side), vertex(ring, side + 1), vertex(ring + 1, side + 1), vertex(ring + 1, side))
+		}
+	}
+
+	return mesh
+}
+
+// atLeast returns the value, or the minimum if it's smaller
+func atLeast(value, minimum uint32) uint32 {
+	if value < minimum {
+		return minimum
+	}
+
+	return value
+}
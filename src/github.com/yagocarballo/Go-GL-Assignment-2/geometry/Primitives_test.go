@@ -0,0 +1,126 @@
+package geometry
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// nan returns a float that is not a number
+func nan() float64 {
+	return math.NaN()
+}
+
+// shape is a mesh with the counts it should have
+type shape struct {
+	name      string
+	mesh      *Mesh
+	vertices  int
+	triangles int
+	closed    bool
+}
+
+// checkShapes checks the counts of every shape, that it's valid (unit normals and UVs inside the texture)
+// and that the closed ones have no holes
+func checkShapes(t *testing.T, shapes []shape) {
+	for _, shape := range shapes {
+		if shape.mesh.VertexCount() != shape.vertices || shape.mesh.TriangleCount() != shape.triangles {
+			t.Errorf("%s: expected %d vertices and %d triangles, found %d and %d",
+				shape.name, shape.vertices, shape.triangles, shape.mesh.VertexCount(), shape.mesh.TriangleCount())
+		}
+		if err := shape.mesh.Validate(); err != nil {
+			t.Errorf("%s: %s", shape.name, err)
+		}
+		if closed := shape.mesh.IsClosed(); closed != shape.closed {
+			t.Errorf("%s: expected closed %v, found %v", shape.name, shape.closed, closed)
+		}
+	}
+}
+
+// checkBounds checks that every vertex is inside a box
+func checkBounds(t *testing.T, name string, mesh *Mesh, min, max mgl32.Vec3) {
+	for index, position := range mesh.Positions {
+		for axis := 0; axis < 3; axis++ {
+			if position[axis] < min[axis] - 1e-5 || position[axis] > max[axis] + 1e-5 {
+				t.Fatalf("%s: vertex %d %v is outside %v - %v", name, index, position, min, max)
+			}
+		}
+	}
+}
+
+func TestPrimitiveCounts(t *testing.T) {
+	checkShapes(t, []shape{
+		{ "cube", Cube(2), 6 * 4, 6 * 2, true },
+		{ "box 2x3x4", Box(1, 2, 3, 2, 3, 4), 2 * (5 * 4 + 3 * 5 + 3 * 4), 2 * 2 * (4 * 3 + 2 * 4 + 2 * 3), true },
+		{ "sphere 8x12", Sphere(8, 12), 9 * 13, 12 * (2 * 8 - 2), true },
+		{ "sphere at the minimum", Sphere(0, 0), 3 * 4, 3 * 2, true },
+		{ "plane 3x5", Plane(2, 4, 3, 5), 4 * 6, 2 * 3 * 5, false },
+		{ "torus 10x6", Torus(2, 0.5, 10, 6), 11 * 7, 2 * 10 * 6, true },
+	})
+
+	checkBounds(t, "cube", Cube(2), mgl32.Vec3{ -1, -1, -1 }, mgl32.Vec3{ 1, 1, 1 })
+	checkBounds(t, "box", Box(1, 2, 3, 2, 3, 4), mgl32.Vec3{ -0.5, -1, -1.5 }, mgl32.Vec3{ 0.5, 1, 1.5 })
+	checkBounds(t, "plane", Plane(2, 4, 3, 5), mgl32.Vec3{ -1, 0, -2 }, mgl32.Vec3{ 1, 0, 2 })
+	checkBounds(t, "torus", Torus(2, 0.5, 10, 6), mgl32.Vec3{ -2.5, -0.5, -2.5 }, mgl32.Vec3{ 2.5, 0.5, 2.5 })
+
+	// Every vertex of the sphere is on it, with the normal pointing out
+	for index, position := range Sphere(8, 12).Positions {
+		if math.Abs(float64(position.Len()) - 1) > 1e-5 {
+			t.Fatalf("sphere vertex %d is %f away from the centre", index, position.Len())
+		}
+	}
+}
+
+func TestFaceNormalsPointOut(t *testing.T) {
+	// The triangles of a shape around the origin go counter-clockwise seen from outside
+	for _, shape := range []struct {
+		name string
+		mesh *Mesh
+	}{
+		{ "cube", Cube(2) }, { "sphere", Sphere(6, 8) }, { "torus", Torus(2, 0.5, 8, 6) },
+	} {
+		mesh := shape.mesh
+		for triangle := 0; triangle < len(mesh.Indices); triangle += 3 {
+			a, b, c := mesh.Positions[mesh.Indices[triangle]], mesh.Positions[mesh.Indices[triangle + 1]], mesh.Positions[mesh.Indices[triangle + 2]]
+			face := b.Sub(a).Cross(c.Sub(a))
+			if face.Len() < 1e-7 {
+				continue
+			}
+
+			// The face normal goes the same way as the vertex normals
+			if normal := mesh.Normals[mesh.Indices[triangle]]; face.Dot(normal) <= 0 {
+				t.Fatalf("%s: triangle %d faces in", shape.name, triangle / 3)
+			}
+		}
+	}
+}
+
+// checkNormalsAndUVs checks that every normal has length 1 and every UV is inside the texture
+func checkNormalsAndUVs(t *testing.T, name string, mesh *Mesh) {
+	if len(mesh.Normals) != len(mesh.Positions) || len(mesh.UVs) != len(mesh.Positions) {
+		t.Fatalf("%s: %d positions, %d normals and %d UVs", name, len(mesh.Positions), len(mesh.Normals), len(mesh.UVs))
+	}
+	for index, normal := range mesh.Normals {
+		if length := float64(normal.Len()); math.Abs(length - 1) > NORMAL_TOLERANCE {
+			t.Fatalf("%s: normal %d %v has length %f", name, index, normal, length)
+		}
+	}
+	for index, uv := range mesh.UVs {
+		if !(uv.X() >= 0 && uv.X() <= 1 && uv.Y() >= 0 && uv.Y() <= 1) {
+			t.Fatalf("%s: UV %d %v is outside [0, 1]", name, index, uv)
+		}
+	}
+}
+
+func TestPrimitiveNormalsAndUVs(t *testing.T) {
+	for _, segments := range []uint32{ 0, 1, 2, 3, 4, 7, 16, 33 } {
+		for name, mesh := range map[string]*Mesh{
+			"box": Box(1, 2, 3, segments, segments + 1, segments + 2), "plane": Plane(2, 4, segments, segments + 3),
+			"sphere": Sphere(segments, segments * 2), "torus": Torus(2, 0.5, segments, segments + 2),
+		} {
+			checkNormalsAndUVs(t, fmt.Sprintf("%s with %d segments", name, segments), mesh)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// profilePoint is a point of the outline that is turned around the y axis to make a shape
+type profilePoint struct {
+	Radius float32    // Distance to the axis
+	Y      float32    // Height
+	Normal mgl32.Vec2 // Normal of the outline (away from the axis, up)
+	V      float32    // Texture coordinate along the outline
+}
+
+//
+// Cylinder
+// Creates a cylinder on the y axis, centred on the origin, with flat lids and smooth sides.
+// The texture wraps once around the sides, and every lid has the texture seen from outside
+//
+// @param segments (uint32) the number of segments around (3 at least)
+// @param stacks (uint32) the number of bands from the top to the bottom (1 at least)
+// @param height (float32) the height
+// @param radius (float32) the radius
+//
+// @return mesh (*Mesh) the cylinder
+//
+func Cylinder(segments, stacks uint32, height, radius float32) *Mesh {
+	segments, stacks = atLeast(segments, 3), atLeast(stacks, 1)
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+
+	profile := make([]profilePoint, stacks + 1)
+	for stack := range profile {
+		t := float32(stack) / float32(stacks)
+		profile[stack] = profilePoint{ radius, height * (0.5 - t), mgl32.Vec2{ 1, 0 }, 1 - t }
+	}
+
+	radii := uniformRadii(segments, radius)
+	mesh.addLid(radii, height * 0.5, true)
+	mesh.revolve(profile, segments)
+	mesh.addLid(radii, height * -0.5, false)
+
+	return mesh
+}
+
+//
+// Cone
+// Creates a cone on the y axis, centred on the origin, with the tip at the top and a flat base.
+// The texture wraps once around the sides (v goes from the base to the tip)
+//
+// @param segments (uint32) the number of segments around (3 at least)
+// @param stacks (uint32) the number of bands from the tip to the base (1 at least)
+// @param height (float32) the height
+// @param radius (float32) the radius of the base
+//
+// @return mesh (*Mesh) the cone
+//
+func Cone(segments, stacks uint32, height, radius float32) *Mesh {
+	segments, stacks = atLeast(segments, 3), atLeast(stacks, 1)
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+
+	// The sides lean in, so the normal leans up by the angle of the slope
+	normal := mgl32.Vec2{ height, radius }.Normalize()
+
+	profile := make([]profilePoint, stacks + 1)
+	for stack := range profile {
+		t := float32(stack) / float32(stacks)
+		profile[stack] = profilePoint{ radius * t, height * (0.5 - t), normal, 1 - t }
+	}
+
+	mesh.revolve(profile, segments)
+	mesh.addLid(uniformRadii(segments, radius), height * -0.5, false)
+
+	return mesh
+}
+
+//
+// Capsule
+// Creates a capsule on the y axis, centred on the origin: a cylinder with a half sphere on each end.
+// The texture wraps once around, and v goes from the bottom to the top at the same speed along the whole outline
+//
+// @param segments (uint32) the number of segments around (3 at least)
+// @param rings (uint32) the number of bands on each half sphere (1 at least)
+// @param height (float32) the height of the cylinder between the half spheres
+// @param radius (float32) the radius
+//
+// @return mesh (*Mesh) the capsule
+//
+func Capsule(segments, rings uint32, height, radius float32) *Mesh {
+	segments, rings = atLeast(segments, 3), atLeast(rings, 1)
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+
+	// The length of the outline, from the top to the bottom
+	length := math.Pi * float64(radius) + float64(height)
+	profile := []profilePoint{}
+
+	for _, half := range []float32{ 1, -1 } {
+		for ring := uint32(0); ring <= rings; ring++ {
+			// From the pole to the equator on the top, from the equator to the pole on the bottom
+			step := ring
+			if half < 0 {
+				step = rings - ring
+			}
+			latitude := float64(half) * (math.Pi / 2 - float64(step) * math.Pi / 2 / float64(rings))
+
+			sin, cos := math.Sin(latitude), math.Cos(latitude)
+			ringRadius := radius * float32(cos)
+			if step == 0 {
+				// cos(pi / 2) isn't exactly 0
+				ringRadius, cos = 0, 0
+			}
+
+			// Distance along the outline from the top
+			distance := float64(radius) * (math.Pi / 2 - latitude)
+			if half < 0 {
+				distance += float64(height)
+			}
+
+			profile = append(profile, profilePoint{
+				ringRadius,
+				half * height * 0.5 + radius * float32(sin),
+				mgl32.Vec2{ float32(cos), float32(sin) },
+				1 - float32(distance / length),
+			})
+		}
+	}
+
+	mesh.revolve(profile, segments)
+
+	return mesh
+}
+
+//
+// Cog
+// Creates a cog on the y axis, centred on the origin: a cylinder whose vertices alternate between
+// the tooth radius and the outer radius, with flat sides. Every side has a slice of the texture (u) from the
+// top to the bottom (v), and every lid has the texture seen from outside
+//
+// @param segments (uint32) the number of vertices around (3 at least, even so the teeth match all around)
+// @param height (float32) the height
+// @param radius (float32) the radius of the odd vertices
+// @param toothSize (float32) the radius of the even vertices
+//
+// @return mesh (*Mesh) the cog
+//
+func Cog(segments uint32, height, radius, toothSize float32) *Mesh {
+	segments = atLeast(segments, 3)
+	mesh := NewMesh(TOPOLOGY_TRIANGLES)
+
+	radii := uniformRadii(segments, radius)
+	for index := range radii {
+		if index % 2 == 0 {
+			radii[index] = toothSize
+		}
+	}
+
+	top, bottom := height * 0.5, height * -0.5
+	mesh.addLid(radii, top, true)
+
+	// Every side is a flat quad with its own vertices
+	for index := uint32(0); index < segments; index++ {
+		topLeft, topRight := rim(radii, index, top), rim(radii, index + 1, top)
+		bottomLeft, bottomRight := rim(radii, index, bottom), rim(radii, index + 1, bottom)
+		normal := topRight.Sub(topLeft).Cross(bottomRight.Sub(topLeft)).Normalize()
+		left, right := around(index, segments), around(index + 1, segments)
+
+		a := mesh.addVertex(topLeft, normal, mgl32.Vec2{ left, 1 })
+		b := mesh.addVertex(topRight, normal, mgl32.Vec2{ right, 1 })
+		c := mesh.addVertex(bottomRight, normal, mgl32.Vec2{ right, 0 })
+		d := mesh.addVertex(bottomLeft, normal, mgl32.Vec2{ left, 0 })
+		mesh.addQuad(a, b, c, d)
+	}
+
+	mesh.addLid(radii, bottom, false)
+
+	return mesh
+}
+
+// revolve turns an outline (from the top to the bottom) around the y axis, with an extra column on the seam.
+// The quads next to a point on the axis are added as a single triangle
+func (mesh *Mesh) revolve(profile []profilePoint, segments uint32) {
+	first := uint32(len(mesh.Positions))
+
+	for _, point := range profile {
+		for segment := uint32(0); segment <= segments; segment++ {
+			angle := angleOf(segment, segments)
+			cos, sin := float32(math.Cos(angle)), float32(math.Sin(angle))
+
+			position := mgl32.Vec3{ point.Radius * cos, point.Y, point.Radius * sin }
+			normal := mgl32.Vec3{ point.Normal.X() * cos, point.Normal.Y(), point.Normal.X() * sin }.Normalize()
+			mesh.addVertex(position, normal, mgl32.Vec2{ around(segment, segments), point.V })
+		}
+	}
+
+	vertex := func(row, segment uint32) uint32 {
+		return first + row * (segments + 1) + segment
+	}
+
+	for row := uint32(0); row + 1 < uint32(len(profile)); row++ {
+		for segment := uint32(0); segment < segments; segment++ {
+			a, b := vertex(row, segment), vertex(row, segment + 1)
+			c, d := vertex(row + 1, segment + 1), vertex(row + 1, segment)
+
+			if profile[row].Radius != 0 {
+				mesh.addTriangle(a, b, c)
+			}
+			if profile[row + 1].Radius != 0 {
+				mesh.addTriangle(a, c, d)
+			}
+		}
+	}
+}
+
+// addLid adds a flat lid (a fan from the centre) facing up or down, with the texture seen from outside
+func (mesh *Mesh) addLid(radii []float32, y float32, up bool) {
+	segments := uint32(len(radii))
+	normal, flip := mgl32.Vec3{ 0, 1, 0 }, float32(-1)
+	if !up {
+		normal, flip = mgl32.Vec3{ 0, -1, 0 }, 1
+	}
+
+	// The texture covers the widest radius
+	widest := float32(0)
+	for _, radius := range radii {
+		if radius > widest {
+			widest = radius
+		}
+	}
+	uv := func(position mgl32.Vec3) mgl32.Vec2 {
+		if widest == 0 {
+			return mgl32.Vec2{ 0.5, 0.5 }
+		}
+		return mgl32.Vec2{ 0.5 + position.X() / (2 * widest), 0.5 + flip * position.Z() / (2 * widest) }
+	}
+
+	center := mesh.addVertex(mgl32.Vec3{ 0, y, 0 }, normal, mgl32.Vec2{ 0.5, 0.5 })
+	first := uint32(len(mesh.Positions))
+	for index := uint32(0); index < segments; index++ {
+		position := rim(radii, index, y)
+		mesh.addVertex(position, normal, uv(position))
+	}
+
+	for index := uint32(0); index < segments; index++ {
+		if up {
+			mesh.addTriangle(center, first + (index + 1) % segments, first + index)
+		} else {
+			mesh.addTriangle(center, first + index, first + (index + 1) % segments)
+		}
+	}
+}
+
+// rim returns the position of a vertex around the y axis (the index wraps around)
+func rim(radii []float32, index uint32, y float32) mgl32.Vec3 {
+	segments := uint32(len(radii))
+	angle := angleOf(index % segments, segments)
+	radius := radii[index % segments]
+
+	return mgl32.Vec3{ radius * float32(math.Cos(angle)), y, radius * float32(math.Sin(angle)) }
+}
+
+// angleOf returns the angle of a vertex around the y axis (from -pi, whole steps so the last one is pi)
+func angleOf(index, segments uint32) float64 {
+	return -math.Pi + 2 * math.Pi * float64(index) / float64(segments)
+}
+
+// around returns the u of a vertex around the y axis (it goes right seen from outside)
+func around(index, segments uint32) float32 {
+	return 1 - float32(index) / float32(segments)
+}
+
+// uniformRadii returns the same radius for every vertex around
+func uniformRadii(segments uint32, radius float32) []float32 {
+	radii := make([]float32, segments)
+	for index := range radii {
+		radii[index] = radius
+	}
+
+	return radii
+}
@@ -0,0 +1,70 @@
+package geometry
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestRevolvedCounts(t *testing.T) {
+	// Every lid is a centre and a vertex per segment, the sides have a ring (and a seam vertex) per row of the outline
+	checkShapes(t, []shape{
+		{ "cylinder 16x1", Cylinder(16, 1, 2, 1), 2 * 17 + 2 * 17, 2 * 16 + 2 * 16, true },
+		{ "cylinder 12x4", Cylinder(12, 4, 2, 1), 2 * 13 + 5 * 13, 2 * 12 + 2 * 12 * 4, true },
+		{ "cone 16x3", Cone(16, 3, 2, 1), 4 * 17 + 17, 16 * (2 * 3 - 1) + 16, true },
+		{ "capsule 12x4", Capsule(12, 4, 1, 0.5), 2 * 5 * 13, 12 * 4 * 4, true },
+		{ "cog 20", Cog(20, 0.5, 1, 0.2), 2 * 21 + 4 * 20, 2 * 20 + 2 * 20, true },
+		{ "cog at the minimum", Cog(0, 0.5, 1, 0.2), 2 * 4 + 4 * 3, 2 * 3 + 2 * 3, true },
+	})
+
+	checkBounds(t, "cylinder", Cylinder(12, 4, 2, 1), mgl32.Vec3{ -1, -1, -1 }, mgl32.Vec3{ 1, 1, 1 })
+	checkBounds(t, "cone", Cone(16, 3, 2, 1), mgl32.Vec3{ -1, -1, -1 }, mgl32.Vec3{ 1, 1, 1 })
+	checkBounds(t, "capsule", Capsule(12, 4, 1, 0.5), mgl32.Vec3{ -0.5, -1, -0.5 }, mgl32.Vec3{ 0.5, 1, 0.5 })
+	checkBounds(t, "cog", Cog(20, 0.5, 1, 0.2), mgl32.Vec3{ -1, -0.25, -1 }, mgl32.Vec3{ 1, 0.25, 1 })
+}
+
+func TestCogRadii(t *testing.T) {
+	mesh := Cog(20, 0.5, 1, 0.2)
+
+	// The vertices of the top lid alternate between the tooth size and the radius
+	for index := 0; index < 20; index++ {
+		position := mesh.Positions[1 + index]
+		expected := 1.0
+		if index % 2 == 0 {
+			expected = 0.2
+		}
+		if radius := math.Hypot(float64(position.X()), float64(position.Z())); math.Abs(radius - expected) > 1e-5 || position.Y() != 0.25 {
+			t.Errorf("vertex %d: expected the radius %f on the top, found %f (%v)", index, expected, radius, position)
+		}
+	}
+
+	// Every side is flat: its four corners share the normal, and it's level
+	for side := 0; side < 20; side++ {
+		first := 21 + side * 4
+		normal := mesh.Normals[first]
+		for corner := 1; corner < 4; corner++ {
+			if mesh.Normals[first + corner] != normal {
+				t.Fatalf("side %d isn't flat", side)
+			}
+		}
+		if math.Abs(float64(normal.Y())) > 1e-6 {
+			t.Errorf("side %d leans: %v", side, normal)
+		}
+	}
+}
+
+func TestRevolvedNormalsAndUVs(t *testing.T) {
+	for _, segments := range []uint32{ 0, 1, 2, 3, 4, 7, 16, 33 } {
+		for _, stacks := range []uint32{ 0, 1, 2, 5 } {
+			for name, mesh := range map[string]*Mesh{
+				"cylinder": Cylinder(segments, stacks, 2, 1), "cone": Cone(segments, stacks, 2, 1), "flat cone": Cone(segments, stacks, 0.1, 3),
+				"capsule": Capsule(segments, stacks, 1, 0.5), "capsule without sides": Capsule(segments, stacks, 0, 0.5),
+			} {
+				checkNormalsAndUVs(t, fmt.Sprintf("%s with %d segments and %d stacks", name, segments, stacks), mesh)
+			}
+		}
+		checkNormalsAndUVs(t, fmt.Sprintf("cog with %d segments", segments), Cog(segments, 0.5, 1, 0.2))
+	}
+}
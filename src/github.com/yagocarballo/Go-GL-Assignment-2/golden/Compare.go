@@ -0,0 +1,145 @@
+package golden
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Largest weighted difference between two colours in YIQ (black against white is about 93% of it)
+const maxYIQDelta = 35215.0
+
+//
+// Tolerance
+// How different a render can be from its golden image and still pass
+//
+type Tolerance struct {
+	Threshold    float64 // Perceptual difference a pixel can have before it counts as different (0 to 1)
+	MaxDiffRatio float64 // Part of the pixels that can be different (0 to 1)
+}
+
+//
+// DefaultTolerance
+// Returns the tolerance of the harness: small colour shifts pass and 0.1% of the pixels can differ (the edges move a bit between drivers)
+//
+func DefaultTolerance() Tolerance {
+	return Tolerance{ 0.1, 0.001 }
+}
+
+//
+// Result
+// The comparison of a render with its golden image
+//
+type Result struct {
+	Width, Height int
+	DiffPixels    int         // Pixels over the threshold
+	MaxDelta      float64     // Largest perceptual difference (0 to 1)
+	SizeMismatch  bool        // The images have different sizes (nothing else is compared)
+	Diff          *image.RGBA // The expected image faded, with the different pixels in red (nil if the sizes don't match)
+	Passed        bool
+}
+
+func (result Result) String() string {
+	if result.SizeMismatch {
+		return "the sizes don't match"
+	}
+
+	return fmt.Sprintf("%d of %d pixels differ (%.3f%%), max delta %.3f",
+		result.DiffPixels, result.Width * result.Height, result.DiffRatio() * 100, result.MaxDelta)
+}
+
+// DiffRatio returns the part of the pixels over the threshold (0 to 1)
+func (result Result) DiffRatio() float64 {
+	if result.Width * result.Height == 0 {
+		return 0
+	}
+
+	return float64(result.DiffPixels) / float64(result.Width * result.Height)
+}
+
+//
+// Compare
+// Compares a render with its golden image using the perceptual difference of every pixel
+// (the distance in YIQ, which weights the brightness more than the hue, like the eye does)
+//
+// @param expected (image.Image) the golden image
+// @param actual (image.Image) the render
+// @param tolerance (Tolerance) how different they can be
+//
+// @return result (Result) the comparison
+//
+func Compare(expected, actual image.Image, tolerance Tolerance) Result {
+	bounds, actualBounds := expected.Bounds(), actual.Bounds()
+	result := Result{ bounds.Dx(), bounds.Dy(), 0, 0, false, nil, false }
+	if bounds.Dx() != actualBounds.Dx() || bounds.Dy() != actualBounds.Dy() {
+		result.SizeMismatch = true
+		return result
+	}
+
+	result.Diff = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			want := expected.At(bounds.Min.X + x, bounds.Min.Y + y)
+			got := actual.At(actualBounds.Min.X + x, actualBounds.Min.Y + y)
+
+			delta := Delta(want, got)
+			result.MaxDelta = math.Max(result.MaxDelta, delta)
+			if delta > tolerance.Threshold {
+				result.DiffPixels++
+				result.Diff.Set(x, y, color.RGBA{ 255, 0, 0, 255 })
+			} else {
+				result.Diff.Set(x, y, faded(want))
+			}
+		}
+	}
+
+	result.Passed = result.DiffRatio() <= tolerance.MaxDiffRatio
+
+	return result
+}
+
+//
+// Delta
+// Returns the perceptual difference of two colours (0 for the same colour, 1 at most).
+// The colours are blended over white first, so the transparent pixels compare by what they look like
+//
+// @param a (color.Color) the first colour
+// @param b (color.Color) the second colour
+//
+// @return delta (float64) the difference (0 to 1)
+//
+func Delta(a, b color.Color) float64 {
+	r1, g1, b1 := blendWhite(a)
+	r2, g2, b2 := blendWhite(b)
+
+	y := yiqY(r1, g1, b1) - yiqY(r2, g2, b2)
+	i := yiqI(r1, g1, b1) - yiqI(r2, g2, b2)
+	q := yiqQ(r1, g1, b1) - yiqQ(r2, g2, b2)
+
+	return (0.5053 * y * y + 0.299 * i * i + 0.1957 * q * q) / maxYIQDelta
+}
+
+// blendWhite returns the colour over a white background (0 to 255 per channel)
+func blendWhite(c color.Color) (float64, float64, float64) {
+	r, g, b, a := c.RGBA()
+	alpha := float64(a) / 0xffff
+	blend := func(channel uint32) float64 {
+		return 255 + (float64(channel) / 0xffff * 255 - 255 * alpha)
+	}
+
+	// RGBA returns premultiplied colours, so adding the white behind is enough
+	return blend(r), blend(g), blend(b)
+}
+
+func yiqY(r, g, b float64) float64 { return r * 0.29889531 + g * 0.58662247 + b * 0.11448223 }
+func yiqI(r, g, b float64) float64 { return r * 0.59597799 - g * 0.27417610 - b * 0.32180189 }
+func yiqQ(r, g, b float64) float64 { return r * 0.21147017 - g * 0.52261711 + b * 0.31114694 }
+
+// faded returns the brightness of a colour mixed with white, the background of the diff image
+func faded(c color.Color) color.RGBA {
+	r, g, b := blendWhite(c)
+	gray := uint8(255 + (yiqY(r, g, b) - 255) * 0.1)
+
+	return color.RGBA{ gray, gray, gray, 255 }
+}
@@ -0,0 +1,87 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// filled returns an image of one colour
+func filled(width, height int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestDelta(t *testing.T) {
+	black, white := color.RGBA{ 0, 0, 0, 255 }, color.RGBA{ 255, 255, 255, 255 }
+
+	if delta := Delta(black, black); delta != 0 {
+		t.Errorf("expected no difference between the same colours, found %f", delta)
+	}
+	if delta := Delta(black, white); delta < 0.9 || delta > 1 {
+		t.Errorf("expected about 0.93 between black and white, found %f", delta)
+	}
+
+	// The eye sees the change of brightness more than the change of hue
+	gray, brighter := color.RGBA{ 128, 128, 128, 255 }, color.RGBA{ 148, 148, 148, 255 }
+	bluer := color.RGBA{ 128, 128, 148, 255 }
+	if Delta(gray, brighter) <= Delta(gray, bluer) {
+		t.Errorf("the brightness (%f) should weight more than the blue (%f)", Delta(gray, brighter), Delta(gray, bluer))
+	}
+
+	// A transparent pixel looks like the white behind it
+	if delta := Delta(color.RGBA{ 0, 0, 0, 0 }, white); delta > 1e-9 {
+		t.Errorf("expected a transparent pixel to be white, found %f", delta)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	expected := filled(100, 100, color.RGBA{ 40, 80, 120, 255 })
+
+	// A small colour shift passes
+	shifted := filled(100, 100, color.RGBA{ 42, 81, 121, 255 })
+	if result := Compare(expected, shifted, DefaultTolerance()); !result.Passed || result.DiffPixels != 0 || result.MaxDelta == 0 {
+		t.Errorf("expected the shifted colour to pass, found %s", result)
+	}
+
+	// 10 different pixels are 0.1% of the image: the limit of the default tolerance
+	actual := filled(100, 100, color.RGBA{ 40, 80, 120, 255 })
+	for x := 0; x < 10; x++ {
+		actual.Set(x, 50, color.White)
+	}
+	result := Compare(expected, actual, DefaultTolerance())
+	if !result.Passed || result.DiffPixels != 10 {
+		t.Errorf("expected 10 different pixels to pass, found %s", result)
+	}
+
+	actual.Set(10, 50, color.White)
+	result = Compare(expected, actual, DefaultTolerance())
+	if result.Passed || result.DiffPixels != 11 {
+		t.Errorf("expected 11 different pixels to fail, found %s", result)
+	}
+
+	// The diff image has the different pixels in red, and the rest faded
+	if result.Diff.RGBAAt(10, 50) != (color.RGBA{ 255, 0, 0, 255 }) {
+		t.Errorf("expected a red pixel, found %v", result.Diff.RGBAAt(10, 50))
+	}
+	if pixel := result.Diff.RGBAAt(11, 50); pixel.R != pixel.G || pixel.R < 200 {
+		t.Errorf("expected a light gray pixel, found %v", pixel)
+	}
+
+	// The images can start anywhere, only the size counts
+	moved := image.NewRGBA(image.Rect(5, 5, 105, 105))
+	copy(moved.Pix, expected.Pix)
+	if result := Compare(expected, moved, DefaultTolerance()); !result.Passed || result.DiffPixels != 0 {
+		t.Errorf("expected the moved image to pass, found %s", result)
+	}
+
+	if result := Compare(expected, filled(100, 99, color.Black), DefaultTolerance()); result.Passed || !result.SizeMismatch || result.Diff != nil {
+		t.Errorf("expected the sizes not to match, found %s", result)
+	}
+}
@@ -0,0 +1,158 @@
+package golden
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+//
+// Case
+// A named render to compare with its golden image (<GoldenDir>/<Name>.png)
+//
+type Case struct {
+	Name   string
+	Render func() (image.Image, error)
+}
+
+//
+// Options
+// Where the golden images are, where the failures go and how different the renders can be
+//
+type Options struct {
+	GoldenDir string    // Folder with the golden images
+	OutputDir string    // Folder for the renders and diff images of the failed cases
+	Update    bool      // Replaces the golden images with the renders instead of comparing them
+	Tolerance Tolerance
+}
+
+//
+// Report
+// The outcome of a case
+//
+type Report struct {
+	Name    string
+	Result  Result
+	Updated bool  // The golden image was written
+	Error   error // The case couldn't be rendered or compared
+}
+
+// Passed checks if the case matched its golden image (or updated it)
+func (report Report) Passed() bool {
+	return report.Error == nil && (report.Updated || report.Result.Passed)
+}
+
+func (report Report) String() string {
+	switch {
+	case report.Error != nil:
+		return fmt.Sprintf("FAIL %s: %s", report.Name, report.Error)
+	case report.Updated:
+		return fmt.Sprintf("UPDATED %s", report.Name)
+	case report.Result.Passed:
+		return fmt.Sprintf("PASS %s (%s)", report.Name, report.Result)
+	default:
+		return fmt.Sprintf("FAIL %s (%s)", report.Name, report.Result)
+	}
+}
+
+//
+// Run
+// Renders every case and compares it with its golden image (or updates it).
+// The failed cases write <Name>.actual.png and <Name>.diff.png to the output folder
+//
+// @param cases ([]Case) the cases
+// @param options (Options) the folders and the tolerance
+//
+// @return reports ([]Report) a report per case (in the same order)
+//
+func Run(cases []Case, options Options) []Report {
+	reports := []Report{}
+	for _, testCase := range cases {
+		reports = append(reports, runCase(testCase, options))
+	}
+
+	return reports
+}
+
+// runCase renders one case and compares it
+func runCase(testCase Case, options Options) Report {
+	report := Report{ testCase.Name, Result{}, false, nil }
+
+	actual, err := testCase.Render()
+	if err != nil {
+		report.Error = fmt.Errorf("render failed: %s", err)
+		return report
+	}
+
+	goldenPath := filepath.Join(options.GoldenDir, testCase.Name + ".png")
+	if options.Update {
+		if report.Error = writePNG(goldenPath, actual); report.Error == nil {
+			report.Updated = true
+		}
+		return report
+	}
+
+	expected, err := readPNG(goldenPath)
+	if os.IsNotExist(err) {
+		report.Error = fmt.Errorf("there is no golden image at %s (run with -update to create it)", goldenPath)
+	} else if err != nil {
+		report.Error = err
+	}
+	if report.Error != nil {
+		report.Error = firstError(report.Error, writePNG(filepath.Join(options.OutputDir, testCase.Name + ".actual.png"), actual))
+		return report
+	}
+
+	report.Result = Compare(expected, actual, options.Tolerance)
+	if !report.Result.Passed {
+		report.Error = writePNG(filepath.Join(options.OutputDir, testCase.Name + ".actual.png"), actual)
+		if report.Result.Diff != nil {
+			report.Error = firstError(report.Error, writePNG(filepath.Join(options.OutputDir, testCase.Name + ".diff.png"), report.Result.Diff))
+		}
+	}
+
+	return report
+}
+
+// readPNG decodes a PNG file
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return png.Decode(file)
+}
+
+// writePNG encodes an image to a PNG file (it creates the folder if it's missing)
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// firstError returns the first error that is not nil
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
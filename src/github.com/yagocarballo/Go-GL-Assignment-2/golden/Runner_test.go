@@ -0,0 +1,93 @@
+package golden
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// render returns the render function of a case that always draws the same image
+func render(img image.Image) func() (image.Image, error) {
+	return func() (image.Image, error) { return img, nil }
+}
+
+// exists checks if a file was written
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestRunUpdatesAndCompares(t *testing.T) {
+	folder, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	options := Options{ filepath.Join(folder, "goldens"), filepath.Join(folder, "output"), true, DefaultTolerance() }
+	blue := filled(20, 10, color.RGBA{ 0, 0, 255, 255 })
+	cases := []Case{ { "blue", render(blue) } }
+
+	// Without golden images the case fails, and the render is kept to check it
+	options.Update = false
+	reports := Run(cases, options)
+	if reports[0].Passed() || !strings.Contains(reports[0].Error.Error(), "-update") {
+		t.Errorf("expected the missing golden image to fail, found %s", reports[0])
+	}
+	if !exists(filepath.Join(options.OutputDir, "blue.actual.png")) {
+		t.Error("the render of the missing golden image wasn't written")
+	}
+
+	// The update writes the golden image
+	options.Update = true
+	if reports := Run(cases, options); !reports[0].Passed() || !reports[0].Updated {
+		t.Fatalf("expected the golden image to be updated, found %s", reports[0])
+	}
+	saved, err := readPNG(filepath.Join(options.GoldenDir, "blue.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := Compare(blue, saved, Tolerance{ 0, 0 }); !result.Passed {
+		t.Errorf("the golden image is not the render: %s", result)
+	}
+
+	// The same render passes, a different one fails with the diff image
+	options.Update = false
+	red := filled(20, 10, color.RGBA{ 255, 0, 0, 255 })
+	cases = append(cases, Case{ "red", render(red) })
+	writePNG(filepath.Join(options.GoldenDir, "red.png"), blue)
+
+	reports = Run(cases, options)
+	if len(reports) != 2 || reports[0].Name != "blue" || reports[1].Name != "red" {
+		t.Fatalf("expected a report per case in order, found %v", reports)
+	}
+	if !reports[0].Passed() || !strings.HasPrefix(reports[0].String(), "PASS blue") {
+		t.Errorf("expected blue to pass, found %s", reports[0])
+	}
+	if reports[1].Passed() || reports[1].Result.DiffPixels != 200 || !strings.HasPrefix(reports[1].String(), "FAIL red") {
+		t.Errorf("expected red to fail, found %s", reports[1])
+	}
+	for _, name := range []string{ "red.actual.png", "red.diff.png" } {
+		if !exists(filepath.Join(options.OutputDir, name)) {
+			t.Errorf("%s wasn't written", name)
+		}
+	}
+}
+
+func TestRunRenderError(t *testing.T) {
+	failing := Case{ "broken", func() (image.Image, error) { return nil, errors.New("no context") } }
+	report := Run([]Case{ failing }, Options{ "missing", "missing", true, DefaultTolerance() })[0]
+
+	// A failed render never updates the golden image
+	if report.Passed() || report.Updated || !strings.Contains(report.String(), "no context") {
+		t.Errorf("expected the render to fail, found %s", report)
+	}
+	if exists(filepath.Join("missing", "broken.png")) {
+		t.Error("the failed render wrote a golden image")
+	}
+}
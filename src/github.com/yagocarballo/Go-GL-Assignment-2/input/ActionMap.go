@@ -0,0 +1,368 @@
+package input
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+//
+// State
+// What happened to the input of an action
+//
+type State int
+
+const (
+	PRESSED State = iota
+	REPEATED
+	RELEASED
+)
+
+func (state State) String() string {
+	switch state {
+	case PRESSED:
+		return "Pressed"
+	case REPEATED:
+		return "Repeated"
+	case RELEASED:
+		return "Released"
+	}
+
+	return fmt.Sprintf("State(%d)", int(state))
+}
+
+//
+// Event
+// An action triggered by an input
+//
+type Event struct {
+	Action string
+	State  State
+}
+
+//
+// Action
+// A named action of the app (Example: move-forward) and the inputs bound to it
+//
+type Action struct {
+	Name        string
+	Group       string    // Title of the section of the help
+	Description string
+	Bindings    []Binding
+}
+
+//
+// ActionMap
+// Translates the keys, mouse buttons and gamepad inputs into actions.
+// It keeps which bindings are held, so the actions can also be polled every frame
+//
+type ActionMap struct {
+	actions []*Action           // In the order they were defined (the order of the help)
+	byName  map[string]*Action
+	bound   map[Binding]*Action // Every binding triggers one action at most
+
+	held    map[Binding]bool
+	axes    map[Binding]float32 // Value of the axes in the direction of the binding (0 to 1)
+}
+
+//
+// NewActionMap
+// Creates an action map without actions
+//
+// @return actionMap (*ActionMap) a pointer to the action map
+//
+func NewActionMap() *ActionMap {
+	return &ActionMap{
+		[]*Action{},                // actions
+		map[string]*Action{},       // byName
+		map[Binding]*Action{},      // bound
+		map[Binding]bool{},         // held
+		map[Binding]float32{},      // axes
+	}
+}
+
+//
+// Define
+// Adds an action (without bindings)
+//
+// @param name (string) the name of the action
+// @param group (string) the section of the help
+// @param description (string) what the action does
+//
+// @return error (error) the error (if the action already exists)
+//
+func (actionMap *ActionMap) Define(name, group, description string) error {
+	if _, ok := actionMap.byName[name]; ok {
+		return fmt.Errorf("the action %q is already defined", name)
+	}
+
+	action := &Action{ name, group, description, []Binding{} }
+	actionMap.actions = append(actionMap.actions, action)
+	actionMap.byName[name] = action
+
+	return nil
+}
+
+//
+// Bind
+// Binds an input to an action
+//
+// @param name (string) the name of the action
+// @param binding (Binding) the input
+//
+// @return error (error) the error (if the action doesn't exist or the input is bound to another action)
+//
+func (actionMap *ActionMap) Bind(name string, binding Binding) error {
+	action, ok := actionMap.byName[name]
+	if !ok {
+		return fmt.Errorf("there is no action called %q", name)
+	}
+
+	if other, ok := actionMap.bound[binding]; ok {
+		if other == action {
+			return nil
+		}
+		return fmt.Errorf("%s is already bound to %q", binding, other.Name)
+	}
+
+	action.Bindings = append(action.Bindings, binding)
+	actionMap.bound[binding] = action
+
+	return nil
+}
+
+//
+// UnbindAll
+// Removes every binding (the actions stay defined)
+//
+func (actionMap *ActionMap) UnbindAll() {
+	for _, action := range actionMap.actions {
+		action.Bindings = []Binding{}
+	}
+
+	actionMap.bound = map[Binding]*Action{}
+	actionMap.held = map[Binding]bool{}
+	actionMap.axes = map[Binding]float32{}
+}
+
+//
+// Actions
+// Returns the actions in the order they were defined
+//
+func (actionMap *ActionMap) Actions() []*Action {
+	return actionMap.actions
+}
+
+//
+// Action
+// Finds an action by name
+//
+// @param name (string) the name of the action
+//
+// @return action (*Action) the action (nil if it doesn't exist)
+//
+func (actionMap *ActionMap) Action(name string) *Action {
+	return actionMap.byName[name]
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////// Resolution ////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// Key
+// Resolves a key event
+//
+// @param key (glfw.Key) the key
+// @param action (glfw.Action) the state of the key
+//
+// @return event (Event) the action event
+// @return ok (bool) false if the key is not bound
+//
+func (actionMap *ActionMap) Key(key glfw.Key, action glfw.Action) (Event, bool) {
+	return actionMap.button(KeyBinding(key), action)
+}
+
+//
+// MouseButton
+// Resolves a mouse button event
+//
+// @param button (glfw.MouseButton) the button
+// @param action (glfw.Action) the state of the button
+//
+// @return event (Event) the action event
+// @return ok (bool) false if the button is not bound
+//
+func (actionMap *ActionMap) MouseButton(button glfw.MouseButton, action glfw.Action) (Event, bool) {
+	return actionMap.button(MouseBinding(button), action)
+}
+
+//
+// GamepadButton
+// Resolves a gamepad button event
+//
+// @param button (int) the index of the button
+// @param action (glfw.Action) the state of the button
+//
+// @return event (Event) the action event
+// @return ok (bool) false if the button is not bound
+//
+func (actionMap *ActionMap) GamepadButton(button int, action glfw.Action) (Event, bool) {
+	return actionMap.button(GamepadButtonBinding(button), action)
+}
+
+//
+// GamepadAxis
+// Stores the value of a gamepad axis (read with Value)
+//
+// @param axis (int) the index of the axis
+// @param value (float32) the position of the axis (-1 to 1)
+//
+func (actionMap *ActionMap) GamepadAxis(axis int, value float32) {
+	for _, sign := range []int{ 1, -1 } {
+		binding := GamepadAxisBinding(axis, sign)
+		if _, ok := actionMap.bound[binding]; !ok {
+			continue
+		}
+
+		directed := value * float32(sign)
+		if directed < 0 {
+			directed = 0
+		}
+		actionMap.axes[binding] = directed
+	}
+}
+
+//
+// ResetAxes
+// Moves every gamepad axis back to 0 (before reading the gamepads of a new frame, or when they disconnect)
+//
+func (actionMap *ActionMap) ResetAxes() {
+	actionMap.axes = map[Binding]float32{}
+}
+
+//
+// AxisValue
+// Returns how far the gamepad axes of an action are pushed (ignoring the keys and buttons)
+//
+// @param name (string) the name of the action
+//
+// @return value (float32) the strongest axis of the action (0 to 1)
+//
+func (actionMap *ActionMap) AxisValue(name string) float32 {
+	action, ok := actionMap.byName[name]
+	if !ok {
+		return 0
+	}
+
+	var value float32
+	for _, binding := range action.Bindings {
+		if axis := actionMap.axes[binding]; axis > value {
+			value = axis
+		}
+	}
+
+	return value
+}
+
+//
+// IsHeld
+// Checks if any input of an action is held (an axis counts once it passes half way)
+//
+// @param name (string) the name of the action
+//
+// @return held (bool) true if the action is held
+//
+func (actionMap *ActionMap) IsHeld(name string) bool {
+	return actionMap.Value(name) >= 0.5
+}
+
+//
+// Value
+// Returns how much an action is held: 1 for a held key or button, the position for an axis
+//
+// @param name (string) the name of the action
+//
+// @return value (float32) the strongest input of the action (0 to 1)
+//
+func (actionMap *ActionMap) Value(name string) float32 {
+	action, ok := actionMap.byName[name]
+	if !ok {
+		return 0
+	}
+
+	var value float32
+	for _, binding := range action.Bindings {
+		if actionMap.held[binding] {
+			return 1
+		}
+		if axis := actionMap.axes[binding]; axis > value {
+			value = axis
+		}
+	}
+
+	return value
+}
+
+// button resolves a key or button and keeps track of the held ones
+func (actionMap *ActionMap) button(binding Binding, action glfw.Action) (Event, bool) {
+	bound, ok := actionMap.bound[binding]
+	if !ok {
+		return Event{}, false
+	}
+
+	state := PRESSED
+	switch action {
+	case glfw.Press:
+		actionMap.held[binding] = true
+	case glfw.Repeat:
+		state = REPEATED
+	case glfw.Release:
+		state = RELEASED
+		delete(actionMap.held, binding)
+	}
+
+	return Event{ bound.Name, state }, true
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+//////////////////////////////////////// Help ///////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// Help
+// Generates the help from the current bindings, one section per group (the columns line up within a section)
+//
+// @return help (string) the help text
+//
+func (actionMap *ActionMap) Help() string {
+	var buffer bytes.Buffer
+	writer := tabwriter.NewWriter(&buffer, 0, 4, 2, ' ', 0)
+
+	group := ""
+	for index, action := range actionMap.actions {
+		if index == 0 || action.Group != group {
+			group = action.Group
+			if index > 0 {
+				fmt.Fprintln(writer)
+			}
+			fmt.Fprintf(writer, "%s\n%s\n", group, strings.Repeat("-", len(group)))
+		}
+
+		labels := []string{}
+		for _, binding := range action.Bindings {
+			labels = append(labels, binding.Label())
+		}
+		if len(labels) == 0 {
+			labels = append(labels, "(unbound)")
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", strings.Join(labels, ", "), action.Name, action.Description)
+	}
+
+	writer.Flush()
+	return buffer.String()
+}
@@ -0,0 +1,201 @@
+package input
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+// testActions returns an action map with two groups of actions, bound to a key, a mouse button and the gamepad
+func testActions(t *testing.T) *ActionMap {
+	actionMap := NewActionMap()
+	actionMap.Define("quit", "General", "Closes the app")
+	actionMap.Define("jump", "Movement", "Jumps")
+	actionMap.Define("move-left", "Movement", "Moves to the left")
+	actionMap.Define("move-right", "Movement", "Moves to the right")
+	actionMap.Define("look", "Movement", "Looks around")
+
+	bindings := []struct {
+		action  string
+		binding Binding
+	}{
+		{ "quit", KeyBinding(glfw.KeyEscape) },
+		{ "jump", KeyBinding(glfw.KeySpace) },
+		{ "jump", GamepadButtonBinding(0) },
+		{ "move-left", KeyBinding(glfw.KeyA) },
+		{ "move-left", GamepadAxisBinding(0, -1) },
+		{ "move-right", GamepadAxisBinding(0, 1) },
+		{ "look", MouseBinding(glfw.MouseButtonRight) },
+	}
+	for _, binding := range bindings {
+		if err := actionMap.Bind(binding.action, binding.binding); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return actionMap
+}
+
+func TestDefineAndBind(t *testing.T) {
+	actionMap := testActions(t)
+
+	if err := actionMap.Define("jump", "Other", "Jumps again"); err == nil {
+		t.Error("an action can't be defined twice")
+	}
+	if err := actionMap.Bind("fly", KeyBinding(glfw.KeyF)); err == nil {
+		t.Error("bound an action that doesn't exist")
+	}
+	if err := actionMap.Bind("quit", KeyBinding(glfw.KeySpace)); err == nil || !strings.Contains(err.Error(), `"jump"`) {
+		t.Errorf("expected an error about the binding of jump, found %v", err)
+	}
+
+	// Binding the same input to the same action again does nothing
+	if err := actionMap.Bind("jump", KeyBinding(glfw.KeySpace)); err != nil || len(actionMap.Action("jump").Bindings) != 2 {
+		t.Errorf("the binding was added twice (%v): %v", err, actionMap.Action("jump").Bindings)
+	}
+
+	names := []string{}
+	for _, action := range actionMap.Actions() {
+		names = append(names, action.Name)
+	}
+	if strings.Join(names, " ") != "quit jump move-left move-right look" {
+		t.Errorf("the actions are not in the order they were defined: %v", names)
+	}
+}
+
+func TestButtonResolution(t *testing.T) {
+	actionMap := testActions(t)
+
+	cases := []struct {
+		name    string
+		resolve func(glfw.Action) (Event, bool)
+		action  string
+	}{
+		{ "key", func(state glfw.Action) (Event, bool) { return actionMap.Key(glfw.KeySpace, state) }, "jump" },
+		{ "mouse button", func(state glfw.Action) (Event, bool) { return actionMap.MouseButton(glfw.MouseButtonRight, state) }, "look" },
+		{ "gamepad button", func(state glfw.Action) (Event, bool) { return actionMap.GamepadButton(0, state) }, "jump" },
+	}
+
+	for _, c := range cases {
+		for _, step := range []struct {
+			state    glfw.Action
+			expected State
+			held     bool
+		}{
+			{ glfw.Press, PRESSED, true },
+			{ glfw.Repeat, REPEATED, true },
+			{ glfw.Release, RELEASED, false },
+		} {
+			event, ok := c.resolve(step.state)
+			if !ok || event != (Event{ c.action, step.expected }) {
+				t.Errorf("%s: expected %s %s, found %v (%v)", c.name, c.action, step.expected, event, ok)
+			}
+			if held := actionMap.IsHeld(c.action); held != step.held {
+				t.Errorf("%s: after %s the action is held: %v", c.name, step.expected, held)
+			}
+		}
+	}
+
+	// Unbound inputs are ignored
+	if _, ok := actionMap.Key(glfw.KeyF, glfw.Press); ok {
+		t.Error("resolved a key that is not bound")
+	}
+	if _, ok := actionMap.MouseButton(glfw.MouseButtonLeft, glfw.Press); ok {
+		t.Error("resolved a mouse button that is not bound")
+	}
+	if _, ok := actionMap.GamepadButton(7, glfw.Press); ok {
+		t.Error("resolved a gamepad button that is not bound")
+	}
+
+	// The action is held while any of its inputs is
+	actionMap.Key(glfw.KeySpace, glfw.Press)
+	actionMap.GamepadButton(0, glfw.Press)
+	actionMap.Key(glfw.KeySpace, glfw.Release)
+	if !actionMap.IsHeld("jump") || actionMap.Value("jump") != 1 {
+		t.Error("the gamepad button still holds the action")
+	}
+	actionMap.GamepadButton(0, glfw.Release)
+	if actionMap.IsHeld("jump") {
+		t.Error("the action is held without inputs")
+	}
+}
+
+func TestGamepadAxes(t *testing.T) {
+	actionMap := testActions(t)
+
+	// Every direction of the axis is a different action, and the other direction is 0
+	actionMap.GamepadAxis(0, -0.75)
+	if value := actionMap.AxisValue("move-left"); value != 0.75 {
+		t.Errorf("expected move-left at 0.75, found %f", value)
+	}
+	if value := actionMap.AxisValue("move-right"); value != 0 {
+		t.Errorf("expected move-right at 0, found %f", value)
+	}
+	if !actionMap.IsHeld("move-left") || actionMap.IsHeld("move-right") {
+		t.Error("only move-left is held")
+	}
+
+	// Under half way the action has a value, but it's not held
+	actionMap.GamepadAxis(0, 0.25)
+	if value := actionMap.Value("move-right"); value != 0.25 || actionMap.IsHeld("move-right") {
+		t.Errorf("expected move-right at 0.25 and not held, found %f", value)
+	}
+
+	// A held key wins over the axis, but the axis value ignores the keys
+	actionMap.GamepadAxis(0, -0.25)
+	actionMap.Key(glfw.KeyA, glfw.Press)
+	if actionMap.Value("move-left") != 1 || actionMap.AxisValue("move-left") != 0.25 {
+		t.Errorf("expected the key to hold move-left, found %f (axis %f)", actionMap.Value("move-left"), actionMap.AxisValue("move-left"))
+	}
+
+	// Unbound axes and unknown actions are ignored
+	actionMap.GamepadAxis(5, 1)
+	if actionMap.AxisValue("jump") != 0 || actionMap.Value("fly") != 0 || actionMap.IsHeld("fly") {
+		t.Error("an unbound axis moved an action")
+	}
+
+	actionMap.ResetAxes()
+	if actionMap.AxisValue("move-left") != 0 || !actionMap.IsHeld("move-left") {
+		t.Error("ResetAxes only moves the axes back")
+	}
+
+	actionMap.UnbindAll()
+	if actionMap.IsHeld("move-left") || len(actionMap.Action("move-left").Bindings) != 0 || len(actionMap.Actions()) != 5 {
+		t.Error("UnbindAll keeps the actions without bindings or held inputs")
+	}
+	if _, ok := actionMap.Key(glfw.KeyA, glfw.Press); ok {
+		t.Error("a key is still bound after UnbindAll")
+	}
+}
+
+func TestHelp(t *testing.T) {
+	actionMap := testActions(t)
+	actionMap.Define("dance", "Movement", "Dances")
+
+	expected := strings.Join([]string{
+		"General",
+		"-------",
+		"Escape  quit  Closes the app",
+		"",
+		"Movement",
+		"--------",
+		"Space, Gamepad Button 0  jump        Jumps",
+		"A, Gamepad Axis 0 -      move-left   Moves to the left",
+		"Gamepad Axis 0 +         move-right  Moves to the right",
+		"Right Mouse Button       look        Looks around",
+		"(unbound)                dance       Dances",
+		"",
+	}, "\n")
+
+	if help := actionMap.Help(); help != expected {
+		t.Errorf("expected the help:\n%s\nfound:\n%s", expected, help)
+	}
+
+	// The help follows the bindings
+	actionMap.UnbindAll()
+	actionMap.Bind("quit", KeyBinding(glfw.KeyQ))
+	if help := actionMap.Help(); !strings.Contains(help, "Q  quit") || !strings.Contains(help, "(unbound)  jump") {
+		t.Errorf("the help didn't change with the bindings:\n%s", help)
+	}
+}
@@ -0,0 +1,239 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+//
+// Device
+// Where the input of a binding comes from
+//
+type Device int
+
+const (
+	DEVICE_KEY Device = iota
+	DEVICE_MOUSE
+	DEVICE_GAMEPAD_BUTTON
+	DEVICE_GAMEPAD_AXIS
+)
+
+// Prefixes of the bindings in the config file (Example: "key:W", "mouse:Right", "gamepad-axis:1-")
+var devicePrefixes = []string{ "key", "mouse", "gamepad-button", "gamepad-axis" }
+
+func (device Device) String() string {
+	if int(device) < len(devicePrefixes) {
+		return devicePrefixes[device]
+	}
+
+	return fmt.Sprintf("Device(%d)", int(device))
+}
+
+//
+// Binding
+// A key, mouse button, gamepad button or one direction of a gamepad axis
+//
+type Binding struct {
+	Device Device
+	Code   int // The key, mouse button, gamepad button or gamepad axis
+	Sign   int // Direction of the axis (1 or -1, 0 for the buttons and keys)
+}
+
+// Names of the keys in the config file
+var keyNames = map[string]glfw.Key{
+	"Space": glfw.KeySpace, "Escape": glfw.KeyEscape, "Enter": glfw.KeyEnter, "Tab": glfw.KeyTab,
+	"Backspace": glfw.KeyBackspace, "Insert": glfw.KeyInsert, "Delete": glfw.KeyDelete,
+	"Right": glfw.KeyRight, "Left": glfw.KeyLeft, "Down": glfw.KeyDown, "Up": glfw.KeyUp,
+	"PageUp": glfw.KeyPageUp, "PageDown": glfw.KeyPageDown, "Home": glfw.KeyHome, "End": glfw.KeyEnd,
+	"LeftShift": glfw.KeyLeftShift, "RightShift": glfw.KeyRightShift,
+	"LeftControl": glfw.KeyLeftControl, "RightControl": glfw.KeyRightControl, "LeftAlt": glfw.KeyLeftAlt,
+	"Minus": glfw.KeyMinus, "Equal": glfw.KeyEqual, "Comma": glfw.KeyComma, "Period": glfw.KeyPeriod,
+	"Slash": glfw.KeySlash, "Semicolon": glfw.KeySemicolon, "Apostrophe": glfw.KeyApostrophe,
+	"LeftBracket": glfw.KeyLeftBracket, "RightBracket": glfw.KeyRightBracket,
+	"Backslash": glfw.KeyBackslash, "GraveAccent": glfw.KeyGraveAccent,
+}
+
+// Names of the mouse buttons in the config file
+var mouseNames = map[string]glfw.MouseButton{
+	"Left": glfw.MouseButtonLeft, "Right": glfw.MouseButtonRight, "Middle": glfw.MouseButtonMiddle,
+}
+
+func init() {
+	// Letters, digits and function keys
+	for letter := glfw.KeyA; letter <= glfw.KeyZ; letter++ {
+		keyNames[string(rune('A' + int(letter - glfw.KeyA)))] = letter
+	}
+	for _, digit := range []glfw.Key{ glfw.Key0, glfw.Key1, glfw.Key2, glfw.Key3, glfw.Key4, glfw.Key5, glfw.Key6, glfw.Key7, glfw.Key8, glfw.Key9 } {
+		keyNames[strconv.Itoa(int(digit - glfw.Key0))] = digit
+	}
+	for function := glfw.KeyF1; function <= glfw.KeyF12; function++ {
+		keyNames[fmt.Sprintf("F%d", int(function - glfw.KeyF1) + 1)] = function
+	}
+}
+
+//
+// KeyBinding
+// Creates the binding of a key
+//
+// @param key (glfw.Key) the key
+//
+// @return binding (Binding) the binding
+//
+func KeyBinding(key glfw.Key) Binding {
+	return Binding{ DEVICE_KEY, int(key), 0 }
+}
+
+//
+// MouseBinding
+// Creates the binding of a mouse button
+//
+// @param button (glfw.MouseButton) the button
+//
+// @return binding (Binding) the binding
+//
+func MouseBinding(button glfw.MouseButton) Binding {
+	return Binding{ DEVICE_MOUSE, int(button), 0 }
+}
+
+//
+// GamepadButtonBinding
+// Creates the binding of a gamepad button
+//
+// @param button (int) the index of the button
+//
+// @return binding (Binding) the binding
+//
+func GamepadButtonBinding(button int) Binding {
+	return Binding{ DEVICE_GAMEPAD_BUTTON, button, 0 }
+}
+
+//
+// GamepadAxisBinding
+// Creates the binding of one direction of a gamepad axis
+//
+// @param axis (int) the index of the axis
+// @param sign (int) the direction (1 or -1)
+//
+// @return binding (Binding) the binding
+//
+func GamepadAxisBinding(axis, sign int) Binding {
+	if sign < 0 {
+		return Binding{ DEVICE_GAMEPAD_AXIS, axis, -1 }
+	}
+
+	return Binding{ DEVICE_GAMEPAD_AXIS, axis, 1 }
+}
+
+//
+// ParseBinding
+// Reads a binding from the config file format: "key:W", "mouse:Right", "gamepad-button:0" or "gamepad-axis:1-"
+//
+// @param text (string) the binding
+//
+// @return binding (Binding) the binding
+// @return error (error) the error (if the binding is not valid)
+//
+func ParseBinding(text string) (Binding, error) {
+	parts := strings.SplitN(text, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return Binding{}, fmt.Errorf("%q should look like device:name (Example: key:W)", text)
+	}
+	prefix, name := parts[0], parts[1]
+
+	switch prefix {
+	case DEVICE_KEY.String():
+		if key, ok := keyNames[name]; ok {
+			return KeyBinding(key), nil
+		}
+		return Binding{}, fmt.Errorf("%q is not a known key", name)
+
+	case DEVICE_MOUSE.String():
+		if button, ok := mouseNames[name]; ok {
+			return MouseBinding(button), nil
+		}
+		return Binding{}, fmt.Errorf("%q is not a mouse button (Left, Right or Middle)", name)
+
+	case DEVICE_GAMEPAD_BUTTON.String():
+		button, err := strconv.Atoi(name)
+		if err != nil || button < 0 {
+			return Binding{}, fmt.Errorf("%q is not a gamepad button number", name)
+		}
+		return GamepadButtonBinding(button), nil
+
+	case DEVICE_GAMEPAD_AXIS.String():
+		sign := 1
+		if strings.HasSuffix(name, "-") {
+			sign = -1
+		} else if !strings.HasSuffix(name, "+") {
+			return Binding{}, fmt.Errorf("%q needs a direction (Example: 1+ or 1-)", name)
+		}
+		axis, err := strconv.Atoi(name[:len(name) - 1])
+		if err != nil || axis < 0 {
+			return Binding{}, fmt.Errorf("%q is not a gamepad axis number", name)
+		}
+		return GamepadAxisBinding(axis, sign), nil
+	}
+
+	return Binding{}, fmt.Errorf("%q is not a device (%s)", prefix, strings.Join(devicePrefixes, ", "))
+}
+
+//
+// String
+// Returns the binding in the config file format
+//
+func (binding Binding) String() string {
+	switch binding.Device {
+	case DEVICE_KEY:
+		return DEVICE_KEY.String() + ":" + nameOf(keyNames, binding.Code)
+	case DEVICE_MOUSE:
+		for name, button := range mouseNames {
+			if int(button) == binding.Code {
+				return DEVICE_MOUSE.String() + ":" + name
+			}
+		}
+		return fmt.Sprintf("%s:%d", DEVICE_MOUSE, binding.Code)
+	case DEVICE_GAMEPAD_AXIS:
+		if binding.Sign < 0 {
+			return fmt.Sprintf("%s:%d-", binding.Device, binding.Code)
+		}
+		return fmt.Sprintf("%s:%d+", binding.Device, binding.Code)
+	}
+
+	return fmt.Sprintf("%s:%d", binding.Device, binding.Code)
+}
+
+//
+// Label
+// Returns the binding as it's shown in the help
+//
+func (binding Binding) Label() string {
+	switch binding.Device {
+	case DEVICE_KEY:
+		return nameOf(keyNames, binding.Code)
+	case DEVICE_MOUSE:
+		return strings.TrimPrefix(binding.String(), DEVICE_MOUSE.String() + ":") + " Mouse Button"
+	case DEVICE_GAMEPAD_BUTTON:
+		return fmt.Sprintf("Gamepad Button %d", binding.Code)
+	case DEVICE_GAMEPAD_AXIS:
+		if binding.Sign < 0 {
+			return fmt.Sprintf("Gamepad Axis %d -", binding.Code)
+		}
+		return fmt.Sprintf("Gamepad Axis %d +", binding.Code)
+	}
+
+	return binding.String()
+}
+
+// nameOf finds the name of a key (or the key code if it has no name)
+func nameOf(names map[string]glfw.Key, code int) string {
+	for name, key := range names {
+		if int(key) == code {
+			return name
+		}
+	}
+
+	return strconv.Itoa(code)
+}
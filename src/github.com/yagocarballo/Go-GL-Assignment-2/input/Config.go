@@ -0,0 +1,132 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+//
+// Config
+// The contents of a bindings file: the inputs of every action
+// (Example: { "bindings": { "move-forward": [ "key:E", "gamepad-axis:1-" ] } })
+//
+type Config struct {
+	Bindings map[string][]string `json:"bindings"`
+}
+
+//
+// ConfigErrors
+// Every problem found in a bindings file (one per line)
+//
+type ConfigErrors []string
+
+func (errs ConfigErrors) Error() string {
+	return strings.Join(errs, "\n")
+}
+
+//
+// LoadBindings
+// Reads a bindings file and replaces the bindings of the action map
+//
+// @param path (string) the path to the bindings file
+//
+// @return error (error) the error (a ConfigErrors list if the file is not valid, the bindings don't change then)
+//
+func (actionMap *ActionMap) LoadBindings(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return actionMap.ParseBindings(data)
+}
+
+//
+// ParseBindings
+// Decodes a bindings file and replaces the bindings of the action map.
+// The actions missing from the file stay unbound
+//
+// @param data ([]byte) the JSON document
+//
+// @return error (error) the error (a ConfigErrors list if the document is not valid, the bindings don't change then)
+//
+func (actionMap *ActionMap) ParseBindings(data []byte) error {
+	config := Config{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ConfigErrors{ err.Error() }
+	}
+
+	errs := ConfigErrors{}
+
+	// Unknown actions (sorted, so the errors are always in the same order)
+	unknown := []string{}
+	for name := range config.Bindings {
+		if actionMap.Action(name) == nil {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errs = append(errs, fmt.Sprintf("bindings.%s: there is no action called %q", name, name))
+	}
+
+	// Reads the bindings in the order of the actions, and checks that they are only used once
+	type pending struct {
+		action  string
+		binding Binding
+	}
+	bindings := []pending{}
+	owners := map[Binding]string{}
+	for _, action := range actionMap.Actions() {
+		for index, text := range config.Bindings[action.Name] {
+			path := fmt.Sprintf("bindings.%s[%d]", action.Name, index)
+
+			binding, err := ParseBinding(text)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", path, err))
+				continue
+			}
+
+			if owner, ok := owners[binding]; ok && owner != action.Name {
+				errs = append(errs, fmt.Sprintf("%s: %s is already bound to %q", path, binding, owner))
+				continue
+			}
+
+			owners[binding] = action.Name
+			bindings = append(bindings, pending{ action.Name, binding })
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	actionMap.UnbindAll()
+	for _, binding := range bindings {
+		actionMap.Bind(binding.action, binding.binding)
+	}
+
+	return nil
+}
+
+//
+// Config
+// Returns the current bindings in the config file format
+//
+// @return config (Config) the bindings
+//
+func (actionMap *ActionMap) Config() Config {
+	config := Config{ map[string][]string{} }
+	for _, action := range actionMap.Actions() {
+		texts := []string{}
+		for _, binding := range action.Bindings {
+			texts = append(texts, binding.String())
+		}
+		config.Bindings[action.Name] = texts
+	}
+
+	return config
+}
@@ -0,0 +1,119 @@
+package input
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+func TestParseBinding(t *testing.T) {
+	cases := []struct {
+		text    string
+		binding Binding
+		label   string
+	}{
+		{ "key:W", KeyBinding(glfw.KeyW), "W" },
+		{ "key:F12", KeyBinding(glfw.KeyF12), "F12" },
+		{ "key:0", KeyBinding(glfw.Key0), "0" },
+		{ "key:Space", KeyBinding(glfw.KeySpace), "Space" },
+		{ "mouse:Right", MouseBinding(glfw.MouseButtonRight), "Right Mouse Button" },
+		{ "gamepad-button:6", GamepadButtonBinding(6), "Gamepad Button 6" },
+		{ "gamepad-axis:1-", GamepadAxisBinding(1, -1), "Gamepad Axis 1 -" },
+		{ "gamepad-axis:2+", GamepadAxisBinding(2, 1), "Gamepad Axis 2 +" },
+	}
+
+	for _, c := range cases {
+		binding, err := ParseBinding(c.text)
+		if err != nil || binding != c.binding {
+			t.Errorf("%s: expected %v, found %v (%v)", c.text, c.binding, binding, err)
+			continue
+		}
+		if binding.String() != c.text {
+			t.Errorf("%s: written back as %s", c.text, binding)
+		}
+		if binding.Label() != c.label {
+			t.Errorf("%s: expected the label %q, found %q", c.text, c.label, binding.Label())
+		}
+	}
+
+	for _, text := range []string{ "W", "key:", "key:Hyper", "mouse:Side", "gamepad-button:-1", "gamepad-axis:1", "gamepad-axis:x+", "joystick:1" } {
+		if _, err := ParseBinding(text); err == nil {
+			t.Errorf("%s: expected an error", text)
+		}
+	}
+}
+
+func TestParseBindings(t *testing.T) {
+	actionMap := testActions(t)
+	err := actionMap.ParseBindings([]byte(`{ "bindings": {
+		"quit": ["key:Q"],
+		"jump": ["key:Space", "gamepad-button:3"],
+		"move-left": ["gamepad-axis:0-"]
+	} }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file replaces every binding, the missing actions stay unbound
+	if event, ok := actionMap.Key(glfw.KeyQ, glfw.Press); !ok || event.Action != "quit" {
+		t.Errorf("expected Q to quit, found %v (%v)", event, ok)
+	}
+	if _, ok := actionMap.Key(glfw.KeyEscape, glfw.Press); ok {
+		t.Error("the old binding of quit is still there")
+	}
+	if event, ok := actionMap.GamepadButton(3, glfw.Press); !ok || event.Action != "jump" {
+		t.Errorf("expected the gamepad button 3 to jump, found %v (%v)", event, ok)
+	}
+	if bindings := actionMap.Action("move-right").Bindings; len(bindings) != 0 {
+		t.Errorf("move-right is not in the file, found %v", bindings)
+	}
+
+	// Config writes the bindings back in the same format
+	expected := map[string][]string{
+		"quit": { "key:Q" }, "jump": { "key:Space", "gamepad-button:3" }, "move-left": { "gamepad-axis:0-" },
+		"move-right": {}, "look": {},
+	}
+	if config := actionMap.Config(); !reflect.DeepEqual(config.Bindings, expected) {
+		t.Errorf("expected %v, found %v", expected, config.Bindings)
+	}
+}
+
+func TestParseBindingsErrors(t *testing.T) {
+	actionMap := testActions(t)
+	err := actionMap.ParseBindings([]byte(`{ "bindings": {
+		"quit": ["key:Space"],
+		"jump": ["key:Space", "key:Hyper"],
+		"zoom": ["key:Z"],
+		"fly": ["key:F"]
+	} }`))
+
+	errs, ok := err.(ConfigErrors)
+	if !ok {
+		t.Fatalf("expected a ConfigErrors list, found %v", err)
+	}
+
+	// The unknown actions come first (sorted), then the bindings in the order of the actions
+	expected := []string{ "bindings.fly:", "bindings.zoom:", "bindings.jump[0]: key:Space is already bound to \"quit\"", "bindings.jump[1]:" }
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, found:\n%v", len(expected), errs)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(errs[i], prefix) {
+			t.Errorf("error %d: expected %q, found %q", i, prefix, errs[i])
+		}
+	}
+
+	// Nothing changes when the file is not valid
+	if event, ok := actionMap.Key(glfw.KeyEscape, glfw.Press); !ok || event.Action != "quit" {
+		t.Error("the bindings changed with an invalid file")
+	}
+
+	if err := actionMap.ParseBindings([]byte(`{ "bindings": [] }`)); err == nil {
+		t.Error("expected an error with the wrong JSON")
+	}
+	if err := actionMap.LoadBindings("missing.json"); err == nil {
+		t.Error("expected an error with a missing file")
+	}
+}
@@ -62,7 +62,7 @@ func (loader *Loader) LoadMTL(filename string) (data []*MtlData, err error) {
 
 		// Read the file and return content or error
 		var secondErr error
-		file, secondErr = os.Open(fmt.Sprintf("%s/%s", dir, file))
+		file, secondErr = os.Open(fmt.Sprintf("%s/%s", dir, filename))
 		if secondErr != nil {
 			log.Println(secondErr)
 			return materials, fmt.Errorf("could not open %s %s", filename, secondErr)
@@ -31,22 +31,25 @@ type ObjectData struct {
 	VertexBufferObjectFaces    		uint32     // Vertex Buffer Object (Faces)
 	VertexBufferObjectTextureCoords	uint32     // Texture Coordinates Buffer Object (Texture Coordinates)
 
-	Model                      mgl32.Mat4 // Transformation Info
+	Model                      mgl32.Mat4 // Transformation Info (a copy of the world matrix of its scene node, updated when it's drawn)
 	Material                   *MtlData   // Material Info
 }
 
 type Loader struct {
 	Materials map[string]*MtlData
+	Device    wrapper.Device // Uploads the textures of the materials
 }
 
 //
 // NewLoader
 // Constructor, Creates a new Loader
 //
+// @param device (wrapper.Device) the device that uploads the textures
+//
 // @return loader (*Loader) a pointer to the new Loader.
 //
-func NewLoader () *Loader {
-	return &Loader{ map[string]*MtlData{} }
+func NewLoader (device wrapper.Device) *Loader {
+	return &Loader{ map[string]*MtlData{}, device }
 }
 
 // objStrings is an intermediate data structure used in parsing.
@@ -103,7 +106,7 @@ func (loader *Loader) Load (filename string) (objectsData []*ObjectData, err err
 
 		// Read the file and return content or error
 		var secondErr error
-		file, secondErr = os.Open(fmt.Sprintf("%s/%s", dir, file))
+		file, secondErr = os.Open(fmt.Sprintf("%s/%s", dir, filename))
 		if secondErr != nil {
 			log.Println(secondErr)
 			return objectsData, fmt.Errorf("could not open %s %s", filename, secondErr)
@@ -42,24 +42,58 @@ func (loader *Loader) LoadTexture(file string) (uint32, error) {
 	}
 	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
 
-	var texture uint32
-	gl.GenTextures(1, &texture)
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, texture)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-	gl.TexImage2D(
+	texture := loader.Device.GenTexture()
+	loader.Device.ActiveTexture(gl.TEXTURE0)
+	loader.Device.BindTexture(gl.TEXTURE_2D, texture)
+	loader.Device.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	loader.Device.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	loader.Device.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	loader.Device.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	loader.Device.TexImage2D(
 		gl.TEXTURE_2D,
 		0,
 		gl.RGBA,
 		int32(rgba.Rect.Size().X),
 		int32(rgba.Rect.Size().Y),
-		0,
 		gl.RGBA,
 		gl.UNSIGNED_BYTE,
-		gl.Ptr(rgba.Pix))
+		rgba.Pix)
 
 	return texture, nil
-}
\ No newline at end of file
+}
+//
+// TextureMaterial
+// Creates a white material with a texture (for the shapes that don't come with a .mtl file).
+// The texture is also used as the normal and specular maps, so it only looks right with the textureMaterial shader
+//
+// @param name (string) the name of the material
+// @param file (string) the path to the image
+//
+// @return material (*MtlData) the material
+// @return error (error) the error loading the texture (if any)
+//
+func (loader *Loader) TextureMaterial(name, file string) (*MtlData, error) {
+	texture, err := loader.LoadTexture(file)
+	if err != nil {
+		return nil, err
+	}
+
+	material := &MtlData{
+		name,				// Name
+		0.2, 0.2, 0.2,		// Ambient colour
+		1.0, 1.0, 1.0,		// Diffuse colour
+		0.5, 0.5, 0.5,		// Specular colour
+		0.0, 0.0, 0.0,		// Emissive colour
+		1.0,				// Transparency
+		1.0,				// Optical Density
+		1,					// Illumination model
+		file,				// Map Texture
+		"",					// Map Specular
+		"",					// Map Normals
+		texture,			// Texture
+		texture,			// Normal Map
+		texture,			// Specular Map
+	}
+
+	return material, nil
+}
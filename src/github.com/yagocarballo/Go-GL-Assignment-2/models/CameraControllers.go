@@ -0,0 +1,331 @@
+package models
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Furthest the controllers look up or down (just under 90 degrees, so the camera can't flip)
+const PITCH_LIMIT float32 = 89.0 * math.Pi / 180.0
+
+//
+// cameraInput
+// Collects the input events of a controller until the next Update
+// (the controllers embed it, so they get the event functions of CameraController)
+//
+type cameraInput struct {
+	held   map[mgl32.Vec3]bool // Directions that are being pressed
+	analog mgl32.Vec3          // Movement from the gamepad (until the next Steer)
+	look   mgl32.Vec2          // Cursor movement since the last update
+	zoom   float32             // Scroll steps since the last update
+}
+
+func newCameraInput() cameraInput {
+	return cameraInput{ map[mgl32.Vec3]bool{}, mgl32.Vec3{}, mgl32.Vec2{}, 0 }
+}
+
+// Move starts (pressed) or stops moving along an axis of the camera (x right, y up, z back)
+func (input *cameraInput) Move(direction mgl32.Vec3, pressed bool) {
+	if pressed {
+		input.held[direction] = true
+	} else {
+		delete(input.held, direction)
+	}
+}
+
+// Steer sets the analog movement along the axes of the camera (-1 to 1 on every axis)
+func (input *cameraInput) Steer(direction mgl32.Vec3) {
+	input.analog = direction
+}
+
+// Look turns the camera by a cursor movement in pixels
+func (input *cameraInput) Look(dx, dy float32) {
+	input.look = input.look.Add(mgl32.Vec2{ dx, dy })
+}
+
+// Zoom moves the camera in (positive) or out (negative), in scroll steps
+func (input *cameraInput) Zoom(amount float32) {
+	input.zoom += amount
+}
+
+// Stop drops the pending input
+func (input *cameraInput) Stop() {
+	input.held = map[mgl32.Vec3]bool{}
+	input.analog = mgl32.Vec3{}
+	input.look = mgl32.Vec2{}
+	input.zoom = 0
+}
+
+// movement returns the direction of the held keys and the gamepad (never longer than 1, so diagonals aren't faster)
+func (input *cameraInput) movement() mgl32.Vec3 {
+	direction := input.analog
+	for held := range input.held {
+		direction = direction.Add(held)
+	}
+
+	if direction.Len() > 1 {
+		return direction.Normalize()
+	}
+
+	return direction
+}
+
+// take returns the look and zoom since the last update and clears them
+func (input *cameraInput) take() (mgl32.Vec2, float32) {
+	look, zoom := input.look, input.zoom
+	input.look, input.zoom = mgl32.Vec2{}, 0
+
+	return look, zoom
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////// Fly Camera ////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// FlyController
+// Free camera: the keys move along the axes of the camera, the cursor turns it
+// around the up of the scene (yaw) and its own x axis (pitch), and the zoom changes the field of view
+//
+type FlyController struct {
+	cameraInput
+
+	Speed          float32 // Units per second
+	Sensitivity    float32 // Radians per pixel
+	ZoomStep       float32 // Degrees of field of view per scroll step
+	MinFieldOfView float32 // Degrees
+	MaxFieldOfView float32 // Degrees
+}
+
+//
+// NewFlyController
+// Creates a fly controller with the default speeds
+//
+// @return controller (*FlyController) a pointer to the controller
+//
+func NewFlyController() *FlyController {
+	return &FlyController{
+		newCameraInput(),
+		10.0,   // Speed
+		0.005,  // Sensitivity
+		2.0,    // ZoomStep
+		10.0,   // MinFieldOfView
+		120.0,  // MaxFieldOfView
+	}
+}
+
+//
+// Update
+// Turns and moves the camera with the input since the last update
+//
+// @param camera (*Camera) the camera
+// @param delta (float32) the elapsed time in seconds
+//
+func (fly *FlyController) Update(camera *Camera, delta float32) {
+	look, zoom := fly.take()
+	transform := camera.Node.GetTransform()
+
+	// Yaw around the up of the scene and pitch around the camera, so the camera never rolls
+	pitch := limitPitch(transform.Forward(), camera.WorldUp, -look.Y() * fly.Sensitivity)
+	transform.RotateWorldRadians(-look.X() * fly.Sensitivity, camera.WorldUp)
+	transform.RotateLocalRadians(pitch, mgl32.Vec3{ 1, 0, 0 })
+
+	// The movement is along the axes of the camera
+	movement := transform.Orientation.Rotate(fly.movement())
+	transform.TranslateWorld(movement.Mul(fly.Speed * delta))
+
+	camera.Node.SetTransform(transform)
+
+	if zoom != 0 {
+		camera.FieldOfView = mgl32.Clamp(camera.FieldOfView - zoom * fly.ZoomStep, fly.MinFieldOfView, fly.MaxFieldOfView)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+//////////////////////////////////// Orbit Camera ///////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// OrbitController
+// Turns the camera around a target: the cursor moves the camera around it, the zoom changes the distance,
+// the left / right and up / down keys move the target and the back / front keys move away or closer
+//
+type OrbitController struct {
+	cameraInput
+
+	Target      mgl32.Vec3
+	Distance    float32
+	MinDistance float32
+	MaxDistance float32
+
+	Speed       float32 // Units per second (for the keys)
+	Sensitivity float32 // Radians per pixel
+	ZoomFactor  float32 // Distance multiplier per scroll step in
+}
+
+//
+// NewOrbitController
+// Creates an orbit controller around a target, starting at the current distance of the camera
+//
+// @param camera (*Camera) the camera
+// @param target (mgl32.Vec3) the point to orbit
+//
+// @return controller (*OrbitController) a pointer to the controller
+//
+func NewOrbitController(camera *Camera, target mgl32.Vec3) *OrbitController {
+	orbit := &OrbitController{
+		newCameraInput(),
+		target,                                 // Target
+		camera.Position().Sub(target).Len(),    // Distance
+		1.0,                                    // MinDistance
+		200.0,                                  // MaxDistance
+		10.0,                                   // Speed
+		0.005,                                  // Sensitivity
+		0.9,                                    // ZoomFactor
+	}
+	orbit.Distance = mgl32.Clamp(orbit.Distance, orbit.MinDistance, orbit.MaxDistance)
+
+	return orbit
+}
+
+//
+// Update
+// Moves the camera around the target with the input since the last update
+//
+// @param camera (*Camera) the camera
+// @param delta (float32) the elapsed time in seconds
+//
+func (orbit *OrbitController) Update(camera *Camera, delta float32) {
+	look, zoom := orbit.take()
+	up := camera.WorldUp
+
+	// Pans the target on the plane of the screen, and moves along the view
+	movement := orbit.movement()
+	orbit.Target = orbit.Target.
+		Add(camera.Right().Mul(movement.X() * orbit.Speed * delta)).
+		Add(camera.Up().Mul(movement.Y() * orbit.Speed * delta))
+	orbit.Distance += movement.Z() * orbit.Speed * delta
+
+	// Every scroll step in gets closer by the same factor
+	orbit.Distance *= float32(math.Pow(float64(orbit.ZoomFactor), float64(zoom)))
+	orbit.Distance = mgl32.Clamp(orbit.Distance, orbit.MinDistance, orbit.MaxDistance)
+
+	// Direction from the target to the camera, split in heading (around the up) and elevation
+	direction := camera.Forward().Mul(-1)
+	heading := horizontal(direction, up, camera.Up().Mul(-1))
+	heading = mgl32.QuatRotate(-look.X() * orbit.Sensitivity, up).Rotate(heading)
+
+	// Moving the cursor down lifts the camera (it looks down at the target)
+	elevation := float32(math.Asin(float64(mgl32.Clamp(direction.Dot(up), -1, 1))))
+	elevation = mgl32.Clamp(elevation + look.Y() * orbit.Sensitivity, -PITCH_LIMIT, PITCH_LIMIT)
+
+	direction = heading.Mul(float32(math.Cos(float64(elevation)))).Add(up.Mul(float32(math.Sin(float64(elevation)))))
+	camera.LookAt(orbit.Target.Add(direction.Mul(orbit.Distance)), orbit.Target, up)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+/////////////////////////////////// Follow Camera ///////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// FollowController
+// Third person camera: stays behind the -z axis of a model and above it, and catches up smoothly.
+// The cursor turns the camera around the model and the zoom changes the distance
+//
+type FollowController struct {
+	cameraInput
+
+	Target      Model
+	Distance    float32 // Distance behind the model
+	Height      float32 // Distance above the model (along the up of the scene)
+	Heading     float32 // Extra turn around the model in radians (from the cursor)
+	Stiffness   float32 // How fast it catches up, per second (0 or less snaps to the position)
+
+	Sensitivity float32 // Radians per pixel
+	ZoomFactor  float32 // Distance multiplier per scroll step in
+}
+
+//
+// NewFollowController
+// Creates a follow controller for a model
+//
+// @param target (Model) the model to follow
+//
+// @return controller (*FollowController) a pointer to the controller
+//
+func NewFollowController(target Model) *FollowController {
+	return &FollowController{
+		newCameraInput(),
+		target, // Target
+		10.0,   // Distance
+		4.0,    // Height
+		0.0,    // Heading
+		5.0,    // Stiffness
+		0.005,  // Sensitivity
+		0.9,    // ZoomFactor
+	}
+}
+
+//
+// Update
+// Moves the camera towards its place behind the model and looks at it
+//
+// @param camera (*Camera) the camera
+// @param delta (float32) the elapsed time in seconds
+//
+func (follow *FollowController) Update(camera *Camera, delta float32) {
+	look, zoom := follow.take()
+	if follow.Target == nil {
+		return
+	}
+
+	up := camera.WorldUp
+	follow.Heading -= look.X() * follow.Sensitivity
+	follow.Distance *= float32(math.Pow(float64(follow.ZoomFactor), float64(zoom)))
+
+	// Behind the model, ignoring if it looks up or down
+	world := follow.Target.GetNode().World()
+	target := world.Col(3).Vec3()
+	back := horizontal(world.Mul4x1(mgl32.Vec4{ 0, 0, 1, 0 }).Vec3(), up, camera.Forward().Mul(-1))
+	back = mgl32.QuatRotate(follow.Heading, up).Rotate(back)
+	desired := target.Add(back.Mul(follow.Distance)).Add(up.Mul(follow.Height))
+
+	// Exponential smoothing, so the result doesn't depend on the frame rate
+	amount := float32(1)
+	if follow.Stiffness > 0 {
+		amount = 1 - float32(math.Exp(float64(-follow.Stiffness * delta)))
+	}
+	position := camera.Position()
+	position = position.Add(desired.Sub(position).Mul(amount))
+
+	camera.LookAt(position, target, up)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////// Helpers //////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+// limitPitch shortens a pitch so the forward stays within PITCH_LIMIT of the horizon
+func limitPitch(forward, up mgl32.Vec3, pitch float32) float32 {
+	current := float32(math.Asin(float64(mgl32.Clamp(forward.Normalize().Dot(up), -1, 1))))
+	return mgl32.Clamp(current + pitch, -PITCH_LIMIT, PITCH_LIMIT) - current
+}
+
+// horizontal removes the up part of a direction (it uses the fallback if the direction is straight up or down)
+func horizontal(direction, up, fallback mgl32.Vec3) mgl32.Vec3 {
+	for _, candidate := range []mgl32.Vec3{ direction, fallback } {
+		flat := candidate.Sub(up.Mul(candidate.Dot(up)))
+		if flat.Len() > 1e-4 {
+			return flat.Normalize()
+		}
+	}
+
+	// Any direction perpendicular to the up
+	flat := up.Cross(mgl32.Vec3{ 1, 0, 0 })
+	if flat.Len() < 1e-4 {
+		flat = up.Cross(mgl32.Vec3{ 0, 0, 1 })
+	}
+
+	return flat.Normalize()
+}
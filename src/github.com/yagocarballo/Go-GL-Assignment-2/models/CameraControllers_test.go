@@ -0,0 +1,163 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// elevation returns the angle between a direction and the horizon (radians)
+func elevation(direction, up mgl32.Vec3) float32 {
+	return float32(math.Asin(float64(mgl32.Clamp(direction.Normalize().Dot(up), -1, 1))))
+}
+
+func TestLimitPitch(t *testing.T) {
+	up := mgl32.Vec3{ 0, 1, 0 }
+	cases := []struct {
+		forward         mgl32.Vec3
+		pitch, expected float32
+	}{
+		{ mgl32.Vec3{ 0, 0, -1 }, 0.5, 0.5 },                         // Within the limit
+		{ mgl32.Vec3{ 0, 0, -1 }, 2, PITCH_LIMIT },                   // Up to the limit
+		{ mgl32.Vec3{ 0, 0, -1 }, -3, -PITCH_LIMIT },                 // Down to the limit
+		{ mgl32.Vec3{ 0, 1, -1 }, 1, PITCH_LIMIT - math.Pi / 4 },     // Already looking up
+		{ mgl32.Vec3{ 0, -1, -1 }, 0.25, 0.25 },                      // Looking down, back up
+		{ mgl32.Vec3{ 0, 1, 0 }, 0.1, PITCH_LIMIT - math.Pi / 2 },    // Straight up goes back to the limit
+	}
+
+	for _, c := range cases {
+		if pitch := limitPitch(c.forward, up, c.pitch); mgl32.Abs(pitch - c.expected) > 1e-5 {
+			t.Errorf("forward %v, pitch %f: expected %f, found %f", c.forward, c.pitch, c.expected, pitch)
+		}
+	}
+
+	// The up of the scene can be any direction
+	if pitch := limitPitch(mgl32.Vec3{ 0, 0, -1 }, mgl32.Vec3{ 0, -1, 0 }, 2); mgl32.Abs(pitch - PITCH_LIMIT) > 1e-5 {
+		t.Errorf("expected %f with the up along -y, found %f", PITCH_LIMIT, pitch)
+	}
+}
+
+func TestFlyControllerNeverRolls(t *testing.T) {
+	camera := NewCamera("View", mgl32.Vec3{ 0, 5, 10 }, mgl32.Vec3{}, mgl32.Vec3{ 0, 1, 0 })
+	fly := NewFlyController()
+	camera.SetController(fly)
+
+	// Far more cursor movement up than the limit
+	for i := 0; i < 20; i++ {
+		fly.Look(37, -200)
+		camera.Update(0.016)
+	}
+
+	if angle := elevation(camera.Forward(), camera.WorldUp); angle > PITCH_LIMIT + 1e-4 {
+		t.Errorf("the camera looks %f radians up, over the limit", angle)
+	}
+	if roll := camera.Right().Dot(camera.WorldUp); mgl32.Abs(roll) > 1e-4 {
+		t.Errorf("the camera rolled (the right goes %f along the up)", roll)
+	}
+
+	// The keys move along the axes of the camera at its speed
+	start := camera.Position()
+	fly.Move(mgl32.Vec3{ 0, 0, -1 }, true)
+	camera.Update(0.5)
+	if moved := camera.Position().Sub(start); !moved.ApproxEqualThreshold(camera.Forward().Mul(fly.Speed * 0.5), 1e-3) {
+		t.Errorf("expected to move %v, moved %v", camera.Forward().Mul(fly.Speed * 0.5), moved)
+	}
+
+	// The zoom changes the field of view within its limits
+	fly.Zoom(100)
+	camera.Update(0)
+	if camera.FieldOfView != fly.MinFieldOfView {
+		t.Errorf("expected the field of view to stop at %f, found %f", fly.MinFieldOfView, camera.FieldOfView)
+	}
+}
+
+func TestOrbitControllerDistance(t *testing.T) {
+	target := mgl32.Vec3{ 1, 2, 3 }
+
+	// The distance starts as the distance of the camera, within the limits
+	camera := NewCamera("View", mgl32.Vec3{ 1, 2, 503 }, target, mgl32.Vec3{ 0, 1, 0 })
+	orbit := NewOrbitController(camera, target)
+	if orbit.Distance != orbit.MaxDistance {
+		t.Errorf("expected the distance to start at %f, found %f", orbit.MaxDistance, orbit.Distance)
+	}
+
+	camera = NewCamera("View", mgl32.Vec3{ 1, 12, 13 }, target, mgl32.Vec3{ 0, 1, 0 })
+	orbit = NewOrbitController(camera, target)
+	start := orbit.Distance
+
+	// Every scroll step gets closer by the zoom factor
+	orbit.Zoom(2)
+	camera.SetController(orbit)
+	camera.Update(0)
+	if expected := start * orbit.ZoomFactor * orbit.ZoomFactor; mgl32.Abs(orbit.Distance - expected) > 1e-3 {
+		t.Errorf("expected the distance %f, found %f", expected, orbit.Distance)
+	}
+	if distance := camera.Position().Sub(target).Len(); mgl32.Abs(distance - orbit.Distance) > 1e-3 {
+		t.Errorf("the camera is %f away from the target, expected %f", distance, orbit.Distance)
+	}
+	if forward := camera.Forward(); !forward.ApproxEqualThreshold(target.Sub(camera.Position()).Normalize(), 1e-4) {
+		t.Errorf("the camera doesn't look at the target: %v", forward)
+	}
+
+	// It can't get closer than the minimum
+	orbit.Zoom(200)
+	camera.Update(0)
+	if orbit.Distance != orbit.MinDistance {
+		t.Errorf("expected the distance to stop at %f, found %f", orbit.MinDistance, orbit.Distance)
+	}
+
+	// Turning around keeps the distance and the elevation under the limit
+	orbit.Look(500, 5000)
+	camera.Update(0)
+	if distance := camera.Position().Sub(target).Len(); mgl32.Abs(distance - orbit.MinDistance) > 1e-3 {
+		t.Errorf("the camera moved to %f from the target", distance)
+	}
+	if angle := elevation(camera.Position().Sub(target), camera.WorldUp); angle > PITCH_LIMIT + 1e-3 {
+		t.Errorf("the camera went over the target (%f radians)", angle)
+	}
+}
+
+func TestFollowControllerSmoothing(t *testing.T) {
+	target := NewTerrain(nil)
+	target.Translate(0, 0, -20)
+
+	// The camera snaps to its place behind and above the model
+	camera := NewCamera("View", mgl32.Vec3{ 0, 0, 10 }, mgl32.Vec3{}, mgl32.Vec3{ 0, 1, 0 })
+	follow := NewFollowController(target)
+	follow.Stiffness = 0
+	camera.SetController(follow)
+	camera.Update(0.016)
+
+	desired := mgl32.Vec3{ 0, follow.Height, -20 + follow.Distance }
+	if position := camera.Position(); !position.ApproxEqualThreshold(desired, 1e-4) {
+		t.Fatalf("expected the camera on %v, found %v", desired, position)
+	}
+
+	// With stiffness it catches up by 1 - e^(-stiffness * delta), whatever the frame rate
+	target.Translate(10, 0, 0)
+	desired = desired.Add(mgl32.Vec3{ 10, 0, 0 })
+	follow.Stiffness = 5
+
+	start := camera.Position()
+	oneStep := NewCamera("One", start, start.Add(mgl32.Vec3{ 0, 0, -1 }), mgl32.Vec3{ 0, 1, 0 })
+	oneStep.SetController(&FollowController{ newCameraInput(), target, follow.Distance, follow.Height, 0, 5, follow.Sensitivity, follow.ZoomFactor })
+	oneStep.Update(0.2)
+
+	expected := start.Add(desired.Sub(start).Mul(1 - float32(math.Exp(-5 * 0.2))))
+	if position := oneStep.Position(); !position.ApproxEqualThreshold(expected, 1e-3) {
+		t.Errorf("expected %v after 0.2 seconds, found %v", expected, position)
+	}
+
+	for i := 0; i < 4; i++ {
+		camera.Update(0.05)
+	}
+	if position := camera.Position(); !position.ApproxEqualThreshold(oneStep.Position(), 1e-3) {
+		t.Errorf("four steps of 0.05 seconds went to %v, one of 0.2 to %v", position, oneStep.Position())
+	}
+
+	// It always looks at the model
+	if forward := camera.Forward(); !forward.ApproxEqualThreshold(mgl32.Vec3{ 10, 0, -20 }.Sub(camera.Position()).Normalize(), 1e-4) {
+		t.Errorf("the camera doesn't look at the model: %v", forward)
+	}
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/collision"
+)
+
+//
+// CollisionShape
+// Creates a heightfield from the vertices of the terrain (it has to be a regular grid, so no skirts).
+// The shape copies the world matrix, so it has to be updated if the terrain moves
+//
+// @return shape (*collision.Heightfield) the collision shape
+//
+func (terrain *Terrain) CollisionShape() *collision.Heightfield {
+	return collision.NewHeightfield(terrain.Vertices, terrain.XSize, terrain.ZSize, terrain.Node.World())
+}
+
+//
+// CollisionMesh
+// Creates a triangle tree from the triangle strips of the terrain (works with any terrain)
+//
+// @return shape (*collision.BVH) the collision shape
+//
+func (terrain *Terrain) CollisionMesh() *collision.BVH {
+	triangles := make([]collision.Triangle, 0, len(terrain.Indices))
+
+	// Same walk along the strips as CalculateNormals
+	position := uint32(0)
+	for x := uint32(0); x + 1 < terrain.XSize; x++ {
+		for tri := uint32(0); tri < terrain.ZSize * 2 - 2; tri++ {
+			triangles = append(triangles, collision.Triangle{
+				A: terrain.Vertices[terrain.Indices[position]],
+				B: terrain.Vertices[terrain.Indices[position + 1]],
+				C: terrain.Vertices[terrain.Indices[position + 2]],
+			})
+			position++
+		}
+		position += 2
+	}
+
+	return collision.NewBVH(triangles, terrain.Node.World())
+}
+
+//
+// CollisionMesh
+// Creates a triangle tree with the faces of every object (in the space of the model node).
+// The shape copies the world matrix of the model, so it has to be updated if the model moves
+//
+// @return shape (*collision.BVH) the collision shape
+//
+func (objectLoader *WavefrontObject) CollisionMesh() *collision.BVH {
+	triangles := []collision.Triangle{}
+
+	for i, object := range objectLoader.Objects {
+		// The objects can have their own transform under the model
+		local := objectLoader.Children[i].Local()
+
+		vertex := func(index uint16) mgl32.Vec3 {
+			i := int(index) * 3
+			return local.Mul4x1(mgl32.Vec4{ object.Vertex[i], object.Vertex[i + 1], object.Vertex[i + 2], 1 }).Vec3()
+		}
+
+		for f := 0; f + 2 < len(object.Faces); f += 3 {
+			triangles = append(triangles, collision.Triangle{
+				A: vertex(object.Faces[f]),
+				B: vertex(object.Faces[f + 1]),
+				C: vertex(object.Faces[f + 2]),
+			})
+		}
+	}
+
+	return collision.NewBVH(triangles, objectLoader.Node.World())
+}
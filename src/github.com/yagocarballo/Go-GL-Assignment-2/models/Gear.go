@@ -0,0 +1,150 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+//
+// Gear
+// An involute gear turning around its y axis. The Node places the gear (it's the one the transforms move)
+// and the Spin node under it turns the teeth, so a gear train can turn the gears without losing where they are
+//
+type Gear struct {
+	Name          string
+
+	Settings      geometry.GearSettings // The shape of the gear
+	Mesh          *geometry.Mesh        // The vertices (nil until MakeGearVBO)
+	Buffers       *MeshBuffers          // The buffers of the mesh
+
+	DrawMode      DrawMode // Defines drawing mode of the gear as points, lines or filled polygons
+
+	Angle         float32    // Rotation of the teeth in radians (from the x axis towards the z axis)
+	Node          *SceneNode // Placement of the gear in the scene graph
+	Spin          *SceneNode // Rotation of the teeth (child of Node)
+
+	ShaderManager *wrapper.ShaderManager // Pointer to the Shader Manager
+	Device        wrapper.Device         // Uploads and draws the buffers
+}
+
+//
+// NewGear
+// Creates a gear (MakeGearVBO builds and uploads it)
+//
+// @param device (wrapper.Device) the device that uploads and draws the buffers
+// @param name (string) the name of the gear
+// @param settings (geometry.GearSettings) the shape of the gear
+// @param shaderManager (*wrapper.ShaderManager) the shaders
+//
+// @return gear (*Gear) a pointer to the gear
+//
+func NewGear(device wrapper.Device, name string, settings geometry.GearSettings, shaderManager *wrapper.ShaderManager) *Gear {
+	gear := &Gear{
+		name,				// Name
+
+		settings,			// Settings
+		nil,				// Mesh
+		nil,				// Buffers
+
+		DRAW_POLYGONS,		// DrawMode
+
+		0,					// Angle
+		NewSceneNode(name),	// Node
+		NewSceneNode(name + " Spin"), // Spin
+
+		shaderManager,		// Pointer to the Shader Manager
+		device,				// Device
+	}
+	gear.Node.AddChild(gear.Spin)
+
+	return gear
+}
+
+// Makes the gear mesh from the settings, paints it from the positions (like the cog) and uploads it
+func (gear *Gear) MakeGearVBO() {
+	gear.Mesh = geometry.Gear(gear.Settings)
+
+	// Define colours as the x,y,z components of the gear vertices (relative to the size of the gear)
+	size := float32(gear.Settings.TipRadius())
+	gear.Mesh.Paint(func(position, normal mgl32.Vec3) mgl32.Vec4 {
+		return mgl32.Vec4{ 0.3 + position.X() / size, 0.5 + position.Y() / size, 0.3 + position.Z() / size, 1.0 }
+	})
+
+	if gear.Buffers != nil {
+		gear.Buffers.Delete()
+	}
+	gear.Buffers = UploadMesh(gear.Device, gear.Mesh)
+}
+
+//
+// SetAngle
+// Turns the teeth to an angle
+//
+// @param angle (float32) the rotation in radians (from the x axis towards the z axis)
+//
+func (gear *Gear) SetAngle(angle float32) {
+	gear.Angle = angle
+
+	// Turning around y takes the x axis away from the z axis, so the angle goes the other way
+	transform := NewTransform()
+	transform.Orientation = mgl32.QuatRotate(-angle, mgl32.Vec3{ 0, 1, 0 })
+	gear.Spin.SetTransform(transform)
+}
+
+// Draws the gear from the previously defined vertex and index buffers
+func (gear *Gear) Draw() {
+	// Adds the Gear Model to the Active Shader
+	model := gear.Spin.World()
+	gear.ShaderManager.SetUniformMatrix4fv(gear.ShaderManager.ActiveShader, "model", 1, false, model[:])
+
+	gear.Buffers.Draw(gear.ShaderManager.CurrentShader(), gear.DrawMode)
+}
+
+func (gear *Gear) ResetModel() {
+	gear.Node.Reset()
+}
+
+func (gear *Gear) Translate(Tx, Ty, Tz float32) {
+	gear.Node.Translate(Tx, Ty, Tz)
+}
+
+func (gear *Gear) Scale(scaleX, scaleY, scaleZ float32) {
+	gear.Node.Scale(scaleX, scaleY, scaleZ)
+}
+
+func (gear *Gear) RotateRadians(radians float32, axis mgl32.Vec3) {
+	gear.Node.RotateRadians(radians, axis)
+}
+
+func (gear *Gear) RotateDegrees(degrees float32, axis mgl32.Vec3) {
+	gear.Node.RotateDegrees(degrees, axis)
+}
+
+func (gear *Gear) GetNode () *SceneNode {
+	return gear.Node
+}
+
+func (gear *Gear) GetDrawMode () DrawMode {
+	return gear.DrawMode
+}
+
+func (gear *Gear) SetDrawMode (drawMode DrawMode) {
+	gear.DrawMode = drawMode
+}
+
+func (gear *Gear) GetName () string {
+	return gear.Name
+}
+
+func (gear *Gear) String () string {
+	return fmt.Sprintf(`
+                  Gear --> %s (%d teeth, module %.2f)
+    -------------------------------------
+    %s
+    -------------------------------------
+    `, gear.Name, gear.Settings.TeethCount(), gear.Settings.Module, gear.Node.World())
+}
@@ -0,0 +1,228 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//
+// GearLink
+// Two gears that turn together: the angle of the child is Offset + Ratio * the angle of the parent
+//
+type GearLink struct {
+	Parent *Gear
+	Child  *Gear
+
+	Ratio  float32 // Turns of the child for every turn of the parent (negative when it turns the other way)
+	Offset float32 // Angle of the child when the parent is at 0, in radians
+}
+
+//
+// MeshGears
+// Places a gear next to another one so their teeth mesh, and links their angles.
+// The child is attached to the placement of the parent (not to its teeth), at the distance of the two
+// pitch radii in the direction given, and turned so its gaps fall on the teeth of the parent.
+// The flanks aren't undercut below the base circle, so a gear with fewer than 17 teeth can dig a little
+// into its mate near the root (about 1% of the module)
+//
+// @param parent (*Gear) the gear that drives
+// @param child (*Gear) the gear that is driven
+// @param direction (float32) the angle of the child around the parent in radians (from the x axis towards the z axis)
+//
+// @return link (GearLink) the link between the two gears
+// @return error (error) the reason the gears can't mesh (if any)
+//
+func MeshGears(parent, child *Gear, direction float32) (GearLink, error) {
+	first, second := parent.Settings, child.Settings
+
+	if first.Module != second.Module {
+		return GearLink{}, fmt.Errorf("%s and %s have different modules (%.3f and %.3f)", parent.Name, child.Name, first.Module, second.Module)
+	}
+	if first.PressureAngle != second.PressureAngle {
+		return GearLink{}, fmt.Errorf("%s and %s have different pressure angles (%.1f and %.1f)", parent.Name, child.Name, first.PressureAngle, second.PressureAngle)
+	}
+	if first.HelixAngle != -second.HelixAngle {
+		return GearLink{}, fmt.Errorf("%s and %s need opposite helix angles (%.1f and %.1f)", parent.Name, child.Name, first.HelixAngle, second.HelixAngle)
+	}
+
+	// The pitch circles touch on the line between the centres
+	distance := float32(first.PitchRadius() + second.PitchRadius())
+	transform := NewTransform()
+	transform.Position = mgl32.Vec3{
+		distance * float32(math.Cos(float64(direction))),
+		0,
+		distance * float32(math.Sin(float64(direction))),
+	}
+	parent.Node.AddChild(child.Node)
+	child.Node.SetTransform(transform)
+
+	// The pitch circles roll on each other, so the child turns the other way, z1 / z2 as fast.
+	// The tooth of the parent on the line of centres has to land in a gap of the child (half a tooth from a tooth),
+	// and the tooth of the parent at 0 is direction * z1 / 2π teeth away from that line
+	teeth := float32(first.TeethCount()) / float32(second.TeethCount())
+	link := GearLink{
+		parent,		// Parent
+		child,		// Child
+
+		-teeth,		// Ratio
+		direction + math.Pi + math.Pi / float32(second.TeethCount()) + teeth * direction, // Offset
+	}
+	link.Apply()
+
+	return link, nil
+}
+
+//
+// CoupleGears
+// Stacks a gear on the shaft of another one (on top of it), so they turn as one
+//
+// @param parent (*Gear) the gear that drives
+// @param child (*Gear) the gear that is driven
+//
+// @return link (GearLink) the link between the two gears
+//
+func CoupleGears(parent, child *Gear) GearLink {
+	transform := NewTransform()
+	transform.Position = mgl32.Vec3{ 0, (parent.Settings.FaceWidth + child.Settings.FaceWidth) * 0.5, 0 }
+	parent.Node.AddChild(child.Node)
+	child.Node.SetTransform(transform)
+
+	link := GearLink{
+		parent,		// Parent
+		child,		// Child
+
+		1,			// Ratio
+		0,			// Offset
+	}
+	link.Apply()
+
+	return link
+}
+
+//
+// Apply
+// Turns the child to follow the angle of the parent
+//
+func (link GearLink) Apply() {
+	link.Child.SetAngle(link.Offset + link.Ratio * link.Parent.Angle)
+}
+
+//
+// GearTrain
+// Turns a driver gear at a constant speed, and the gears linked to it at their ratios
+//
+type GearTrain struct {
+	Driver *Gear
+	Speed  float32 // Speed of the driver in radians per second
+
+	Links  []GearLink // In the order they're applied (a parent is always turned before its children)
+}
+
+//
+// NewGearTrain
+// Creates a gear train with only the driver
+//
+// @param driver (*Gear) the gear that turns the others
+// @param speed (float32) the speed of the driver in radians per second
+//
+// @return train (*GearTrain) a pointer to the gear train
+//
+func NewGearTrain(driver *Gear, speed float32) *GearTrain {
+	return &GearTrain{
+		driver,			// Driver
+		speed,			// Speed
+
+		[]GearLink{},	// Links
+	}
+}
+
+//
+// Mesh
+// Meshes a new gear with a gear of the train (see MeshGears)
+//
+// @param parent (*Gear) a gear of the train
+// @param child (*Gear) the new gear
+// @param direction (float32) the angle of the child around the parent in radians
+//
+// @return error (error) the reason the gears can't mesh (if any)
+//
+func (train *GearTrain) Mesh(parent, child *Gear, direction float32) error {
+	if !train.Contains(parent) {
+		return fmt.Errorf("%s is not in the gear train", parent.Name)
+	}
+	if train.Contains(child) {
+		return errors.New(child.Name + " is already in the gear train")
+	}
+
+	link, err := MeshGears(parent, child, direction)
+	if err != nil {
+		return err
+	}
+	train.Links = append(train.Links, link)
+
+	return nil
+}
+
+//
+// Couple
+// Stacks a new gear on the shaft of a gear of the train (see CoupleGears)
+//
+// @param parent (*Gear) a gear of the train
+// @param child (*Gear) the new gear
+//
+// @return error (error) the reason the gears can't be coupled (if any)
+//
+func (train *GearTrain) Couple(parent, child *Gear) error {
+	if !train.Contains(parent) {
+		return fmt.Errorf("%s is not in the gear train", parent.Name)
+	}
+	if train.Contains(child) {
+		return errors.New(child.Name + " is already in the gear train")
+	}
+
+	train.Links = append(train.Links, CoupleGears(parent, child))
+
+	return nil
+}
+
+//
+// Contains
+// Checks if a gear is the driver or is linked to it
+//
+// @param gear (*Gear) the gear
+//
+// @return contains (bool) true if the gear is in the train
+//
+func (train *GearTrain) Contains(gear *Gear) bool {
+	if gear == train.Driver {
+		return true
+	}
+
+	for _, link := range train.Links {
+		if link.Child == gear {
+			return true
+		}
+	}
+
+	return false
+}
+
+//
+// Update
+// Turns the driver and the gears linked to it
+//
+// @param seconds (float32) the elapsed time in seconds
+//
+func (train *GearTrain) Update(seconds float32) {
+	// Keeps the angle small, so it doesn't lose precision after running for a while
+	// (the linked gears jump a whole number of teeth when it wraps, which looks the same)
+	angle := math.Mod(float64(train.Driver.Angle + train.Speed * seconds), 2 * math.Pi)
+	train.Driver.SetAngle(float32(angle))
+
+	for _, link := range train.Links {
+		link.Apply()
+	}
+}
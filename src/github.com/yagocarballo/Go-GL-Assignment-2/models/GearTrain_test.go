@@ -0,0 +1,240 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+)
+
+// testGear returns a gear without buffers (the links only need the settings and the nodes)
+func testGear(name string, module float32, teeth uint32, helix float32) *Gear {
+	settings := geometry.NewGearSettings(module, teeth, 1)
+	settings.HelixAngle = helix
+	settings.FlankSegments = 16
+
+	return NewGear(nil, name, settings, nil)
+}
+
+// worldOutline returns the profile of a gear in the scene, on the xz plane (the middle of the face width)
+func worldOutline(gear *Gear) []mgl32.Vec2 {
+	world := gear.Spin.World()
+	outline := []mgl32.Vec2{}
+	for _, point := range gear.Settings.Profile() {
+		position := world.Mul4x1(mgl32.Vec4{ point.X(), 0, point.Y(), 1 })
+		outline = append(outline, mgl32.Vec2{ position.X(), position.Z() })
+	}
+
+	return outline
+}
+
+// inside checks if a point is inside a polygon (counting the edges a ray along x crosses)
+func inside(point mgl32.Vec2, polygon []mgl32.Vec2) bool {
+	crossings := 0
+	for index := range polygon {
+		a, b := polygon[index], polygon[(index + 1) % len(polygon)]
+		if (a.Y() > point.Y()) != (b.Y() > point.Y()) && point.X() < a.X() + (point.Y() - a.Y()) / (b.Y() - a.Y()) * (b.X() - a.X()) {
+			crossings++
+		}
+	}
+
+	return crossings % 2 == 1
+}
+
+// distanceToEdges returns the distance from a point to the nearest edge
+func distanceToEdges(point mgl32.Vec2, edges [][2]mgl32.Vec2) float64 {
+	nearest := math.Inf(1)
+	for _, edge := range edges {
+		a, direction := edge[0], edge[1].Sub(edge[0])
+		t := float32(math.Max(0, math.Min(1, float64(point.Sub(a).Dot(direction) / direction.Dot(direction)))))
+		nearest = math.Min(nearest, float64(point.Sub(a.Add(direction.Mul(t))).Len()))
+	}
+
+	return nearest
+}
+
+// nearEdges returns the edges of a polygon with a corner close to a point
+func nearEdges(polygon []mgl32.Vec2, center mgl32.Vec2, reach float32) [][2]mgl32.Vec2 {
+	edges := [][2]mgl32.Vec2{}
+	for index := range polygon {
+		a, b := polygon[index], polygon[(index + 1) % len(polygon)]
+		if a.Sub(center).Len() < reach || b.Sub(center).Len() < reach {
+			edges = append(edges, [2]mgl32.Vec2{ a, b })
+		}
+	}
+
+	return edges
+}
+
+// meshing returns how deep the teeth of the child dig into the parent, and the gap between them
+// (only the teeth that can reach the other gear are compared)
+func meshing(parent, child *Gear) (depth, gap float64) {
+	parentCenter, childCenter := parent.Node.WorldPosition(), child.Node.WorldPosition()
+	parentOutline := worldOutline(parent)
+	edges := nearEdges(parentOutline, mgl32.Vec2{ childCenter.X(), childCenter.Z() }, float32(child.Settings.TipRadius()) * 1.1)
+	reach := float32(parent.Settings.TipRadius()) * 1.1
+
+	gap = math.Inf(1)
+	for _, point := range worldOutline(child) {
+		if point.Sub(mgl32.Vec2{ parentCenter.X(), parentCenter.Z() }).Len() > reach {
+			continue
+		}
+
+		distance := distanceToEdges(point, edges)
+		if inside(point, parentOutline) {
+			depth = math.Max(depth, distance)
+		} else {
+			gap = math.Min(gap, distance)
+		}
+	}
+
+	return depth, gap
+}
+
+func TestMeshGearsPhase(t *testing.T) {
+	pairs := []struct {
+		name          string
+		parent, child *Gear
+		direction     float32
+	}{
+		{ "20 and 31 teeth", testGear("Driver", 1, 20, 0), testGear("Driven", 1, 31, 0), 0 },
+		{ "17 and 17 teeth, at an angle", testGear("Driver", 0.5, 17, 0), testGear("Driven", 0.5, 17, 0), 2.1 },
+		{ "40 and 24 helical teeth", testGear("Driver", 0.25, 40, 15), testGear("Driven", 0.25, 24, -15), -0.7 },
+	}
+
+	for _, pair := range pairs {
+		link, err := MeshGears(pair.parent, pair.child, pair.direction)
+		if err != nil {
+			t.Fatalf("%s: %s", pair.name, err)
+		}
+		module := float64(pair.parent.Settings.Module)
+
+		// The centres are the two pitch radii apart
+		distance := pair.child.Node.WorldPosition().Sub(pair.parent.Node.WorldPosition()).Len()
+		if expected := pair.parent.Settings.PitchRadius() + pair.child.Settings.PitchRadius(); math.Abs(float64(distance) - expected) > 1e-4 {
+			t.Errorf("%s: expected the centres %f apart, found %f", pair.name, expected, distance)
+		}
+
+		// While they turn, the teeth touch without going through each other (the flanks are drawn with segments)
+		for step := 0; step < 40; step++ {
+			pair.parent.SetAngle(float32(step) * 0.037)
+			link.Apply()
+
+			if depth, gap := meshing(pair.parent, pair.child); depth > 0.02 * module || gap > 0.02 * module {
+				t.Fatalf("%s, step %d: the teeth dig %f and are %f apart (module %f)", pair.name, step, depth, gap, module)
+			}
+		}
+
+		// Half a tooth off, they go through each other
+		link.Offset += math.Pi / float32(pair.child.Settings.TeethCount())
+		link.Apply()
+		if depth, _ := meshing(pair.parent, pair.child); depth < 0.3 * module {
+			t.Errorf("%s: half a tooth off, the teeth only dig %f", pair.name, depth)
+		}
+	}
+}
+
+func TestMeshGearsErrors(t *testing.T) {
+	cases := []struct {
+		child    *Gear
+		expected string
+	}{
+		{ testGear("Other module", 2, 20, 0), "modules" },
+		{ testGear("Same helix", 1, 20, 10), "helix" },
+	}
+	pressure := testGear("Other pressure", 1, 20, -10)
+	pressure.Settings.PressureAngle = 25
+	cases = append(cases, struct {
+		child    *Gear
+		expected string
+	}{ pressure, "pressure" })
+
+	for _, c := range cases {
+		parent := testGear("Driver", 1, 20, 10)
+		if _, err := MeshGears(parent, c.child, 0); err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%s: expected an error about the %s, found %v", c.child.Name, c.expected, err)
+		}
+		if c.child.Node.GetParent() != nil {
+			t.Errorf("%s: was attached to the parent", c.child.Name)
+		}
+	}
+}
+
+func TestGearTrainRatios(t *testing.T) {
+	driver := testGear("Driver", 1, 12, 0)
+	idler := testGear("Idler", 1, 36, 0)
+	stacked := testGear("Stacked", 1, 10, 0)
+	output := testGear("Output", 1, 40, 0)
+	reverse := testGear("Reverse", 1, 24, 0)
+
+	train := NewGearTrain(driver, 0.5)
+	for _, err := range []error{
+		train.Mesh(driver, idler, 0),
+		train.Couple(idler, stacked),
+		train.Mesh(stacked, output, 1),
+		train.Mesh(driver, reverse, math.Pi),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Turns of every gear for each turn of the driver: the meshes reverse the direction and multiply by z1 / z2,
+	// the gears on the same shaft turn together
+	ratios := map[*Gear]float64{
+		driver:  1,
+		idler:   -12.0 / 36,
+		stacked: -12.0 / 36,
+		output:  12.0 / 36 * 10.0 / 40,
+		reverse: -12.0 / 24,
+	}
+
+	angles := func() map[*Gear]float64 {
+		found := map[*Gear]float64{}
+		for gear := range ratios {
+			found[gear] = float64(gear.Angle)
+		}
+		return found
+	}
+
+	// Small steps, so the driver doesn't wrap
+	for step := 0; step < 10; step++ {
+		before := angles()
+		train.Update(0.1)
+		after := angles()
+
+		turned := after[driver] - before[driver]
+		if math.Abs(turned - 0.05) > 1e-5 {
+			t.Fatalf("step %d: expected the driver to turn 0.05, found %f", step, turned)
+		}
+		for gear, ratio := range ratios {
+			if found := (after[gear] - before[gear]) / turned; math.Abs(found - ratio) > 1e-4 {
+				t.Errorf("step %d, %s: expected the ratio %f, found %f", step, gear.Name, ratio, found)
+			}
+		}
+	}
+
+	// The gear on the shaft sits on top of the idler, and stays in place while they turn
+	if position := stacked.Node.WorldPosition().Sub(idler.Node.WorldPosition()); !position.ApproxEqualThreshold(mgl32.Vec3{ 0, 1, 0 }, 1e-5) {
+		t.Errorf("expected the stacked gear on top of the idler, found %v", position)
+	}
+
+	// Long runs keep the driver angle small
+	train.Update(1000)
+	if driver.Angle < 0 || driver.Angle >= 2 * math.Pi {
+		t.Errorf("expected the driver angle in [0, 2π), found %f", driver.Angle)
+	}
+
+	if err := train.Mesh(testGear("Loose", 1, 20, 0), testGear("New", 1, 20, 0), 0); err == nil {
+		t.Error("meshed a gear with a gear that is not in the train")
+	}
+	if err := train.Couple(driver, output); err == nil {
+		t.Error("coupled a gear that is already in the train")
+	}
+	if len(train.Links) != 4 || !train.Contains(output) || train.Contains(testGear("Loose", 1, 20, 0)) {
+		t.Errorf("expected the 4 links, found %d", len(train.Links))
+	}
+}
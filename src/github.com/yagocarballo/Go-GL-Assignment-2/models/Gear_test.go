@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+func TestGearBuffers(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	shaderManager := testShaders(t, device)
+	settings := geometry.NewGearSettings(0.2, 18, 0.4)
+	settings.Bore = 1
+	gear := NewGear(device, "Gear", settings, shaderManager)
+
+	checkMeshModel(t, device, shaderManager, gear, func() (*geometry.Mesh, *MeshBuffers) {
+		gear.MakeGearVBO()
+		return gear.Mesh, gear.Buffers
+	})
+
+	// The teeth turn under the placement: the model is the world matrix of the spin
+	gear.SetAngle(0.4)
+	device.Reset()
+	gear.Draw()
+	world := gear.Spin.World()
+	if uniform := device.Uniform(shaderManager.CurrentShader(), "model"); !sameFloats(world[:], uniform) {
+		t.Errorf("expected the world matrix of the spin in the model uniform, found %v", uniform)
+	}
+}
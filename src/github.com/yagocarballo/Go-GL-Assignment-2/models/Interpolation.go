@@ -0,0 +1,71 @@
+package models
+
+//
+// Interpolation
+// Smooths the rendering of a fixed step loop: it remembers the transforms of some nodes before an update,
+// and blends them with the transforms after it, so a frame between two updates draws the nodes in between
+//
+type Interpolation struct {
+	nodes    []*SceneNode
+	previous []Transform // Transforms before the last update
+	current  []Transform // Transforms after the last update (while they are blended)
+	applied  bool
+}
+
+//
+// NewInterpolation
+// Creates an interpolation of some nodes
+//
+// @param nodes (...*SceneNode) the nodes that move on the updates
+//
+// @return interpolation (*Interpolation) a pointer to the interpolation
+//
+func NewInterpolation(nodes ...*SceneNode) *Interpolation {
+	interpolation := &Interpolation{ nodes, make([]Transform, len(nodes)), make([]Transform, len(nodes)), false }
+	interpolation.Store()
+
+	return interpolation
+}
+
+//
+// Store
+// Remembers the transforms of the nodes (called before every update)
+//
+func (interpolation *Interpolation) Store() {
+	for index, node := range interpolation.nodes {
+		interpolation.previous[index] = node.GetTransform()
+	}
+}
+
+//
+// Apply
+// Moves the nodes between the transforms before and after the last update (Restore puts them back)
+//
+// @param alpha (float32) how far from the last update to the next one (0 to 1)
+//
+func (interpolation *Interpolation) Apply(alpha float32) {
+	if interpolation.applied {
+		interpolation.Restore()
+	}
+
+	for index, node := range interpolation.nodes {
+		interpolation.current[index] = node.GetTransform()
+		node.SetTransform(InterpolateTransforms(interpolation.previous[index], interpolation.current[index], alpha))
+	}
+	interpolation.applied = true
+}
+
+//
+// Restore
+// Puts the nodes back at the transforms after the last update (called after drawing)
+//
+func (interpolation *Interpolation) Restore() {
+	if !interpolation.applied {
+		return
+	}
+
+	for index, node := range interpolation.nodes {
+		node.SetTransform(interpolation.current[index])
+	}
+	interpolation.applied = false
+}
@@ -0,0 +1,237 @@
+package models
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+// Kind of light
+type LightType uint32
+
+const (
+	LIGHT_POINT LightType = 0 + iota // Shines in every direction from its position
+	LIGHT_DIRECTIONAL                // Shines along its direction from far away (like the sun)
+	LIGHT_SPOT                       // Shines along its direction from its position, inside a cone
+)
+
+var lightTypeNames = []string{
+	"[ Point Light ]",
+	"[ Directional Light ]",
+	"[ Spot Light ]",
+}
+
+func (lightType LightType) String() string {
+	return lightTypeNames[lightType]
+}
+
+// Size of the gizmos of the directional and spot lights
+const (
+	LIGHT_GIZMO_LENGTH = 1.5
+	LIGHT_ARROW_RADIUS = 0.4
+)
+
+//
+// Light
+// A light of the scene. The node places it (and turns its direction), and the gizmo shows where it is
+// (a sphere for the point lights, an arrow for the directional lights and the cone of the spot lights)
+//
+type Light struct {
+	Name          string
+	Type          LightType
+
+	Color         mgl32.Vec3 // Colour of the light (it multiplies the diffuse and specular colours of the materials)
+	Intensity     float32    // Brightness (it multiplies the colour)
+	Range         float32    // Distance where the light fades out (point and spot lights)
+	Direction     mgl32.Vec3 // Direction the light shines to, in the space of the node (directional and spot lights)
+	InnerCone     float32    // Half angle of the fully lit part of a spot light, in degrees
+	OuterCone     float32    // Half angle where a spot light fades out, in degrees
+
+	Gizmo         *geometry.Mesh // The vertices of the gizmo (nil until MakeGizmoVBO)
+	Buffers       *MeshBuffers   // The buffers of the gizmo
+	DrawMode      DrawMode       // Defines drawing mode of the gizmo as points, lines or filled polygons
+
+	Node          *SceneNode // Transform of the light in the scene graph
+
+	ShaderManager *wrapper.ShaderManager // Pointer to the Shader Manager
+	Device        wrapper.Device         // Uploads and draws the buffers
+}
+
+//
+// NewLight
+// Creates a white light with the range the shaders used to have (MakeGizmoVBO builds its gizmo)
+//
+// @param device (wrapper.Device) the device that uploads and draws the buffers
+// @param name (string) the name of the light
+// @param lightType (LightType) the kind of light
+// @param shaderManager (*wrapper.ShaderManager) the shaders
+//
+// @return light (*Light) a pointer to the light
+//
+func NewLight(device wrapper.Device, name string, lightType LightType, shaderManager *wrapper.ShaderManager) *Light {
+	return &Light{
+		name,						// Name
+		lightType,					// Type
+
+		mgl32.Vec3{ 1, 1, 1 },		// Color
+		1,							// Intensity
+		90.5,						// Range
+		mgl32.Vec3{ 0, 0, -1 },		// Direction
+		20,							// InnerCone
+		30,							// OuterCone
+
+		nil,						// Gizmo
+		nil,						// Buffers
+		DRAW_POLYGONS,				// DrawMode
+
+		NewSceneNode(name),			// Node
+
+		shaderManager,				// Pointer to the Shader Manager
+		device,						// Device
+	}
+}
+
+//
+// MakeGizmoVBO
+// Makes the gizmo mesh of the light (painted with its colour) and uploads it.
+// The cones of the directional and spot lights are built along y and turned to the direction when they are drawn
+//
+// @param latitudes (uint32) the number of bands of the sphere of a point light
+// @param longitudes (uint32) the number of segments around the sphere or the cone
+//
+func (light *Light) MakeGizmoVBO(latitudes, longitudes uint32) {
+	switch light.Type {
+	case LIGHT_DIRECTIONAL:
+		light.Gizmo = geometry.Cone(longitudes, 1, LIGHT_GIZMO_LENGTH, LIGHT_ARROW_RADIUS)
+	case LIGHT_SPOT:
+		// The base of the cone shows how wide the light is
+		radius := LIGHT_GIZMO_LENGTH * float32(math.Tan(float64(light.OuterCone) * math.Pi / 180))
+		light.Gizmo = geometry.Cone(longitudes, 1, LIGHT_GIZMO_LENGTH, radius)
+	default:
+		light.Gizmo = geometry.Sphere(latitudes, longitudes)
+	}
+	light.Gizmo.SetColor(mgl32.Vec4{ light.Color.X(), light.Color.Y(), light.Color.Z(), 1 })
+
+	if light.Buffers != nil {
+		light.Buffers.Delete()
+	}
+	light.Buffers = UploadMesh(light.Device, light.Gizmo)
+}
+
+//
+// WorldPosition
+// Calculates where the light is in world space
+//
+// @return position (mgl32.Vec3) the position
+//
+func (light *Light) WorldPosition() mgl32.Vec3 {
+	return light.Node.WorldPosition()
+}
+
+//
+// WorldDirection
+// Calculates the direction the light shines to in world space (turned by the node)
+//
+// @return direction (mgl32.Vec3) the unit direction
+//
+func (light *Light) WorldDirection() mgl32.Vec3 {
+	direction := light.Node.World().Mul4x1(mgl32.Vec4{ light.Direction.X(), light.Direction.Y(), light.Direction.Z(), 0 }).Vec3()
+	if direction.Len() == 0 {
+		return mgl32.Vec3{ 0, 0, -1 }
+	}
+
+	return direction.Normalize()
+}
+
+// gizmoMatrix turns the y axis of the gizmo cone to the direction of the light
+// (the tip of the arrow leads, the tip of the spot cone stays on the light)
+func (light *Light) gizmoMatrix() mgl32.Mat4 {
+	model := light.Node.World()
+	if light.Type == LIGHT_POINT {
+		return model
+	}
+
+	// The direction is in the space of the node, so the cone is turned before the node transform
+	axis := mgl32.Vec3{ 0, 1, 0 }
+	offset := mgl32.Ident4()
+	if light.Type == LIGHT_SPOT {
+		axis = mgl32.Vec3{ 0, -1, 0 }
+		offset = mgl32.Translate3D(0, -LIGHT_GIZMO_LENGTH * 0.5, 0)
+	}
+
+	direction := light.Direction
+	if direction.Len() == 0 {
+		direction = mgl32.Vec3{ 0, 0, -1 }
+	}
+	direction = direction.Normalize()
+
+	turn := mgl32.Ident4()
+	cross, cosine := axis.Cross(direction), axis.Dot(direction)
+	switch {
+	case cross.Len() > 1e-6:
+		turn = mgl32.HomogRotate3D(float32(math.Atan2(float64(cross.Len()), float64(cosine))), cross.Normalize())
+	case cosine < 0:
+		// Opposite directions: any perpendicular axis works
+		turn = mgl32.HomogRotate3D(math.Pi, mgl32.Vec3{ 1, 0, 0 })
+	}
+
+	return model.Mul4(turn).Mul4(offset)
+}
+
+// Draws the gizmo of the light from the previously defined vertex and index buffers
+func (light *Light) Draw() {
+	// Adds the Gizmo Model to the Active Shader
+	model := light.gizmoMatrix()
+	light.ShaderManager.SetUniformMatrix4fv(light.ShaderManager.ActiveShader, "model", 1, false, model[:])
+
+	light.Buffers.Draw(light.ShaderManager.CurrentShader(), light.DrawMode)
+}
+
+func (light *Light) ResetModel() {
+	light.Node.Reset()
+}
+
+func (light *Light) Translate(Tx, Ty, Tz float32) {
+	light.Node.Translate(Tx, Ty, Tz)
+}
+
+func (light *Light) Scale(scaleX, scaleY, scaleZ float32) {
+	light.Node.Scale(scaleX, scaleY, scaleZ)
+}
+
+func (light *Light) RotateRadians(radians float32, axis mgl32.Vec3) {
+	light.Node.RotateRadians(radians, axis)
+}
+
+func (light *Light) RotateDegrees(degrees float32, axis mgl32.Vec3) {
+	light.Node.RotateDegrees(degrees, axis)
+}
+
+func (light *Light) GetNode () *SceneNode {
+	return light.Node
+}
+
+func (light *Light) GetDrawMode () DrawMode {
+	return light.DrawMode
+}
+
+func (light *Light) SetDrawMode (drawMode DrawMode) {
+	light.DrawMode = drawMode
+}
+
+func (light *Light) GetName () string {
+	return light.Name
+}
+
+func (light *Light) String () string {
+	return fmt.Sprintf(`
+                 Light --> %s %s
+    -------------------------------------
+    %s
+    -------------------------------------
+    `, light.Name, light.Type, light.Node.World())
+}
@@ -0,0 +1,218 @@
+package models
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+// Uniform buffer of the lights (it has to match the Lights block of the shaders)
+const (
+	MAX_LIGHTS        = 8        // Lights the shaders can take
+	LIGHTS_BLOCK      = "Lights" // Name of the uniform block
+	LIGHTS_BINDING    = 0        // Binding point of the uniform buffer
+
+	lightFloats       = 16                                       // Four vec4 per light
+	lightsHeaderSize  = 16                                       // The number of lights (an ivec4)
+	lightsBlockSize   = lightsHeaderSize + MAX_LIGHTS * lightFloats * 4 // Bytes of the block (std140 layout)
+)
+
+//
+// LightManager
+// Uploads the lights to a uniform buffer shared by every shader with a Lights block:
+//
+//     struct Light {
+//         vec4 position;  // View space position (w is 0 for the directional lights)
+//         vec4 direction; // View space direction the light shines to
+//         vec4 colour;    // Colour times the intensity (a is the range)
+//         vec4 cone;      // Cosines of the inner and outer half angles (z is 1 for the spot lights)
+//     };
+//     layout(std140) uniform Lights {
+//         ivec4 lightcount; // x is the number of lights
+//         Light lights[MAX_LIGHTS];
+//     };
+//
+type LightManager struct {
+	Lights []*Light // The lights, in the order they are uploaded (MAX_LIGHTS at most)
+	Buffer uint32   // The uniform buffer
+
+	Device wrapper.Device
+}
+
+//
+// NewLightManager
+// Creates the uniform buffer of the lights and binds it to LIGHTS_BINDING
+//
+// @param device (wrapper.Device) the device that uploads the buffer
+//
+// @return manager (*LightManager) a pointer to the light manager
+//
+func NewLightManager(device wrapper.Device) *LightManager {
+	manager := &LightManager{
+		[]*Light{},				// Lights
+		device.GenBuffer(),		// Buffer
+
+		device,					// Device
+	}
+
+	device.BindBuffer(gl.UNIFORM_BUFFER, manager.Buffer)
+	device.BufferData(gl.UNIFORM_BUFFER, lightsBlockSize, nil, gl.DYNAMIC_DRAW)
+	device.BindBufferBase(gl.UNIFORM_BUFFER, LIGHTS_BINDING, manager.Buffer)
+	device.BindBuffer(gl.UNIFORM_BUFFER, 0)
+
+	return manager
+}
+
+//
+// Add
+// Adds a light to the ones that are uploaded
+//
+// @param light (*Light) the light
+//
+// @return error (error) an error if there are MAX_LIGHTS lights already
+//
+func (manager *LightManager) Add(light *Light) error {
+	if len(manager.Lights) >= MAX_LIGHTS {
+		return fmt.Errorf("%s can't be added, the shaders take %d lights at most", light.Name, MAX_LIGHTS)
+	}
+
+	manager.Lights = append(manager.Lights, light)
+
+	return nil
+}
+
+//
+// Remove
+// Stops uploading a light
+//
+// @param light (*Light) the light
+//
+func (manager *LightManager) Remove(light *Light) {
+	for index, current := range manager.Lights {
+		if current == light {
+			manager.Lights = append(manager.Lights[:index], manager.Lights[index + 1:]...)
+			return
+		}
+	}
+}
+
+//
+// Light
+// Finds a light by name
+//
+// @param name (string) the name of the light
+//
+// @return light (*Light) the light (nil if it doesn't exist)
+//
+func (manager *LightManager) Light(name string) *Light {
+	for _, light := range manager.Lights {
+		if light.Name == name {
+			return light
+		}
+	}
+
+	return nil
+}
+
+//
+// BindShaders
+// Connects the Lights block of every shader (that has one) to the uniform buffer
+//
+// @param shaderManager (*wrapper.ShaderManager) the shaders (they have to be loaded already)
+//
+func (manager *LightManager) BindShaders(shaderManager *wrapper.ShaderManager) {
+	for _, shader := range shaderManager.Shaders {
+		if index := manager.Device.GetUniformBlockIndex(shader.Shader, LIGHTS_BLOCK); index != gl.INVALID_INDEX {
+			manager.Device.UniformBlockBinding(shader.Shader, index, LIGHTS_BINDING)
+		}
+	}
+}
+
+//
+// Upload
+// Sends the lights to the uniform buffer, in the view space of a camera
+// (once for every view the scene is drawn from, like the water reflection)
+//
+// @param view (mgl32.Mat4) the view matrix
+//
+func (manager *LightManager) Upload(view mgl32.Mat4) {
+	count := len(manager.Lights)
+	if count > MAX_LIGHTS {
+		count = MAX_LIGHTS
+	}
+
+	data := make([]float32, 0, count * lightFloats)
+	for _, light := range manager.Lights[:count] {
+		data = append(data, packLight(light, view)...)
+	}
+
+	manager.Device.BindBuffer(gl.UNIFORM_BUFFER, manager.Buffer)
+	manager.Device.BufferSubData(gl.UNIFORM_BUFFER, 0, lightsHeaderSize, []int32{ int32(count), 0, 0, 0 })
+	if count > 0 {
+		manager.Device.BufferSubData(gl.UNIFORM_BUFFER, lightsHeaderSize, len(data) * 4, data)
+	}
+	manager.Device.BindBuffer(gl.UNIFORM_BUFFER, 0)
+
+	// Binds the buffer again, in case something else took the binding point
+	manager.Device.BindBufferBase(gl.UNIFORM_BUFFER, LIGHTS_BINDING, manager.Buffer)
+}
+
+// packLight returns the four vec4 of a light in view space
+func packLight(light *Light, view mgl32.Mat4) []float32 {
+	position := light.WorldPosition()
+	viewPosition := view.Mul4x1(mgl32.Vec4{ position.X(), position.Y(), position.Z(), 1 })
+	if light.Type == LIGHT_DIRECTIONAL {
+		viewPosition = mgl32.Vec4{}
+	}
+
+	direction := light.WorldDirection()
+	viewDirection := view.Mul4x1(mgl32.Vec4{ direction.X(), direction.Y(), direction.Z(), 0 }).Vec3().Normalize()
+
+	colour := light.Color.Mul(light.Intensity)
+
+	// The inner cone is kept inside the outer one, so the fade always has some width
+	inner := math.Cos(float64(light.InnerCone) * math.Pi / 180)
+	outer := math.Cos(float64(light.OuterCone) * math.Pi / 180)
+	inner = math.Max(inner, outer + 1e-4)
+	spot := float32(0)
+	if light.Type == LIGHT_SPOT {
+		spot = 1
+	}
+
+	return []float32{
+		viewPosition.X(), viewPosition.Y(), viewPosition.Z(), viewPosition.W(),
+		viewDirection.X(), viewDirection.Y(), viewDirection.Z(), 0,
+		colour.X(), colour.Y(), colour.Z(), light.Range,
+		float32(inner), float32(outer), spot, 0,
+	}
+}
+
+//
+// Draw
+// Draws the gizmos of the lights with the active shader
+//
+func (manager *LightManager) Draw() {
+	for _, light := range manager.Lights {
+		light.Draw()
+	}
+}
+
+//
+// Delete
+// Deletes the uniform buffer and the buffers of the gizmos
+//
+func (manager *LightManager) Delete() {
+	for _, light := range manager.Lights {
+		if light.Buffers != nil {
+			light.Buffers.Delete()
+			light.Buffers = nil
+		}
+	}
+
+	manager.Device.DeleteBuffer(manager.Buffer)
+	manager.Buffer = 0
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+// uploadedLight returns the four vec4 of a light in the uniform buffer (after the 16 bytes of the count)
+func uploadedLight(device *wrapper.FakeDevice, buffer uint32, index int) []float32 {
+	values := device.BufferFloats(buffer)[lightsHeaderSize / 4:]
+	return values[index * lightFloats:(index + 1) * lightFloats]
+}
+
+func TestLightManagerUpload(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	manager := NewLightManager(device)
+	if size := len(device.Buffers[manager.Buffer]); size != 16 + MAX_LIGHTS * 64 {
+		t.Fatalf("expected a block of %d bytes, found %d", 16 + MAX_LIGHTS * 64, size)
+	}
+	if device.BufferBases[[2]uint32{ gl.UNIFORM_BUFFER, LIGHTS_BINDING }] != manager.Buffer {
+		t.Error("the buffer isn't bound to LIGHTS_BINDING")
+	}
+
+	point := NewLight(device, "Point", LIGHT_POINT, nil)
+	point.Node.Translate(1, 2, 3)
+	point.Color, point.Intensity, point.Range = mgl32.Vec3{ 1, 0.5, 0 }, 2, 10
+
+	sun := NewLight(device, "Sun", LIGHT_DIRECTIONAL, nil)
+	sun.Node.Translate(5, 5, 5)
+	sun.Direction = mgl32.Vec3{ 0, -2, 0 }
+
+	// The inner cone is wider than the outer one
+	spot := NewLight(device, "Spot", LIGHT_SPOT, nil)
+	spot.InnerCone, spot.OuterCone = 40, 30
+
+	for _, light := range []*Light{ point, sun, spot } {
+		if err := manager.Add(light); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	device.Reset()
+	manager.Upload(mgl32.Translate3D(0, 0, -10))
+	for _, err := range device.Errors {
+		t.Errorf("device error: %s", err)
+	}
+
+	if header := device.BufferUint32s(manager.Buffer)[:4]; header[0] != 3 || header[1] != 0 || header[2] != 0 || header[3] != 0 {
+		t.Errorf("expected the count (3, 0, 0, 0) in the first 16 bytes, found %v", header)
+	}
+
+	outer := float32(math.Cos(30 * math.Pi / 180))
+	lights := []struct {
+		name     string
+		expected []float32
+	}{
+		// Moved by the view, with the colour times the intensity and the range
+		{ "point", []float32{ 1, 2, -7, 1, 0, 0, -1, 0, 2, 1, 0, 10, float32(math.Cos(20 * math.Pi / 180)), outer, 0, 0 } },
+		// No position (w is 0), and the direction is normalised
+		{ "directional", []float32{ 0, 0, 0, 0, 0, -1, 0, 0, 1, 1, 1, 90.5, float32(math.Cos(20 * math.Pi / 180)), outer, 0, 0 } },
+		// The inner cone is kept just inside the outer one
+		{ "spot", []float32{ 0, 0, -10, 1, 0, 0, -1, 0, 1, 1, 1, 90.5, outer + 1e-4, outer, 1, 0 } },
+	}
+	for index, light := range lights {
+		if values := uploadedLight(device, manager.Buffer, index); !nearFloats(values, light.expected, 1e-5) {
+			t.Errorf("%s: expected %v, found %v", light.name, light.expected, values)
+		}
+	}
+	if values := uploadedLight(device, manager.Buffer, 2); !(values[12] > values[13]) {
+		t.Errorf("the cosine of the inner cone (%f) isn't above the outer one (%f)", values[12], values[13])
+	}
+}
+
+func TestLightManagerTruncates(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	manager := NewLightManager(device)
+
+	for index := 0; index < MAX_LIGHTS; index++ {
+		light := NewLight(device, "Light", LIGHT_POINT, nil)
+		light.Range = float32(index + 1)
+		if err := manager.Add(light); err != nil {
+			t.Fatalf("light %d: %s", index, err)
+		}
+	}
+	if err := manager.Add(NewLight(device, "Extra", LIGHT_POINT, nil)); err == nil || len(manager.Lights) != MAX_LIGHTS {
+		t.Error("expected an error when adding more than MAX_LIGHTS lights")
+	}
+
+	// The lights that go past the block are left out of the upload
+	manager.Lights = append(manager.Lights, NewLight(device, "Extra", LIGHT_POINT, nil), NewLight(device, "Extra", LIGHT_POINT, nil))
+	manager.Upload(mgl32.Ident4())
+	for _, err := range device.Errors {
+		t.Errorf("device error: %s", err)
+	}
+
+	if count := device.BufferUint32s(manager.Buffer)[0]; count != MAX_LIGHTS {
+		t.Errorf("expected %d lights, found %d", MAX_LIGHTS, count)
+	}
+	if size := len(device.Buffers[manager.Buffer]); size != lightsBlockSize {
+		t.Errorf("the upload resized the block to %d bytes", size)
+	}
+
+	// The range is the last float of the colour, 64 bytes after the one of the light before
+	for index := 0; index < MAX_LIGHTS; index++ {
+		if values := uploadedLight(device, manager.Buffer, index); values[11] != float32(index + 1) {
+			t.Errorf("light %d: expected the range %d, found %f", index, index + 1, values[11])
+		}
+	}
+}
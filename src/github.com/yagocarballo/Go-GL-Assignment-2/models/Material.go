@@ -0,0 +1,52 @@
+package models
+
+import (
+	"github.com/go-gl/gl/all-core/gl"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/loader"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+// useMaterial sends the colours of a material (if any) to the shader, binds its textures (diffuse, normals and specular
+// on the units 0, 1 and 2) and enables the blending if it's transparent (the caller disables it after drawing)
+func useMaterial(device wrapper.Device, shaderProgram uint32, material *loader.MtlData) {
+	if material == nil {
+		return
+	}
+
+	ambientUniform := device.GetUniformLocation(shaderProgram, "ambient");
+	diffuseUniform := device.GetUniformLocation(shaderProgram, "diffuse");
+	specularUniform := device.GetUniformLocation(shaderProgram, "specular");
+	emissiveUniform := device.GetUniformLocation(shaderProgram, "emissive");
+
+	device.Uniform4f(ambientUniform, material.KaR, material.KaG, material.KaB, material.Tr); // Ambient colour.
+	device.Uniform4f(diffuseUniform, material.KdR, material.KdG, material.KdB, material.Tr); // Diffuse colour.
+	device.Uniform4f(specularUniform, material.KsR, material.KsG, material.KsB, material.Tr); // Specular colour.
+	device.Uniform4f(emissiveUniform, material.KeR, material.KeG, material.KeB, material.Tr); // Emissive colour.
+
+	if material.Texture != 0 {
+		textureUniform := device.GetUniformLocation(shaderProgram, "DiffuseTextureSampler")
+		device.Uniform1i(textureUniform, 0)
+
+		normalTextureUniform := device.GetUniformLocation(shaderProgram, "NormalTextureSampler")
+		device.Uniform1i(normalTextureUniform, 1)
+
+		specularTextureUniform := device.GetUniformLocation(shaderProgram, "SpecularTextureSampler")
+		device.Uniform1i(specularTextureUniform, 2)
+
+		device.ActiveTexture(gl.TEXTURE0)
+		device.BindTexture(gl.TEXTURE_2D, material.Texture)
+
+		device.ActiveTexture(gl.TEXTURE1)
+		device.BindTexture(gl.TEXTURE_2D, material.NormalMap)
+
+		device.ActiveTexture(gl.TEXTURE2)
+		device.BindTexture(gl.TEXTURE_2D, material.SpecularMap)
+	}
+
+	if material.Tr < 1.0 {
+		// Enables Transparencies
+		device.Enable(gl.BLEND)
+		device.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	}
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+// Names of the attributes in the shaders (basic uses colour, the materials use texcoord)
+const (
+	ATTRIBUTE_POSITION = "position"
+	ATTRIBUTE_COLOR    = "colour"
+	ATTRIBUTE_NORMAL   = "normal"
+	ATTRIBUTE_UV       = "texcoord"
+)
+
+//
+// MeshBuffers
+// The buffers of a geometry.Mesh on the GPU (0 for the attributes the mesh doesn't have)
+//
+type MeshBuffers struct {
+	Positions   uint32
+	Normals     uint32
+	Colors      uint32
+	UVs         uint32
+	Indices     uint32
+
+	VertexCount int32
+	IndexCount  int32
+	Topology    geometry.Topology
+
+	Device      wrapper.Device
+}
+
+//
+// UploadMesh
+// Copies the attributes and indices of a mesh into buffers
+//
+// @param device (wrapper.Device) the device that creates the buffers
+// @param mesh (*geometry.Mesh) the mesh
+//
+// @return buffers (*MeshBuffers) a pointer to the buffers
+//
+func UploadMesh(device wrapper.Device, mesh *geometry.Mesh) *MeshBuffers {
+	return &MeshBuffers{
+		uploadBuffer(device, gl.ARRAY_BUFFER, len(mesh.Positions) * SizeOfVec3, mesh.Positions),	// Positions
+		uploadBuffer(device, gl.ARRAY_BUFFER, len(mesh.Normals) * SizeOfVec3, mesh.Normals),		// Normals
+		uploadBuffer(device, gl.ARRAY_BUFFER, len(mesh.Colors) * SizeOfVec4, mesh.Colors),			// Colors
+		uploadBuffer(device, gl.ARRAY_BUFFER, len(mesh.UVs) * SizeOfVec2, flipUVs(mesh.UVs)),		// UVs
+		uploadBuffer(device, gl.ELEMENT_ARRAY_BUFFER, len(mesh.Indices) * SizeOfUint32, mesh.Indices),	// Indices
+
+		int32(len(mesh.Positions)),	// VertexCount
+		int32(len(mesh.Indices)),	// IndexCount
+		mesh.Topology,				// Topology
+
+		device,						// Device
+	}
+}
+
+// flipUVs turns v upside down: the textures are uploaded from the top row (like the loader does with the .obj files)
+func flipUVs(uvs []mgl32.Vec2) []mgl32.Vec2 {
+	flipped := make([]mgl32.Vec2, len(uvs))
+	for index, uv := range uvs {
+		flipped[index] = mgl32.Vec2{ uv.X(), 1 - uv.Y() }
+	}
+
+	return flipped
+}
+
+// uploadBuffer creates a buffer with the data (0 if there is no data)
+func uploadBuffer(device wrapper.Device, target uint32, size int, data interface{}) uint32 {
+	if size == 0 {
+		return 0
+	}
+
+	buffer := device.GenBuffer()
+	device.BindBuffer(target, buffer)
+	device.BufferData(target, size, data, gl.STATIC_DRAW)
+	device.BindBuffer(target, 0)
+
+	return buffer
+}
+
+//
+// Draw
+// Binds the attributes the shader has and draws the mesh
+//
+// @param shaderProgram (uint32) the active shader (the attributes are found by name)
+// @param drawMode (DrawMode) points, lines (wireframe) or polygons
+//
+func (buffers *MeshBuffers) Draw(shaderProgram uint32, drawMode DrawMode) {
+	device := buffers.Device
+
+	enabled := []uint32{}
+	for _, attribute := range []struct{ name string; buffer uint32; size int32 }{
+		{ ATTRIBUTE_POSITION, buffers.Positions, 3 },
+		{ ATTRIBUTE_COLOR, buffers.Colors, 4 },
+		{ ATTRIBUTE_NORMAL, buffers.Normals, 3 },
+		{ ATTRIBUTE_UV, buffers.UVs, 2 },
+	} {
+		// Skips the attributes the mesh or the shader don't have
+		location := device.GetAttribLocation(shaderProgram, attribute.name)
+		if attribute.buffer == 0 || location < 0 {
+			continue
+		}
+
+		device.BindBuffer(gl.ARRAY_BUFFER, attribute.buffer)
+		device.VertexAttribPointer(uint32(location), attribute.size, gl.FLOAT, false, 0, 0)
+		device.EnableVertexAttribArray(uint32(location))
+		enabled = append(enabled, uint32(location))
+	}
+
+	device.PointSize(3.0)
+
+	if drawMode == DRAW_LINES {
+		device.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	} else {
+		device.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	}
+
+	if drawMode == DRAW_POINTS || buffers.Indices == 0 {
+		device.DrawArrays(gl.POINTS, 0, buffers.VertexCount)
+	} else {
+		device.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, buffers.Indices)
+		device.DrawElements(buffers.mode(), buffers.IndexCount, gl.UNSIGNED_INT, 0)
+	}
+
+	// The same location can be a different attribute in the next shader, so none stays on
+	for _, location := range enabled {
+		device.DisableVertexAttribArray(location)
+	}
+}
+
+// mode returns the OpenGL primitive of the topology
+func (buffers *MeshBuffers) mode() uint32 {
+	switch buffers.Topology {
+	case geometry.TOPOLOGY_LINES:
+		return gl.LINES
+	case geometry.TOPOLOGY_POINTS:
+		return gl.POINTS
+	}
+
+	return gl.TRIANGLES
+}
+
+//
+// Delete
+// Deletes the buffers
+//
+func (buffers *MeshBuffers) Delete() {
+	for _, buffer := range []uint32{ buffers.Positions, buffers.Normals, buffers.Colors, buffers.UVs, buffers.Indices } {
+		if buffer != 0 {
+			buffers.Device.DeleteBuffer(buffer)
+		}
+	}
+
+	buffers.Positions, buffers.Normals, buffers.Colors, buffers.UVs, buffers.Indices = 0, 0, 0, 0, 0
+}
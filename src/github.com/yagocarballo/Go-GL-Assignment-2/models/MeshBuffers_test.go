@@ -0,0 +1,248 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+// testShaders returns a shader manager with an active "basic" shader made on the fake device
+func testShaders(t *testing.T, device *wrapper.FakeDevice) *wrapper.ShaderManager {
+	program, err := device.CreateProgram("void main() {}", "void main() {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shaderManager := wrapper.NewShaderManager(device)
+	shaderManager.Shaders["basic"] = wrapper.Shader{ Name: "basic", Shader: program, Uniforms: map[string]int32{} }
+	shaderManager.CreateUniform("basic", "model")
+	shaderManager.EnableShader("basic")
+
+	return shaderManager
+}
+
+// flatten returns the components of the vectors one after the other (how they are uploaded)
+func flatten(vectors interface{}) []float32 {
+	values := []float32{}
+	switch vectors := vectors.(type) {
+	case []mgl32.Vec2:
+		for _, vector := range vectors {
+			values = append(values, vector[:]...)
+		}
+	case []mgl32.Vec3:
+		for _, vector := range vectors {
+			values = append(values, vector[:]...)
+		}
+	case []mgl32.Vec4:
+		for _, vector := range vectors {
+			values = append(values, vector[:]...)
+		}
+	}
+
+	return values
+}
+
+// sameFloats compares the values of a buffer with the expected ones
+func sameFloats(expected, found []float32) bool {
+	if len(expected) != len(found) {
+		return false
+	}
+	for index := range expected {
+		if expected[index] != found[index] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkUpload checks that every attribute of the mesh is in its own buffer, with the right size and contents
+func checkUpload(t *testing.T, device *wrapper.FakeDevice, buffers *MeshBuffers, mesh *geometry.Mesh) {
+	for _, err := range device.Errors {
+		t.Errorf("device error: %s", err)
+	}
+
+	uvs := make([]mgl32.Vec2, len(mesh.UVs))
+	for index, uv := range mesh.UVs {
+		uvs[index] = mgl32.Vec2{ uv.X(), 1 - uv.Y() }
+	}
+
+	attributes := []struct {
+		name     string
+		buffer   uint32
+		size     int
+		expected []float32
+	}{
+		{ "positions", buffers.Positions, len(mesh.Positions) * SizeOfVec3, flatten(mesh.Positions) },
+		{ "normals", buffers.Normals, len(mesh.Normals) * SizeOfVec3, flatten(mesh.Normals) },
+		{ "colours", buffers.Colors, len(mesh.Colors) * SizeOfVec4, flatten(mesh.Colors) },
+		{ "UVs (v flipped)", buffers.UVs, len(mesh.UVs) * SizeOfVec2, flatten(uvs) },
+	}
+	for _, attribute := range attributes {
+		if attribute.size == 0 {
+			if attribute.buffer != 0 {
+				t.Errorf("%s: the mesh has none, but buffer %d was made", attribute.name, attribute.buffer)
+			}
+			continue
+		}
+		if size := len(device.Buffers[attribute.buffer]); size != attribute.size {
+			t.Errorf("%s: expected %d bytes, found %d", attribute.name, attribute.size, size)
+		}
+		if !sameFloats(attribute.expected, device.BufferFloats(attribute.buffer)) {
+			t.Errorf("%s: the buffer doesn't have the values of the mesh", attribute.name)
+		}
+	}
+
+	indices := device.BufferUint32s(buffers.Indices)
+	if len(device.Buffers[buffers.Indices]) != len(mesh.Indices) * SizeOfUint32 || len(indices) != len(mesh.Indices) {
+		t.Errorf("indices: expected %d bytes, found %d", len(mesh.Indices) * SizeOfUint32, len(device.Buffers[buffers.Indices]))
+	}
+	for index := range indices {
+		if indices[index] != mesh.Indices[index] {
+			t.Fatalf("index %d: expected %d, found %d", index, mesh.Indices[index], indices[index])
+		}
+	}
+
+	if buffers.VertexCount != int32(len(mesh.Positions)) || buffers.IndexCount != int32(len(mesh.Indices)) {
+		t.Errorf("expected %d vertices and %d indices, found %d and %d", len(mesh.Positions), len(mesh.Indices), buffers.VertexCount, buffers.IndexCount)
+	}
+	if device.Bindings[gl.ARRAY_BUFFER] != 0 || device.Bindings[gl.ELEMENT_ARRAY_BUFFER] != 0 {
+		t.Error("a buffer was left bound after the upload")
+	}
+}
+
+// checkDraw checks the draw call of every draw mode, and that the attributes are turned off after drawing
+func checkDraw(t *testing.T, device *wrapper.FakeDevice, buffers *MeshBuffers, setDrawMode func(DrawMode), draw func()) {
+	modes := []struct {
+		drawMode DrawMode
+		polygon  uint32
+		call     wrapper.Call
+	}{
+		{ DRAW_POINTS, gl.FILL, wrapper.Call{ Name: "DrawArrays", Args: []interface{}{ uint32(gl.POINTS), int32(0), buffers.VertexCount } } },
+		{ DRAW_LINES, gl.LINE, wrapper.Call{ Name: "DrawElements", Args: []interface{}{ uint32(gl.TRIANGLES), buffers.IndexCount, uint32(gl.UNSIGNED_INT), 0 } } },
+		{ DRAW_POLYGONS, gl.FILL, wrapper.Call{ Name: "DrawElements", Args: []interface{}{ uint32(gl.TRIANGLES), buffers.IndexCount, uint32(gl.UNSIGNED_INT), 0 } } },
+	}
+
+	for _, mode := range modes {
+		device.Reset()
+		setDrawMode(mode.drawMode)
+		draw()
+
+		for _, err := range device.Errors {
+			t.Errorf("%s: device error: %s", mode.drawMode, err)
+		}
+
+		polygonModes := device.CallsNamed("PolygonMode")
+		if len(polygonModes) != 1 || polygonModes[0].Args[1] != mode.polygon {
+			t.Errorf("%s: expected the polygon mode 0x%x, found %v", mode.drawMode, mode.polygon, polygonModes)
+		}
+
+		draws := append(device.CallsNamed("DrawArrays"), device.CallsNamed("DrawElements")...)
+		if len(draws) != 1 || draws[0].String() != mode.call.String() {
+			t.Errorf("%s: expected %s, found %v", mode.drawMode, mode.call, draws)
+		}
+
+		enabled, disabled := device.CallsNamed("EnableVertexAttribArray"), device.CallsNamed("DisableVertexAttribArray")
+		if len(enabled) == 0 || len(enabled) != len(disabled) {
+			t.Errorf("%s: %d attributes enabled and %d disabled", mode.drawMode, len(enabled), len(disabled))
+		}
+	}
+}
+
+func TestUploadMesh(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	mesh := geometry.Cube(2)
+	mesh.Paint(func(position, normal mgl32.Vec3) mgl32.Vec4 { return normal.Vec4(1) })
+
+	buffers := UploadMesh(device, mesh)
+	checkUpload(t, device, buffers, mesh)
+
+	// One buffer per attribute, and the indices
+	if calls := device.CallsNamed("GenBuffer"); len(calls) != 5 {
+		t.Errorf("expected 5 buffers, found %d", len(calls))
+	}
+
+	buffers.Delete()
+	if len(device.Buffers) != 0 || buffers.Positions != 0 || buffers.Indices != 0 {
+		t.Errorf("the buffers weren't deleted: %v", device.Buffers)
+	}
+}
+
+func TestMeshBuffersDrawSkipsMissingAttributes(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	shaderManager := testShaders(t, device)
+
+	// A mesh without colours or UVs only binds the positions and the normals
+	mesh := geometry.Cube(1)
+	mesh.UVs = nil
+	buffers := UploadMesh(device, mesh)
+	device.Reset()
+	buffers.Draw(shaderManager.CurrentShader(), DRAW_POLYGONS)
+
+	pointers := device.CallsNamed("VertexAttribPointer")
+	if len(pointers) != 2 || pointers[0].Args[1] != int32(3) || pointers[1].Args[1] != int32(3) {
+		t.Errorf("expected the positions and normals (3 floats each), found %v", pointers)
+	}
+
+	// Lines meshes draw lines
+	lines := &geometry.Mesh{ Positions: []mgl32.Vec3{ { 0, 0, 0 }, { 1, 0, 0 } }, Indices: []uint32{ 0, 1 }, Topology: geometry.TOPOLOGY_LINES }
+	buffers = UploadMesh(device, lines)
+	device.Reset()
+	buffers.Draw(shaderManager.CurrentShader(), DRAW_POLYGONS)
+	if draws := device.CallsNamed("DrawElements"); len(draws) != 1 || draws[0].Args[0] != uint32(gl.LINES) {
+		t.Errorf("expected the lines to be drawn as lines, found %v", draws)
+	}
+}
+
+// meshModel is a model drawn from its MeshBuffers with the active shader of the shader manager
+type meshModel interface {
+	Model
+	Draw()
+}
+
+// checkMeshModel checks the upload and the draw calls of a model, that it sends its world matrix, and that making it again
+// replaces the buffers
+func checkMeshModel(t *testing.T, device *wrapper.FakeDevice, shaderManager *wrapper.ShaderManager, model meshModel, make func() (*geometry.Mesh, *MeshBuffers)) {
+	mesh, buffers := make()
+	checkUpload(t, device, buffers, mesh)
+
+	model.Translate(1, 2, 3)
+	model.RotateDegrees(30, mgl32.Vec3{ 0, 1, 0 })
+	checkDraw(t, device, buffers, model.SetDrawMode, model.Draw)
+
+	world := model.GetNode().World()
+	if uniform := device.Uniform(shaderManager.CurrentShader(), "model"); !sameFloats(world[:], uniform) {
+		t.Errorf("expected the world matrix in the model uniform, found %v", uniform)
+	}
+
+	// The old buffers are deleted when the model is made again
+	old := len(device.Buffers)
+	device.Reset()
+	_, buffers = make()
+	if deleted := device.CallsNamed("DeleteBuffer"); len(deleted) != old || len(device.Buffers) != old {
+		t.Errorf("expected the %d buffers to be replaced, %d were deleted and there are %d", old, len(deleted), len(device.Buffers))
+	}
+	if buffers.Positions == 0 {
+		t.Error("the model was made again without buffers")
+	}
+}
+
+func TestUploadPrimitiveSizes(t *testing.T) {
+	// The fake device fails when the size of an upload is not the size of its data (the cog uploaded twice its positions)
+	meshes := map[string]*geometry.Mesh{
+		"box": geometry.Box(1, 2, 3, 2, 3, 4), "sphere": geometry.Sphere(8, 12), "plane": geometry.Plane(2, 2, 3, 3),
+		"torus": geometry.Torus(2, 0.5, 10, 6), "cylinder": geometry.Cylinder(12, 4, 2, 1), "cone": geometry.Cone(16, 3, 2, 1),
+		"capsule": geometry.Capsule(12, 4, 1, 0.5), "cog": geometry.Cog(20, 0.5, 1, 0.2), "icosphere": geometry.Icosphere(2),
+	}
+
+	for name, mesh := range meshes {
+		device := wrapper.NewFakeDevice()
+		mesh.SetColor(mgl32.Vec4{ 1, 0, 0, 1 })
+		buffers := UploadMesh(device, mesh)
+		t.Run(name, func(t *testing.T) { checkUpload(t, device, buffers, mesh) })
+	}
+}
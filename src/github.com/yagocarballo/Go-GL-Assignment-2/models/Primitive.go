@@ -0,0 +1,146 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+	"github.com/yagocarballo/Go-GL-Assignment-2/loader"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+//
+// Primitive
+// Any mesh of the geometry package drawn with a material, so it can be textured with the material shaders
+// (Example: NewPrimitive(device, "Ball", geometry.Icosphere(3), material))
+//
+type Primitive struct {
+	Name      string
+
+	Mesh      *geometry.Mesh   // The vertices (with the UVs of the texture)
+	Buffers   *MeshBuffers     // The buffers of the mesh (nil until CreateObject)
+	Material  *loader.MtlData  // The colours and textures (nil keeps the ones of the shader)
+
+	Node      *SceneNode // Transform of the primitive in the scene graph
+	DrawMode  DrawMode
+
+	Device    wrapper.Device // Uploads and draws the buffers
+}
+
+//
+// NewPrimitive
+// Creates a primitive (CreateObject uploads it)
+//
+// @param device (wrapper.Device) the device that uploads and draws the buffers
+// @param name (string) the name of the primitive
+// @param mesh (*geometry.Mesh) the mesh
+// @param material (*loader.MtlData) the material (can be nil)
+//
+// @return primitive (*Primitive) a pointer to the primitive
+//
+func NewPrimitive(device wrapper.Device, name string, mesh *geometry.Mesh, material *loader.MtlData) *Primitive {
+	return &Primitive{
+		name,				// Name
+
+		mesh,				// Mesh
+		nil,				// Buffers
+		material,			// Material
+
+		NewSceneNode(name),	// Node
+		DRAW_POLYGONS,		// DrawMode
+
+		device,				// Device
+	}
+}
+
+//
+// CreateObject
+// Checks the mesh and uploads it (again, if it was already uploaded)
+//
+// @return error (error) the problem with the mesh (if any)
+//
+func (primitive *Primitive) CreateObject() error {
+	if err := primitive.Mesh.Validate(); err != nil {
+		return fmt.Errorf("%s: %s", primitive.Name, err)
+	}
+
+	primitive.DeleteObject()
+	primitive.Buffers = UploadMesh(primitive.Device, primitive.Mesh)
+
+	return nil
+}
+
+//
+// DeleteObject
+// Deletes the buffers (the material textures belong to the caller)
+//
+func (primitive *Primitive) DeleteObject() {
+	if primitive.Buffers != nil {
+		primitive.Buffers.Delete()
+		primitive.Buffers = nil
+	}
+}
+
+//
+// DrawObject
+// Sends the model matrix and the material to the shader and draws the mesh
+//
+// @param shaderProgram (uint32) the active shader
+//
+func (primitive *Primitive) DrawObject(shaderProgram uint32) {
+	model := primitive.Node.World()
+	primitive.Device.UniformMatrix4fv(primitive.Device.GetUniformLocation(shaderProgram, "model"), 1, false, model[:])
+
+	useMaterial(primitive.Device, shaderProgram, primitive.Material)
+	primitive.Buffers.Draw(shaderProgram, primitive.DrawMode)
+
+	// Disables transparencies
+	primitive.Device.Disable(gl.BLEND)
+}
+
+func (primitive *Primitive) ResetModel() {
+	primitive.Node.Reset()
+}
+
+func (primitive *Primitive) Translate(Tx, Ty, Tz float32) {
+	primitive.Node.Translate(Tx, Ty, Tz)
+}
+
+func (primitive *Primitive) Scale(scaleX, scaleY, scaleZ float32) {
+	primitive.Node.Scale(scaleX, scaleY, scaleZ)
+}
+
+func (primitive *Primitive) RotateRadians(radians float32, axis mgl32.Vec3) {
+	primitive.Node.RotateRadians(radians, axis)
+}
+
+func (primitive *Primitive) RotateDegrees(degrees float32, axis mgl32.Vec3) {
+	primitive.Node.RotateDegrees(degrees, axis)
+}
+
+func (primitive *Primitive) GetNode () *SceneNode {
+	return primitive.Node
+}
+
+func (primitive *Primitive) GetDrawMode () DrawMode {
+	return primitive.DrawMode
+}
+
+func (primitive *Primitive) SetDrawMode (drawMode DrawMode) {
+	primitive.DrawMode = drawMode
+}
+
+func (primitive *Primitive) GetName () string {
+	return primitive.Name
+}
+
+func (primitive *Primitive) String () string {
+	return fmt.Sprintf(`
+             Primitive --> %s (%s)
+    -------------------------------------
+    %s
+    -------------------------------------
+    `, primitive.Name, primitive.Mesh, primitive.Node.World())
+}
@@ -0,0 +1,336 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//
+// SceneNode
+// A node of the scene graph. It keeps a local Transform (position, orientation and scale),
+// and the world matrix is the world matrix of the parent times the local matrix.
+// The matrices are cached, and changing a node marks it and every node under it as dirty
+//
+type SceneNode struct {
+	Name        string
+
+	transform   Transform  // Local transform (relative to the parent)
+
+	parent      *SceneNode
+	children    []*SceneNode
+
+	local       mgl32.Mat4 // Cached local matrix (Translation * Rotation * Scale)
+	world       mgl32.Mat4 // Cached world matrix (Parent * Local)
+	dirty       bool       // The cached matrices are out of date
+}
+
+//
+// NewSceneNode
+// Creates a node with the identity transform and no parent
+//
+// @param name (string) the name of the node
+//
+// @return node (*SceneNode) a pointer to the node
+//
+func NewSceneNode(name string) *SceneNode {
+	return &SceneNode{
+		name,                       // Name
+		NewTransform(),             // transform
+		nil,                        // parent
+		[]*SceneNode{},             // children
+		mgl32.Ident4(),             // local
+		mgl32.Ident4(),             // world
+		false,                      // dirty
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////// Hierarchy /////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// AddChild
+// Attaches a node under this one (it's removed from its old parent).
+// The local transform of the child is kept, so it moves with its new parent
+//
+// @param child (*SceneNode) the node to attach
+//
+func (node *SceneNode) AddChild(child *SceneNode) {
+	if child == nil || child == node || child.IsAncestorOf(node) {
+		return
+	}
+
+	if child.parent != nil {
+		child.parent.RemoveChild(child)
+	}
+
+	child.parent = node
+	node.children = append(node.children, child)
+	child.markDirty()
+}
+
+//
+// RemoveChild
+// Detaches a node from this one (it becomes a root node)
+//
+// @param child (*SceneNode) the node to detach
+//
+func (node *SceneNode) RemoveChild(child *SceneNode) {
+	for index, current := range node.children {
+		if current == child {
+			node.children = append(node.children[:index], node.children[index + 1:]...)
+			child.parent = nil
+			child.markDirty()
+			return
+		}
+	}
+}
+
+//
+// GetParent
+// Returns the parent of the node (nil on root nodes)
+//
+func (node *SceneNode) GetParent() *SceneNode {
+	return node.parent
+}
+
+//
+// GetChildren
+// Returns the nodes attached to this one
+//
+func (node *SceneNode) GetChildren() []*SceneNode {
+	return node.children
+}
+
+//
+// IsAncestorOf
+// Checks if the node is above another node in the graph
+//
+// @param other (*SceneNode) the other node
+//
+// @return ancestor (bool) true if other is under this node
+//
+func (node *SceneNode) IsAncestorOf(other *SceneNode) bool {
+	for current := other.parent; current != nil; current = current.parent {
+		if current == node {
+			return true
+		}
+	}
+
+	return false
+}
+
+//
+// Walk
+// Calls a function on this node and every node under it (parents before children)
+//
+// @param visit (func(*SceneNode)) the function
+//
+func (node *SceneNode) Walk(visit func(*SceneNode)) {
+	visit(node)
+	for _, child := range node.children {
+		child.Walk(visit)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////// Transform /////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// GetTransform
+// Returns a copy of the local transform
+//
+func (node *SceneNode) GetTransform() Transform {
+	return node.transform
+}
+
+//
+// SetTransform
+// Replaces the local transform
+//
+// @param transform (Transform) the local transform
+//
+func (node *SceneNode) SetTransform(transform Transform) {
+	transform.Orientation = transform.Orientation.Normalize()
+	node.transform = transform
+	node.markDirty()
+}
+
+//
+// Reset
+// Sets the local transform back to the identity
+//
+func (node *SceneNode) Reset() {
+	node.SetTransform(NewTransform())
+}
+
+//
+// Translate
+// Moves the node along its own axes (the same as multiplying the local matrix by a translation)
+//
+// @param Tx (float32) the distance along x
+// @param Ty (float32) the distance along y
+// @param Tz (float32) the distance along z
+//
+func (node *SceneNode) Translate(Tx, Ty, Tz float32) {
+	node.transform.TranslateLocal(mgl32.Vec3{ Tx, Ty, Tz })
+	node.markDirty()
+}
+
+//
+// TranslateWorld
+// Moves the node along the axes of the world (whatever the parents are)
+//
+// @param offset (mgl32.Vec3) the distance along every axis
+//
+func (node *SceneNode) TranslateWorld(offset mgl32.Vec3) {
+	if node.parent != nil {
+		offset = node.parent.World().Inv().Mul4x1(offset.Vec4(0)).Vec3()
+	}
+
+	node.transform.TranslateWorld(offset)
+	node.markDirty()
+}
+
+//
+// Scale
+// Scales the node along its own axes (the same as multiplying the local matrix by a scale)
+//
+// @param scaleX (float32) the scale along x
+// @param scaleY (float32) the scale along y
+// @param scaleZ (float32) the scale along z
+//
+func (node *SceneNode) Scale(scaleX, scaleY, scaleZ float32) {
+	node.transform.ScaleBy(mgl32.Vec3{ scaleX, scaleY, scaleZ })
+	node.markDirty()
+}
+
+//
+// RotateRadians
+// Rotates the node around one of its own axes (the same as multiplying the local matrix by a rotation)
+//
+// @param radians (float32) the angle in radians
+// @param axis (mgl32.Vec3) the axis
+//
+func (node *SceneNode) RotateRadians(radians float32, axis mgl32.Vec3) {
+	node.transform.RotateLocalRadians(radians, axis)
+	node.markDirty()
+}
+
+//
+// RotateDegrees
+// Rotates the node around one of its own axes
+//
+// @param degrees (float32) the angle in degrees
+// @param axis (mgl32.Vec3) the axis
+//
+func (node *SceneNode) RotateDegrees(degrees float32, axis mgl32.Vec3) {
+	node.RotateRadians(mgl32.DegToRad(degrees), axis)
+}
+
+//
+// RotateWorldRadians
+// Rotates the node around an axis of the world, without moving it
+//
+// @param radians (float32) the angle in radians
+// @param axis (mgl32.Vec3) the axis
+//
+func (node *SceneNode) RotateWorldRadians(radians float32, axis mgl32.Vec3) {
+	if node.parent != nil {
+		axis = node.parent.World().Inv().Mul4x1(axis.Vec4(0)).Vec3()
+	}
+
+	node.transform.RotateWorldRadians(radians, axis)
+	node.markDirty()
+}
+
+//
+// RotateWorldDegrees
+// Rotates the node around an axis of the world, without moving it
+//
+// @param degrees (float32) the angle in degrees
+// @param axis (mgl32.Vec3) the axis
+//
+func (node *SceneNode) RotateWorldDegrees(degrees float32, axis mgl32.Vec3) {
+	node.RotateWorldRadians(mgl32.DegToRad(degrees), axis)
+}
+
+//
+// SetMatrix
+// Replaces the local transform with the position, orientation and scale of a matrix
+// (the matrix can't have shear or a perspective)
+//
+// @param matrix (mgl32.Mat4) the local matrix
+//
+func (node *SceneNode) SetMatrix(matrix mgl32.Mat4) {
+	node.SetTransform(TransformFromMatrix(matrix))
+}
+
+//
+// Local
+// Returns the local matrix (Translation * Rotation * Scale)
+//
+func (node *SceneNode) Local() mgl32.Mat4 {
+	node.update()
+	return node.local
+}
+
+//
+// World
+// Returns the world matrix (the matrices of the parents times the local matrix)
+//
+func (node *SceneNode) World() mgl32.Mat4 {
+	node.update()
+	return node.world
+}
+
+//
+// WorldPosition
+// Returns the position of the node in world space
+//
+func (node *SceneNode) WorldPosition() mgl32.Vec3 {
+	return node.World().Col(3).Vec3()
+}
+
+// markDirty invalidates the cache of the node and everything under it.
+// If a node is dirty its children are dirty too, so it can stop there
+func (node *SceneNode) markDirty() {
+	if node.dirty {
+		return
+	}
+
+	node.dirty = true
+	for _, child := range node.children {
+		child.markDirty()
+	}
+}
+
+// update recalculates the cached matrices (the parent is updated first)
+func (node *SceneNode) update() {
+	if !node.dirty {
+		return
+	}
+
+	node.local = node.transform.Matrix()
+
+	if node.parent != nil {
+		node.world = node.parent.World().Mul4(node.local)
+	} else {
+		node.world = node.local
+	}
+
+	node.dirty = false
+}
+
+func (node *SceneNode) String() string {
+	return fmt.Sprintf(`
+             Scene Node --> %s
+    -------------------------------------
+    %s
+    Children: %d
+    %s
+    -------------------------------------
+    `, node.Name, node.transform, len(node.children), node.World())
+}
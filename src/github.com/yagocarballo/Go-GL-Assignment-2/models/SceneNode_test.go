@@ -0,0 +1,153 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// nearFloats checks that no value is further than tolerance from the other one
+// (ApproxEqualThreshold is relative, so it fails on the rounding errors around 0)
+func nearFloats(a, b []float32, tolerance float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if !(math.Abs(float64(a[index] - b[index])) < float64(tolerance)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nearVec3 compares two vectors with an absolute tolerance
+func nearVec3(a, b mgl32.Vec3, tolerance float32) bool {
+	return nearFloats(a[:], b[:], tolerance)
+}
+
+// nearMat4 compares two matrices with an absolute tolerance
+func nearMat4(a, b mgl32.Mat4, tolerance float32) bool {
+	return nearFloats(a[:], b[:], tolerance)
+}
+
+// testHierarchy returns a root moved and scaled, a child moved along the root and a grandchild turned on the child
+func testHierarchy() (root, child, grandchild *SceneNode) {
+	root, child, grandchild = NewSceneNode("Root"), NewSceneNode("Child"), NewSceneNode("Grandchild")
+	root.Translate(10, 0, 0)
+	root.Scale(2, 2, 2)
+	child.Translate(1, 2, 0)
+	child.RotateDegrees(90, mgl32.Vec3{ 0, 1, 0 })
+	grandchild.Translate(0, 0, 1)
+
+	root.AddChild(child)
+	child.AddChild(grandchild)
+
+	return root, child, grandchild
+}
+
+func TestWorldMatrixComposition(t *testing.T) {
+	root, child, grandchild := testHierarchy()
+
+	if !nearMat4(child.World(), root.Local().Mul4(child.Local()), 1e-5) {
+		t.Errorf("the world matrix of the child is not Parent * Local:\n%v", child.World())
+	}
+	if !nearMat4(grandchild.World(), root.Local().Mul4(child.Local()).Mul4(grandchild.Local()), 1e-5) {
+		t.Errorf("the world matrix of the grandchild is not Root * Child * Local:\n%v", grandchild.World())
+	}
+
+	// (1, 2, 0) scaled by 2 from (10, 0, 0), then the z of the grandchild turned to x by the child
+	if position := child.WorldPosition(); !nearVec3(position, mgl32.Vec3{ 12, 4, 0 }, 1e-5) {
+		t.Errorf("expected the child on (12, 4, 0), found %v", position)
+	}
+	if position := grandchild.WorldPosition(); !nearVec3(position, mgl32.Vec3{ 14, 4, 0 }, 1e-5) {
+		t.Errorf("expected the grandchild on (14, 4, 0), found %v", position)
+	}
+}
+
+func TestDirtyPropagation(t *testing.T) {
+	root, child, grandchild := testHierarchy()
+	grandchild.World()
+	if root.dirty || child.dirty || grandchild.dirty {
+		t.Fatal("reading the world matrix updates every parent")
+	}
+
+	// Moving the root invalidates everything under it
+	root.Translate(0, 5, 0)
+	if !child.dirty || !grandchild.dirty {
+		t.Error("the nodes under the root are still clean")
+	}
+	if position := grandchild.WorldPosition(); !nearVec3(position, mgl32.Vec3{ 14, 14, 0 }, 1e-5) {
+		t.Errorf("expected the grandchild to follow the root to (14, 14, 0), found %v", position)
+	}
+
+	// Changing the child doesn't touch the root
+	child.Translate(0, 0, 0)
+	if root.dirty || !grandchild.dirty {
+		t.Error("only the child and the nodes under it are dirty")
+	}
+
+	// SetMatrix replaces the local transform and moves the children too
+	grandchild.World()
+	root.SetMatrix(mgl32.Translate3D(-3, 0, 0))
+	if !grandchild.dirty {
+		t.Error("SetMatrix didn't invalidate the children")
+	}
+	if position := grandchild.WorldPosition(); !nearVec3(position, mgl32.Vec3{ -1, 2, 0 }, 1e-5) {
+		t.Errorf("expected the grandchild on (-1, 2, 0), found %v", position)
+	}
+}
+
+func TestAddChildKeepsTheLocalTransform(t *testing.T) {
+	root, child, grandchild := testHierarchy()
+	other := NewSceneNode("Other")
+	other.Translate(0, -7, 0)
+
+	// The grandchild keeps its local transform, so it moves with the new parent
+	grandchild.World()
+	other.AddChild(grandchild)
+	if grandchild.GetParent() != other || len(child.GetChildren()) != 0 {
+		t.Fatal("the grandchild wasn't moved to the other node")
+	}
+	if position := grandchild.WorldPosition(); !nearVec3(position, mgl32.Vec3{ 0, -7, 1 }, 1e-5) {
+		t.Errorf("expected the grandchild on (0, -7, 1), found %v", position)
+	}
+
+	// Removing it makes it a root node
+	other.RemoveChild(grandchild)
+	if grandchild.GetParent() != nil || !nearMat4(grandchild.World(), grandchild.Local(), 1e-5) {
+		t.Errorf("the removed node still follows its parent:\n%v", grandchild.World())
+	}
+
+	// A node can't be its own ancestor
+	child.AddChild(root)
+	child.AddChild(child)
+	if root.GetParent() != nil || child.GetParent() != root || !root.IsAncestorOf(child) {
+		t.Error("AddChild made a loop")
+	}
+
+	visited := []string{}
+	root.Walk(func(node *SceneNode) { visited = append(visited, node.Name) })
+	if len(visited) != 2 || visited[0] != "Root" || visited[1] != "Child" {
+		t.Errorf("unexpected walk %v", visited)
+	}
+}
+
+func TestWorldMovesUnderParents(t *testing.T) {
+	root, child, _ := testHierarchy()
+	start := child.WorldPosition()
+
+	// The world axes don't depend on the scale of the root or the turn of the child
+	child.TranslateWorld(mgl32.Vec3{ 0, 0, 3 })
+	if position := child.WorldPosition(); !nearVec3(position, start.Add(mgl32.Vec3{ 0, 0, 3 }), 1e-5) {
+		t.Errorf("expected the child on %v, found %v", start.Add(mgl32.Vec3{ 0, 0, 3 }), position)
+	}
+
+	root.RotateDegrees(90, mgl32.Vec3{ 0, 0, 1 })
+	before := child.WorldPosition()
+	child.RotateWorldDegrees(90, mgl32.Vec3{ 0, 1, 0 })
+	if position := child.WorldPosition(); !nearVec3(position, before, 1e-5) {
+		t.Errorf("a world rotation moved the child from %v to %v", before, position)
+	}
+}
@@ -6,25 +6,36 @@ package models
 import (
 	"github.com/go-gl/gl/all-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
-	"github.com/ojrac/opensimplex-go"
 	"fmt"
+	"image"
+	"math"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/generation"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
 )
 
 const (
 	SizeOfUint16 = 2
 	SizeOfInt32 = 4
+	SizeOfUint32 = 4
 	SizeOfFloat32 = 4
+	SizeOfVec2 = SizeOfFloat32 * 2
 	SizeOfVec3 = SizeOfFloat32 * 3
+	SizeOfVec4 = SizeOfFloat32 * 4
 )
 
 type Terrain struct  {
 	Seed                  int64
-	Frequency, NoiseScale float32
+	NoiseSettings         generation.NoiseSettings
+	HeightSource          generation.HeightSource // If nil, OpenSimplex noise is made from the Seed and NoiseSettings
+	ErosionSettings       *generation.ErosionSettings // If not nil, the heights are eroded before creating the vertices
+	Biomes                *generation.Biomes // If not nil, the colours come from the biome rules instead of the height
 	ColorTone			  mgl32.Vec4
 
 	XSize, ZSize          uint32
-	PerlinOctaves         uint32
 	HeightScale           float32
+	Spacing               mgl32.Vec2 // Distance between two vertices on (x, z)
+	Offset                mgl32.Vec2 // Where the terrain sits in the world on (x, z), the moisture is sampled there
 
 	VBOVertices           uint32
 	VBOColors             uint32
@@ -37,15 +48,21 @@ type Terrain struct  {
 	Indices               []uint16
 
 	Noise                 []float32
+	SedimentMap           []float32 // Material deposited by the erosion on every vertex
+	FlowMap               []float32 // Water that went through every vertex during the erosion
+	SplatWeights          [][]float32 // Weight of every biome rule on every vertex
 
-	Model                 mgl32.Mat4
+	Node                  *SceneNode // Transform of the terrain in the scene graph
 
 	Name                  string
 	DrawMode              DrawMode // Defines drawing mode of cube as points, lines or filled polygons
+
+	Device                wrapper.Device // Uploads and draws the buffers (the heights can be built without it)
 }
 
-func NewTerrain () *Terrain {
+func NewTerrain (device wrapper.Device) *Terrain {
 	return NewTerrainWithSeed(
+		device,	// Device
 		0, 		// Seed
 		4.0, 	// Frequency
 		5.0,	// NoiseScale
@@ -56,17 +73,26 @@ func NewTerrain () *Terrain {
 //	Define the vertex attributes for vertex positions and normals.
 //	Make these match your application and vertex shader
 //	You might also want to add colours and texture coordinates
-func NewTerrainWithSeed(seed int64, frequency, scale float32, colorTone mgl32.Vec4) *Terrain {
+func NewTerrainWithSeed(device wrapper.Device, seed int64, frequency, scale float32, colorTone mgl32.Vec4) *Terrain {
+	return NewTerrainWithSettings(device, seed, generation.NewNoiseSettings(frequency, scale), colorTone)
+}
+
+//	Creates a Terrain with full control over the fractal noise
+//	(octaves, lacunarity, gain, mode, domain warping and 3D/4D noise)
+func NewTerrainWithSettings(device wrapper.Device, seed int64, settings generation.NoiseSettings, colorTone mgl32.Vec4) *Terrain {
 	return &Terrain{
 		seed,			// Seed
-		frequency,		// Frequency
-		scale,			// NoiseScale
+		settings,		// NoiseSettings
+		nil,			// HeightSource
+		nil,			// ErosionSettings
+		nil,			// Biomes
 		colorTone,		// Color Tone
 
 		0,				// XSize: Set to zero because we haven't created the heightField array yet
 		0,				// ZSize
-		4,				// PerlinOctaves
 		1.0,			// HeightScale
+		mgl32.Vec2{},	// Spacing
+		mgl32.Vec2{},	// Offset
 
 		0,				// VBOVertices
 		0,				// VBOColors
@@ -78,42 +104,66 @@ func NewTerrainWithSeed(seed int64, frequency, scale float32, colorTone mgl32.Ve
 		[]mgl32.Vec3{},	// Colors
 		[]uint16{},		// Indices
 
-		[]float32{},	// Noise
+		[]float32{},	// Noise (Heights)
+		[]float32{},	// SedimentMap
+		[]float32{},	// FlowMap
+		[][]float32{},	// SplatWeights
 
-		mgl32.Ident4(), // Model
+		NewSceneNode("Terrain"), // Node
 
 		"Terrain",
 		DRAW_POLYGONS,
+
+		device,			// Device
 	}
 }
 
+//	Creates a Terrain that takes its heights from any HeightSource
+//	(for example the diamond-square generator: generation.NewRandomMid)
+func NewTerrainWithSource(device wrapper.Device, source generation.HeightSource, colorTone mgl32.Vec4) *Terrain {
+	terrain := NewTerrainWithSettings(device, 0, generation.NewNoiseSettings(4.0, 5.0), colorTone)
+	terrain.HeightSource = source
+	return terrain
+}
+
 //
 // Copy the vertices, normals and element indices into vertex buffers
 //
 func (terrain *Terrain) CreateObject() {
 	// Generate the vertex buffer object
-	gl.GenBuffers(1, &terrain.VBOVertices)
-	gl.BindBuffer(gl.ARRAY_BUFFER, terrain.VBOVertices)
-	gl.BufferData(gl.ARRAY_BUFFER, int(len(terrain.Vertices) * 3 * 4), gl.Ptr(terrain.Vertices), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	terrain.VBOVertices = terrain.Device.GenBuffer()
+	terrain.Device.BindBuffer(gl.ARRAY_BUFFER, terrain.VBOVertices)
+	terrain.Device.BufferData(gl.ARRAY_BUFFER, len(terrain.Vertices) * SizeOfVec3, terrain.Vertices, gl.STATIC_DRAW)
+	terrain.Device.BindBuffer(gl.ARRAY_BUFFER, 0)
 
 	/* Store the normals in a buffer object */
-	gl.GenBuffers(1, &terrain.VBONormals)
-	gl.BindBuffer(gl.ARRAY_BUFFER, terrain.VBONormals)
-	gl.BufferData(gl.ARRAY_BUFFER, int(len(terrain.Normals) * 3 * 4), gl.Ptr(terrain.Normals), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	terrain.VBONormals = terrain.Device.GenBuffer()
+	terrain.Device.BindBuffer(gl.ARRAY_BUFFER, terrain.VBONormals)
+	terrain.Device.BufferData(gl.ARRAY_BUFFER, len(terrain.Normals) * SizeOfVec3, terrain.Normals, gl.STATIC_DRAW)
+	terrain.Device.BindBuffer(gl.ARRAY_BUFFER, 0)
 
 	/* Store the Colors in a buffer object */
-	gl.GenBuffers(1, &terrain.VBOColors)
-	gl.BindBuffer(gl.ARRAY_BUFFER, terrain.VBOColors)
-	gl.BufferData(gl.ARRAY_BUFFER, int(len(terrain.Colors) * 5 * 4), gl.Ptr(terrain.Colors), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	terrain.VBOColors = terrain.Device.GenBuffer()
+	terrain.Device.BindBuffer(gl.ARRAY_BUFFER, terrain.VBOColors)
+	terrain.Device.BufferData(gl.ARRAY_BUFFER, len(terrain.Colors) * SizeOfVec3, terrain.Colors, gl.STATIC_DRAW)
+	terrain.Device.BindBuffer(gl.ARRAY_BUFFER, 0)
 
 	// Generate a buffer for the indices
-	gl.GenBuffers(1, &terrain.VBOIndices)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, terrain.VBOIndices)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, int(len(terrain.Indices) * SizeOfUint16), gl.Ptr(terrain.Indices), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	terrain.VBOIndices = terrain.Device.GenBuffer()
+	terrain.Device.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, terrain.VBOIndices)
+	terrain.Device.BufferData(gl.ELEMENT_ARRAY_BUFFER, int(len(terrain.Indices) * SizeOfUint16), terrain.Indices, gl.STATIC_DRAW)
+	terrain.Device.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
+}
+
+//
+// Deletes the buffer objects (the CPU side arrays are kept)
+//
+func (terrain *Terrain) DeleteObject() {
+	for _, buffer := range []uint32{ terrain.VBOVertices, terrain.VBONormals, terrain.VBOColors, terrain.VBOIndices } {
+		terrain.Device.DeleteBuffer(buffer)
+	}
+
+	terrain.VBOVertices, terrain.VBONormals, terrain.VBOColors, terrain.VBOIndices = 0, 0, 0, 0
 }
 
 /* Enable vertex attributes and draw object
@@ -122,84 +172,82 @@ create object but this method is more general
 This code is almost untouched fomr the tutorial code except that I changed the
 number of elements per vertex from 4 to 3*/
 func (terrain *Terrain) DrawObject(shaderProgram uint32) {
-	toneUniform := gl.GetAttribLocation(shaderProgram, gl.Str("tone\x00"))
-	gl.Uniform4f(toneUniform, terrain.ColorTone.X(), terrain.ColorTone.Y(), terrain.ColorTone.Z(), terrain.ColorTone.W())
-//	gl.Uniform4f(toneUniform, 0.0, 1.0, 0.5, 1.0)
-//	gl.Uniform4fv(toneUniform, 1, &terrain.ColorTone[0])
+	toneUniform := terrain.Device.GetAttribLocation(shaderProgram, "tone")
+	terrain.Device.Uniform4f(toneUniform, terrain.ColorTone.X(), terrain.ColorTone.Y(), terrain.ColorTone.Z(), terrain.ColorTone.W())
+//	terrain.Device.Uniform4f(toneUniform, 0.0, 1.0, 0.5, 1.0)
+//	terrain.Device.Uniform4fv(toneUniform, 1, &terrain.ColorTone[0])
 
 
 	// Reads the uniform Locations
-	modelUniform := gl.GetUniformLocation(shaderProgram, gl.Str("model\x00"));
+	modelUniform := terrain.Device.GetUniformLocation(shaderProgram, "model");
 
 	// Send our uniforms variables to the currently bound shader
-	gl.UniformMatrix4fv(modelUniform, 1, false, &terrain.Model[0]);
+	model := terrain.Node.World()
+	terrain.Device.UniformMatrix4fv(modelUniform, 1, false, model[:]);
 
 	// Get the vertices uniform position
-	verticesUniform := uint32(gl.GetAttribLocation(shaderProgram, gl.Str("position\x00")))
-	normalsUniform := uint32(gl.GetAttribLocation(shaderProgram, gl.Str("normal\x00")))
-	colorsUniform := uint32(gl.GetAttribLocation(shaderProgram, gl.Str("colour\x00")))
+	verticesUniform := uint32(terrain.Device.GetAttribLocation(shaderProgram, "position"))
+	normalsUniform := uint32(terrain.Device.GetAttribLocation(shaderProgram, "normal"))
+	colorsUniform := uint32(terrain.Device.GetAttribLocation(shaderProgram, "colour"))
 
 
 	// Describe our vertices array to OpenGL (it can't guess its format automatically)
-	gl.EnableVertexAttribArray(verticesUniform)
-	gl.BindBuffer(gl.ARRAY_BUFFER, terrain.VBOVertices)
-	gl.VertexAttribPointer(
+	terrain.Device.EnableVertexAttribArray(verticesUniform)
+	terrain.Device.BindBuffer(gl.ARRAY_BUFFER, terrain.VBOVertices)
+	terrain.Device.VertexAttribPointer(
 		verticesUniform, // attribute index
 		3,               // number of elements per vertex, here (x,y,z)
 		gl.FLOAT,        // the type of each element
 		false,           // take our values as-is
 		0,               // no extra data between each position
-		nil,             // offset of first element
+		0,               // offset of first element
 	)
 
-	gl.EnableVertexAttribArray(normalsUniform)
-	gl.BindBuffer(gl.ARRAY_BUFFER, terrain.VBONormals)
-	gl.VertexAttribPointer(
+	terrain.Device.EnableVertexAttribArray(normalsUniform)
+	terrain.Device.BindBuffer(gl.ARRAY_BUFFER, terrain.VBONormals)
+	terrain.Device.VertexAttribPointer(
 		normalsUniform, 	// attribute
 		3,                  // number of elements per vertex, here (x,y,z)
 		gl.FLOAT,           // the type of each element
 		false,           	// take our values as-is
 		0,                  // no extra data between each position
-		nil,                // offset of first element
+		0,                  // offset of first element
 	)
 
-	gl.EnableVertexAttribArray(colorsUniform)
-	gl.BindBuffer(gl.ARRAY_BUFFER, terrain.VBOColors)
-	gl.VertexAttribPointer(
+	terrain.Device.EnableVertexAttribArray(colorsUniform)
+	terrain.Device.BindBuffer(gl.ARRAY_BUFFER, terrain.VBOColors)
+	terrain.Device.VertexAttribPointer(
 		colorsUniform, 	// attribute
 		3,                  // number of elements per vertex, here (x,y,z)
 		gl.FLOAT,           // the type of each element
 		false,           	// take our values as-is
 		0,                  // no extra data between each position
-		nil,                // offset of first element
+		0,                  // offset of first element
 	)
 
-	size := int32(len(terrain.Indices))
-
-	gl.PointSize(3.0)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, terrain.VBOIndices);
-	gl.GetBufferParameteriv(gl.ELEMENT_ARRAY_BUFFER, gl.BUFFER_SIZE, &size);
+	terrain.Device.PointSize(3.0)
+	terrain.Device.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, terrain.VBOIndices);
 
 	// Enable this line to show model in wireframe
 	switch terrain.DrawMode {
-	case 1:
-		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-	case 2:
-		gl.DrawArrays(gl.POINTS, 0, int32(len(terrain.Vertices)))
-//		gl.DrawElements(gl.POINTS, int32(len(terrain.Indices)), gl.UNSIGNED_SHORT, nil)
+	case DRAW_LINES:
+		terrain.Device.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	case DRAW_POINTS:
+		terrain.Device.DrawArrays(gl.POINTS, 0, int32(len(terrain.Vertices)))
+//		terrain.Device.DrawElements(gl.POINTS, int32(len(terrain.Indices)), gl.UNSIGNED_SHORT, 0)
 		return
 	default:
-		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+		terrain.Device.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
 	}
 
 	var location int = 0
 	/* Draw the triangle strips */
 	for i := uint32(0); i < terrain.XSize - 1; i++ {
 		location = SizeOfUint16 * int(i * terrain.ZSize * 2)
-		gl.DrawElements(gl.TRIANGLE_STRIP, int32(terrain.ZSize * 2), gl.UNSIGNED_SHORT, gl.PtrOffset(location))
+		terrain.Device.DrawElements(gl.TRIANGLE_STRIP, int32(terrain.ZSize * 2), gl.UNSIGNED_SHORT, location)
 	}
 
-//	gl.DrawElements(
+//	terrain.Device.DrawElements(
 //		gl.TRIANGLE_STRIP,
 //		len(terrain.Indices),
 //		gl.UNSIGNED_SHORT,
@@ -209,91 +257,102 @@ func (terrain *Terrain) DrawObject(shaderProgram uint32) {
 
 
 /* Define the terrian heights */
-/* Uses code adapted from OpenGL Shading Language Cookbook: Chapter 8 */
-func (terrain *Terrain) CalculateNoise(freq, scale float32) {
-	//	Create the array to store the noise values
-	//	The size is the number of vertices * number of octaves
-	terrain.Noise = []float32{}
-	for i := uint32(0); i < (terrain.XSize * terrain.ZSize * terrain.PerlinOctaves); i++ {
-		terrain.Noise = append(terrain.Noise, 0)
+/* Uses the HeightSource or OpenSimplex noise when there is no HeightSource */
+func (terrain *Terrain) CalculateNoise() {
+	source := terrain.HeightSource
+	if source == nil {
+		source = generation.NewSimplexSource(terrain.Seed, terrain.NoiseSettings)
 	}
 
-	xFactor := 1. / float32(terrain.XSize - 1)
-	zFactor := 1. / float32(terrain.ZSize - 1)
-
-	noiseGenerator := opensimplex.NewWithSeed(terrain.Seed)
-
-	for row := uint32(0); row < terrain.ZSize; row++ {
-		for col := uint32(0); col < terrain.XSize; col++ {
-			x := xFactor * float32(col)
-			z := zFactor * float32(row)
-			sum := float32(0.0);
-			current_scale := scale;
-			current_freq := freq;
-
-			// Compute the sum for each octave
-			for oct := uint32(0); oct < 4; oct++ {
-				p := noiseGenerator.Eval2(float64(x) * float64(current_freq), float64(z) * float64(current_freq))
-//				p := noiseGenerator.Eval3(float64(x) * float64(current_freq), float64(z) * float64(current_freq), float64(oct) * float64(current_freq))
-//				p := noiseGenerator.Eval4(float64(x) * float64(current_freq), float64(z) * float64(current_freq), float64(oct) * float64(current_freq), float64(current_freq))
-				val := float32(p) / current_scale
-				sum += val;
-				result := (sum + 1.0) / 2.0
-
-				// Store the noise value in our noise array
-				terrain.Noise[(row * terrain.XSize + col) * 4 + oct] = result
-
-				// Move to the next frequency and scale
-				current_freq *= 2.0
-				current_scale *= scale
-			}
-		}
+	// One height per vertex
+	terrain.Noise = source.Generate(terrain.XSize, terrain.ZSize)
+}
+
+/* Erodes the terrain heights (if there are ErosionSettings) */
+/* This has to run after CalculateNoise and before the vertices are created */
+/* (cellWidth is the distance between two vertices, in world units) */
+func (terrain *Terrain) Erode(cellWidth float32) {
+	if terrain.ErosionSettings == nil {
+		return
 	}
+
+	settings := *terrain.ErosionSettings
+
+	// The heights are between 0 and 1 and get multiplied by the HeightScale,
+	// so one cell is (cellWidth / HeightScale) height units wide
+	if settings.Thermal.CellSize == 0 && terrain.HeightScale != 0 {
+		settings.Thermal.CellSize = cellWidth / terrain.HeightScale
+	}
+
+	erosion := generation.NewErosion(terrain.Noise, terrain.XSize, terrain.ZSize)
+	erosion.Erode(settings)
+
+	terrain.SedimentMap = erosion.Sediment
+	terrain.FlowMap = erosion.Flow
 }
 
 //	Define the vertex array that specifies the terrain
 //	(x, y) specifies the pixel dimensions of the heightfield (x * y) vertices
 //	(xs, ys) specifies the size of the heightfield region
 func (terrain *Terrain) CreateTerrain(xp, zp uint32, xs, zs float32) {
-	terrain.XSize = xp
-	terrain.ZSize = zp
+	terrain.BuildTerrain(xp, zp, xs, zs)
+	terrain.CreateObject()
+}
+
+//	Same as CreateTerrain but without creating the buffer objects,
+//	so it can run outside of the OpenGL thread
+func (terrain *Terrain) BuildTerrain(xp, zp uint32, xs, zs float32) {
 	width := xs
 	height := zs
 
+	/* Scale heights in relation to the terrain size */
+	terrain.HeightScale = xs;
+
+	/* Define starting (x,z) positions and the step changes */
+	xpos_step := width / float32(xp);
+	zpos_step := height / float32(zp);
+
+	terrain.buildGrid(xp, zp, mgl32.Vec2{ -width / 2.0, -height / 2.0 }, mgl32.Vec2{ xpos_step, zpos_step })
+}
+
+// buildGrid creates (xp * zp) vertices from the first position and the distance between two of them,
+// with the heights multiplied by the HeightScale (the chunks use it to put their edges in exact places)
+func (terrain *Terrain) buildGrid(xp, zp uint32, start, step mgl32.Vec2) {
+	terrain.XSize = xp
+	terrain.ZSize = zp
+	terrain.Spacing = step
+
 	/* Create array of vertices */
 	numVertices := terrain.XSize * terrain.ZSize;
 	terrain.Vertices = make([]mgl32.Vec3, numVertices);
 	terrain.Colors   = make([]mgl32.Vec3, numVertices);
 	terrain.Normals  = make([]mgl32.Vec3, numVertices);
 
-	/* Scale heights in relation to the terrain size */
-	terrain.HeightScale = xs;
+	xpos := start.X();
+	xpos_step := step.X();
+	zpos_step := step.Y();
+	zpos_start := start.Y();
 
 	/* First calculate the noise array which we'll use for our vertex height values */
-	terrain.CalculateNoise(terrain.Frequency, terrain.NoiseScale)
+	terrain.CalculateNoise()
+	terrain.Erode(xpos_step)
 
 //	if wrapper.DEBUG {
 //		// Debug code to check that noise values are sensible
-//		for i := uint32(0); i < (terrain.XSize * terrain.ZSize * terrain.PerlinOctaves); i++ {
+//		for i := uint32(0); i < (terrain.XSize * terrain.ZSize); i++ {
 //			log.Printf("\n noise[%d] = %f", i, terrain.Noise[i]);
 //		}
 //	}
 
-	/* Define starting (x,z) positions and the step changes */
-	xpos := -width / 2.0;
-	xpos_step := width / float32(xp);
-	zpos_step := height / float32(zp);
-	zpos_start := -height / 2.0;
-
 	/* Define the vertex positions and the initial normals for a flat surface */
 	for x := uint32(0); x < terrain.XSize; x++ {
 		zpos := zpos_start;
 		for z := uint32(0); z < terrain.ZSize; z++ {
-			height := terrain.Noise[(x * terrain.ZSize + z) * 4 + 3]
-			terrain.Vertices[x * terrain.XSize + z]	= mgl32.Vec3{ xpos, (height - 0.5) * terrain.HeightScale, zpos }
-			terrain.Normals[x * terrain.XSize + z]	= mgl32.Vec3{ 0, 1.0, 0 } // Normals for a flat surface
+			height := terrain.Noise[x * terrain.ZSize + z]
+			terrain.Vertices[x * terrain.ZSize + z]	= mgl32.Vec3{ xpos, (height - 0.5) * terrain.HeightScale, zpos }
+			terrain.Normals[x * terrain.ZSize + z]	= mgl32.Vec3{ 0, 1.0, 0 } // Normals for a flat surface
 
-			terrain.Colors[x * terrain.XSize + z]	= mgl32.Vec3{
+			terrain.Colors[x * terrain.ZSize + z]	= mgl32.Vec3{
 				((1.0 * height) / 1.0),
 				((1.0 * height) / 1.0),
 				((1.0 * height) / 1.0),
@@ -304,7 +363,57 @@ func (terrain *Terrain) CreateTerrain(xp, zp uint32, xs, zs float32) {
 		xpos += xpos_step;
 	}
 
-	/* Define vertices for triangle strips */
+	terrain.CreateIndices()
+	terrain.CalculateNormals()
+	terrain.ApplyBiomes()
+}
+
+//	Colours every vertex with the biome rules (if there are any),
+//	using the height, the slope of the normal and the moisture
+func (terrain *Terrain) ApplyBiomes() {
+	if terrain.Biomes == nil {
+		return
+	}
+
+	// The first vertex counted in vertices from the world origin, so the terrains that share an edge
+	// (like the chunks) get the same moisture on it. The rows go along x, so it can be read as [x * ZSize + z]
+	spacing := float64(terrain.Spacing.X())
+	var startX, startZ int64
+	if spacing > 0 && len(terrain.Vertices) > 0 {
+		startX = int64(math.Floor(float64(terrain.Offset.X() + terrain.Vertices[0].X()) / spacing + 0.5))
+		startZ = int64(math.Floor(float64(terrain.Offset.Y() + terrain.Vertices[0].Z()) / spacing + 0.5))
+	}
+
+	moisture := terrain.Biomes.Moisture(startZ, startX, terrain.ZSize, terrain.XSize, spacing)
+	terrain.SplatWeights = make([][]float32, terrain.XSize * terrain.ZSize)
+
+	for v := range terrain.SplatWeights {
+		slope := generation.SlopeFromNormal(terrain.Normals[v])
+		weights := terrain.Biomes.Weights(terrain.Noise[v], slope, moisture[v])
+
+		terrain.SplatWeights[v] = weights
+		terrain.Colors[v] = terrain.Biomes.Color(weights)
+	}
+}
+
+//	Returns the splat map of the terrain, the first 4 biome rules go in the RGBA channels
+//	(the rows of the image go along x, like the vertices)
+func (terrain *Terrain) SplatMap() *image.RGBA {
+	if len(terrain.SplatWeights) != int(terrain.XSize * terrain.ZSize) {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+
+	return generation.SplatMap(terrain.SplatWeights, int(terrain.ZSize), int(terrain.XSize))
+}
+
+//	Saves the splat map of the terrain as a PNG file
+func (terrain *Terrain) ExportSplatMap(path string) error {
+	return generation.SavePNG(path, terrain.SplatMap())
+}
+
+//	Define vertices for triangle strips
+func (terrain *Terrain) CreateIndices() {
+	terrain.Indices = make([]uint16, 0, (terrain.XSize - 1) * terrain.ZSize * 2)
 	for x := uint32(0); x < terrain.XSize - 1; x++ {
 		top    := uint16(x * terrain.ZSize);
 		bottom := uint16(top + uint16(terrain.ZSize));
@@ -314,9 +423,6 @@ func (terrain *Terrain) CreateTerrain(xp, zp uint32, xs, zs float32) {
 			bottom ++
 		}
 	}
-
-	terrain.CalculateNormals()
-	terrain.CreateObject()
 }
 
 //	Calculate normals by using cross products along the triangle strips
@@ -361,19 +467,27 @@ func (terrain *Terrain) CalculateNormals() {
 }
 
 func (terrain *Terrain) ResetModel() {
-	terrain.Model = mgl32.Ident4()
+	terrain.Node.Reset()
 }
 
 func (terrain *Terrain) Translate(Tx, Ty, Tz float32) {
-	terrain.Model = terrain.Model.Mul4(mgl32.Translate3D(Tx, Ty, Tz))
+	terrain.Node.Translate(Tx, Ty, Tz)
 }
 
 func (terrain *Terrain) Scale(scaleX, scaleY, scaleZ float32) {
-	terrain.Model = terrain.Model.Mul4(mgl32.Scale3D(scaleX, scaleY, scaleZ))
+	terrain.Node.Scale(scaleX, scaleY, scaleZ)
+}
+
+func (terrain *Terrain) RotateRadians(radians float32, axis mgl32.Vec3) {
+	terrain.Node.RotateRadians(radians, axis)
+}
+
+func (terrain *Terrain) RotateDegrees(degrees float32, axis mgl32.Vec3) {
+	terrain.Node.RotateDegrees(degrees, axis)
 }
 
-func (terrain *Terrain) Rotate(angle float32, axis mgl32.Vec3) {
-	terrain.Model = terrain.Model.Mul4(mgl32.HomogRotate3D(angle, axis))
+func (terrain *Terrain) GetNode () *SceneNode {
+	return terrain.Node
 }
 
 func (terrain *Terrain) GetDrawMode () DrawMode {
@@ -394,6 +508,6 @@ func (terrain *Terrain) String () string {
     -------------------------------------
     %s
     -------------------------------------
-    `, terrain.Name, terrain.Model)
+    `, terrain.Name, terrain.Node.World())
 }
 
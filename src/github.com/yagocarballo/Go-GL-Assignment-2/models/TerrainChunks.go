@@ -0,0 +1,494 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/generation"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+//
+// TerrainChunk
+// One tile of the chunked terrain
+//
+type TerrainChunk struct {
+	X, Z    int32    // Position of the chunk, in chunks
+	LOD     uint32   // Level of detail (0 is the most detailed)
+	Terrain *Terrain // The tile, its vertices are centred on the chunk
+}
+
+type chunkKey struct {
+	x, z int32
+}
+
+//
+// TerrainChunks
+// An endless terrain made of tiles that are generated around the camera.
+// The tiles are generated in background goroutines and the buffers are
+// created in the OpenGL thread when calling Update
+//
+type TerrainChunks struct {
+	Name            string
+
+	Source          generation.RegionSource // The same source for every chunk, so the edges match
+	ColorTone       mgl32.Vec4
+	Biomes          *generation.Biomes // If not nil, the chunks are coloured with the biome rules
+
+	ChunkSize       float32 // Width of a chunk, in world units
+	Resolution      uint32  // Vertices per side on LOD 0 (2^n + 1 so every LOD shares the corners)
+	HeightScale     float32 // Multiplies the heights of every chunk
+	NoiseSpan       float64 // Width of a chunk, in noise units
+	SkirtDepth      float32 // How far down the skirts go to hide the cracks between LODs
+
+	ViewDistance    int32   // Chunks loaded around the camera
+	EvictDistance   int32   // Chunks further than this are deleted
+	LODDistance     int32   // Chunks between two levels of detail
+	MaxLOD          uint32  // The lowest level of detail
+	UploadsPerFrame int     // Chunks sent to the GPU on every Update
+
+	Node            *SceneNode // The chunks are children of this node
+	DrawMode        DrawMode
+	Device          wrapper.Device // The device of the chunks
+
+	chunks          map[chunkKey]*TerrainChunk
+	pending         map[chunkKey]uint32
+	results         chan *TerrainChunk
+	workers         chan struct{}
+	done            chan struct{} // Closed by DeleteObjects, so the pending chunks stop
+}
+
+//
+// NewTerrainChunks
+// Creates a chunked terrain made with fractal OpenSimplex noise
+//
+// @param device (wrapper.Device) the device that uploads and draws the chunks
+// @param seed (int64) the seed of the noise
+// @param settings (generation.NoiseSettings) the fractal noise settings
+// @param colorTone (mgl32.Vec4) the tone of the terrain
+//
+// @return chunks (*TerrainChunks) a pointer to the chunked terrain
+//
+func NewTerrainChunks(device wrapper.Device, seed int64, settings generation.NoiseSettings, colorTone mgl32.Vec4) *TerrainChunks {
+	return NewTerrainChunksWithSource(device, generation.NewSimplexSource(seed, settings), colorTone)
+}
+
+func NewTerrainChunksWithSource(device wrapper.Device, source generation.RegionSource, colorTone mgl32.Vec4) *TerrainChunks {
+	viewDistance := int32(4)
+
+	return &TerrainChunks{
+		"Terrain Chunks",	// Name
+
+		source,				// Source
+		colorTone,			// ColorTone
+		nil,				// Biomes
+
+		50.0,				// ChunkSize
+		65,					// Resolution
+		350.0,				// HeightScale
+		50.0 / 350.0,		// NoiseSpan: The same noise per unit as the 350x350 terrain
+		2.0,				// SkirtDepth
+
+		viewDistance,		// ViewDistance
+		viewDistance + 2,	// EvictDistance
+		2,					// LODDistance
+		3,					// MaxLOD
+		2,					// UploadsPerFrame
+
+		NewSceneNode("Terrain Chunks"),	// Node
+		DRAW_POLYGONS,		// DrawMode
+		device,				// Device
+
+		make(map[chunkKey]*TerrainChunk),
+		make(map[chunkKey]uint32),
+		make(chan *TerrainChunk, (2 * viewDistance + 1) * (2 * viewDistance + 1)),
+		make(chan struct{}, runtime.NumCPU()),
+		make(chan struct{}),
+	}
+}
+
+//
+// Update
+// Requests the chunks around the camera, uploads the generated ones and deletes the far ones.
+// (Has to be called from the OpenGL thread)
+//
+// @param position (mgl32.Vec3) the camera position in world space
+//
+func (chunks *TerrainChunks) Update(position mgl32.Vec3) {
+	local := chunks.Node.World().Inv().Mul4x1(position.Vec4(1))
+	centerX := int32(math.Floor(float64(local.X() / chunks.ChunkSize)))
+	centerZ := int32(math.Floor(float64(local.Z() / chunks.ChunkSize)))
+
+	// Uploads the chunks that finished generating
+	for uploads := 0; uploads < chunks.UploadsPerFrame; uploads++ {
+		var chunk *TerrainChunk
+		select {
+		case chunk = <-chunks.results:
+		default:
+		}
+
+		if chunk == nil {
+			break
+		}
+
+		// The chunk might want another level of detail by now (and it's already requested)
+		key := chunkKey{ chunk.X, chunk.Z }
+		if lod, ok := chunks.pending[key]; !ok || lod != chunk.LOD {
+			continue
+		}
+		delete(chunks.pending, key)
+
+		// The camera might have moved away while it was generating
+		if chunkDistance(key, centerX, centerZ) > chunks.EvictDistance {
+			continue
+		}
+
+		chunk.Terrain.CreateObject()
+
+		// Moves the chunk to its place under the chunks node
+		chunk.Terrain.Node.Reset()
+		chunk.Terrain.Node.Translate((float32(key.x) + 0.5) * chunks.ChunkSize, 0, (float32(key.z) + 0.5) * chunks.ChunkSize)
+		chunks.Node.AddChild(chunk.Terrain.Node)
+
+		// The old chunk stays visible until the new level of detail is ready
+		if old, ok := chunks.chunks[key]; ok {
+			old.Terrain.DeleteObject()
+			chunks.Node.RemoveChild(old.Terrain.Node)
+		}
+		chunks.chunks[key] = chunk
+	}
+
+	// Requests the missing chunks, from the closest to the furthest
+	for ring := int32(0); ring <= chunks.ViewDistance; ring++ {
+		for x := centerX - ring; x <= centerX + ring; x++ {
+			for z := centerZ - ring; z <= centerZ + ring; z++ {
+				key := chunkKey{ x, z }
+				if chunkDistance(key, centerX, centerZ) != ring {
+					continue
+				}
+
+				lod := chunks.lodFor(ring)
+				if chunk, ok := chunks.chunks[key]; ok && chunk.LOD == lod {
+					continue
+				}
+
+				if pending, ok := chunks.pending[key]; ok && pending == lod {
+					continue
+				}
+
+				chunks.request(key, lod)
+			}
+		}
+	}
+
+	// Deletes the chunks that are too far
+	for key, chunk := range chunks.chunks {
+		if chunkDistance(key, centerX, centerZ) > chunks.EvictDistance {
+			chunk.Terrain.DeleteObject()
+			chunks.Node.RemoveChild(chunk.Terrain.Node)
+			delete(chunks.chunks, key)
+		}
+	}
+}
+
+//
+// BuildChunk
+// Generates the vertices of a chunk (doesn't use OpenGL, so it can run in any goroutine)
+//
+// @param x (int32) the x position of the chunk, in chunks
+// @param z (int32) the z position of the chunk, in chunks
+// @param lod (uint32) the level of detail
+//
+// @return chunk (*TerrainChunk) the generated chunk
+//
+func (chunks *TerrainChunks) BuildChunk(x, z int32, lod uint32) *TerrainChunk {
+	cells := (chunks.Resolution - 1) >> lod
+	if cells < 1 {
+		cells = 1
+	}
+
+	// The terrain reads its heights as [x * ZSize + z], so the noise rows go along x.
+	// The region starts one sample before the chunk: the extra ring is cut after calculating the normals
+	step := chunks.NoiseSpan / float64(cells)
+	region := generation.NewRegion(chunks.Source, int64(z) * int64(cells) - 1, int64(x) * int64(cells) - 1, step)
+
+	terrain := NewTerrainWithSource(chunks.Device, region, chunks.ColorTone)
+	terrain.Name = fmt.Sprintf("%s [%d, %d]", chunks.Name, x, z)
+	terrain.Node.Name = terrain.Name
+	terrain.HeightScale = chunks.HeightScale
+	terrain.Biomes = chunks.Biomes
+	terrain.Offset = mgl32.Vec2{ (float32(x) + 0.5) * chunks.ChunkSize, (float32(z) + 0.5) * chunks.ChunkSize }
+
+	// The first and the last vertices sit on the edges of the chunk, so the neighbours share them.
+	// With the ring around the chunk the edge vertices get the triangles of the neighbours too, so their normals match
+	cellSize := chunks.ChunkSize / float32(cells)
+	start := -chunks.ChunkSize / 2.0 - cellSize
+	terrain.buildGrid(cells + 3, cells + 3, mgl32.Vec2{ start, start }, mgl32.Vec2{ cellSize, cellSize })
+	terrain.Crop(1)
+	terrain.AddSkirts(chunks.SkirtDepth)
+
+	return &TerrainChunk{ x, z, lod, terrain }
+}
+
+//
+// DrawObject
+// Draws every uploaded chunk
+//
+// @param shaderProgram (uint32) the shader program to draw with
+//
+func (chunks *TerrainChunks) DrawObject(shaderProgram uint32) {
+	for _, chunk := range chunks.chunks {
+		chunk.Terrain.DrawMode = chunks.DrawMode
+		chunk.Terrain.DrawObject(shaderProgram)
+	}
+}
+
+//
+// DeleteObjects
+// Deletes the buffers of every chunk and stops the chunks that are still generating
+// (the next Update requests them again)
+//
+func (chunks *TerrainChunks) DeleteObjects() {
+	for key, chunk := range chunks.chunks {
+		chunk.Terrain.DeleteObject()
+		chunks.Node.RemoveChild(chunk.Terrain.Node)
+		delete(chunks.chunks, key)
+	}
+
+	close(chunks.done)
+	chunks.done = make(chan struct{})
+	chunks.pending = make(map[chunkKey]uint32)
+
+	// Drops the chunks that finished but were not uploaded
+	for len(chunks.results) > 0 {
+		<-chunks.results
+	}
+}
+
+//
+// Chunks
+// Returns the chunks that are currently uploaded
+//
+func (chunks *TerrainChunks) Chunks() []*TerrainChunk {
+	list := make([]*TerrainChunk, 0, len(chunks.chunks))
+	for _, chunk := range chunks.chunks {
+		list = append(list, chunk)
+	}
+
+	return list
+}
+
+// request generates a chunk in the background (the workers channel limits how many run at once).
+// The goroutine gives up if DeleteObjects runs before it gets a worker or sends the chunk
+func (chunks *TerrainChunks) request(key chunkKey, lod uint32) {
+	chunks.pending[key] = lod
+	done := chunks.done
+
+	go func() {
+		select {
+		case chunks.workers <- struct{}{}:
+		case <-done:
+			return
+		}
+
+		chunk := chunks.BuildChunk(key.x, key.z, lod)
+		<-chunks.workers
+
+		select {
+		case chunks.results <- chunk:
+		case <-done:
+		}
+	}()
+}
+
+// lodFor returns the level of detail for a chunk that is (distance) chunks away from the camera
+func (chunks *TerrainChunks) lodFor(distance int32) uint32 {
+	if chunks.LODDistance <= 0 {
+		return 0
+	}
+
+	lod := uint32(distance / chunks.LODDistance)
+	if lod > chunks.MaxLOD {
+		lod = chunks.MaxLOD
+	}
+
+	return lod
+}
+
+// chunkDistance is the number of rings between the chunk and the centre
+func chunkDistance(key chunkKey, centerX, centerZ int32) int32 {
+	dx, dz := key.x - centerX, key.z - centerZ
+	if dx < 0 {
+		dx = -dx
+	}
+	if dz < 0 {
+		dz = -dz
+	}
+
+	if dx > dz {
+		return dx
+	}
+	return dz
+}
+
+//
+// AddSkirts
+// Adds a ring of vertices around the terrain that goes down (depth) units,
+// so the cracks between terrains with different resolutions are not visible
+//
+// @param depth (float32) how far down the skirt goes
+//
+func (terrain *Terrain) AddSkirts(depth float32) {
+	if depth <= 0 {
+		return
+	}
+
+	xSize, zSize := terrain.XSize + 2, terrain.ZSize + 2
+	vertices := make([]mgl32.Vec3, xSize * zSize)
+	normals := make([]mgl32.Vec3, xSize * zSize)
+	colors := make([]mgl32.Vec3, xSize * zSize)
+
+	var splatWeights [][]float32
+	if len(terrain.SplatWeights) > 0 {
+		splatWeights = make([][]float32, xSize * zSize)
+	}
+
+	for x := uint32(0); x < xSize; x++ {
+		for z := uint32(0); z < zSize; z++ {
+			// The skirt uses the position of the closest edge vertex
+			source := clampIndex(x, terrain.XSize) * terrain.ZSize + clampIndex(z, terrain.ZSize)
+
+			vertex := terrain.Vertices[source]
+			if x == 0 || z == 0 || x == xSize - 1 || z == zSize - 1 {
+				vertex[1] -= depth
+			}
+
+			vertices[x * zSize + z] = vertex
+			normals[x * zSize + z] = terrain.Normals[source]
+			colors[x * zSize + z] = terrain.Colors[source]
+			if splatWeights != nil {
+				splatWeights[x * zSize + z] = terrain.SplatWeights[source]
+			}
+		}
+	}
+
+	terrain.XSize, terrain.ZSize = xSize, zSize
+	terrain.Vertices, terrain.Normals, terrain.Colors = vertices, normals, colors
+	terrain.SplatWeights = splatWeights
+	terrain.CreateIndices()
+}
+
+//
+// Crop
+// Removes (ring) vertices from every side of the terrain, keeping the normals and colours they gave to the rest
+//
+// @param ring (uint32) the number of vertices removed from each side
+//
+func (terrain *Terrain) Crop(ring uint32) {
+	if ring == 0 || terrain.XSize <= 2 * ring || terrain.ZSize <= 2 * ring {
+		return
+	}
+
+	xSize, zSize := terrain.XSize - 2 * ring, terrain.ZSize - 2 * ring
+	vertices := make([]mgl32.Vec3, 0, xSize * zSize)
+	normals := make([]mgl32.Vec3, 0, xSize * zSize)
+	colors := make([]mgl32.Vec3, 0, xSize * zSize)
+
+	var splatWeights [][]float32
+	if len(terrain.SplatWeights) > 0 {
+		splatWeights = make([][]float32, 0, xSize * zSize)
+	}
+
+	// The maps of the heights are only there if they were made
+	cropMap := func(values []float32) []float32 {
+		if len(values) != len(terrain.Vertices) {
+			return values
+		}
+
+		cropped := make([]float32, 0, xSize * zSize)
+		for x := ring; x < ring + xSize; x++ {
+			cropped = append(cropped, values[x * terrain.ZSize + ring:x * terrain.ZSize + ring + zSize]...)
+		}
+		return cropped
+	}
+
+	for x := ring; x < ring + xSize; x++ {
+		for z := ring; z < ring + zSize; z++ {
+			source := x * terrain.ZSize + z
+
+			vertices = append(vertices, terrain.Vertices[source])
+			normals = append(normals, terrain.Normals[source])
+			colors = append(colors, terrain.Colors[source])
+			if splatWeights != nil {
+				splatWeights = append(splatWeights, terrain.SplatWeights[source])
+			}
+		}
+	}
+
+	terrain.Noise, terrain.SedimentMap, terrain.FlowMap = cropMap(terrain.Noise), cropMap(terrain.SedimentMap), cropMap(terrain.FlowMap)
+	terrain.XSize, terrain.ZSize = xSize, zSize
+	terrain.Vertices, terrain.Normals, terrain.Colors = vertices, normals, colors
+	terrain.SplatWeights = splatWeights
+	terrain.CreateIndices()
+}
+
+// clampIndex maps an index of the grid with skirts to the grid without them
+func clampIndex(index, size uint32) uint32 {
+	if index == 0 {
+		return 0
+	}
+
+	if index - 1 >= size {
+		return size - 1
+	}
+
+	return index - 1
+}
+
+func (chunks *TerrainChunks) ResetModel() {
+	chunks.Node.Reset()
+}
+
+func (chunks *TerrainChunks) Translate(Tx, Ty, Tz float32) {
+	chunks.Node.Translate(Tx, Ty, Tz)
+}
+
+func (chunks *TerrainChunks) Scale(scaleX, scaleY, scaleZ float32) {
+	chunks.Node.Scale(scaleX, scaleY, scaleZ)
+}
+
+func (chunks *TerrainChunks) RotateRadians(radians float32, axis mgl32.Vec3) {
+	chunks.Node.RotateRadians(radians, axis)
+}
+
+func (chunks *TerrainChunks) RotateDegrees(degrees float32, axis mgl32.Vec3) {
+	chunks.Node.RotateDegrees(degrees, axis)
+}
+
+func (chunks *TerrainChunks) GetNode () *SceneNode {
+	return chunks.Node
+}
+
+func (chunks *TerrainChunks) GetDrawMode () DrawMode {
+	return chunks.DrawMode
+}
+
+func (chunks *TerrainChunks) SetDrawMode (drawMode DrawMode) {
+	chunks.DrawMode = drawMode
+}
+
+func (chunks *TerrainChunks) GetName () string {
+	return chunks.Name
+}
+
+func (chunks *TerrainChunks) String () string {
+	return fmt.Sprintf(`
+            Terrain Chunks --> %s (%d chunks, %d pending)
+    -------------------------------------
+    %s
+    -------------------------------------
+    `, chunks.Name, len(chunks.chunks), len(chunks.pending), chunks.Node.World())
+}
@@ -0,0 +1,291 @@
+package models
+
+import (
+	"math"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/generation"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+// testChunks returns small chunks without skirts, so the vertices are only the grid
+func testChunks() *TerrainChunks {
+	chunks := NewTerrainChunks(wrapper.NewFakeDevice(), 999999, generation.NewNoiseSettings(4.0, 5.0), mgl32.Vec4{ 1, 1, 1, 1 })
+	chunks.Resolution = 17
+	chunks.SkirtDepth = 0
+
+	return chunks
+}
+
+// edgeIndices returns the vertices on one side of a chunk
+// (side 0 is the lowest x, 1 the highest x, 2 the lowest z and 3 the highest z)
+func edgeIndices(terrain *Terrain, side int) []uint32 {
+	indices := []uint32{}
+	for i := uint32(0); i < terrain.XSize; i++ {
+		switch side {
+		case 0: indices = append(indices, i)
+		case 1: indices = append(indices, (terrain.XSize - 1) * terrain.ZSize + i)
+		case 2: indices = append(indices, i * terrain.ZSize)
+		case 3: indices = append(indices, i * terrain.ZSize + terrain.ZSize - 1)
+		}
+	}
+
+	return indices
+}
+
+// edge returns the world positions of the vertices on one side of a chunk
+func edge(chunks *TerrainChunks, chunk *TerrainChunk, side int) []mgl32.Vec3 {
+	offset := mgl32.Vec3{ (float32(chunk.X) + 0.5) * chunks.ChunkSize, 0, (float32(chunk.Z) + 0.5) * chunks.ChunkSize }
+	positions := []mgl32.Vec3{}
+	for _, index := range edgeIndices(chunk.Terrain, side) {
+		positions = append(positions, chunk.Terrain.Vertices[index].Add(offset))
+	}
+
+	return positions
+}
+
+// edgeNormals returns the normals of the vertices on one side of a chunk
+func edgeNormals(chunk *TerrainChunk, side int) []mgl32.Vec3 {
+	normals := []mgl32.Vec3{}
+	for _, index := range edgeIndices(chunk.Terrain, side) {
+		normals = append(normals, chunk.Terrain.Normals[index])
+	}
+
+	return normals
+}
+
+func closeTo(a, b mgl32.Vec3) bool {
+	return a.Sub(b).Len() < 1e-3
+}
+
+func TestChunkEdgesMatch(t *testing.T) {
+	chunks := testChunks()
+	center := chunks.BuildChunk(0, 0, 0)
+
+	neighbours := []struct {
+		chunk            *TerrainChunk
+		ownSide, theirSide int
+	}{
+		{ chunks.BuildChunk(1, 0, 0), 1, 0 },
+		{ chunks.BuildChunk(-1, 0, 0), 0, 1 },
+		{ chunks.BuildChunk(0, 1, 0), 3, 2 },
+		{ chunks.BuildChunk(0, -1, 0), 2, 3 },
+	}
+
+	for _, neighbour := range neighbours {
+		own := edge(chunks, center, neighbour.ownSide)
+		their := edge(chunks, neighbour.chunk, neighbour.theirSide)
+
+		for i := range own {
+			if !closeTo(own[i], their[i]) {
+				t.Fatalf("chunk [%d, %d], vertex %d: %v doesn't meet %v", neighbour.chunk.X, neighbour.chunk.Z, i, own[i], their[i])
+			}
+		}
+
+		// The light is the same on both sides, so there is no seam
+		ownNormals, theirNormals := edgeNormals(center, neighbour.ownSide), edgeNormals(neighbour.chunk, neighbour.theirSide)
+		for i := range ownNormals {
+			if ownNormals[i].Sub(theirNormals[i]).Len() > 1e-4 {
+				t.Fatalf("chunk [%d, %d], vertex %d: the normal %v doesn't match %v", neighbour.chunk.X, neighbour.chunk.Z, i, ownNormals[i], theirNormals[i])
+			}
+		}
+	}
+
+	// The ring around the chunk is cut, and the normals of the edges lean like the ones inside
+	terrain := center.Terrain
+	if terrain.XSize != 17 || terrain.ZSize != 17 || len(terrain.Noise) != 17 * 17 || len(terrain.Indices) != 16 * 17 * 2 {
+		t.Errorf("expected a 17x17 grid, found %dx%d (%d heights, %d indices)", terrain.XSize, terrain.ZSize, len(terrain.Noise), len(terrain.Indices))
+	}
+	for side := 0; side < 4; side++ {
+		for i, normal := range edgeNormals(center, side) {
+			if math.Abs(float64(normal.Len()) - 1) > 1e-4 || normal.Y() <= 0 {
+				t.Fatalf("side %d, vertex %d: the normal %v doesn't point up", side, i, normal)
+			}
+		}
+	}
+}
+
+func TestChunkEdgesMatchBetweenLODs(t *testing.T) {
+	chunks := testChunks()
+	detailed := chunks.BuildChunk(0, 0, 0)
+
+	for lod := uint32(1); lod <= 3; lod++ {
+		coarse := chunks.BuildChunk(1, 0, lod)
+		own := edge(chunks, detailed, 1)
+		their := edge(chunks, coarse, 0)
+
+		// Every vertex of the coarse edge is also on the detailed edge
+		every := 1 << lod
+		if (len(own) - 1) != (len(their) - 1) * every {
+			t.Fatalf("lod %d: %d vertices can't share an edge with %d", lod, len(their), len(own))
+		}
+
+		for i := range their {
+			if !closeTo(own[i * every], their[i]) {
+				t.Errorf("lod %d, vertex %d: %v doesn't meet %v", lod, i, their[i], own[i * every])
+			}
+		}
+	}
+}
+
+func TestChunkVertexCounts(t *testing.T) {
+	chunks := testChunks()
+	chunks.SkirtDepth = 2.0
+
+	for lod, cells := range []uint32{ 16, 8, 4, 2, 1, 1 } {
+		chunk := chunks.BuildChunk(2, -3, uint32(lod))
+
+		// The grid has (cells + 1) vertices per side and the skirts add one more on each side
+		side := cells + 3
+		if chunk.Terrain.XSize != side || chunk.Terrain.ZSize != side || len(chunk.Terrain.Vertices) != int(side * side) {
+			t.Errorf("lod %d: expected %dx%d vertices, found %dx%d (%d)", lod, side, side, chunk.Terrain.XSize, chunk.Terrain.ZSize, len(chunk.Terrain.Vertices))
+		}
+
+		// The skirt goes down from the edge
+		skirt, border := chunk.Terrain.Vertices[0], chunk.Terrain.Vertices[side + 1]
+		if math.Abs(float64(border.Y() - skirt.Y() - chunks.SkirtDepth)) > 1e-4 {
+			t.Errorf("lod %d: the skirt is %f below the edge", lod, border.Y() - skirt.Y())
+		}
+	}
+}
+
+func TestLODForDistance(t *testing.T) {
+	chunks := testChunks()
+	expected := []uint32{ 0, 0, 1, 1, 2, 2, 3, 3, 3, 3 }
+	for distance, lod := range expected {
+		if found := chunks.lodFor(int32(distance)); found != lod {
+			t.Errorf("distance %d: expected lod %d, found %d", distance, lod, found)
+		}
+	}
+
+	chunks.LODDistance = 0
+	if chunks.lodFor(8) != 0 {
+		t.Error("without a lod distance every chunk should use lod 0")
+	}
+}
+
+// waitFor calls Update until the condition is true (or fails the test after a few seconds)
+func waitFor(t *testing.T, chunks *TerrainChunks, condition func() bool) {
+	deadline := time.Now().Add(10 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the chunks")
+		}
+
+		chunks.Update(mgl32.Vec3{ 0, 0, 0 })
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestUpdateLoadsTheChunksAroundTheCamera(t *testing.T) {
+	chunks := testChunks()
+	chunks.ViewDistance, chunks.EvictDistance = 1, 2
+	chunks.UploadsPerFrame = 9
+
+	waitFor(t, chunks, func() bool { return len(chunks.chunks) == 9 })
+
+	for key, chunk := range chunks.chunks {
+		if chunk.LOD != chunks.lodFor(chunkDistance(key, 0, 0)) {
+			t.Errorf("chunk %v has lod %d", key, chunk.LOD)
+		}
+	}
+	if len(chunks.pending) != 0 {
+		t.Errorf("%d chunks are still pending", len(chunks.pending))
+	}
+
+	chunks.DeleteObjects()
+}
+
+func TestUpdateDropsStaleLODs(t *testing.T) {
+	chunks := testChunks()
+	chunks.ViewDistance = 0
+
+	// The chunk was asked for again with another level of detail while it was generating
+	key := chunkKey{ 0, 0 }
+	chunks.pending[key] = 1
+	chunks.results <- chunks.BuildChunk(0, 0, 2)
+
+	chunks.Update(mgl32.Vec3{ 0, 0, 0 })
+	if _, ok := chunks.chunks[key]; ok {
+		t.Fatal("the chunk with the old level of detail was uploaded")
+	}
+	if _, ok := chunks.pending[key]; !ok {
+		t.Fatal("the chunk is not pending anymore")
+	}
+
+	// A result nobody asked for is dropped too
+	chunks.results <- chunks.BuildChunk(3, 3, 0)
+	chunks.Update(mgl32.Vec3{ 0, 0, 0 })
+	if _, ok := chunks.chunks[chunkKey{ 3, 3 }]; ok {
+		t.Fatal("a chunk that wasn't pending was uploaded")
+	}
+}
+
+func TestDeleteObjectsStopsThePendingChunks(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	chunks := testChunks()
+	chunks.ViewDistance = 3
+
+	// Nothing reads the results, so the goroutines would block on a full channel
+	chunks.results = make(chan *TerrainChunk)
+	chunks.Update(mgl32.Vec3{ 0, 0, 0 })
+	if len(chunks.pending) != 49 {
+		t.Fatalf("expected 49 pending chunks, found %d", len(chunks.pending))
+	}
+
+	chunks.DeleteObjects()
+	if len(chunks.pending) != 0 {
+		t.Fatalf("%d chunks are still pending", len(chunks.pending))
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines are still running", runtime.NumGoroutine() - before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestChunkMoistureIsContinuous(t *testing.T) {
+	chunks := testChunks()
+
+	// The colour only depends on the moisture, so it has to match on the shared edges
+	settings := generation.NewNoiseSettings(4.0, 5.0)
+	chunks.Biomes = generation.NewBiomes()
+	chunks.Biomes.Rules = []generation.BiomeRule{
+		{ Name: "Dry", Color: mgl32.Vec3{ 1, 0, 0 }, MinHeight: -1, MaxHeight: 2, MaxSlope: 90, MaxMoisture: 0.5, Blend: 0.3 },
+		{ Name: "Wet", Color: mgl32.Vec3{ 0, 0, 1 }, MinHeight: -1, MaxHeight: 2, MaxSlope: 90, MinMoisture: 0.5, MaxMoisture: 1, Blend: 0.3 },
+	}
+	chunks.Biomes.MoistureSeed = 3
+	chunks.Biomes.MoistureSettings = &settings
+	chunks.Biomes.MoistureScale = 1.0 / 50.0
+
+	center := chunks.BuildChunk(0, 0, 0)
+	right := chunks.BuildChunk(1, 0, 0)
+	coarse := chunks.BuildChunk(0, 1, 1)
+
+	last := (center.Terrain.XSize - 1) * center.Terrain.ZSize
+	for z := uint32(0); z < center.Terrain.ZSize; z++ {
+		if !closeTo(center.Terrain.Colors[last + z], right.Terrain.Colors[z]) {
+			t.Fatalf("vertex %d: the colour jumps from %v to %v", z, center.Terrain.Colors[last + z], right.Terrain.Colors[z])
+		}
+	}
+
+	for x := uint32(0); x < coarse.Terrain.XSize; x++ {
+		own := center.Terrain.Colors[(x * 2) * center.Terrain.ZSize + center.Terrain.ZSize - 1]
+		if !closeTo(own, coarse.Terrain.Colors[x * coarse.Terrain.ZSize]) {
+			t.Fatalf("lod 1, vertex %d: the colour jumps from %v to %v", x, own, coarse.Terrain.Colors[x * coarse.Terrain.ZSize])
+		}
+	}
+
+	// The chunks are not flat
+	if center.Terrain.Colors[0] == center.Terrain.Colors[len(center.Terrain.Colors) - 1] && center.Terrain.Colors[0] == right.Terrain.Colors[last] {
+		t.Error("the moisture is the same everywhere")
+	}
+}
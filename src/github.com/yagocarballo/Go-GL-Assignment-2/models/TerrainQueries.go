@@ -0,0 +1,343 @@
+package models
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//
+// TerrainHit
+// Where a ray touched the terrain (in world space)
+//
+type TerrainHit struct {
+	Point    mgl32.Vec3
+	Normal   mgl32.Vec3
+	Distance float32 // Distance from the origin of the ray
+}
+
+//
+// HeightAt
+// Returns the height of the ground below (or above) a world position,
+// interpolated inside the triangle that contains it
+//
+// @param x (float32) the x position in world space
+// @param z (float32) the z position in world space
+//
+// @return height (float32) the y position of the ground in world space
+// @return ok (bool) false if the position is outside of the terrain
+//
+func (terrain *Terrain) HeightAt(x, z float32) (float32, bool) {
+	hit, ok := terrain.verticalHit(x, z)
+	return hit.Point.Y(), ok
+}
+
+//
+// NormalAt
+// Returns the normal of the ground at a world position
+//
+// @param x (float32) the x position in world space
+// @param z (float32) the z position in world space
+//
+// @return normal (mgl32.Vec3) the normal in world space
+// @return ok (bool) false if the position is outside of the terrain
+//
+func (terrain *Terrain) NormalAt(x, z float32) (mgl32.Vec3, bool) {
+	hit, ok := terrain.verticalHit(x, z)
+	return hit.Normal, ok
+}
+
+//
+// Raycast
+// Finds the first point where a ray touches the terrain
+//
+// @param origin (mgl32.Vec3) the start of the ray in world space
+// @param direction (mgl32.Vec3) the direction of the ray in world space
+//
+// @return hit (TerrainHit) the point, normal and distance of the hit
+// @return ok (bool) false if the ray doesn't touch the terrain
+//
+func (terrain *Terrain) Raycast(origin, direction mgl32.Vec3) (TerrainHit, bool) {
+	if terrain.XSize < 2 || terrain.ZSize < 2 || direction.Len() == 0 {
+		return TerrainHit{}, false
+	}
+
+	// The ray is moved to the terrain space, so the same t works in both spaces
+	model := terrain.Node.World()
+	inverse := model.Inv()
+	localOrigin := inverse.Mul4x1(origin.Vec4(1)).Vec3()
+	localDirection := inverse.Mul4x1(direction.Vec4(0)).Vec3()
+
+	minimum, maximum := terrain.bounds()
+	start, end, ok := clipRay(localOrigin, localDirection, minimum, maximum)
+	if !ok {
+		return TerrainHit{}, false
+	}
+
+	t, ok := terrain.march(localOrigin, localDirection, start, end)
+	if !ok {
+		return TerrainHit{}, false
+	}
+
+	local := localOrigin.Add(localDirection.Mul(t))
+	_, localNormal, _ := terrain.localSample(local.X(), local.Z())
+
+	// Normals use the inverse transpose, so non uniform scales don't bend them
+	normal := inverse.Transpose().Mul4x1(localNormal.Vec4(0)).Vec3()
+	if normal.Len() > 0 {
+		normal = normal.Normalize()
+	}
+
+	return TerrainHit{
+		model.Mul4x1(local.Vec4(1)).Vec3(),
+		normal,
+		t * direction.Len(),
+	}, true
+}
+
+//
+// PlaceOnGround
+// Moves a model to a position on the ground, optionally tilted to follow the slope.
+// The model is reset, so its scale and rotations have to be applied afterwards.
+// If the model has a parent node, the position is moved to the space of the parent
+//
+// @param object (Model) the model to move
+// @param x (float32) the x position in world space
+// @param z (float32) the z position in world space
+// @param offset (float32) distance to keep above the ground
+// @param alignToNormal (bool) rotates the model so its y axis follows the normal of the ground
+//
+// @return ok (bool) false if the position is outside of the terrain (the model is not moved)
+//
+func (terrain *Terrain) PlaceOnGround(object Model, x, z, offset float32, alignToNormal bool) bool {
+	hit, ok := terrain.verticalHit(x, z)
+	if !ok {
+		return false
+	}
+
+	position := mgl32.Vec3{ x, hit.Point.Y() + offset, z }
+	normal := hit.Normal
+	if parent := object.GetNode().GetParent(); parent != nil {
+		inverse := parent.World().Inv()
+		position = inverse.Mul4x1(position.Vec4(1)).Vec3()
+		normal = parent.World().Transpose().Mul4x1(normal.Vec4(0)).Vec3().Normalize()
+	}
+
+	object.ResetModel()
+	object.Translate(position.X(), position.Y(), position.Z())
+
+	if alignToNormal {
+		up := mgl32.Vec3{ 0, 1, 0 }
+		axis := up.Cross(normal)
+		if axis.Len() > 1e-6 {
+			angle := float32(math.Acos(float64(mgl32.Clamp(up.Dot(normal), -1, 1))))
+			object.RotateRadians(angle, axis.Normalize())
+		}
+	}
+
+	return true
+}
+
+// verticalHit casts a ray straight down from above the terrain
+func (terrain *Terrain) verticalHit(x, z float32) (TerrainHit, bool) {
+	minimum, maximum := terrain.bounds()
+
+	// The highest corner of the bounding box in world space
+	model := terrain.Node.World()
+	top := float32(math.Inf(-1))
+	for _, corner := range [8]mgl32.Vec3{
+		{ minimum.X(), minimum.Y(), minimum.Z() }, { maximum.X(), minimum.Y(), minimum.Z() },
+		{ minimum.X(), maximum.Y(), minimum.Z() }, { maximum.X(), maximum.Y(), minimum.Z() },
+		{ minimum.X(), minimum.Y(), maximum.Z() }, { maximum.X(), minimum.Y(), maximum.Z() },
+		{ minimum.X(), maximum.Y(), maximum.Z() }, { maximum.X(), maximum.Y(), maximum.Z() },
+	} {
+		top = float32(math.Max(float64(top), float64(model.Mul4x1(corner.Vec4(1)).Y())))
+	}
+
+	return terrain.Raycast(mgl32.Vec3{ x, top + 1, z }, mgl32.Vec3{ 0, -1, 0 })
+}
+
+// march walks along the ray (in terrain space) until it goes through the ground,
+// then finds the exact point with a bisection
+func (terrain *Terrain) march(origin, direction mgl32.Vec3, start, end float32) (float32, bool) {
+	above := func(t float32) (float32, bool) {
+		point := origin.Add(direction.Mul(t))
+		height, _, ok := terrain.localSample(point.X(), point.Z())
+		return point.Y() - height, ok
+	}
+
+	// Vertical rays go straight to the height of the ground
+	horizontal := float32(math.Hypot(float64(direction.X()), float64(direction.Z())))
+	if horizontal < 1e-6 {
+		if direction.Y() == 0 {
+			return 0, false
+		}
+
+		height, _, ok := terrain.localSample(origin.X(), origin.Z())
+		t := (height - origin.Y()) / direction.Y()
+		return t, ok && t >= start && t <= end
+	}
+
+	// Half a cell per step, so it doesn't jump over the triangles
+	minimum, maximum := terrain.bounds()
+	cell := float32(math.Min(
+		float64((maximum.X() - minimum.X()) / float32(terrain.XSize - 1)),
+		float64((maximum.Z() - minimum.Z()) / float32(terrain.ZSize - 1)),
+	))
+	step := cell / 2.0 / horizontal
+	if step <= 0 {
+		step = (end - start) / 64.0
+	}
+
+	previousT := start
+	previous, _ := above(start)
+	if previous <= 0 {
+		return start, true
+	}
+
+	for t := start + step; previousT < end; t += step {
+		if t > end {
+			t = end
+		}
+
+		current, _ := above(t)
+		if current <= 0 {
+			low, high := previousT, t
+			for i := 0; i < 32; i++ {
+				middle := (low + high) / 2.0
+				if value, _ := above(middle); value > 0 {
+					low = middle
+				} else {
+					high = middle
+				}
+			}
+
+			return high, true
+		}
+
+		previousT = t
+	}
+
+	return 0, false
+}
+
+// localSample returns the height and normal of the ground in terrain space.
+// It uses the same two triangles per cell as the triangle strips, and the normal is the one of the triangle
+// (the vertex normals add up the strips with both windings, so they don't follow the slope)
+func (terrain *Terrain) localSample(x, z float32) (float32, mgl32.Vec3, bool) {
+	if terrain.XSize < 2 || terrain.ZSize < 2 {
+		return 0, mgl32.Vec3{}, false
+	}
+
+	row := terrain.findCell(x, terrain.XSize, func(i uint32) float32 { return terrain.Vertices[i * terrain.ZSize].X() })
+	col := terrain.findCell(z, terrain.ZSize, func(i uint32) float32 { return terrain.Vertices[i].Z() })
+	if row < 0 || col < 0 {
+		return 0, mgl32.Vec3{}, false
+	}
+
+	i00 := uint32(row) * terrain.ZSize + uint32(col)
+	i10 := i00 + terrain.ZSize
+	i01 := i00 + 1
+	i11 := i10 + 1
+
+	fx := fraction(x, terrain.Vertices[i00].X(), terrain.Vertices[i10].X())
+	fz := fraction(z, terrain.Vertices[i00].Z(), terrain.Vertices[i01].Z())
+
+	// Barycentric weights of the triangle that contains the point
+	var indices [3]uint32
+	var weights [3]float32
+	if fx + fz <= 1 {
+		indices = [3]uint32{ i00, i10, i01 }
+		weights = [3]float32{ 1 - fx - fz, fx, fz }
+	} else {
+		indices = [3]uint32{ i11, i01, i10 }
+		weights = [3]float32{ fx + fz - 1, 1 - fx, 1 - fz }
+	}
+
+	height := float32(0)
+	for v := 0; v < 3; v++ {
+		height += terrain.Vertices[indices[v]].Y() * weights[v]
+	}
+
+	// The second triangle goes back along -x and -z from its first vertex, so both normals point up
+	first := terrain.Vertices[indices[0]]
+	normal := terrain.Vertices[indices[2]].Sub(first).Cross(terrain.Vertices[indices[1]].Sub(first))
+	if normal.Len() > 0 {
+		normal = normal.Normalize()
+	}
+
+	return height, normal, true
+}
+
+// findCell returns the first vertex of the cell that contains the position (or -1 if it's outside)
+func (terrain *Terrain) findCell(position float32, size uint32, coordinate func(uint32) float32) int {
+	if position < coordinate(0) || position > coordinate(size - 1) {
+		return -1
+	}
+
+	// The vertices grow along each axis (the skirts repeat the edges, so some cells have no width)
+	cell := sort.Search(int(size), func(i int) bool { return coordinate(uint32(i)) >= position }) - 1
+	if cell < 0 {
+		cell = 0
+	}
+	if cell > int(size) - 2 {
+		cell = int(size) - 2
+	}
+
+	return cell
+}
+
+// bounds returns the bounding box of the vertices in terrain space
+func (terrain *Terrain) bounds() (mgl32.Vec3, mgl32.Vec3) {
+	if len(terrain.Vertices) == 0 {
+		return mgl32.Vec3{}, mgl32.Vec3{}
+	}
+
+	minimum, maximum := terrain.Vertices[0], terrain.Vertices[0]
+	for _, vertex := range terrain.Vertices {
+		for axis := 0; axis < 3; axis++ {
+			minimum[axis] = float32(math.Min(float64(minimum[axis]), float64(vertex[axis])))
+			maximum[axis] = float32(math.Max(float64(maximum[axis]), float64(vertex[axis])))
+		}
+	}
+
+	return minimum, maximum
+}
+
+// clipRay returns the part of the ray (t >= 0) that is inside the box (slab method)
+func clipRay(origin, direction, minimum, maximum mgl32.Vec3) (float32, float32, bool) {
+	start, end := float32(0), float32(math.MaxFloat32)
+
+	for axis := 0; axis < 3; axis++ {
+		if direction[axis] == 0 {
+			if origin[axis] < minimum[axis] || origin[axis] > maximum[axis] {
+				return 0, 0, false
+			}
+			continue
+		}
+
+		near := (minimum[axis] - origin[axis]) / direction[axis]
+		far := (maximum[axis] - origin[axis]) / direction[axis]
+		if near > far {
+			near, far = far, near
+		}
+
+		start = float32(math.Max(float64(start), float64(near)))
+		end = float32(math.Min(float64(end), float64(far)))
+		if start > end {
+			return 0, 0, false
+		}
+	}
+
+	return start, end, true
+}
+
+// fraction returns where the value is between a and b (0 to 1)
+func fraction(value, a, b float32) float32 {
+	if a == b {
+		return 0
+	}
+
+	return mgl32.Clamp((value - a) / (b - a), 0, 1)
+}
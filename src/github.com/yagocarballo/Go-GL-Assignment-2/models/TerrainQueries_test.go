@@ -0,0 +1,164 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// rampSource goes from 0 on the first column to 1 on the last one
+type rampSource struct{}
+
+func (rampSource) Generate(xSize, zSize uint32) []float32 {
+	heights := make([]float32, xSize * zSize)
+	for row := uint32(0); row < zSize; row++ {
+		for col := uint32(0); col < xSize; col++ {
+			heights[row * xSize + col] = float32(col) / float32(xSize - 1)
+		}
+	}
+
+	return heights
+}
+
+// rampTerrain is a 10x10 terrain that goes up along z, from y = -5 on z = -5 to y = 5 on z = 4
+// (the heights are multiplied by the width and the last vertex is one step before the edge)
+func rampTerrain() *Terrain {
+	terrain := NewTerrainWithSource(nil, rampSource{}, mgl32.Vec4{ 1, 1, 1, 1 })
+	terrain.BuildTerrain(10, 10, 10, 10)
+
+	return terrain
+}
+
+// rampHeight is the height of the ramp terrain (without transforms) at a z position
+func rampHeight(z float32) float32 {
+	return (z + 5) * 10 / 9 - 5
+}
+
+// rampNormal is the normal of the ramp terrain (without transforms)
+var rampNormal = mgl32.Vec3{ 0, 1, -10.0 / 9.0 }.Normalize()
+
+func TestHeightAtFollowsTheVertices(t *testing.T) {
+	terrain := rampTerrain()
+
+	// Every vertex is exactly on the ground
+	for _, vertex := range terrain.Vertices {
+		height, ok := terrain.HeightAt(vertex.X(), vertex.Z())
+		if !ok || math.Abs(float64(height - vertex.Y())) > 1e-4 {
+			t.Fatalf("vertex %v: found the height %f (%v)", vertex, height, ok)
+		}
+	}
+
+	// Between the vertices the ramp is interpolated
+	for _, point := range [][2]float32{ { 0.3, -4.2 }, { -2.7, 1.55 }, { 3.9, 3.99 } } {
+		height, ok := terrain.HeightAt(point[0], point[1])
+		if !ok || math.Abs(float64(height - rampHeight(point[1]))) > 1e-4 {
+			t.Errorf("%v: expected %f, found %f (%v)", point, rampHeight(point[1]), height, ok)
+		}
+	}
+
+	for _, point := range [][2]float32{ { -5.5, 0 }, { 0, 4.5 }, { 20, 20 } } {
+		if _, ok := terrain.HeightAt(point[0], point[1]); ok {
+			t.Errorf("%v is outside of the terrain", point)
+		}
+	}
+
+	normal, ok := terrain.NormalAt(0.5, 0.5)
+	if !ok || !normal.ApproxEqualThreshold(rampNormal, 1e-4) {
+		t.Errorf("expected the normal %v, found %v", rampNormal, normal)
+	}
+}
+
+func TestHeightAtUsesTheTransform(t *testing.T) {
+	terrain := rampTerrain()
+	terrain.Translate(100, 20, -50)
+	terrain.Scale(2, 3, 2)
+	terrain.RotateDegrees(180, mgl32.Vec3{ 0, 1, 0 })
+
+	// The world position goes back to the terrain space: z -> -(z + 50) / 2
+	for _, point := range [][2]float32{ { 100, -50 }, { 97, -44 }, { 105.5, -57.2 } } {
+		localZ := -(point[1] + 50) / 2
+		expected := 20 + 3 * rampHeight(localZ)
+
+		height, ok := terrain.HeightAt(point[0], point[1])
+		if !ok || math.Abs(float64(height - expected)) > 1e-3 {
+			t.Errorf("%v: expected %f, found %f (%v)", point, expected, height, ok)
+		}
+	}
+}
+
+func TestRaycast(t *testing.T) {
+	terrain := rampTerrain()
+
+	// Straight down
+	hit, ok := terrain.Raycast(mgl32.Vec3{ 1, 50, 2 }, mgl32.Vec3{ 0, -1, 0 })
+	expected := mgl32.Vec3{ 1, rampHeight(2), 2 }
+	if !ok || !hit.Point.ApproxEqualThreshold(expected, 1e-3) || math.Abs(float64(hit.Distance - (50 - expected.Y()))) > 1e-3 {
+		t.Errorf("expected to hit %v after %f units, found %v after %f (%v)", expected, 50 - expected.Y(), hit.Point, hit.Distance, ok)
+	}
+
+	// A slanted ray along z (y = 10 - t, z = -4 + t) meets the ramp where 10 - t = (t + 1) * 10 / 9 - 5
+	hit, ok = terrain.Raycast(mgl32.Vec3{ 0, 10, -4 }, mgl32.Vec3{ 0, -1, 1 })
+	along := float32(125.0 / 19.0)
+	expected = mgl32.Vec3{ 0, 10 - along, -4 + along }
+	if !ok || !hit.Point.ApproxEqualThreshold(expected, 1e-3) {
+		t.Errorf("expected to hit %v, found %v (%v)", expected, hit.Point, ok)
+	}
+	if math.Abs(float64(hit.Distance) - float64(along) * math.Sqrt2) > 1e-3 {
+		t.Errorf("expected the distance %f, found %f", float64(along) * math.Sqrt2, hit.Distance)
+	}
+	if !hit.Normal.ApproxEqualThreshold(rampNormal, 1e-4) {
+		t.Errorf("unexpected normal %v", hit.Normal)
+	}
+
+	// Pointing up, away from the terrain and parallel to it
+	misses := [][2]mgl32.Vec3{
+		{ { 0, 10, 0 }, { 0, 1, 0 } },
+		{ { 0, 10, 0 }, { 1, 0, 0 } },
+		{ { 20, 0, 0 }, { 1, -1, 0 } },
+		{ { 0, 10, 0 }, { 0, 0, 0 } },
+	}
+	for _, ray := range misses {
+		if hit, ok := terrain.Raycast(ray[0], ray[1]); ok {
+			t.Errorf("the ray %v should miss, hit %v", ray, hit.Point)
+		}
+	}
+}
+
+func TestPlaceOnGround(t *testing.T) {
+	terrain := rampTerrain()
+	object := NewTerrain(nil)
+
+	if !terrain.PlaceOnGround(object, 1, 2, 0.5, false) {
+		t.Fatal("the object is on the terrain")
+	}
+	ground := mgl32.Vec3{ 1, rampHeight(2), 2 }
+	if position := object.GetNode().WorldPosition(); !position.ApproxEqualThreshold(ground.Add(mgl32.Vec3{ 0, 0.5, 0 }), 1e-4) {
+		t.Errorf("expected 0.5 above %v, found %v", ground, position)
+	}
+
+	// The y axis follows the normal of the ramp
+	terrain.PlaceOnGround(object, 1, 2, 0, true)
+	up := object.GetNode().World().Mul4x1(mgl32.Vec4{ 0, 1, 0, 0 }).Vec3()
+	if !up.ApproxEqualThreshold(rampNormal, 1e-4) {
+		t.Errorf("expected the y axis to follow the normal, found %v", up)
+	}
+
+	// With a parent the position is moved to the space of the parent
+	parent := NewSceneNode("Parent")
+	parent.Translate(10, 0, 0)
+	parent.Scale(2, 2, 2)
+	parent.AddChild(object.GetNode())
+	terrain.PlaceOnGround(object, 1, 2, 0, false)
+	if position := object.GetNode().WorldPosition(); !position.ApproxEqualThreshold(ground, 1e-4) {
+		t.Errorf("expected %v in world space, found %v", ground, position)
+	}
+
+	// Outside of the terrain the object doesn't move
+	if terrain.PlaceOnGround(object, 50, 50, 0, false) {
+		t.Error("the object is outside of the terrain")
+	}
+	if position := object.GetNode().WorldPosition(); !position.ApproxEqualThreshold(ground, 1e-4) {
+		t.Errorf("the object moved to %v", position)
+	}
+}
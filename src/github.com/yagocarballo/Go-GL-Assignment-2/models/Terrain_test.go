@@ -0,0 +1,105 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+func TestTerrainBuffers(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	shaderManager := testShaders(t, device)
+	terrain := rampTerrain()
+	terrain.Device = device
+	terrain.CreateObject()
+
+	for _, err := range device.Errors {
+		t.Errorf("device error: %s", err)
+	}
+
+	// The colours are stored as Vec3 and the indices as uint16
+	buffers := []struct {
+		name     string
+		buffer   uint32
+		expected []float32
+	}{
+		{ "vertices", terrain.VBOVertices, flatten(terrain.Vertices) },
+		{ "normals", terrain.VBONormals, flatten(terrain.Normals) },
+		{ "colours", terrain.VBOColors, flatten(terrain.Colors) },
+	}
+	for _, buffer := range buffers {
+		if size := len(device.Buffers[buffer.buffer]); size != len(terrain.Vertices) * SizeOfVec3 {
+			t.Errorf("%s: expected %d bytes, found %d", buffer.name, len(terrain.Vertices) * SizeOfVec3, size)
+		}
+		if !sameFloats(buffer.expected, device.BufferFloats(buffer.buffer)) {
+			t.Errorf("%s: the buffer doesn't have the values of the terrain", buffer.name)
+		}
+	}
+
+	indices := device.BufferUint16s(terrain.VBOIndices)
+	if len(indices) != len(terrain.Indices) || len(indices) != 9 * 10 * 2 {
+		t.Fatalf("expected %d indices (two per vertex of every strip), found %d", 9 * 10 * 2, len(indices))
+	}
+	for index := range indices {
+		if indices[index] != terrain.Indices[index] {
+			t.Fatalf("index %d: expected %d, found %d", index, terrain.Indices[index], indices[index])
+		}
+	}
+	if device.Bindings[gl.ARRAY_BUFFER] != 0 || device.Bindings[gl.ELEMENT_ARRAY_BUFFER] != 0 {
+		t.Error("a buffer was left bound after the upload")
+	}
+
+	// Polygons and lines draw one triangle strip per row, points draw the vertices
+	for _, drawMode := range []DrawMode{ DRAW_POINTS, DRAW_LINES, DRAW_POLYGONS } {
+		device.Reset()
+		terrain.SetDrawMode(drawMode)
+		terrain.Translate(0, 1, 0)
+		terrain.DrawObject(shaderManager.CurrentShader())
+
+		for _, err := range device.Errors {
+			t.Errorf("%s: device error: %s", drawMode, err)
+		}
+
+		strips := device.CallsNamed("DrawElements")
+		if drawMode == DRAW_POINTS {
+			if points := device.CallsNamed("DrawArrays"); len(points) != 1 || points[0].String() != "DrawArrays(0, 0, 100)" || len(strips) != 0 {
+				t.Errorf("%s: expected the 100 vertices as points, found %v %v", drawMode, points, strips)
+			}
+			continue
+		}
+
+		polygonModes := device.CallsNamed("PolygonMode")
+		if expected := map[DrawMode]uint32{ DRAW_LINES: gl.LINE, DRAW_POLYGONS: gl.FILL }[drawMode]; len(polygonModes) != 1 || polygonModes[0].Args[1] != expected {
+			t.Errorf("%s: expected the polygon mode 0x%x, found %v", drawMode, expected, polygonModes)
+		}
+		if len(strips) != 9 {
+			t.Fatalf("%s: expected 9 strips, found %d", drawMode, len(strips))
+		}
+		for row, strip := range strips {
+			expected := wrapper.Call{ Name: "DrawElements", Args: []interface{}{ uint32(gl.TRIANGLE_STRIP), int32(20), uint32(gl.UNSIGNED_SHORT), row * 20 * SizeOfUint16 } }
+			if strip.String() != expected.String() {
+				t.Errorf("%s, row %d: expected %s, found %s", drawMode, row, expected, strip)
+			}
+		}
+
+		world := terrain.Node.World()
+		if uniform := device.Uniform(shaderManager.CurrentShader(), "model"); !sameFloats(world[:], uniform) {
+			t.Errorf("%s: expected the world matrix in the model uniform, found %v", drawMode, uniform)
+		}
+	}
+
+	terrain.DeleteObject()
+	if len(device.Buffers) != 0 {
+		t.Errorf("the buffers weren't deleted: %v", device.Buffers)
+	}
+
+	// The heights don't need a device
+	heightsOnly := NewTerrainWithSource(nil, rampSource{}, mgl32.Vec4{ 1, 1, 1, 1 })
+	heightsOnly.BuildTerrain(4, 4, 4, 4)
+	if len(heightsOnly.Vertices) != 16 {
+		t.Errorf("expected 16 vertices, found %d", len(heightsOnly.Vertices))
+	}
+}
@@ -0,0 +1,282 @@
+package models
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//
+// Transform
+// Position, orientation and scale of an object (the matrix is Translation * Rotation * Scale).
+// The local operations move along the axes of the object, the world operations move along
+// the axes of the space the transform lives in (the parent node)
+//
+type Transform struct {
+	Position    mgl32.Vec3
+	Orientation mgl32.Quat // Always a unit quaternion
+	Scale       mgl32.Vec3
+}
+
+//
+// NewTransform
+// Creates the identity transform
+//
+// @return transform (Transform) the transform
+//
+func NewTransform() Transform {
+	return Transform{
+		mgl32.Vec3{},           // Position
+		mgl32.QuatIdent(),      // Orientation
+		mgl32.Vec3{ 1, 1, 1 },  // Scale
+	}
+}
+
+//
+// TransformFromMatrix
+// Splits a matrix in position, orientation and scale
+// (the matrix can't have shear or a perspective, a mirror is stored as a negative x scale)
+//
+// @param matrix (mgl32.Mat4) the matrix
+//
+// @return transform (Transform) the transform
+//
+func TransformFromMatrix(matrix mgl32.Mat4) Transform {
+	transform := NewTransform()
+	transform.Position = matrix.Col(3).Vec3()
+
+	axisX, axisY, axisZ := matrix.Col(0).Vec3(), matrix.Col(1).Vec3(), matrix.Col(2).Vec3()
+	transform.Scale = mgl32.Vec3{ axisX.Len(), axisY.Len(), axisZ.Len() }
+
+	// A mirrored matrix flips the x axis
+	if matrix.Mat3().Det() < 0 {
+		transform.Scale[0] = -transform.Scale[0]
+	}
+
+	if transform.Scale.X() == 0 || transform.Scale.Y() == 0 || transform.Scale.Z() == 0 {
+		return transform
+	}
+
+	rotation := mgl32.Mat4FromCols(
+		axisX.Mul(1 / transform.Scale.X()).Vec4(0),
+		axisY.Mul(1 / transform.Scale.Y()).Vec4(0),
+		axisZ.Mul(1 / transform.Scale.Z()).Vec4(0),
+		mgl32.Vec4{ 0, 0, 0, 1 },
+	)
+	transform.Orientation = mgl32.Mat4ToQuat(rotation).Normalize()
+
+	return transform
+}
+
+//
+// InterpolateTransforms
+// Blends two transforms (linear for the position and scale, spherical for the orientation)
+//
+// @param from (Transform) the transform at 0
+// @param to (Transform) the transform at 1
+// @param amount (float32) how far from the first transform (0 to 1)
+//
+// @return transform (Transform) the blended transform
+//
+func InterpolateTransforms(from, to Transform, amount float32) Transform {
+	// Takes the shortest way around
+	target := to.Orientation
+	if from.Orientation.Dot(target) < 0 {
+		target = target.Scale(-1)
+	}
+
+	return Transform{
+		from.Position.Add(to.Position.Sub(from.Position).Mul(amount)),
+		mgl32.QuatSlerp(from.Orientation, target, amount).Normalize(),
+		from.Scale.Add(to.Scale.Sub(from.Scale).Mul(amount)),
+	}
+}
+
+//
+// Matrix
+// Returns the matrix of the transform (Translation * Rotation * Scale)
+//
+func (transform Transform) Matrix() mgl32.Mat4 {
+	return mgl32.Translate3D(transform.Position.X(), transform.Position.Y(), transform.Position.Z()).
+		Mul4(transform.Orientation.Mat4()).
+		Mul4(mgl32.Scale3D(transform.Scale.X(), transform.Scale.Y(), transform.Scale.Z()))
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////// Translation ///////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// TranslateLocal
+// Moves along the axes of the object (the distances are scaled, like multiplying the matrix by a translation)
+//
+// @param offset (mgl32.Vec3) the distance along every axis
+//
+func (transform *Transform) TranslateLocal(offset mgl32.Vec3) {
+	scaled := mgl32.Vec3{ offset.X() * transform.Scale.X(), offset.Y() * transform.Scale.Y(), offset.Z() * transform.Scale.Z() }
+	transform.Position = transform.Position.Add(transform.Orientation.Rotate(scaled))
+}
+
+//
+// TranslateWorld
+// Moves along the axes of the parent space
+//
+// @param offset (mgl32.Vec3) the distance along every axis
+//
+func (transform *Transform) TranslateWorld(offset mgl32.Vec3) {
+	transform.Position = transform.Position.Add(offset)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////// Rotation /////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// RotateLocalRadians
+// Rotates around an axis of the object (like multiplying the matrix by a rotation).
+// With a non uniform scale the rotation happens before the scale, as the transform can't store a shear
+//
+// @param radians (float32) the angle in radians
+// @param axis (mgl32.Vec3) the axis in object space
+//
+func (transform *Transform) RotateLocalRadians(radians float32, axis mgl32.Vec3) {
+	if axis.Len() == 0 {
+		return
+	}
+
+	// Normalizing every time stops the rounding errors from piling up
+	transform.Orientation = transform.Orientation.Mul(mgl32.QuatRotate(radians, axis.Normalize())).Normalize()
+}
+
+//
+// RotateLocalDegrees
+// Rotates around an axis of the object
+//
+// @param degrees (float32) the angle in degrees
+// @param axis (mgl32.Vec3) the axis in object space
+//
+func (transform *Transform) RotateLocalDegrees(degrees float32, axis mgl32.Vec3) {
+	transform.RotateLocalRadians(mgl32.DegToRad(degrees), axis)
+}
+
+//
+// RotateWorldRadians
+// Rotates around an axis of the parent space (the position doesn't change)
+//
+// @param radians (float32) the angle in radians
+// @param axis (mgl32.Vec3) the axis in parent space
+//
+func (transform *Transform) RotateWorldRadians(radians float32, axis mgl32.Vec3) {
+	if axis.Len() == 0 {
+		return
+	}
+
+	transform.Orientation = mgl32.QuatRotate(radians, axis.Normalize()).Mul(transform.Orientation).Normalize()
+}
+
+//
+// RotateWorldDegrees
+// Rotates around an axis of the parent space (the position doesn't change)
+//
+// @param degrees (float32) the angle in degrees
+// @param axis (mgl32.Vec3) the axis in parent space
+//
+func (transform *Transform) RotateWorldDegrees(degrees float32, axis mgl32.Vec3) {
+	transform.RotateWorldRadians(mgl32.DegToRad(degrees), axis)
+}
+
+//
+// EulerRadians
+// Returns the orientation as angles around x, y and z (applied in that order: Rx * Ry * Rz)
+//
+// @return angles (mgl32.Vec3) the angles in radians
+//
+func (transform Transform) EulerRadians() mgl32.Vec3 {
+	rotation := transform.Orientation.Mat4()
+
+	sinY := mgl32.Clamp(rotation.At(0, 2), -1, 1)
+	y := math.Asin(float64(sinY))
+
+	// Gimbal lock: x and z turn around the same axis, so z is kept at 0
+	if math.Abs(float64(sinY)) > 0.99999 {
+		x := math.Atan2(float64(rotation.At(2, 1)), float64(rotation.At(1, 1)))
+		return mgl32.Vec3{ float32(x), float32(y), 0 }
+	}
+
+	x := math.Atan2(float64(-rotation.At(1, 2)), float64(rotation.At(2, 2)))
+	z := math.Atan2(float64(-rotation.At(0, 1)), float64(rotation.At(0, 0)))
+
+	return mgl32.Vec3{ float32(x), float32(y), float32(z) }
+}
+
+//
+// EulerDegrees
+// Returns the orientation as angles around x, y and z (applied in that order: Rx * Ry * Rz)
+//
+// @return angles (mgl32.Vec3) the angles in degrees
+//
+func (transform Transform) EulerDegrees() mgl32.Vec3 {
+	radians := transform.EulerRadians()
+	return mgl32.Vec3{ mgl32.RadToDeg(radians.X()), mgl32.RadToDeg(radians.Y()), mgl32.RadToDeg(radians.Z()) }
+}
+
+//
+// SetEulerRadians
+// Replaces the orientation with angles around x, y and z (applied in that order: Rx * Ry * Rz)
+//
+// @param angles (mgl32.Vec3) the angles in radians
+//
+func (transform *Transform) SetEulerRadians(angles mgl32.Vec3) {
+	transform.Orientation = mgl32.QuatRotate(angles.X(), mgl32.Vec3{ 1, 0, 0 }).
+		Mul(mgl32.QuatRotate(angles.Y(), mgl32.Vec3{ 0, 1, 0 })).
+		Mul(mgl32.QuatRotate(angles.Z(), mgl32.Vec3{ 0, 0, 1 })).
+		Normalize()
+}
+
+//
+// SetEulerDegrees
+// Replaces the orientation with angles around x, y and z (applied in that order: Rx * Ry * Rz)
+//
+// @param angles (mgl32.Vec3) the angles in degrees
+//
+func (transform *Transform) SetEulerDegrees(angles mgl32.Vec3) {
+	transform.SetEulerRadians(mgl32.Vec3{ mgl32.DegToRad(angles.X()), mgl32.DegToRad(angles.Y()), mgl32.DegToRad(angles.Z()) })
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+/////////////////////////////////////// Scale ///////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// ScaleBy
+// Multiplies the scale along the axes of the object
+//
+// @param factors (mgl32.Vec3) the scale along every axis
+//
+func (transform *Transform) ScaleBy(factors mgl32.Vec3) {
+	transform.Scale = mgl32.Vec3{ transform.Scale.X() * factors.X(), transform.Scale.Y() * factors.Y(), transform.Scale.Z() * factors.Z() }
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+//////////////////////////////////////// Axes ///////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+// Right returns the x axis of the object in parent space
+func (transform Transform) Right() mgl32.Vec3 {
+	return transform.Orientation.Rotate(mgl32.Vec3{ 1, 0, 0 })
+}
+
+// Up returns the y axis of the object in parent space
+func (transform Transform) Up() mgl32.Vec3 {
+	return transform.Orientation.Rotate(mgl32.Vec3{ 0, 1, 0 })
+}
+
+// Forward returns the direction the object looks at in parent space (-z, like OpenGL)
+func (transform Transform) Forward() mgl32.Vec3 {
+	return transform.Orientation.Rotate(mgl32.Vec3{ 0, 0, -1 })
+}
+
+func (transform Transform) String() string {
+	return fmt.Sprintf("Position: %v, Rotation (degrees): %v, Scale: %v", transform.Position, transform.EulerDegrees(), transform.Scale)
+}
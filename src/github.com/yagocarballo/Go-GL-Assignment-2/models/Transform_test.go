@@ -0,0 +1,204 @@
+package models
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// randomTransform returns a transform with a random position, orientation and (positive) scale
+func randomTransform(random *rand.Rand) Transform {
+	transform := NewTransform()
+	transform.Position = mgl32.Vec3{ random.Float32() * 20 - 10, random.Float32() * 20 - 10, random.Float32() * 20 - 10 }
+	transform.RotateLocalRadians(random.Float32() * 2 * math.Pi, mgl32.Vec3{ random.Float32() - 0.5, random.Float32() - 0.5, random.Float32() - 0.5 })
+	transform.Scale = mgl32.Vec3{ 0.1 + random.Float32() * 3, 0.1 + random.Float32() * 3, 0.1 + random.Float32() * 3 }
+
+	return transform
+}
+
+// sameTransform compares two transforms (q and -q are the same orientation)
+func sameTransform(a, b Transform) bool {
+	return nearVec3(a.Position, b.Position, 1e-4) &&
+		nearVec3(a.Scale, b.Scale, 1e-4) &&
+		mgl32.Abs(mgl32.Abs(a.Orientation.Dot(b.Orientation)) - 1) < 1e-4
+}
+
+func TestTransformMatrixIsTRS(t *testing.T) {
+	transform := NewTransform()
+	transform.Position = mgl32.Vec3{ 1, 2, 3 }
+	transform.RotateLocalDegrees(90, mgl32.Vec3{ 0, 0, 1 })
+	transform.Scale = mgl32.Vec3{ 2, 3, 4 }
+
+	expected := mgl32.Translate3D(1, 2, 3).Mul4(mgl32.HomogRotate3D(math.Pi / 2, mgl32.Vec3{ 0, 0, 1 })).Mul4(mgl32.Scale3D(2, 3, 4))
+	if matrix := transform.Matrix(); !nearMat4(matrix, expected, 1e-5) {
+		t.Errorf("expected T * R * S:\n%v\nfound:\n%v", expected, matrix)
+	}
+
+	if !nearMat4(NewTransform().Matrix(), mgl32.Ident4(), 1e-6) {
+		t.Error("the new transform is not the identity")
+	}
+}
+
+func TestTransformFromMatrixRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 200; i++ {
+		transform := randomTransform(random)
+		back := TransformFromMatrix(transform.Matrix())
+		if !sameTransform(transform, back) {
+			t.Fatalf("transform %d: %v came back as %v", i, transform, back)
+		}
+		if !nearMat4(back.Matrix(), transform.Matrix(), 1e-4) {
+			t.Fatalf("transform %d: the matrix changed", i)
+		}
+	}
+
+	// A mirror is kept as a negative x scale, so the matrix is the same
+	mirror := mgl32.Translate3D(4, 0, 0).Mul4(mgl32.HomogRotate3D(0.5, mgl32.Vec3{ 0, 1, 0 })).Mul4(mgl32.Scale3D(-2, 1, 3))
+	back := TransformFromMatrix(mirror)
+	if back.Scale.X() >= 0 || !nearMat4(back.Matrix(), mirror, 1e-5) {
+		t.Errorf("the mirror was lost: %v", back)
+	}
+
+	// A zero scale can't have an orientation
+	if flat := TransformFromMatrix(mgl32.Scale3D(1, 0, 1)); flat.Orientation != mgl32.QuatIdent() {
+		t.Errorf("expected no orientation, found %v", flat.Orientation)
+	}
+}
+
+func TestSetMatrixDecomposes(t *testing.T) {
+	node := NewSceneNode("Node")
+	node.Translate(3, 0, 0)
+	node.RotateDegrees(30, mgl32.Vec3{ 1, 0, 0 })
+	node.Scale(2, 2, 2)
+	matrix := node.Local()
+
+	// A node set from the matrix behaves the same when it's moved later
+	other := NewSceneNode("Other")
+	other.SetMatrix(matrix)
+	if !sameTransform(node.GetTransform(), other.GetTransform()) {
+		t.Fatalf("expected %v, found %v", node.GetTransform(), other.GetTransform())
+	}
+
+	node.Translate(0, 1, 0)
+	other.Translate(0, 1, 0)
+	if !nearMat4(node.Local(), other.Local(), 1e-5) {
+		t.Errorf("the nodes moved apart:\n%v\n%v", node.Local(), other.Local())
+	}
+}
+
+func TestDegreesAndRadians(t *testing.T) {
+	degrees, radians := NewTransform(), NewTransform()
+	degrees.RotateLocalDegrees(90, mgl32.Vec3{ 0, 1, 0 })
+	radians.RotateLocalRadians(math.Pi / 2, mgl32.Vec3{ 0, 1, 0 })
+	if !sameTransform(degrees, radians) {
+		t.Errorf("90 degrees is not pi / 2 radians: %v, %v", degrees, radians)
+	}
+
+	// Turning the forward (-z) 90 degrees around y looks along -x
+	if forward := degrees.Forward(); !nearVec3(forward, mgl32.Vec3{ -1, 0, 0 }, 1e-5) {
+		t.Errorf("expected the forward (-1, 0, 0), found %v", forward)
+	}
+
+	// 180 radians (what the default scene uses) is not half a turn: it's 180 - 28 * 2 * pi radians
+	half, original := NewTransform(), NewTransform()
+	half.RotateLocalDegrees(180, mgl32.Vec3{ 0, 1, 0 })
+	original.RotateLocalRadians(180, mgl32.Vec3{ 0, 1, 0 })
+	if sameTransform(half, original) {
+		t.Error("180 radians and 180 degrees are the same turn")
+	}
+	wrapped := NewTransform()
+	wrapped.RotateLocalRadians(float32(180 - 28 * 2 * math.Pi), mgl32.Vec3{ 0, 1, 0 })
+	if !sameTransform(original, wrapped) {
+		t.Errorf("expected 180 radians to be %v, found %v", wrapped, original)
+	}
+
+	// The world rotations take degrees the same way
+	world := NewTransform()
+	world.RotateWorldDegrees(90, mgl32.Vec3{ 0, 1, 0 })
+	if !sameTransform(world, degrees) {
+		t.Errorf("expected %v, found %v", degrees, world)
+	}
+}
+
+func TestLocalAndWorldOperations(t *testing.T) {
+	transform := NewTransform()
+	transform.RotateLocalDegrees(90, mgl32.Vec3{ 0, 0, 1 })
+	transform.ScaleBy(mgl32.Vec3{ 2, 2, 2 })
+
+	// The local x is the world y, and the distance is scaled
+	transform.TranslateLocal(mgl32.Vec3{ 1, 0, 0 })
+	if !nearVec3(transform.Position, mgl32.Vec3{ 0, 2, 0 }, 1e-5) {
+		t.Errorf("expected (0, 2, 0), found %v", transform.Position)
+	}
+
+	transform.TranslateWorld(mgl32.Vec3{ 1, 0, 0 })
+	if !nearVec3(transform.Position, mgl32.Vec3{ 1, 2, 0 }, 1e-5) {
+		t.Errorf("expected (1, 2, 0), found %v", transform.Position)
+	}
+
+	// A local turn around x is a turn around the world y (the local x)
+	local := transform
+	local.RotateLocalDegrees(90, mgl32.Vec3{ 1, 0, 0 })
+	world := transform
+	world.RotateWorldDegrees(90, mgl32.Vec3{ 0, 1, 0 })
+	if !sameTransform(local, world) || local.Position != transform.Position {
+		t.Errorf("expected the same orientation, found %v and %v", local, world)
+	}
+
+	// A zero axis does nothing
+	unchanged := transform
+	unchanged.RotateLocalDegrees(45, mgl32.Vec3{})
+	unchanged.RotateWorldDegrees(45, mgl32.Vec3{})
+	if unchanged != transform {
+		t.Errorf("a zero axis changed the transform to %v", unchanged)
+	}
+}
+
+func TestEulerRoundTrip(t *testing.T) {
+	for _, angles := range []mgl32.Vec3{ { 0, 0, 0 }, { 30, 45, 60 }, { -120, 10, 170 }, { 90, 0, 0 } } {
+		transform := NewTransform()
+		transform.SetEulerDegrees(angles)
+		if back := transform.EulerDegrees(); !nearVec3(back, angles, 1e-2) {
+			t.Errorf("%v came back as %v", angles, back)
+		}
+	}
+
+	// In the gimbal lock the orientation is kept, even if the angles change
+	transform := NewTransform()
+	transform.SetEulerDegrees(mgl32.Vec3{ 20, 90, 30 })
+	again := NewTransform()
+	again.SetEulerDegrees(transform.EulerDegrees())
+	if !sameTransform(transform, again) {
+		t.Errorf("the gimbal lock changed the orientation: %v, %v", transform, again)
+	}
+}
+
+func TestInterpolateTransforms(t *testing.T) {
+	from, to := NewTransform(), NewTransform()
+	to.Position = mgl32.Vec3{ 10, 0, 0 }
+	to.Scale = mgl32.Vec3{ 3, 3, 3 }
+	to.RotateLocalDegrees(90, mgl32.Vec3{ 0, 1, 0 })
+
+	middle := InterpolateTransforms(from, to, 0.5)
+	expected := NewTransform()
+	expected.Position = mgl32.Vec3{ 5, 0, 0 }
+	expected.Scale = mgl32.Vec3{ 2, 2, 2 }
+	expected.RotateLocalDegrees(45, mgl32.Vec3{ 0, 1, 0 })
+	if !sameTransform(middle, expected) {
+		t.Errorf("expected %v, found %v", expected, middle)
+	}
+
+	// The negated quaternion is the same orientation, so it takes the short way
+	flipped := to
+	flipped.Orientation = to.Orientation.Scale(-1)
+	if middle := InterpolateTransforms(from, flipped, 0.5); !sameTransform(middle, expected) {
+		t.Errorf("took the long way around: %v", middle)
+	}
+
+	if !sameTransform(InterpolateTransforms(from, to, 0), from) || !sameTransform(InterpolateTransforms(from, to, 1), to) {
+		t.Error("the ends are not the transforms")
+	}
+}
@@ -0,0 +1,409 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+//
+// Wave
+// One Gerstner wave of the water surface
+//
+type Wave struct {
+	Amplitude  float32    // Height of the crests
+	Wavelength float32    // Distance between two crests
+	Direction  mgl32.Vec2 // Direction of the wave on the (x, z) plane
+	Speed      float32    // Units per second the crests move
+	Steepness  float32    // 0 is a sine wave, 1 gives sharp crests
+}
+
+//
+// Water
+// A water surface made of a grid that is uploaded once and moved with Gerstner waves.
+// Only the positions and normals are sent again on every Update
+//
+type Water struct {
+	Waves         []Wave
+	ColorTone     mgl32.Vec4
+	Time          float32 // Seconds since the waves started
+
+	XSize, ZSize  uint32
+
+	VBOVertices   uint32
+	VBOColors     uint32
+	VBONormals    uint32
+	VBOIndices    uint32
+
+	Base          []mgl32.Vec3 // The flat grid, the waves move these positions
+	Vertices      []mgl32.Vec3
+	Normals       []mgl32.Vec3
+	Colors        []mgl32.Vec3
+	Indices       []uint16
+
+	Node          *SceneNode // Transform of the water in the scene graph
+
+	Name          string
+	DrawMode      DrawMode
+
+	Device        wrapper.Device // Uploads and draws the buffers
+}
+
+//
+// NewWave
+// Creates a Gerstner wave
+//
+// @param amplitude (float32) height of the crests
+// @param wavelength (float32) distance between two crests
+// @param direction (float32) direction of the wave on the (x, z) plane, in degrees
+// @param speed (float32) units per second the crests move
+//
+// @return wave (Wave) the wave
+//
+func NewWave(amplitude, wavelength, direction, speed float32) Wave {
+	angle := float64(direction * DEG_TO_RADIANS)
+
+	return Wave{
+		amplitude,
+		wavelength,
+		mgl32.Vec2{ float32(math.Cos(angle)), float32(math.Sin(angle)) },
+		speed,
+		0.5,
+	}
+}
+
+//
+// RandomWaves
+// Creates (count) waves from a seed, each one shorter and lower than the one before
+// (like the default waves, but going in random directions)
+//
+// @param seed (int64) the seed of the waves
+// @param count (int) the number of waves
+//
+// @return waves ([]Wave) the waves
+//
+func RandomWaves(seed int64, count int) []Wave {
+	random := rand.New(rand.NewSource(seed))
+	waves := make([]Wave, count)
+
+	amplitude, wavelength, speed := float32(1.2), float32(60.0), float32(6.0)
+	for i := range waves {
+		waves[i] = NewWave(
+			amplitude * (0.8 + random.Float32() * 0.4),
+			wavelength * (0.8 + random.Float32() * 0.4),
+			random.Float32() * 360.0,
+			speed * (0.8 + random.Float32() * 0.4),
+		)
+
+		amplitude *= 0.5
+		wavelength *= 0.55
+		speed *= 0.7
+	}
+
+	return waves
+}
+
+//
+// NewWater
+// Creates a water surface with a few default waves
+//
+// @param device (wrapper.Device) the device that uploads and draws the buffers
+// @param colorTone (mgl32.Vec4) the tone of the water
+//
+// @return water (*Water) a pointer to the water
+//
+func NewWater(device wrapper.Device, colorTone mgl32.Vec4) *Water {
+	return &Water{
+		[]Wave{
+			NewWave(1.2, 60.0, 20.0, 6.0),
+			NewWave(0.6, 31.0, 75.0, 4.0),
+			NewWave(0.3, 17.0, -40.0, 3.0),
+		},
+		colorTone,		// ColorTone
+		0,				// Time
+
+		0,				// XSize
+		0,				// ZSize
+
+		0,				// VBOVertices
+		0,				// VBOColors
+		0,				// VBONormals
+		0,				// VBOIndices
+
+		[]mgl32.Vec3{},	// Base
+		[]mgl32.Vec3{},	// Vertices
+		[]mgl32.Vec3{},	// Normals
+		[]mgl32.Vec3{},	// Colors
+		[]uint16{},		// Indices
+
+		NewSceneNode("Water"),	// Node
+
+		"Water",
+		DRAW_POLYGONS,
+
+		device,			// Device
+	}
+}
+
+//
+// CreateWater
+// Creates the grid and uploads it (only once, Update moves the vertices)
+//
+// @param xp (uint32) number of vertices along x
+// @param zp (uint32) number of vertices along z
+// @param xs (float32) width of the water
+// @param zs (float32) depth of the water
+//
+func (water *Water) CreateWater(xp, zp uint32, xs, zs float32) {
+	water.BuildWater(xp, zp, xs, zs)
+	water.CreateObject()
+}
+
+//
+// BuildWater
+// Creates the flat grid, the colours and the indices (doesn't use OpenGL)
+//
+// @param xp (uint32) number of vertices along x
+// @param zp (uint32) number of vertices along z
+// @param xs (float32) width of the water
+// @param zs (float32) depth of the water
+//
+func (water *Water) BuildWater(xp, zp uint32, xs, zs float32) {
+	water.XSize = xp
+	water.ZSize = zp
+
+	numVertices := xp * zp
+	water.Base = make([]mgl32.Vec3, numVertices)
+	water.Vertices = make([]mgl32.Vec3, numVertices)
+	water.Normals = make([]mgl32.Vec3, numVertices)
+	water.Colors = make([]mgl32.Vec3, numVertices)
+
+	xStep := xs / float32(xp - 1)
+	zStep := zs / float32(zp - 1)
+
+	for x := uint32(0); x < xp; x++ {
+		for z := uint32(0); z < zp; z++ {
+			water.Base[x * zp + z] = mgl32.Vec3{ -xs / 2.0 + float32(x) * xStep, 0, -zs / 2.0 + float32(z) * zStep }
+			water.Colors[x * zp + z] = mgl32.Vec3{ 0.5, 0.5, 0.5 }
+		}
+	}
+
+	// Same triangle strips as the terrain
+	water.Indices = make([]uint16, 0, (xp - 1) * zp * 2)
+	for x := uint32(0); x < xp - 1; x++ {
+		top := uint16(x * zp)
+		bottom := uint16(top + uint16(zp))
+		for z := uint32(0); z < zp; z++ {
+			water.Indices = append(water.Indices, top, bottom)
+			top++
+			bottom++
+		}
+	}
+
+	water.CalculateWaves()
+}
+
+//
+// CalculateWaves
+// Moves the vertices and normals of the grid to the current Time (sum of Gerstner waves)
+//
+func (water *Water) CalculateWaves() {
+	count := float32(len(water.Waves))
+
+	for v, base := range water.Base {
+		position := base
+		normal := mgl32.Vec3{ 0, 1, 0 }
+
+		for _, wave := range water.Waves {
+			if wave.Wavelength <= 0 {
+				continue
+			}
+
+			direction := wave.Direction
+			if direction.Len() > 0 {
+				direction = direction.Normalize()
+			}
+
+			k := 2.0 * math.Pi / float64(wave.Wavelength)
+			phase := k * (float64(direction.Dot(mgl32.Vec2{ base.X(), base.Z() })) - float64(wave.Speed * water.Time))
+			sin, cos := float32(math.Sin(phase)), float32(math.Cos(phase))
+
+			// Keeps the crests from looping over themselves
+			steepness := float32(0)
+			if wave.Amplitude > 0 {
+				steepness = wave.Steepness / (float32(k) * wave.Amplitude * count)
+			}
+
+			ka := float32(k) * wave.Amplitude
+
+			position[0] += steepness * wave.Amplitude * direction.X() * cos
+			position[1] += wave.Amplitude * sin
+			position[2] += steepness * wave.Amplitude * direction.Y() * cos
+
+			normal[0] -= direction.X() * ka * cos
+			normal[1] -= steepness * ka * sin
+			normal[2] -= direction.Y() * ka * cos
+		}
+
+		water.Vertices[v] = position
+		water.Normals[v] = normal.Normalize()
+	}
+}
+
+//
+// Update
+// Moves the waves forward and sends the new vertices and normals to the buffers
+//
+// @param seconds (float32) the time since the last update, in seconds
+//
+func (water *Water) Update(seconds float32) {
+	water.Time += seconds
+	water.CalculateWaves()
+
+	if water.VBOVertices == 0 {
+		return
+	}
+
+	water.Device.BindBuffer(gl.ARRAY_BUFFER, water.VBOVertices)
+	water.Device.BufferSubData(gl.ARRAY_BUFFER, 0, len(water.Vertices) * SizeOfVec3, water.Vertices)
+
+	water.Device.BindBuffer(gl.ARRAY_BUFFER, water.VBONormals)
+	water.Device.BufferSubData(gl.ARRAY_BUFFER, 0, len(water.Normals) * SizeOfVec3, water.Normals)
+	water.Device.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+//
+// CreateObject
+// Creates the buffers (the positions and normals are DYNAMIC_DRAW, as they change every frame)
+//
+func (water *Water) CreateObject() {
+	water.VBOVertices = water.Device.GenBuffer()
+	water.Device.BindBuffer(gl.ARRAY_BUFFER, water.VBOVertices)
+	water.Device.BufferData(gl.ARRAY_BUFFER, len(water.Vertices) * SizeOfVec3, water.Vertices, gl.DYNAMIC_DRAW)
+
+	water.VBONormals = water.Device.GenBuffer()
+	water.Device.BindBuffer(gl.ARRAY_BUFFER, water.VBONormals)
+	water.Device.BufferData(gl.ARRAY_BUFFER, len(water.Normals) * SizeOfVec3, water.Normals, gl.DYNAMIC_DRAW)
+
+	water.VBOColors = water.Device.GenBuffer()
+	water.Device.BindBuffer(gl.ARRAY_BUFFER, water.VBOColors)
+	water.Device.BufferData(gl.ARRAY_BUFFER, len(water.Colors) * SizeOfVec3, water.Colors, gl.STATIC_DRAW)
+	water.Device.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	water.VBOIndices = water.Device.GenBuffer()
+	water.Device.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, water.VBOIndices)
+	water.Device.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(water.Indices) * SizeOfUint16, water.Indices, gl.STATIC_DRAW)
+	water.Device.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
+}
+
+//
+// DeleteObject
+// Deletes the buffers (the CPU side arrays are kept)
+//
+func (water *Water) DeleteObject() {
+	for _, buffer := range []uint32{ water.VBOVertices, water.VBONormals, water.VBOColors, water.VBOIndices } {
+		water.Device.DeleteBuffer(buffer)
+	}
+
+	water.VBOVertices, water.VBONormals, water.VBOColors, water.VBOIndices = 0, 0, 0, 0
+}
+
+//
+// DrawObject
+// Draws the water with the given shader (uses the same attributes as the terrain)
+//
+// @param shaderProgram (uint32) the shader program to draw with
+//
+func (water *Water) DrawObject(shaderProgram uint32) {
+	toneUniform := water.Device.GetUniformLocation(shaderProgram, "tone")
+	water.Device.Uniform4f(toneUniform, water.ColorTone.X(), water.ColorTone.Y(), water.ColorTone.Z(), water.ColorTone.W())
+
+	modelUniform := water.Device.GetUniformLocation(shaderProgram, "model")
+	model := water.Node.World()
+	water.Device.UniformMatrix4fv(modelUniform, 1, false, model[:])
+
+	verticesUniform := uint32(water.Device.GetAttribLocation(shaderProgram, "position"))
+	normalsUniform := uint32(water.Device.GetAttribLocation(shaderProgram, "normal"))
+	colorsUniform := uint32(water.Device.GetAttribLocation(shaderProgram, "colour"))
+
+	water.Device.EnableVertexAttribArray(verticesUniform)
+	water.Device.BindBuffer(gl.ARRAY_BUFFER, water.VBOVertices)
+	water.Device.VertexAttribPointer(verticesUniform, 3, gl.FLOAT, false, 0, 0)
+
+	water.Device.EnableVertexAttribArray(normalsUniform)
+	water.Device.BindBuffer(gl.ARRAY_BUFFER, water.VBONormals)
+	water.Device.VertexAttribPointer(normalsUniform, 3, gl.FLOAT, false, 0, 0)
+
+	water.Device.EnableVertexAttribArray(colorsUniform)
+	water.Device.BindBuffer(gl.ARRAY_BUFFER, water.VBOColors)
+	water.Device.VertexAttribPointer(colorsUniform, 3, gl.FLOAT, false, 0, 0)
+
+	water.Device.PointSize(3.0)
+	water.Device.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, water.VBOIndices)
+
+	switch water.DrawMode {
+	case DRAW_LINES:
+		water.Device.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	case DRAW_POINTS:
+		water.Device.DrawArrays(gl.POINTS, 0, int32(len(water.Vertices)))
+		return
+	default:
+		water.Device.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	}
+
+	// One triangle strip per row
+	for i := uint32(0); i < water.XSize - 1; i++ {
+		location := SizeOfUint16 * int(i * water.ZSize * 2)
+		water.Device.DrawElements(gl.TRIANGLE_STRIP, int32(water.ZSize * 2), gl.UNSIGNED_SHORT, location)
+	}
+}
+
+func (water *Water) ResetModel() {
+	water.Node.Reset()
+}
+
+func (water *Water) Translate(Tx, Ty, Tz float32) {
+	water.Node.Translate(Tx, Ty, Tz)
+}
+
+func (water *Water) Scale(scaleX, scaleY, scaleZ float32) {
+	water.Node.Scale(scaleX, scaleY, scaleZ)
+}
+
+func (water *Water) RotateRadians(radians float32, axis mgl32.Vec3) {
+	water.Node.RotateRadians(radians, axis)
+}
+
+func (water *Water) RotateDegrees(degrees float32, axis mgl32.Vec3) {
+	water.Node.RotateDegrees(degrees, axis)
+}
+
+func (water *Water) GetNode () *SceneNode {
+	return water.Node
+}
+
+func (water *Water) GetDrawMode () DrawMode {
+	return water.DrawMode
+}
+
+func (water *Water) SetDrawMode (drawMode DrawMode) {
+	water.DrawMode = drawMode
+}
+
+func (water *Water) GetName () string {
+	return water.Name
+}
+
+func (water *Water) String () string {
+	return fmt.Sprintf(`
+                 Water --> %s (%d waves, %.2fs)
+    -------------------------------------
+    %s
+    -------------------------------------
+    `, water.Name, len(water.Waves), water.Time, water.Node.World())
+}
@@ -0,0 +1,259 @@
+package models
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+//
+// WaterPass
+// Renders the reflection and refraction of the scene into offscreen framebuffers
+// and draws the water with them (using the water shader).
+// If the framebuffers can't be created it falls back to a plain transparent surface
+//
+type WaterPass struct {
+	Water         *Water
+
+	Reflection    *wrapper.Framebuffer
+	Refraction    *wrapper.Framebuffer
+	Fallback      bool    // True when there are no framebuffers
+
+	Width, Height int32   // Size of the window
+	TextureScale  float32 // Size of the reflection, relative to the window
+
+	DeepColor     mgl32.Vec4 // Colour of the water far from the shore (the shallow colour is the water tone)
+	MaxDepth      float32    // Depth where the water reaches the DeepColor
+	ShoreFade     float32    // Depth where the water stops being transparent near the shore
+	FresnelPower  float32    // Higher values show more refraction when looking down
+	Distortion    float32    // How much the waves bend the reflection and refraction
+
+	Near, Far     float32    // The projection planes (to read the depth texture)
+
+	Device        wrapper.Device
+}
+
+//
+// NewWaterPass
+// Creates the water pass and its framebuffers
+//
+// @param device (wrapper.Device) the device that creates the framebuffers and draws the water
+// @param water (*Water) the water to draw
+// @param width (int32) the width of the window
+// @param height (int32) the height of the window
+// @param near (float32) the near plane of the projection
+// @param far (float32) the far plane of the projection
+//
+// @return pass (*WaterPass) a pointer to the water pass
+//
+func NewWaterPass(device wrapper.Device, water *Water, width, height int32, near, far float32) *WaterPass {
+	pass := &WaterPass{
+		water,
+
+		nil,							// Reflection
+		nil,							// Refraction
+		true,							// Fallback
+
+		width,							// Width
+		height,							// Height
+		0.5,							// TextureScale
+
+		mgl32.Vec4{ 0.0, 0.1, 0.25, 1 },// DeepColor
+		20.0,							// MaxDepth
+		1.5,							// ShoreFade
+		2.0,							// FresnelPower
+		0.02,							// Distortion
+
+		near,							// Near
+		far,							// Far
+
+		device,							// Device
+	}
+
+	pass.CreateFramebuffers()
+	return pass
+}
+
+//
+// CreateFramebuffers
+// (Re)creates the framebuffers for the current window size, or enables the fallback if it fails
+//
+func (pass *WaterPass) CreateFramebuffers() {
+	pass.DeleteFramebuffers()
+
+	var err error
+	pass.Reflection, err = wrapper.NewFramebuffer(pass.Device, int32(float32(pass.Width) * pass.TextureScale), int32(float32(pass.Height) * pass.TextureScale))
+	if err == nil {
+		pass.Refraction, err = wrapper.NewFramebuffer(pass.Device, pass.Width, pass.Height)
+	}
+
+	if err != nil {
+		log.Println("Water reflections disabled:", err)
+		pass.DeleteFramebuffers()
+		pass.Fallback = true
+		return
+	}
+
+	pass.Fallback = false
+}
+
+//
+// DeleteFramebuffers
+// Deletes the framebuffers (if there are any)
+//
+func (pass *WaterPass) DeleteFramebuffers() {
+	if pass.Reflection != nil {
+		pass.Reflection.Delete()
+		pass.Reflection = nil
+	}
+
+	if pass.Refraction != nil {
+		pass.Refraction.Delete()
+		pass.Refraction = nil
+	}
+}
+
+//
+// Resize
+// Changes the size of the framebuffers when the window changes size
+//
+// @param width (int32) the width of the window
+// @param height (int32) the height of the window
+//
+func (pass *WaterPass) Resize(width, height int32) {
+	if width == pass.Width && height == pass.Height {
+		return
+	}
+
+	pass.Width, pass.Height = width, height
+	if !pass.Fallback {
+		pass.CreateFramebuffers()
+	}
+}
+
+//
+// Plane
+// The plane of the water surface in world space (a, b, c, d), with the normal going up
+//
+func (pass *WaterPass) Plane() mgl32.Vec4 {
+	model := pass.Water.Node.World()
+	point := model.Mul4x1(mgl32.Vec4{ 0, 0, 0, 1 }).Vec3()
+	normal := model.Inv().Transpose().Mul4x1(mgl32.Vec4{ 0, 1, 0, 0 }).Vec3().Normalize()
+
+	return normal.Vec4(-normal.Dot(point))
+}
+
+//
+// ReflectionMatrix
+// Mirrors the world on the water plane (the reflected view is view * ReflectionMatrix)
+//
+func (pass *WaterPass) ReflectionMatrix() mgl32.Mat4 {
+	plane := pass.Plane()
+	a, b, c, d := plane.X(), plane.Y(), plane.Z(), plane.W()
+
+	return mgl32.Mat4FromRows(
+		mgl32.Vec4{ 1 - 2 * a * a, -2 * a * b, -2 * a * c, -2 * a * d },
+		mgl32.Vec4{ -2 * a * b, 1 - 2 * b * b, -2 * b * c, -2 * b * d },
+		mgl32.Vec4{ -2 * a * c, -2 * b * c, 1 - 2 * c * c, -2 * c * d },
+		mgl32.Vec4{ 0, 0, 0, 1 },
+	)
+}
+
+//
+// Render
+// Draws the scene into the reflection and refraction framebuffers (does nothing in fallback mode)
+//
+// @param view (mgl32.Mat4) the view matrix of the camera
+// @param drawScene (func(view mgl32.Mat4, clipPlane mgl32.Vec4)) draws everything but the water,
+//        the shaders have to discard what is on the negative side of the clip plane
+//
+func (pass *WaterPass) Render(view mgl32.Mat4, drawScene func(view mgl32.Mat4, clipPlane mgl32.Vec4)) {
+	if pass.Fallback {
+		return
+	}
+
+	plane := pass.Plane()
+
+	// A little overlap hides the gap on the edges of the waves
+	offset := pass.Water.maxAmplitude()
+
+	pass.Device.Enable(gl.CLIP_DISTANCE0)
+
+	// Reflection: the mirrored view, only what is above the water
+	pass.Reflection.Bind()
+	pass.Device.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	drawScene(view.Mul4(pass.ReflectionMatrix()), mgl32.Vec4{ plane.X(), plane.Y(), plane.Z(), plane.W() + offset })
+
+	// Refraction: the normal view, only what is under the water
+	pass.Refraction.Bind()
+	pass.Device.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	drawScene(view, mgl32.Vec4{ -plane.X(), -plane.Y(), -plane.Z(), -plane.W() + offset })
+
+	pass.Refraction.Unbind(pass.Width, pass.Height)
+	pass.Device.Disable(gl.CLIP_DISTANCE0)
+}
+
+//
+// DrawObject
+// Draws the water with the reflection and refraction textures
+//
+// @param shaderProgram (uint32) the water shader program
+//
+func (pass *WaterPass) DrawObject(shaderProgram uint32) {
+	fallback := uint32(0)
+	if pass.Fallback {
+		fallback = 1
+	} else {
+		textures := []uint32{ pass.Reflection.ColorTexture, pass.Refraction.ColorTexture, pass.Refraction.DepthTexture }
+		names := []string{ "reflection", "refraction", "depthmap" }
+
+		for unit, texture := range textures {
+			pass.Device.ActiveTexture(gl.TEXTURE0 + uint32(unit))
+			pass.Device.BindTexture(gl.TEXTURE_2D, texture)
+			pass.Device.Uniform1i(pass.Device.GetUniformLocation(shaderProgram, names[unit]), int32(unit))
+		}
+	}
+
+	pass.Device.Uniform1ui(pass.Device.GetUniformLocation(shaderProgram, "fallback"), fallback)
+	pass.Device.Uniform4f(pass.Device.GetUniformLocation(shaderProgram, "deepcolour"), pass.DeepColor.X(), pass.DeepColor.Y(), pass.DeepColor.Z(), pass.DeepColor.W())
+	pass.Device.Uniform1f(pass.Device.GetUniformLocation(shaderProgram, "maxdepth"), pass.MaxDepth)
+	pass.Device.Uniform1f(pass.Device.GetUniformLocation(shaderProgram, "shorefade"), pass.ShoreFade)
+	pass.Device.Uniform1f(pass.Device.GetUniformLocation(shaderProgram, "fresnelpower"), pass.FresnelPower)
+	pass.Device.Uniform1f(pass.Device.GetUniformLocation(shaderProgram, "distortion"), pass.Distortion)
+	pass.Device.Uniform1f(pass.Device.GetUniformLocation(shaderProgram, "near"), pass.Near)
+	pass.Device.Uniform1f(pass.Device.GetUniformLocation(shaderProgram, "far"), pass.Far)
+
+	// The shader does the shoreline fade with the alpha
+	pass.Device.Enable(gl.BLEND)
+	pass.Device.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	pass.Water.DrawObject(shaderProgram)
+
+	pass.Device.Disable(gl.BLEND)
+
+	if !pass.Fallback {
+		for unit := 2; unit >= 0; unit-- {
+			pass.Device.ActiveTexture(gl.TEXTURE0 + uint32(unit))
+			pass.Device.BindTexture(gl.TEXTURE_2D, 0)
+		}
+	}
+}
+
+func (pass *WaterPass) String() string {
+	return fmt.Sprintf("Water Pass --> %s (%dx%d, fallback: %t)", pass.Water.Name, pass.Width, pass.Height, pass.Fallback)
+}
+
+// maxAmplitude is the highest the waves can go above the water plane
+func (water *Water) maxAmplitude() float32 {
+	amplitude := float32(0)
+	for _, wave := range water.Waves {
+		amplitude += wave.Amplitude
+	}
+
+	// In world units (the model can scale the water)
+	return amplitude * water.Node.World().Mul4x1(mgl32.Vec4{ 0, 1, 0, 0 }).Vec3().Len()
+}
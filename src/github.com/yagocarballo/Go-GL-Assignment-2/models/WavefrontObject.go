@@ -19,10 +19,15 @@ type WavefrontObject struct {
 
 	Objects						[]*loader.ObjectData
 
+	Node						*SceneNode   // Transform of the whole model
+	Children					[]*SceneNode // Transform of every object (group) of the file, children of Node
+
 	DrawMode					DrawMode
+
+	Device						wrapper.Device
 }
 
-func NewObjectLoader () *WavefrontObject {
+func NewObjectLoader (device wrapper.Device) *WavefrontObject {
 	return &WavefrontObject{
 		"Obj", // Name
 
@@ -31,12 +36,17 @@ func NewObjectLoader () *WavefrontObject {
 
 		[]*loader.ObjectData{}, // Objects
 
+		NewSceneNode("Obj"),	// Node
+		[]*SceneNode{},			// Children
+
 		DRAW_POLYGONS, // Draw Mode
+
+		device, // Device
 	}
 }
 
 func (objectLoader *WavefrontObject) LoadObject (filename string) {
-	load := loader.NewLoader()
+	load := loader.NewLoader(objectLoader.Device)
 	objects, err := load.Load(filename)
 
 	log.Printf("Loaded %d Objects. \n", len(objects))
@@ -47,11 +57,23 @@ func (objectLoader *WavefrontObject) LoadObject (filename string) {
 	}
 
 	objectLoader.Objects = objects
+
+	// Every object of the file gets its own node under the model
+	for _, child := range objectLoader.Children {
+		objectLoader.Node.RemoveChild(child)
+	}
+	objectLoader.Children = []*SceneNode{}
+	for _, object := range objects {
+		child := NewSceneNode(object.Name)
+		objectLoader.Node.AddChild(child)
+		objectLoader.Children = append(objectLoader.Children, child)
+	}
 }
 
 func (objectLoader *WavefrontObject) CreateObject () {
-	for _, object := range objectLoader.Objects {
+	for index, object := range objectLoader.Objects {
 		// Sets the Model in the Initial position
+		objectLoader.Children[index].Reset()
 		object.Model = mgl32.Ident4()
 
 		if wrapper.DEBUG {
@@ -60,144 +82,91 @@ func (objectLoader *WavefrontObject) CreateObject () {
 		}
 
 		// Generate the vertex buffer object
-		gl.GenBuffers(1, &object.VertexBufferObjectVertices)
-		gl.BindBuffer(gl.ARRAY_BUFFER, object.VertexBufferObjectVertices)
-		gl.BufferData(gl.ARRAY_BUFFER, int(len(object.Vertex)*4), gl.Ptr(&(object.Vertex[0])), gl.STATIC_DRAW)
-		gl.BindBuffer(gl.ARRAY_BUFFER, 0);
+		object.VertexBufferObjectVertices = objectLoader.Device.GenBuffer()
+		objectLoader.Device.BindBuffer(gl.ARRAY_BUFFER, object.VertexBufferObjectVertices)
+		objectLoader.Device.BufferData(gl.ARRAY_BUFFER, len(object.Vertex) * SizeOfFloat32, object.Vertex, gl.STATIC_DRAW)
+		objectLoader.Device.BindBuffer(gl.ARRAY_BUFFER, 0);
 
 		// Obj might not have normals
 		if len(object.Normals) != 0 {
 			// Store the normals in a buffer object
-			gl.GenBuffers(1, &object.VertexBufferObjectNormals)
-			gl.BindBuffer(gl.ARRAY_BUFFER, object.VertexBufferObjectNormals)
-			gl.BufferData(gl.ARRAY_BUFFER, int(len(object.Normals) * 4), gl.Ptr(&(object.Normals[0])), gl.STATIC_DRAW)
-			gl.BindBuffer(gl.ARRAY_BUFFER, 0);
+			object.VertexBufferObjectNormals = objectLoader.Device.GenBuffer()
+			objectLoader.Device.BindBuffer(gl.ARRAY_BUFFER, object.VertexBufferObjectNormals)
+			objectLoader.Device.BufferData(gl.ARRAY_BUFFER, len(object.Normals) * SizeOfFloat32, object.Normals, gl.STATIC_DRAW)
+			objectLoader.Device.BindBuffer(gl.ARRAY_BUFFER, 0);
 		}
 
 		// Generate a buffer for the indices
-		gl.GenBuffers(1, &object.VertexBufferObjectFaces)
-		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, object.VertexBufferObjectFaces)
-		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, int(len(object.Faces)*3), gl.Ptr(&(object.Faces[0])), gl.STATIC_DRAW)
-		gl.BindBuffer(gl.ARRAY_BUFFER, 0);
+		object.VertexBufferObjectFaces = objectLoader.Device.GenBuffer()
+		objectLoader.Device.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, object.VertexBufferObjectFaces)
+		objectLoader.Device.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(object.Faces) * SizeOfUint16, object.Faces, gl.STATIC_DRAW)
+		objectLoader.Device.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0);
 
 		if len(object.Coordinates) != 0 {
 			// Generate a buffer for the Texture Coordinates
-			gl.GenBuffers(1, &object.VertexBufferObjectTextureCoords)
-			gl.BindBuffer(gl.ARRAY_BUFFER, object.VertexBufferObjectTextureCoords)
-			gl.BufferData(gl.ARRAY_BUFFER, int(len(object.Coordinates) * 2) * 2, gl.Ptr(&(object.Coordinates[0])), gl.STATIC_DRAW)
-			gl.BindBuffer(gl.ARRAY_BUFFER, 0);
+			object.VertexBufferObjectTextureCoords = objectLoader.Device.GenBuffer()
+			objectLoader.Device.BindBuffer(gl.ARRAY_BUFFER, object.VertexBufferObjectTextureCoords)
+			objectLoader.Device.BufferData(gl.ARRAY_BUFFER, len(object.Coordinates) * SizeOfFloat32, object.Coordinates, gl.STATIC_DRAW)
+			objectLoader.Device.BindBuffer(gl.ARRAY_BUFFER, 0);
 		}
 	}
 }
 
 func (objectLoader *WavefrontObject) DrawObject(shaderProgram uint32) {
-	for _, object := range objectLoader.Objects {
+	for index, object := range objectLoader.Objects {
 		// Reads the uniform Locations
-		modelUniform := gl.GetUniformLocation(shaderProgram, gl.Str("model\x00"));
-		ambientUniform := gl.GetUniformLocation(shaderProgram, gl.Str("ambient\x00"));
-		diffuseUniform := gl.GetUniformLocation(shaderProgram, gl.Str("diffuse\x00"));
-		specularUniform := gl.GetUniformLocation(shaderProgram, gl.Str("specular\x00"));
-		emissiveUniform := gl.GetUniformLocation(shaderProgram, gl.Str("emissive\x00"));
+		modelUniform := objectLoader.Device.GetUniformLocation(shaderProgram, "model");
 
 		// Send our uniforms variables to the currently bound shader
-		if object.Material != nil {
-			gl.Uniform4f(ambientUniform, object.Material.KaR, object.Material.KaG, object.Material.KaB, object.Material.Tr); // Ambient colour.
-			gl.Uniform4f(diffuseUniform, object.Material.KdR, object.Material.KdG, object.Material.KdB, object.Material.Tr); // Diffuse colour.
-			gl.Uniform4f(specularUniform, object.Material.KsR, object.Material.KsG, object.Material.KsB, object.Material.Tr); // Specular colour.
-			gl.Uniform4f(emissiveUniform, object.Material.KeR, object.Material.KeG, object.Material.KeB, object.Material.Tr); // Emissive colour.
+		useMaterial(objectLoader.Device, shaderProgram, object.Material)
 
-			if object.Material.Texture != 0 {
-				textureUniform := gl.GetUniformLocation(shaderProgram, gl.Str("DiffuseTextureSampler\x00"))
-				gl.Uniform1i(textureUniform, 0)
-
-				normalTextureUniform := gl.GetUniformLocation(shaderProgram, gl.Str("NormalTextureSampler\x00"))
-				gl.Uniform1i(normalTextureUniform, 1)
-
-				specularTextureUniform := gl.GetUniformLocation(shaderProgram, gl.Str("SpecularTextureSampler\x00"))
-				gl.Uniform1i(specularTextureUniform, 2)
-
-				gl.ActiveTexture(gl.TEXTURE0)
-				gl.BindTexture(gl.TEXTURE_2D, object.Material.Texture)
-
-				gl.ActiveTexture(gl.TEXTURE1)
-				gl.BindTexture(gl.TEXTURE_2D, object.Material.NormalMap)
-
-				gl.ActiveTexture(gl.TEXTURE2)
-				gl.BindTexture(gl.TEXTURE_2D, object.Material.SpecularMap)
+		// Geometry
+		// The transforms are on the scene nodes, the object keeps a copy of its matrix for the code that still reads it
+		object.Model = objectLoader.Children[index].World()
+		objectLoader.Device.UniformMatrix4fv(modelUniform, 1, false, object.Model[:]);
+
+		// Describe our vertices array to OpenGL (it can't guess its format automatically).
+		// The attributes the shader or the object don't have are skipped (GetAttribLocation gives -1)
+		enabled := []uint32{}
+		for _, attribute := range []struct{ name string; buffer uint32; size int32 }{
+			{ "position", object.VertexBufferObjectVertices, 3 },	// (x,y,z)
+			{ "normal", object.VertexBufferObjectNormals, 3 },		// (x,y,z)
+			{ "texcoord", object.VertexBufferObjectTextureCoords, 2 },	// (u,v)
+		} {
+			location := objectLoader.Device.GetAttribLocation(shaderProgram, attribute.name)
+			if attribute.buffer == 0 || location < 0 {
+				continue
 			}
 
-			if object.Material.Tr < 1.0 {
-				// Enables Transparencies
-				gl.Enable(gl.BLEND)
-//				gl.BlendFunc(gl.SRC_COLOR, gl.ONE)
-//				gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
-				gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-			}
+			objectLoader.Device.BindBuffer(gl.ARRAY_BUFFER, attribute.buffer)
+			objectLoader.Device.VertexAttribPointer(uint32(location), attribute.size, gl.FLOAT, false, 0, 0)
+			objectLoader.Device.EnableVertexAttribArray(uint32(location))
+			enabled = append(enabled, uint32(location))
 		}
 
-		// Geometry
-		var size int32    // Used to get the byte size of the element (vertex index) array
-
-		gl.UniformMatrix4fv(modelUniform, 1, false, &object.Model[0]);
-
-		// Get the vertices uniform position
-		verticesUniform := uint32(gl.GetAttribLocation(shaderProgram, gl.Str("position\x00")))
-		normalsUniform := uint32(gl.GetAttribLocation(shaderProgram, gl.Str("normal\x00")))
-		textureCoordinatesUniform := uint32(gl.GetAttribLocation(shaderProgram, gl.Str("texcoord\x00")))
-
-		// Describe our vertices array to OpenGL (it can't guess its format automatically)
-
-		gl.BindBuffer(gl.ARRAY_BUFFER, object.VertexBufferObjectVertices);
-		gl.VertexAttribPointer(
-			verticesUniform,				// attribute index
-			3,								// number of elements per vertex, here (x,y,z)
-			gl.FLOAT,						// the type of each element
-			false,							// take our values as-is
-			0,								// no extra data between each position
-			nil,							// offset of first element
-		)
-
-		gl.EnableVertexAttribArray(normalsUniform)
-		gl.BindBuffer(gl.ARRAY_BUFFER, object.VertexBufferObjectNormals);
-		gl.VertexAttribPointer(
-			normalsUniform,				// attribute
-			3, 							// number of elements per vertex, here (x,y,z)
-			gl.FLOAT,					// the type of each element
-			false,						// take our values as-is
-			0,							// no extra data between each position
-			nil,						// offset of first element
-		)
-
-		gl.EnableVertexAttribArray(textureCoordinatesUniform)
-		gl.BindBuffer(gl.ARRAY_BUFFER, object.VertexBufferObjectTextureCoords);
-		gl.VertexAttribPointer(
-			textureCoordinatesUniform,	// attribute
-			2, 							// number of elements per vertex, here (u,v)
-			gl.FLOAT,					// the type of each element
-			false,						// take our values as-is
-			0,							// no extra data between each position
-			nil,						// offset of first element
-		)
-
-		size = int32(len(object.Vertex))
-
-		gl.PointSize(3.0)
+		objectLoader.Device.PointSize(3.0)
 
 		// Enable this line to show model in wireframe
 		switch objectLoader.DrawMode {
-		case 1:
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+		case DRAW_LINES:
+			objectLoader.Device.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
 		default:
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+			objectLoader.Device.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+		}
+
+		if objectLoader.DrawMode == DRAW_POINTS {
+			objectLoader.Device.DrawArrays(gl.POINTS, 0, int32(len(object.Vertex) / 3))
+		} else {
+			objectLoader.Device.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, object.VertexBufferObjectFaces);
+			objectLoader.Device.DrawElements(gl.TRIANGLES, int32(len(object.Faces)), gl.UNSIGNED_SHORT, 0)
 		}
 
-		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, object.VertexBufferObjectFaces);
-		gl.GetBufferParameteriv(gl.ELEMENT_ARRAY_BUFFER, gl.BUFFER_SIZE, &size);
-		gl.DrawElements(gl.TRIANGLES, int32(len(object.Faces)), gl.UNSIGNED_SHORT, nil)
-//		gl.DrawElements(gl.POINTS, int32(len(object.Faces)), gl.UNSIGNED_SHORT, nil)
+		for _, location := range enabled {
+			objectLoader.Device.DisableVertexAttribArray(location)
+		}
 
 		// Disables transparencies
-		gl.Disable(gl.BLEND)
+		objectLoader.Device.Disable(gl.BLEND)
 	}
 }
 
@@ -205,45 +174,49 @@ func (objectLoader *WavefrontObject) DrawObject(shaderProgram uint32) {
 // Individual Objects
 
 func (objectLoader *WavefrontObject) ResetChildModel(index int) {
-	objectLoader.Objects[index].Model = mgl32.Ident4()
+	objectLoader.Children[index].Reset()
 }
 
 func (objectLoader *WavefrontObject) TranslateChild(index int, Tx, Ty, Tz float32) {
-	objectLoader.Objects[index].Model = objectLoader.Objects[index].Model.Mul4(mgl32.Translate3D(Tx, Ty, Tz))
+	objectLoader.Children[index].Translate(Tx, Ty, Tz)
 }
 
 func (objectLoader *WavefrontObject) ScaleChild(index int, scaleX, scaleY, scaleZ float32) {
-	objectLoader.Objects[index].Model = objectLoader.Objects[index].Model.Mul4(mgl32.Scale3D(scaleX, scaleY, scaleZ))
+	objectLoader.Children[index].Scale(scaleX, scaleY, scaleZ)
 }
 
-func (objectLoader *WavefrontObject) RotateChild(index int, angle float32, axis mgl32.Vec3) {
-	objectLoader.Objects[index].Model = objectLoader.Objects[index].Model.Mul4(mgl32.HomogRotate3D(angle, axis))
+func (objectLoader *WavefrontObject) RotateChildRadians(index int, radians float32, axis mgl32.Vec3) {
+	objectLoader.Children[index].RotateRadians(radians, axis)
+}
+
+func (objectLoader *WavefrontObject) RotateChildDegrees(index int, degrees float32, axis mgl32.Vec3) {
+	objectLoader.Children[index].RotateDegrees(degrees, axis)
 }
 
 // All Objects
 
 func (objectLoader *WavefrontObject) ResetModel() {
-	for index, _ := range objectLoader.Objects {
-		objectLoader.Objects[index].Model = mgl32.Ident4()
-	}
+	objectLoader.Node.Reset()
 }
 
 func (objectLoader *WavefrontObject) Translate(Tx, Ty, Tz float32) {
-	for index, _ := range objectLoader.Objects {
-		objectLoader.Objects[index].Model = objectLoader.Objects[index].Model.Mul4(mgl32.Translate3D(Tx, Ty, Tz))
-	}
+	objectLoader.Node.Translate(Tx, Ty, Tz)
 }
 
 func (objectLoader *WavefrontObject) Scale(scaleX, scaleY, scaleZ float32) {
-	for index, _ := range objectLoader.Objects {
-		objectLoader.Objects[index].Model = objectLoader.Objects[index].Model.Mul4(mgl32.Scale3D(scaleX, scaleY, scaleZ))
-	}
+	objectLoader.Node.Scale(scaleX, scaleY, scaleZ)
 }
 
-func (objectLoader *WavefrontObject) Rotate(angle float32, axis mgl32.Vec3) {
-	for index, _ := range objectLoader.Objects {
-		objectLoader.Objects[index].Model = objectLoader.Objects[index].Model.Mul4(mgl32.HomogRotate3D(angle, axis))
-	}
+func (objectLoader *WavefrontObject) RotateRadians(radians float32, axis mgl32.Vec3) {
+	objectLoader.Node.RotateRadians(radians, axis)
+}
+
+func (objectLoader *WavefrontObject) RotateDegrees(degrees float32, axis mgl32.Vec3) {
+	objectLoader.Node.RotateDegrees(degrees, axis)
+}
+
+func (objectLoader *WavefrontObject) GetNode () *SceneNode {
+	return objectLoader.Node
 }
 
 func (objectLoader *WavefrontObject) GetDrawMode () DrawMode {
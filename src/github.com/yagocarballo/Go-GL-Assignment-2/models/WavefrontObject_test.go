@@ -0,0 +1,174 @@
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+// twoSquares is a .obj file with two objects: a square made of two triangles and one triangle
+const twoSquares = `o Square
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+vn 0 0 1
+f 1/1/1 2/2/1 3/3/1
+f 1/1/1 3/3/1 4/4/1
+o Triangle
+v 0 0 1
+v 1 0 1
+v 0 1 1
+vt 0 0
+vt 1 0
+vt 0 1
+vn 0 0 -1
+f 5/5/2 6/6/2 7/7/2
+`
+
+// testObject loads the two squares file on the device
+func testObject(t *testing.T, device wrapper.Device) *WavefrontObject {
+	folder, err := ioutil.TempDir("", "wavefront")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	path := filepath.Join(folder, "squares.obj")
+	if err := ioutil.WriteFile(path, []byte(twoSquares), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	object := NewObjectLoader(device)
+	object.LoadObject(path)
+	if len(object.Objects) != 2 || len(object.Children) != 2 {
+		t.Fatalf("expected 2 objects, found %d", len(object.Objects))
+	}
+
+	return object
+}
+
+func TestWavefrontObjectBuffers(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	shaderManager := testShaders(t, device)
+	object := testObject(t, device)
+	object.CreateObject()
+
+	for _, err := range device.Errors {
+		t.Errorf("device error: %s", err)
+	}
+
+	faces := 0
+	for _, data := range object.Objects {
+		buffers := []struct {
+			name     string
+			buffer   uint32
+			expected []float32
+		}{
+			{ "vertices", data.VertexBufferObjectVertices, data.Vertex },
+			{ "normals", data.VertexBufferObjectNormals, data.Normals },
+			{ "texture coordinates", data.VertexBufferObjectTextureCoords, data.Coordinates },
+		}
+		for _, buffer := range buffers {
+			if len(buffer.expected) == 0 || !sameFloats(buffer.expected, device.BufferFloats(buffer.buffer)) {
+				t.Errorf("%s, %s: the buffer doesn't have the values of the file (%d values)", data.Name, buffer.name, len(buffer.expected))
+			}
+		}
+
+		indices := device.BufferUint16s(data.VertexBufferObjectFaces)
+		if len(device.Buffers[data.VertexBufferObjectFaces]) != len(data.Faces) * SizeOfUint16 {
+			t.Errorf("%s: expected %d bytes of faces, found %d", data.Name, len(data.Faces) * SizeOfUint16, len(device.Buffers[data.VertexBufferObjectFaces]))
+		}
+		for index := range indices {
+			if indices[index] != data.Faces[index] || int(indices[index]) >= len(data.Vertex) / 3 {
+				t.Fatalf("%s, index %d: expected %d, found %d", data.Name, index, data.Faces[index], indices[index])
+			}
+		}
+		faces += len(data.Faces)
+	}
+	if faces != 9 {
+		t.Errorf("expected 3 triangles, found %d indices", faces)
+	}
+	if device.Bindings[gl.ARRAY_BUFFER] != 0 || device.Bindings[gl.ELEMENT_ARRAY_BUFFER] != 0 {
+		t.Error("a buffer was left bound after the upload")
+	}
+
+	// Every object is drawn with the world matrix of its own node
+	object.Translate(0, 0, -5)
+	object.TranslateChild(1, 2, 0, 0)
+	for _, drawMode := range []DrawMode{ DRAW_POINTS, DRAW_LINES, DRAW_POLYGONS } {
+		device.Reset()
+		object.SetDrawMode(drawMode)
+		object.DrawObject(shaderManager.CurrentShader())
+
+		for _, err := range device.Errors {
+			t.Errorf("%s: device error: %s", drawMode, err)
+		}
+
+		draws := append(device.CallsNamed("DrawArrays"), device.CallsNamed("DrawElements")...)
+		if len(draws) != 2 {
+			t.Fatalf("%s: expected a draw per object, found %v", drawMode, draws)
+		}
+		for index, data := range object.Objects {
+			expected := wrapper.Call{ Name: "DrawElements", Args: []interface{}{ uint32(gl.TRIANGLES), int32(len(data.Faces)), uint32(gl.UNSIGNED_SHORT), 0 } }
+			if drawMode == DRAW_POINTS {
+				expected = wrapper.Call{ Name: "DrawArrays", Args: []interface{}{ uint32(gl.POINTS), int32(0), int32(len(data.Vertex) / 3) } }
+			}
+			if draws[index].String() != expected.String() {
+				t.Errorf("%s, %s: expected %s, found %s", drawMode, data.Name, expected, draws[index])
+			}
+		}
+
+		polygonModes := device.CallsNamed("PolygonMode")
+		expected := uint32(gl.FILL)
+		if drawMode == DRAW_LINES {
+			expected = gl.LINE
+		}
+		if len(polygonModes) != 2 || polygonModes[0].Args[1] != expected {
+			t.Errorf("%s: expected the polygon mode 0x%x, found %v", drawMode, expected, polygonModes)
+		}
+	}
+
+	matrices := device.CallsNamed("UniformMatrix4fv")
+	if len(matrices) != 2 {
+		t.Fatalf("expected a model matrix per object, found %d", len(matrices))
+	}
+	for index, data := range object.Objects {
+		world := object.Children[index].World()
+		if data.Model != world {
+			t.Errorf("%s: the model is not the world matrix of its node", data.Name)
+		}
+	}
+	if position := object.Children[1].WorldPosition(); position != (mgl32.Vec3{ 2, 0, -5 }) {
+		t.Errorf("expected the second object on (2, 0, -5), found %v", position)
+	}
+
+	// The position, normal and texture coordinates are enabled for each object and disabled after its draw
+	if enables, disables := device.CallsNamed("EnableVertexAttribArray"), device.CallsNamed("DisableVertexAttribArray"); len(enables) != 6 || len(disables) != 6 {
+		t.Errorf("expected 3 attributes enabled and disabled per object, found %v and %v", enables, disables)
+	}
+
+	// An attribute the shader doesn't have is skipped instead of being enabled on -1
+	device.Reset()
+	device.Programs[shaderManager.CurrentShader()].Locations["texcoord"] = -1
+	object.DrawObject(shaderManager.CurrentShader())
+	for _, err := range device.Errors {
+		t.Errorf("without texture coordinates: device error: %s", err)
+	}
+	if pointers := device.CallsNamed("VertexAttribPointer"); len(pointers) != 4 {
+		t.Errorf("expected the position and the normals of each object, found %v", pointers)
+	}
+	if enables, disables := device.CallsNamed("EnableVertexAttribArray"), device.CallsNamed("DisableVertexAttribArray"); len(enables) != 4 || len(disables) != 4 {
+		t.Errorf("expected 2 attributes enabled and disabled per object, found %v and %v", enables, disables)
+	}
+}
@@ -5,39 +5,199 @@ import (
     "fmt"
 )
 
+// Default lens of a new camera
+const DEFAULT_FIELD_OF_VIEW float32 = 45.0
+const DEFAULT_NEAR_PLANE float32 = 0.1
+const DEFAULT_FAR_PLANE float32 = 100.0
+
+//
+// Camera
+// A camera with a position, an orientation and a perspective lens.
+// The node is the camera in the world (it looks along its -z axis), so the view matrix is its inverse
+//
 type Camera struct {
-    Name    string
-    Model   mgl32.Mat4
+    Name        string
+    Node        *SceneNode
+
+    WorldUp     mgl32.Vec3       // The up direction of the scene (the controllers turn around it)
+
+    FieldOfView float32          // Vertical field of view in degrees
+    AspectRatio float32          // Width / Height of the viewport
+    Near, Far   float32          // Projection planes
+
+    Controller  CameraController // Moves the camera on every Update (nil leaves it where it is)
+}
+
+//
+// CameraController
+// Moves a camera from the input events. The events are stored and applied on the next Update
+//
+type CameraController interface {
+    // Move starts (pressed) or stops moving along an axis of the camera (x right, y up, z back)
+    Move(direction mgl32.Vec3, pressed bool)
+
+    // Steer sets the analog movement along the axes of the camera, -1 to 1 on every axis (it lasts until the next Steer)
+    Steer(direction mgl32.Vec3)
+
+    // Look turns the camera by a cursor movement in pixels
+    Look(dx, dy float32)
+
+    // Zoom moves the camera in (positive) or out (negative), in scroll steps
+    Zoom(amount float32)
+
+    // Stop drops the pending input (when the camera stops getting the events)
+    Stop()
+
+    // Update moves the camera, delta is the elapsed time in seconds
+    Update(camera *Camera, delta float32)
+}
+
+//
+// NewCamera
+// Creates a camera at eye looking at center, with the default lens
+//
+// @param name (string) the name of the camera
+// @param eye (mgl32.Vec3) the position of the camera
+// @param center (mgl32.Vec3) the point the camera looks at
+// @param up (mgl32.Vec3) the up direction of the scene
+//
+// @return camera (*Camera) a pointer to the camera
+//
+func NewCamera (name string, eye, center, up mgl32.Vec3) *Camera {
+    camera := &Camera{
+        name,                       // Name
+        NewSceneNode(name),         // Node
+        up.Normalize(),             // WorldUp
+        DEFAULT_FIELD_OF_VIEW,      // FieldOfView
+        4.0 / 3.0,                  // AspectRatio
+        DEFAULT_NEAR_PLANE,         // Near
+        DEFAULT_FAR_PLANE,          // Far
+        nil,                        // Controller
+    }
+    camera.LookAt(eye, center, up)
+
+    return camera
+}
+
+//
+// LookAt
+// Moves the camera to eye and turns it to center (it has no roll relative to up)
+//
+// @param eye (mgl32.Vec3) the position of the camera
+// @param center (mgl32.Vec3) the point the camera looks at
+// @param up (mgl32.Vec3) the up direction
+//
+func (camera *Camera) LookAt (eye, center, up mgl32.Vec3) {
+    if eye.ApproxEqual(center) {
+        return
+    }
+
+    camera.Node.SetMatrix(mgl32.LookAtV(eye, center, up).Inv())
+}
+
+//
+// View
+// Returns the view matrix (world to camera space)
+//
+func (camera *Camera) View () mgl32.Mat4 {
+    return camera.Node.World().Inv()
+}
+
+//
+// Projection
+// Returns the perspective projection matrix of the lens
+//
+func (camera *Camera) Projection () mgl32.Mat4 {
+    return mgl32.Perspective(mgl32.DegToRad(camera.FieldOfView), camera.AspectRatio, camera.Near, camera.Far)
+}
+
+//
+// SetViewport
+// Updates the aspect ratio for a new window size
+//
+// @param width (int) the width of the viewport
+// @param height (int) the height of the viewport
+//
+func (camera *Camera) SetViewport (width, height int) {
+    if width > 0 && height > 0 {
+        camera.AspectRatio = float32(width) / float32(height)
+    }
+}
+
+//
+// Update
+// Moves the camera with its controller
+//
+// @param delta (float32) the elapsed time in seconds
+//
+func (camera *Camera) Update (delta float32) {
+    if camera.Controller != nil {
+        camera.Controller.Update(camera, delta)
+    }
+}
+
+//
+// SetController
+// Replaces the controller (the old one drops its pending input)
+//
+// @param controller (CameraController) the new controller (nil to stop moving the camera)
+//
+func (camera *Camera) SetController (controller CameraController) {
+    if camera.Controller != nil {
+        camera.Controller.Stop()
+    }
+
+    camera.Controller = controller
 }
 
-func NewCamera (name string, initialModel mgl32.Mat4) *Camera {
-    return &Camera{ name, initialModel }
+// Position returns the position of the camera in the world
+func (camera *Camera) Position () mgl32.Vec3 {
+    return camera.Node.WorldPosition()
 }
 
-func (camera *Camera) Draw () {
+// Forward returns the direction the camera looks at in the world
+func (camera *Camera) Forward () mgl32.Vec3 {
+    return camera.Node.World().Mul4x1(mgl32.Vec4{ 0, 0, -1, 0 }).Vec3().Normalize()
+}
 
+// Right returns the right of the camera in the world
+func (camera *Camera) Right () mgl32.Vec3 {
+    return camera.Node.World().Mul4x1(mgl32.Vec4{ 1, 0, 0, 0 }).Vec3().Normalize()
+}
+
+// Up returns the up of the camera in the world (it's the WorldUp only when looking at the horizon)
+func (camera *Camera) Up () mgl32.Vec3 {
+    return camera.Node.World().Mul4x1(mgl32.Vec4{ 0, 1, 0, 0 }).Vec3().Normalize()
 }
 
 func (camera *Camera) ResetModel () {
-    camera.Model = mgl32.Ident4()
+    camera.Node.Reset()
 }
 
 func (camera *Camera) Translate (Tx, Ty, Tz float32) {
-    camera.Model = camera.Model.Mul4(mgl32.Translate3D(Tx, Ty, Tz))
+    camera.Node.Translate(Tx, Ty, Tz)
 }
 
 func (camera *Camera) Scale (scaleX, scaleY, scaleZ float32) {
-    camera.Model = camera.Model.Mul4(mgl32.Scale3D(scaleX, scaleY, scaleZ))
+    camera.Node.Scale(scaleX, scaleY, scaleZ)
 }
 
-func (camera *Camera) Rotate (angle float32, axis mgl32.Vec3) {
-    camera.Model = camera.Model.Mul4(mgl32.HomogRotate3D(angle, axis))
+func (camera *Camera) RotateRadians (radians float32, axis mgl32.Vec3) {
+    camera.Node.RotateRadians(radians, axis)
+}
+
+func (camera *Camera) RotateDegrees (degrees float32, axis mgl32.Vec3) {
+    camera.Node.RotateDegrees(degrees, axis)
 }
 
 func (camera *Camera) GetName () string {
     return camera.Name
 }
 
+func (camera *Camera) GetNode () *SceneNode {
+    return camera.Node
+}
+
 func (camera *Camera) GetDrawMode () DrawMode { return DRAW_POLYGONS }
 func (camera *Camera) SetDrawMode (drawMode DrawMode) {}
 
@@ -45,8 +205,9 @@ func (camera *Camera) String () string {
     return fmt.Sprintf(`
              Camera --> %s
     -------------------------------------
-    %s
+    Position: %v
+    Forward: %v
+    Field of View: %v, Aspect Ratio: %v, Near: %v, Far: %v
     -------------------------------------
-    `, camera.Name, camera.Model)
+    `, camera.Name, camera.Position(), camera.Forward(), camera.FieldOfView, camera.AspectRatio, camera.Near, camera.Far)
 }
-
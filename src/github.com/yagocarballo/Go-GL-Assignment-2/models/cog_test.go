@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+func TestCogBuffers(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	shaderManager := testShaders(t, device)
+	cog := NewCog(device, "Cog", 20, 0.5, 1, 0.2, shaderManager)
+
+	checkMeshModel(t, device, shaderManager, cog, func() (*geometry.Mesh, *MeshBuffers) {
+		cog.MakeCogVBO()
+		return cog.Mesh, cog.Buffers
+	})
+
+	// The old code uploaded 8 * len * 3 bytes from the float32 slice, now every buffer has the bytes of its data
+	for _, call := range device.CallsNamed("BufferData") {
+		if call.Args[1].(int) % SizeOfFloat32 != 0 {
+			t.Errorf("%s doesn't upload whole values", call)
+		}
+	}
+	if size := len(device.Buffers[cog.Buffers.Positions]); size != len(cog.Mesh.Positions) * 3 * 4 {
+		t.Errorf("expected %d bytes of positions, found %d", len(cog.Mesh.Positions) * 12, size)
+	}
+
+	// The colours are the positions moved a bit
+	positions, colors := device.BufferFloats(cog.Buffers.Positions), device.BufferFloats(cog.Buffers.Colors)
+	for index := 0; index < len(positions) / 3; index++ {
+		expected := []float32{ 0.3 + positions[index * 3], 0.5 + positions[index * 3 + 1], 0.3 + positions[index * 3 + 2], 1 }
+		if !sameFloats(expected, colors[index * 4:index * 4 + 4]) {
+			t.Fatalf("vertex %d: expected the colour %v, found %v", index, expected, colors[index * 4:index * 4 + 4])
+		}
+	}
+}
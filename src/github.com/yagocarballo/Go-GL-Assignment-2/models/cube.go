@@ -1,109 +1,86 @@
 package models
 
 import (
-	"github.com/go-gl/gl/all-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
     "fmt"
 
+    "github.com/yagocarballo/Go-GL-Assignment-2/geometry"
     "github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
 )
 
 type Cube struct {
     Name                                       string
 
-	bufferObject, normalsObject, coloursObject uint32
-	elementBuffer                              uint32
+	Mesh                                       *geometry.Mesh // The vertices (nil until MakeVBO)
+	Buffers                                    *MeshBuffers   // The buffers of the mesh
 
 	DrawMode                                   DrawMode // Defines drawing mode of cube as points, lines or filled polygons
 
-	vertexPositions, vertexColours, normals    *[]float32
+	Size                                       float32  // The length of the sides
 
-	Model                                      mgl32.Mat4
+	Node                                       *SceneNode // Transform of the cube in the scene graph
 
 
     ShaderManager                              *wrapper.ShaderManager // Pointer to the Shader Manager
+    Device                                     wrapper.Device         // Uploads and draws the buffers
 }
 
-func NewCube(name string, vertexPositions, vertexColours, normals *[]float32, shaderManager *wrapper.ShaderManager) *Cube {
+func NewCube(device wrapper.Device, name string, size float32, shaderManager *wrapper.ShaderManager) *Cube {
 	return &Cube{
         name,               // Name
-		0, 0, 0,            // bufferObject, normals, colours
-		0,                  // elementBuffer
+		nil,                // Mesh
+		nil,                // Buffers
 		DRAW_POLYGONS,      // drawmode
-		vertexPositions, vertexColours, normals, // vertexPositions, vertexColours, normals
-		mgl32.Ident4(),     // model
+		size,               // Size
+		NewSceneNode(name), // Node
         shaderManager,      // Pointer to the Shader Manager
+        device,             // Device
 	}
 }
 
+// Makes the cube mesh (a flat normal on every face), paints it from the normals and uploads it
 func (cube *Cube) MakeVBO() {
-	// Create a vertex buffer object to store vertices for the cube
-	gl.GenBuffers(1, &cube.bufferObject);
-	gl.BindBuffer(gl.ARRAY_BUFFER, cube.bufferObject);
-	gl.BufferData(gl.ARRAY_BUFFER, len(*cube.vertexPositions) * 4, gl.Ptr(*cube.vertexPositions), gl.STATIC_DRAW);
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0);
-
-	// Create a vertex buffer object to store vertex colours for the cube
-	gl.GenBuffers(1, &cube.coloursObject);
-	gl.BindBuffer(gl.ARRAY_BUFFER, cube.coloursObject);
-	gl.BufferData(gl.ARRAY_BUFFER, len(*cube.vertexColours) * 4, gl.Ptr(*cube.vertexColours), gl.STATIC_DRAW);
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0);
-
-	// Create the normals buffer for the cube
-	gl.GenBuffers(1, &cube.normalsObject);
-	gl.BindBuffer(gl.ARRAY_BUFFER, cube.normalsObject);
-	gl.BufferData(gl.ARRAY_BUFFER, len(*cube.normals) * 4, gl.Ptr(*cube.normals), gl.STATIC_DRAW);
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0);
+	cube.Mesh = geometry.Cube(cube.Size)
+	cube.Mesh.Paint(func(position, normal mgl32.Vec3) mgl32.Vec4 {
+		return mgl32.Vec4{ 0.5 + normal.X() * 0.5, 0.5 + normal.Y() * 0.5, 0.5 + normal.Z() * 0.5, 1.0 }
+	})
+
+	if cube.Buffers != nil {
+		cube.Buffers.Delete()
+	}
+	cube.Buffers = UploadMesh(cube.Device, cube.Mesh)
 }
 
 func (cube *Cube) Draw() {
-    // Adds the Sphere Model to the Active Shader
-    cube.ShaderManager.SetUniformMatrix4fv(cube.ShaderManager.ActiveShader, "model", 1, false, &cube.Model[0])
-
-    /* Bind cube vertices. Note that this is in attribute index 0 */
-	gl.BindBuffer(gl.ARRAY_BUFFER, cube.bufferObject)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 0, nil)
-
-	/* Bind cube colours. Note that this is in attribute index 1 */
-	gl.BindBuffer(gl.ARRAY_BUFFER, cube.coloursObject)
-	gl.EnableVertexAttribArray(1)
-	gl.VertexAttribPointer(1, 4, gl.FLOAT, false, 0, nil)
-
-	/* Bind cube normals. Note that this is in attribute index 2 */
-	gl.EnableVertexAttribArray(2)
-	gl.BindBuffer(gl.ARRAY_BUFFER, cube.normalsObject)
-	gl.VertexAttribPointer(2, 3, gl.FLOAT, false, 0, nil)
-
-	// Enable this line to show model in wireframe
-	if cube.DrawMode == DRAW_LINES {
-		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-	} else {
-		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-	}
+    // Adds the Cube Model to the Active Shader
+    model := cube.Node.World()
+    cube.ShaderManager.SetUniformMatrix4fv(cube.ShaderManager.ActiveShader, "model", 1, false, model[:])
 
-	/* Draw our cube*/
-	if cube.DrawMode == DRAW_POINTS {
-		gl.DrawArrays(gl.POINTS, 0, int32(32))
-	} else {
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-	}
+	cube.Buffers.Draw(cube.ShaderManager.CurrentShader(), cube.DrawMode)
 }
 
 func (cube *Cube) ResetModel () {
-	cube.Model = mgl32.Ident4()
+	cube.Node.Reset()
 }
 
 func (cube *Cube) Translate (Tx, Ty, Tz float32) {
-	cube.Model = cube.Model.Mul4(mgl32.Translate3D(Tx, Ty, Tz))
+	cube.Node.Translate(Tx, Ty, Tz)
 }
 
 func (cube *Cube) Scale (scaleX, scaleY, scaleZ float32) {
-	cube.Model = cube.Model.Mul4(mgl32.Scale3D(scaleX, scaleY, scaleZ))
+	cube.Node.Scale(scaleX, scaleY, scaleZ)
+}
+
+func (cube *Cube) RotateRadians (radians float32, axis mgl32.Vec3) {
+	cube.Node.RotateRadians(radians, axis)
+}
+
+func (cube *Cube) RotateDegrees (degrees float32, axis mgl32.Vec3) {
+	cube.Node.RotateDegrees(degrees, axis)
 }
 
-func (cube *Cube) Rotate (angle float32, axis mgl32.Vec3) {
-	cube.Model = cube.Model.Mul4(mgl32.HomogRotate3D(angle, axis))
+func (cube *Cube) GetNode () *SceneNode {
+    return cube.Node
 }
 
 func (cube *Cube) GetDrawMode () DrawMode {
@@ -124,5 +101,5 @@ func (cube *Cube) String () string {
     -------------------------------------
     %s
     -------------------------------------
-    `, cube.Name, cube.Model)
+    `, cube.Name, cube.Node.World())
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+func TestCubeBuffers(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	shaderManager := testShaders(t, device)
+	cube := NewCube(device, "Cube", 2, shaderManager)
+
+	checkMeshModel(t, device, shaderManager, cube, func() (*geometry.Mesh, *MeshBuffers) {
+		cube.MakeVBO()
+		return cube.Mesh, cube.Buffers
+	})
+
+	// Every face is painted from its normal
+	colors := device.BufferFloats(cube.Buffers.Colors)
+	for index, normal := range cube.Mesh.Normals {
+		expected := mgl32.Vec4{ 0.5 + normal.X() * 0.5, 0.5 + normal.Y() * 0.5, 0.5 + normal.Z() * 0.5, 1 }
+		if !sameFloats(expected[:], colors[index * 4:index * 4 + 4]) {
+			t.Fatalf("vertex %d: expected the colour %v, found %v", index, expected, colors[index * 4:index * 4 + 4])
+		}
+	}
+
+	// The positions go from -size / 2 to size / 2
+	for index, value := range device.BufferFloats(cube.Buffers.Positions) {
+		if value != -1 && value != 1 {
+			t.Fatalf("component %d is %f, expected -1 or 1", index, value)
+		}
+	}
+}
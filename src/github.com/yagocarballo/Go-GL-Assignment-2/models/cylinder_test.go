@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+func TestCylinderBuffers(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	shaderManager := testShaders(t, device)
+	cylinder := NewCylinder(device, "Cylinder", 16, 1.5, 1, shaderManager)
+
+	checkMeshModel(t, device, shaderManager, cylinder, func() (*geometry.Mesh, *MeshBuffers) {
+		cylinder.MakeCylinderVBO()
+		return cylinder.Mesh, cylinder.Buffers
+	})
+
+	// The colours are the positions moved a bit
+	positions, colors := device.BufferFloats(cylinder.Buffers.Positions), device.BufferFloats(cylinder.Buffers.Colors)
+	for index := 0; index < len(positions) / 3; index++ {
+		expected := []float32{ 0.3 + positions[index * 3], 0.3 + positions[index * 3 + 1], 0.5 + positions[index * 3 + 2], 1 }
+		if !sameFloats(expected, colors[index * 4:index * 4 + 4]) {
+			t.Fatalf("vertex %d: expected the colour %v, found %v", index, expected, colors[index * 4:index * 4 + 4])
+		}
+	}
+}
@@ -10,9 +10,11 @@ type Model interface {
     ResetModel()
     Translate(float32, float32, float32)
     Scale(float32, float32, float32)
-    Rotate(float32, mgl32.Vec3)
+    RotateRadians(float32, mgl32.Vec3)
+    RotateDegrees(float32, mgl32.Vec3)
 
     GetName() string
+    GetNode() *SceneNode
     GetDrawMode() DrawMode
     SetDrawMode(DrawMode)
 }
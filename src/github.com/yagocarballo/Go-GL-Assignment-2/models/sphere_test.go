@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/geometry"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+func TestSphereBuffers(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	shaderManager := testShaders(t, device)
+	sphere := NewSphere(device, "Sphere", 8, 12, shaderManager)
+
+	checkMeshModel(t, device, shaderManager, sphere, func() (*geometry.Mesh, *MeshBuffers) {
+		sphere.MakeSphereVBO()
+		return sphere.Mesh, sphere.Buffers
+	})
+
+	// The colour only changes between the rings (the vertex on the seam is in the ring twice)
+	colors := device.BufferFloats(sphere.Buffers.Colors)
+	ring := 12 + 1
+	for index := 0; index < len(colors) / 4; index++ {
+		if index % ring != 0 && !sameFloats(colors[index * 4:index * 4 + 4], colors[(index - 1) * 4:index * 4]) {
+			t.Fatalf("vertex %d has a different colour than the vertex before it in its ring", index)
+		}
+	}
+
+	// The unit sphere has its vertices at a distance of 1
+	positions := device.BufferFloats(sphere.Buffers.Positions)
+	for index := 0; index < len(positions); index += 3 {
+		x, y, z := positions[index], positions[index + 1], positions[index + 2]
+		if length := x * x + y * y + z * z; length < 0.9999 || length > 1.0001 {
+			t.Fatalf("vertex %d is %f away from the centre", index / 3, length)
+		}
+	}
+}
+
+func TestSphereSeedRepeatsTheColors(t *testing.T) {
+	device := wrapper.NewFakeDevice()
+	shaderManager := testShaders(t, device)
+
+	colors := func(seed int64) []float32 {
+		sphere := NewSphere(device, "Sphere", 8, 12, shaderManager)
+		sphere.Seed = seed
+		sphere.MakeSphereVBO()
+		return device.BufferFloats(sphere.Buffers.Colors)
+	}
+
+	if !sameFloats(colors(42), colors(42)) {
+		t.Error("the same seed painted different bands")
+	}
+	if sameFloats(colors(42), colors(43)) {
+		t.Error("different seeds painted the same bands")
+	}
+}
@@ -0,0 +1,203 @@
+package scene
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+//
+// Description
+// The contents of a scene file (everything that used to be hard-coded in InitApp)
+//
+type Description struct {
+	Camera   CameraDescription    `json:"camera"`
+	Lights   []LightDescription   `json:"lights"`
+	Terrains []TerrainDescription `json:"terrains"`
+	Water    *WaterDescription    `json:"water,omitempty"`
+	Objects  []ObjectDescription  `json:"objects"`
+}
+
+//
+// CameraDescription
+// The camera: placed at the eye looking at the center (the up is also the up of the scene for the controllers),
+// then moved by a list of transforms along its own axes
+//
+type CameraDescription struct {
+	Name        string      `json:"name"`
+	Eye         mgl32.Vec3  `json:"eye"`
+	Center      mgl32.Vec3  `json:"center"`
+	Up          mgl32.Vec3  `json:"up"`
+	FieldOfView float32     `json:"fieldOfView"` // Vertical, in degrees
+	Near        float32     `json:"near"`
+	Far         float32     `json:"far"`
+	Transform   []Operation `json:"transform,omitempty"`
+}
+
+//
+// LightDescription
+// A point, directional or spot light (drawn as a sphere, an arrow or a cone).
+// The direction is only used by the directional and spot lights, and the cones only by the spot lights
+//
+type LightDescription struct {
+	Name      string     `json:"name"`
+	Type      string     `json:"type"` // One of the LIGHT_TYPE_* values
+	Position  mgl32.Vec3 `json:"position"`
+	Color     mgl32.Vec3 `json:"color"`
+	Intensity float32    `json:"intensity"`
+	Range     float32    `json:"range"`     // Distance where the light fades out
+	Direction mgl32.Vec3 `json:"direction"`
+	InnerCone float32    `json:"innerCone"` // Half angles, in degrees
+	OuterCone float32    `json:"outerCone"`
+	Latitude  uint32     `json:"latitude"`
+	Longitude uint32     `json:"longitude"`
+}
+
+// Types of light in the scene files
+const (
+	LIGHT_TYPE_POINT       = "point"
+	LIGHT_TYPE_DIRECTIONAL = "directional"
+	LIGHT_TYPE_SPOT        = "spot"
+)
+
+//
+// TerrainDescription
+// A terrain made from OpenSimplex noise
+//
+type TerrainDescription struct {
+	Name      string      `json:"name"`
+	Seed      int64       `json:"seed"`
+	Frequency float32     `json:"frequency"`
+	Scale     float32     `json:"scale"`
+	Tone      mgl32.Vec4  `json:"tone"`
+	Points    [2]uint32   `json:"points"` // Number of vertices along x and z
+	Size      mgl32.Vec2  `json:"size"`   // Size along x and z
+	Shader    string      `json:"shader"`
+	Transform []Operation `json:"transform,omitempty"`
+}
+
+//
+// WaterDescription
+// The water plane
+//
+type WaterDescription struct {
+	Tone      mgl32.Vec4  `json:"tone"`
+	Points    [2]uint32   `json:"points"` // Number of vertices along x and z
+	Size      mgl32.Vec2  `json:"size"`   // Size along x and z
+	Transform []Operation `json:"transform,omitempty"`
+}
+
+//
+// ObjectDescription
+// A Wavefront object, loaded once per instance.
+// The instances are spread over the scatter area (from the seed), so they don't all sit on the same place
+//
+type ObjectDescription struct {
+	Name      string             `json:"name"`
+	Path      string             `json:"path"`
+	Shader    string             `json:"shader"`
+	Tone      mgl32.Vec4         `json:"tone"`                // Added to the colour by the shaders that use it (the water tone by default)
+	Instances int                `json:"instances"`
+	Scatter   mgl32.Vec2         `json:"scatter,omitempty"`   // Size along x and z of the area the instances are moved into
+	Seed      int64              `json:"seed,omitempty"`      // Seed of the scatter positions
+	Parent    string             `json:"parent,omitempty"`    // The object moves with the first instance of this object
+	Ground    *GroundDescription `json:"ground,omitempty"`
+	Transform []Operation        `json:"transform,omitempty"`
+}
+
+//
+// GroundDescription
+// Sits the object on a terrain: the first translate of the transform gives the x and z,
+// the height comes from the terrain (it keeps the translate if it's outside of the terrain)
+//
+type GroundDescription struct {
+	Terrain string  `json:"terrain"`
+	Offset  float32 `json:"offset"` // Distance from the ground to the pivot of the model, along the world y
+}
+
+//
+// Operation
+// One step of a transform (only one of the fields is set).
+// The steps are applied in order, the same way as calling Translate, Scale and Rotate on a model
+//
+type Operation struct {
+	Translate *mgl32.Vec3 `json:"translate,omitempty"`
+	Scale     *mgl32.Vec3 `json:"scale,omitempty"`
+	Rotate    *Rotation   `json:"rotate,omitempty"`
+	Matrix    *mgl32.Mat4 `json:"matrix,omitempty"` // Replaces the whole model matrix (column major, used by Save)
+}
+
+//
+// Rotation
+// A rotation step around an axis of the model
+//
+type Rotation struct {
+	Angle float32    `json:"angle"`
+	Unit  string     `json:"unit"` // UNIT_DEGREES or UNIT_RADIANS
+	Axis  mgl32.Vec3 `json:"axis"`
+}
+
+// Units of the rotation angles
+const (
+	UNIT_DEGREES = "degrees"
+	UNIT_RADIANS = "radians"
+)
+
+//
+// LoadDescription
+// Reads and validates a scene file
+//
+// @param path (string) the path to the scene file
+//
+// @return description (*Description) the scene description
+// @return error (error) the error (an Errors list if the file is not valid)
+//
+func LoadDescription(path string) (*Description, error) {
+	contents, err := wrapper.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// ReadFile adds a null terminator for OpenGL
+	return Parse([]byte(strings.TrimSuffix(contents, "\x00")))
+}
+
+//
+// SaveDescription
+// Writes a scene description as indented JSON
+//
+// @param path (string) the path to the scene file
+// @param description (*Description) the scene description
+//
+// @return error (error) the error (if any)
+//
+func SaveDescription(path string, description *Description) error {
+	data, err := Marshal(description)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+//
+// Marshal
+// Encodes a scene description as indented JSON
+//
+// @param description (*Description) the scene description
+//
+// @return data ([]byte) the JSON document
+// @return error (error) the error (if any)
+//
+func Marshal(description *Description) ([]byte, error) {
+	data, err := json.MarshalIndent(description, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
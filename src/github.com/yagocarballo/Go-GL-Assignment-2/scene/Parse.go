@@ -0,0 +1,772 @@
+package scene
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/models"
+)
+
+//
+// FieldError
+// A problem with one field of a scene file
+//
+type FieldError struct {
+	Path    string // Where the problem is (Example: objects[2].transform[1].rotate.axis)
+	Message string
+}
+
+func (err *FieldError) Error() string {
+	if err.Path == "" {
+		return err.Message
+	}
+
+	return fmt.Sprintf("%s: %s", err.Path, err.Message)
+}
+
+//
+// Errors
+// Every problem found in a scene file (one per line)
+//
+type Errors []*FieldError
+
+func (errs Errors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+//
+// Parse
+// Decodes and validates a scene file.
+// Every field is checked, so all the problems are reported at once
+//
+// @param data ([]byte) the JSON document
+//
+// @return description (*Description) the scene description
+// @return error (error) an Errors list if the document is not valid
+//
+func Parse(data []byte) (*Description, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, Errors{ syntaxError(data, err) }
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, Errors{ &FieldError{ "", "unexpected data after the scene" } }
+	}
+
+	parser := &parser{}
+	description := parser.description(document)
+	if len(parser.errors) > 0 {
+		return nil, parser.errors
+	}
+
+	return description, nil
+}
+
+// syntaxError adds the line and column to the JSON syntax errors
+func syntaxError(data []byte, err error) *FieldError {
+	syntax, ok := err.(*json.SyntaxError)
+	if !ok {
+		return &FieldError{ "", err.Error() }
+	}
+
+	// The offset is after the character that failed
+	offset := int(syntax.Offset) - 1
+	if offset > len(data) {
+		offset = len(data)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndex(data[:offset], []byte("\n"))
+
+	return &FieldError{ "", fmt.Sprintf("line %d, column %d: %s", line, column, syntax.Error()) }
+}
+
+// parser walks the decoded document and collects the errors with the path of the field
+type parser struct {
+	errors Errors
+}
+
+func (p *parser) fail(path, format string, args ...interface{}) {
+	p.errors = append(p.errors, &FieldError{ path, fmt.Sprintf(format, args...) })
+}
+
+func (p *parser) description(document interface{}) *Description {
+	fields := p.object("", document, "camera", "lights", "terrains", "water", "objects")
+	description := &Description{}
+	if fields == nil {
+		return description
+	}
+
+	if value, ok := p.required("", fields, "camera"); ok {
+		description.Camera = p.camera("camera", value)
+	}
+
+	if value, ok := p.required("", fields, "lights"); ok {
+		for i, light := range p.array("lights", value) {
+			description.Lights = append(description.Lights, p.light(fmt.Sprintf("lights[%d]", i), light))
+		}
+		if len(description.Lights) == 0 && p.isArray(value) {
+			p.fail("lights", "at least one light is required")
+		}
+		if len(description.Lights) > models.MAX_LIGHTS {
+			p.fail("lights", "the shaders take %d lights at most, found %d", models.MAX_LIGHTS, len(description.Lights))
+		}
+	}
+
+	if value, ok := fields["terrains"]; ok {
+		for i, terrain := range p.array("terrains", value) {
+			description.Terrains = append(description.Terrains, p.terrain(fmt.Sprintf("terrains[%d]", i), terrain))
+		}
+	}
+
+	if value, ok := fields["water"]; ok {
+		description.Water = p.water("water", value)
+	}
+
+	if value, ok := fields["objects"]; ok {
+		for i, object := range p.array("objects", value) {
+			description.Objects = append(description.Objects, p.object3D(fmt.Sprintf("objects[%d]", i), object, description.Terrains, description.Water, description.Objects))
+		}
+	}
+
+	// The names are used to find the models, so they can't repeat
+	p.unique("lights", len(description.Lights), func(i int) string { return description.Lights[i].Name })
+	p.unique("terrains", len(description.Terrains), func(i int) string { return description.Terrains[i].Name })
+	p.unique("objects", len(description.Objects), func(i int) string { return description.Objects[i].Name })
+
+	return description
+}
+
+func (p *parser) camera(path string, value interface{}) CameraDescription {
+	fields := p.object(path, value, "name", "eye", "center", "up", "fieldOfView", "near", "far", "transform")
+	camera := CameraDescription{
+		"View",                               // Name
+		mgl32.Vec3{},                         // Eye
+		mgl32.Vec3{},                         // Center
+		mgl32.Vec3{ 0, 1, 0 },                // Up
+		models.DEFAULT_FIELD_OF_VIEW,         // FieldOfView
+		models.DEFAULT_NEAR_PLANE,            // Near
+		models.DEFAULT_FAR_PLANE,             // Far
+		nil,                                  // Transform
+	}
+	if fields == nil {
+		return camera
+	}
+
+	p.optionalString(path, fields, "name", &camera.Name)
+	if value, ok := p.required(path, fields, "eye"); ok {
+		camera.Eye = p.vec3(path + ".eye", value)
+	}
+	if value, ok := p.required(path, fields, "center"); ok {
+		camera.Center = p.vec3(path + ".center", value)
+	}
+	if value, ok := fields["up"]; ok {
+		camera.Up = p.vec3(path + ".up", value)
+		if camera.Up.Len() == 0 {
+			p.fail(path + ".up", "must not be a zero vector")
+		}
+	}
+	if camera.Eye == camera.Center && p.has(fields, "eye", "center") {
+		p.fail(path + ".center", "must be different from the eye")
+	}
+	if value, ok := fields["fieldOfView"]; ok {
+		camera.FieldOfView = p.float(path + ".fieldOfView", value)
+		if camera.FieldOfView <= 0 || camera.FieldOfView >= 180 {
+			p.fail(path + ".fieldOfView", "must be between 0 and 180 degrees")
+		}
+	}
+	if value, ok := fields["near"]; ok {
+		camera.Near = p.positive(path + ".near", value)
+	}
+	if value, ok := fields["far"]; ok {
+		camera.Far = p.positive(path + ".far", value)
+	}
+	if camera.Far <= camera.Near && camera.Near > 0 {
+		p.fail(path + ".far", "must be further than the near plane (%v)", camera.Near)
+	}
+
+	camera.Transform = p.transform(path, fields)
+
+	return camera
+}
+
+func (p *parser) light(path string, value interface{}) LightDescription {
+	fields := p.object(path, value, "name", "type", "position", "color", "intensity", "range", "direction", "innerCone", "outerCone", "latitude", "longitude")
+	light := LightDescription{
+		"",                                   // Name
+		LIGHT_TYPE_POINT,                     // Type
+		mgl32.Vec3{},                         // Position
+		mgl32.Vec3{ 1, 1, 1 },                // Color
+		1,                                    // Intensity
+		90.5,                                 // Range
+		mgl32.Vec3{ 0, 0, -1 },               // Direction
+		20,                                   // InnerCone
+		30,                                   // OuterCone
+		20,                                   // Latitude
+		20,                                   // Longitude
+	}
+	if fields == nil {
+		return light
+	}
+
+	light.Name = p.requiredString(path, fields, "name")
+	p.optionalString(path, fields, "type", &light.Type)
+	if light.Type != LIGHT_TYPE_POINT && light.Type != LIGHT_TYPE_DIRECTIONAL && light.Type != LIGHT_TYPE_SPOT {
+		p.fail(path + ".type", "expected %q, %q or %q, found %q", LIGHT_TYPE_POINT, LIGHT_TYPE_DIRECTIONAL, LIGHT_TYPE_SPOT, light.Type)
+	}
+	if value, ok := p.required(path, fields, "position"); ok {
+		light.Position = p.vec3(path + ".position", value)
+	}
+	if value, ok := fields["color"]; ok {
+		light.Color = p.vec3(path + ".color", value)
+		if light.Color.X() < 0 || light.Color.Y() < 0 || light.Color.Z() < 0 {
+			p.fail(path + ".color", "must not have negative components")
+		}
+	}
+	if value, ok := fields["intensity"]; ok {
+		light.Intensity = p.float(path + ".intensity", value)
+		if light.Intensity < 0 {
+			p.fail(path + ".intensity", "must not be negative")
+		}
+	}
+	if value, ok := fields["range"]; ok {
+		light.Range = p.positive(path + ".range", value)
+	}
+
+	// The directional and spot lights need to know where they shine to
+	if light.Type == LIGHT_TYPE_DIRECTIONAL || light.Type == LIGHT_TYPE_SPOT {
+		if value, ok := p.required(path, fields, "direction"); ok {
+			light.Direction = p.vec3(path + ".direction", value)
+			if light.Direction.Len() == 0 {
+				p.fail(path + ".direction", "must not be a zero vector")
+			}
+		}
+	}
+
+	if value, ok := fields["innerCone"]; ok {
+		light.InnerCone = p.float(path + ".innerCone", value)
+	}
+	if value, ok := fields["outerCone"]; ok {
+		light.OuterCone = p.float(path + ".outerCone", value)
+	}
+	if light.OuterCone <= 0 || light.OuterCone >= 90 {
+		p.fail(path + ".outerCone", "must be between 0 and 90 degrees")
+	}
+	if light.InnerCone <= 0 || light.InnerCone > light.OuterCone {
+		p.fail(path + ".innerCone", "must be greater than 0 and not wider than the outer cone (%v)", light.OuterCone)
+	}
+
+	if value, ok := fields["latitude"]; ok {
+		light.Latitude = uint32(p.integer(path + ".latitude", value, 2, 1000))
+	}
+	if value, ok := fields["longitude"]; ok {
+		light.Longitude = uint32(p.integer(path + ".longitude", value, 3, 1000))
+	}
+
+	return light
+}
+
+func (p *parser) terrain(path string, value interface{}) TerrainDescription {
+	fields := p.object(path, value, "name", "seed", "frequency", "scale", "tone", "points", "size", "shader", "transform")
+	terrain := TerrainDescription{
+		"Terrain",                                // Name
+		0,                                        // Seed
+		4.0,                                      // Frequency
+		5.0,                                      // Scale
+		mgl32.Vec4{ 0.662, 0.405, 0.022, 1 },     // Tone
+		[2]uint32{},                              // Points
+		mgl32.Vec2{},                             // Size
+		"terrain",                                // Shader
+		nil,                                      // Transform
+	}
+	if fields == nil {
+		return terrain
+	}
+
+	p.optionalString(path, fields, "name", &terrain.Name)
+	if value, ok := p.required(path, fields, "seed"); ok {
+		terrain.Seed = p.integer(path + ".seed", value, math.MinInt64, math.MaxInt64)
+	}
+	if value, ok := fields["frequency"]; ok {
+		terrain.Frequency = p.positive(path + ".frequency", value)
+	}
+	if value, ok := fields["scale"]; ok {
+		terrain.Scale = p.positive(path + ".scale", value)
+	}
+	if value, ok := fields["tone"]; ok {
+		terrain.Tone = p.vec4(path + ".tone", value)
+	}
+	if value, ok := p.required(path, fields, "points"); ok {
+		terrain.Points = p.points(path + ".points", value)
+	}
+	if value, ok := p.required(path, fields, "size"); ok {
+		terrain.Size = p.size(path + ".size", value)
+	}
+	p.optionalString(path, fields, "shader", &terrain.Shader)
+
+	terrain.Transform = p.transform(path, fields)
+
+	return terrain
+}
+
+func (p *parser) water(path string, value interface{}) *WaterDescription {
+	fields := p.object(path, value, "tone", "points", "size", "transform")
+	water := &WaterDescription{ mgl32.Vec4{ 0, 0.618, 1, 0.9 }, [2]uint32{}, mgl32.Vec2{}, nil }
+	if fields == nil {
+		return water
+	}
+
+	if value, ok := fields["tone"]; ok {
+		water.Tone = p.vec4(path + ".tone", value)
+	}
+	if value, ok := p.required(path, fields, "points"); ok {
+		water.Points = p.points(path + ".points", value)
+	}
+	if value, ok := p.required(path, fields, "size"); ok {
+		water.Size = p.size(path + ".size", value)
+	}
+
+	water.Transform = p.transform(path, fields)
+
+	return water
+}
+
+func (p *parser) object3D(path string, value interface{}, terrains []TerrainDescription, water *WaterDescription, previous []ObjectDescription) ObjectDescription {
+	fields := p.object(path, value, "name", "path", "shader", "tone", "instances", "scatter", "seed", "parent", "ground", "transform")
+	object := ObjectDescription{
+		"",                                   // Name
+		"",                                   // Path
+		"",                                   // Shader
+		mgl32.Vec4{},                         // Tone
+		1,                                    // Instances
+		mgl32.Vec2{},                         // Scatter
+		0,                                    // Seed
+		"",                                   // Parent
+		nil,                                  // Ground
+		nil,                                  // Transform
+	}
+
+	// The objects used to be drawn with the tone of the water
+	if water != nil {
+		object.Tone = water.Tone
+	}
+	if fields == nil {
+		return object
+	}
+
+	object.Name = p.requiredString(path, fields, "name")
+	object.Path = p.requiredString(path, fields, "path")
+	object.Shader = p.requiredString(path, fields, "shader")
+	if value, ok := fields["tone"]; ok {
+		object.Tone = p.vec4(path + ".tone", value)
+	}
+	if value, ok := fields["instances"]; ok {
+		object.Instances = int(p.integer(path + ".instances", value, 1, 10000))
+	}
+	if value, ok := fields["scatter"]; ok {
+		object.Scatter = p.vec2(path + ".scatter", value)
+		if object.Scatter.X() < 0 || object.Scatter.Y() < 0 {
+			p.fail(path + ".scatter", "must not have negative components")
+		}
+	}
+	if value, ok := fields["seed"]; ok {
+		object.Seed = p.integer(path + ".seed", value, math.MinInt64, math.MaxInt64)
+	}
+
+	object.Transform = p.transform(path, fields)
+
+	if _, ok := fields["parent"]; ok {
+		p.optionalString(path, fields, "parent", &object.Parent)
+
+		// The parent has to be built first
+		found := false
+		for _, other := range previous {
+			found = found || other.Name == object.Parent
+		}
+		if !found && object.Parent != "" {
+			p.fail(path + ".parent", "there is no object called %q before this one", object.Parent)
+		}
+	}
+
+	if value, ok := fields["ground"]; ok {
+		if object.Parent != "" {
+			p.fail(path + ".ground", "can't be used with a parent (the ground position is in world space)")
+		}
+
+		if _, ok := fields["scatter"]; ok {
+			p.fail(path + ".ground", "can't be used with a scatter (the ground position is the first translate)")
+		}
+
+		object.Ground = p.ground(path + ".ground", value, terrains)
+
+		// The ground position is read from the first translate
+		if len(object.Transform) == 0 || object.Transform[0].Translate == nil {
+			p.fail(path + ".transform", "must start with a translate when the object sits on the ground")
+		}
+	}
+
+	return object
+}
+
+func (p *parser) ground(path string, value interface{}, terrains []TerrainDescription) *GroundDescription {
+	fields := p.object(path, value, "terrain", "offset")
+	ground := &GroundDescription{}
+	if fields == nil {
+		return ground
+	}
+
+	if len(terrains) > 0 {
+		ground.Terrain = terrains[0].Name
+	}
+	p.optionalString(path, fields, "terrain", &ground.Terrain)
+	if value, ok := fields["offset"]; ok {
+		ground.Offset = p.float(path + ".offset", value)
+	}
+
+	found := false
+	for _, terrain := range terrains {
+		found = found || terrain.Name == ground.Terrain
+	}
+	if !found {
+		if _, named := fields["terrain"]; named {
+			p.fail(path + ".terrain", "there is no terrain called %q", ground.Terrain)
+		} else {
+			p.fail(path, "there are no terrains to sit on")
+		}
+	}
+
+	return ground
+}
+
+// transform reads the optional "transform" list of an object
+func (p *parser) transform(path string, fields map[string]interface{}) []Operation {
+	value, ok := fields["transform"]
+	if !ok {
+		return nil
+	}
+
+	path += ".transform"
+	operations := []Operation{}
+	for i, step := range p.array(path, value) {
+		operations = append(operations, p.operation(fmt.Sprintf("%s[%d]", path, i), step))
+	}
+
+	return operations
+}
+
+func (p *parser) operation(path string, value interface{}) Operation {
+	fields := p.object(path, value, "translate", "scale", "rotate", "matrix")
+	operation := Operation{}
+	if fields == nil {
+		return operation
+	}
+
+	if len(fields) != 1 {
+		p.fail(path, "must have exactly one of translate, scale, rotate or matrix")
+		return operation
+	}
+
+	if value, ok := fields["translate"]; ok {
+		translate := p.vec3(path + ".translate", value)
+		operation.Translate = &translate
+	}
+
+	if value, ok := fields["scale"]; ok {
+		scale := p.vec3(path + ".scale", value)
+		if scale.X() == 0 || scale.Y() == 0 || scale.Z() == 0 {
+			p.fail(path + ".scale", "must not have zero components")
+		}
+		operation.Scale = &scale
+	}
+
+	if value, ok := fields["rotate"]; ok {
+		rotatePath := path + ".rotate"
+		rotate := &Rotation{}
+		if rotation := p.object(rotatePath, value, "angle", "unit", "axis"); rotation != nil {
+			if angle, ok := p.required(rotatePath, rotation, "angle"); ok {
+				rotate.Angle = p.float(rotatePath + ".angle", angle)
+			}
+			// The unit is required, so 180 can't be read as radians by mistake
+			rotate.Unit = p.requiredString(rotatePath, rotation, "unit")
+			if rotate.Unit != "" && rotate.Unit != UNIT_DEGREES && rotate.Unit != UNIT_RADIANS {
+				p.fail(rotatePath + ".unit", "expected %q or %q, found %q", UNIT_DEGREES, UNIT_RADIANS, rotate.Unit)
+			}
+			if axis, ok := p.required(rotatePath, rotation, "axis"); ok {
+				rotate.Axis = p.vec3(rotatePath + ".axis", axis)
+				if rotate.Axis.Len() == 0 {
+					p.fail(rotatePath + ".axis", "must not be a zero vector")
+				}
+			}
+		}
+		operation.Rotate = rotate
+	}
+
+	if value, ok := fields["matrix"]; ok {
+		matrix := mgl32.Mat4{}
+		copy(matrix[:], p.floats(path + ".matrix", value, 16))
+		operation.Matrix = &matrix
+	}
+
+	return operation
+}
+
+// unique reports the repeated names of a list
+func (p *parser) unique(path string, count int, name func(int) string) {
+	seen := map[string]bool{}
+	for i := 0; i < count; i++ {
+		if seen[name(i)] {
+			p.fail(fmt.Sprintf("%s[%d].name", path, i), "%q is used more than once", name(i))
+		}
+		seen[name(i)] = true
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////// Values ////////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+// object checks that the value is an object without unknown fields
+func (p *parser) object(path string, value interface{}, known ...string) map[string]interface{} {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		p.fail(path, "expected an object, found %s", kind(value))
+		return nil
+	}
+
+	// Sorted, so the errors always come in the same order
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		isKnown := false
+		for _, knownName := range known {
+			isKnown = isKnown || name == knownName
+		}
+		if !isKnown {
+			p.fail(join(path, name), "unknown field (expected one of %s)", strings.Join(known, ", "))
+		}
+	}
+
+	return fields
+}
+
+func (p *parser) required(path string, fields map[string]interface{}, name string) (interface{}, bool) {
+	value, ok := fields[name]
+	if !ok {
+		p.fail(join(path, name), "missing required field")
+	}
+
+	return value, ok
+}
+
+func (p *parser) has(fields map[string]interface{}, names ...string) bool {
+	for _, name := range names {
+		if _, ok := fields[name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *parser) requiredString(path string, fields map[string]interface{}, name string) string {
+	value := ""
+	if _, ok := p.required(path, fields, name); ok {
+		p.optionalString(path, fields, name, &value)
+	}
+
+	return value
+}
+
+func (p *parser) optionalString(path string, fields map[string]interface{}, name string, target *string) {
+	value, ok := fields[name]
+	if !ok {
+		return
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		p.fail(join(path, name), "expected a string, found %s", kind(value))
+		return
+	}
+	if text == "" {
+		p.fail(join(path, name), "must not be empty")
+		return
+	}
+
+	*target = text
+}
+
+func (p *parser) isArray(value interface{}) bool {
+	_, ok := value.([]interface{})
+	return ok
+}
+
+func (p *parser) array(path string, value interface{}) []interface{} {
+	values, ok := value.([]interface{})
+	if !ok {
+		p.fail(path, "expected an array, found %s", kind(value))
+	}
+
+	return values
+}
+
+func (p *parser) float(path string, value interface{}) float32 {
+	number, ok := value.(json.Number)
+	if !ok {
+		p.fail(path, "expected a number, found %s", kind(value))
+		return 0
+	}
+
+	result, err := number.Float64()
+	if err != nil || math.Abs(result) > math.MaxFloat32 {
+		p.fail(path, "%s is out of range", number)
+		return 0
+	}
+
+	return float32(result)
+}
+
+func (p *parser) positive(path string, value interface{}) float32 {
+	result := p.float(path, value)
+	if _, ok := value.(json.Number); ok && result <= 0 {
+		p.fail(path, "must be greater than 0")
+	}
+
+	return result
+}
+
+func (p *parser) integer(path string, value interface{}, minimum, maximum int64) int64 {
+	number, ok := value.(json.Number)
+	if !ok {
+		p.fail(path, "expected an integer, found %s", kind(value))
+		return 0
+	}
+
+	result, err := number.Int64()
+	if err != nil {
+		p.fail(path, "expected an integer, found %s", number)
+		return 0
+	}
+
+	if result < minimum || result > maximum {
+		p.fail(path, "must be between %d and %d", minimum, maximum)
+	}
+
+	return result
+}
+
+func (p *parser) floats(path string, value interface{}, size int) []float32 {
+	values := p.array(path, value)
+	if values == nil {
+		return make([]float32, size)
+	}
+
+	if len(values) != size {
+		p.fail(path, "expected %d numbers, found %d", size, len(values))
+	}
+
+	result := make([]float32, size)
+	for i := 0; i < size && i < len(values); i++ {
+		result[i] = p.float(fmt.Sprintf("%s[%d]", path, i), values[i])
+	}
+
+	return result
+}
+
+func (p *parser) vec3(path string, value interface{}) mgl32.Vec3 {
+	values := p.floats(path, value, 3)
+	return mgl32.Vec3{ values[0], values[1], values[2] }
+}
+
+func (p *parser) vec4(path string, value interface{}) mgl32.Vec4 {
+	values := p.floats(path, value, 4)
+	return mgl32.Vec4{ values[0], values[1], values[2], values[3] }
+}
+
+func (p *parser) vec2(path string, value interface{}) mgl32.Vec2 {
+	values := p.floats(path, value, 2)
+	return mgl32.Vec2{ values[0], values[1] }
+}
+
+func (p *parser) size(path string, value interface{}) mgl32.Vec2 {
+	values := p.floats(path, value, 2)
+	for i, size := range values {
+		if size <= 0 {
+			p.fail(fmt.Sprintf("%s[%d]", path, i), "must be greater than 0")
+		}
+	}
+
+	return mgl32.Vec2{ values[0], values[1] }
+}
+
+func (p *parser) points(path string, value interface{}) [2]uint32 {
+	points := [2]uint32{}
+
+	values := p.array(path, value)
+	if values == nil {
+		return points
+	}
+
+	if len(values) != 2 {
+		p.fail(path, "expected 2 integers, found %d", len(values))
+	}
+
+	// The terrain and the water use 16 bit indices
+	for i := 0; i < 2 && i < len(values); i++ {
+		points[i] = uint32(p.integer(fmt.Sprintf("%s[%d]", path, i), values[i], 2, 256))
+	}
+
+	return points
+}
+
+// join adds a field name to a path
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// kind describes the type of a decoded JSON value
+func kind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "a number"
+	case string:
+		return "a string"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
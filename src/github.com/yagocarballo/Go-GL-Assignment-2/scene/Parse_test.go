@@ -0,0 +1,256 @@
+package scene
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/models"
+)
+
+// minimalScene is the smallest valid scene, the objects are added in place of %s
+const minimalScene = `{
+	"camera": { "eye": [0, 0, 10], "center": [0, 0, 0] },
+	"lights": [ { "name": "Light", "position": [0, 5, 0] } ],
+	"terrains": [ { "name": "Ground", "seed": 3, "points": [10, 10], "size": [20, 20] } ],
+	%s
+	"objects": [ %s ]
+}`
+
+// parseScene parses the minimal scene with some water (or an empty string) and objects
+func parseScene(water, objects string) (*Description, error) {
+	return Parse([]byte(fmt.Sprintf(minimalScene, water, objects)))
+}
+
+// expectErrors checks that the parse failed with (at least) these paths
+func expectErrors(t *testing.T, err error, paths ...string) {
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected an Errors list, found %v", err)
+	}
+
+	for _, path := range paths {
+		found := false
+		for _, fieldError := range errs {
+			found = found || fieldError.Path == path
+		}
+		if !found {
+			t.Errorf("expected an error on %q, found:\n%v", path, errs)
+		}
+	}
+}
+
+func TestParseMinimalScene(t *testing.T) {
+	description, err := parseScene("", `{ "name": "House", "path": "house.obj", "shader": "basic" }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	camera := description.Camera
+	if camera.Name != "View" || camera.Up != (mgl32.Vec3{ 0, 1, 0 }) || camera.FieldOfView != models.DEFAULT_FIELD_OF_VIEW {
+		t.Errorf("the camera doesn't have the defaults: %+v", camera)
+	}
+
+	light := description.Lights[0]
+	if light.Type != LIGHT_TYPE_POINT || light.Color != (mgl32.Vec3{ 1, 1, 1 }) || light.Position != (mgl32.Vec3{ 0, 5, 0 }) {
+		t.Errorf("unexpected light %+v", light)
+	}
+
+	terrain := description.Terrains[0]
+	if terrain.Seed != 3 || terrain.Points != [2]uint32{ 10, 10 } || terrain.Shader != "terrain" {
+		t.Errorf("unexpected terrain %+v", terrain)
+	}
+
+	object := description.Objects[0]
+	if object.Instances != 1 || object.Tone != (mgl32.Vec4{}) || object.Scatter != (mgl32.Vec2{}) || object.Ground != nil {
+		t.Errorf("the object doesn't have the defaults: %+v", object)
+	}
+	if description.Water != nil {
+		t.Error("the scene has no water")
+	}
+}
+
+func TestParseObjectTone(t *testing.T) {
+	water := `"water": { "tone": [0.1, 0.2, 0.3, 0.4], "points": [4, 4], "size": [10, 10] },`
+	description, err := parseScene(water, `
+		{ "name": "Plain", "path": "a.obj", "shader": "basic" },
+		{ "name": "Toned", "path": "b.obj", "shader": "basic", "tone": [1, 0, 0, 1] }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a tone the objects take the one of the water
+	if tone := description.Objects[0].Tone; tone != (mgl32.Vec4{ 0.1, 0.2, 0.3, 0.4 }) {
+		t.Errorf("expected the tone of the water, found %v", tone)
+	}
+	if tone := description.Objects[1].Tone; tone != (mgl32.Vec4{ 1, 0, 0, 1 }) {
+		t.Errorf("expected the tone of the object, found %v", tone)
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	description, err := parseScene("", `{ "name": "House", "path": "house.obj", "shader": "basic", "transform": [
+		{ "translate": [1, 2, 3] },
+		{ "scale": [2, 2, 2] },
+		{ "rotate": { "angle": 90, "unit": "degrees", "axis": [0, 1, 0] } },
+		{ "matrix": [1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 5, 6, 7, 1] }
+	] }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operations := description.Objects[0].Transform
+	if len(operations) != 4 {
+		t.Fatalf("expected 4 operations, found %d", len(operations))
+	}
+	if operations[0].Translate == nil || *operations[0].Translate != (mgl32.Vec3{ 1, 2, 3 }) {
+		t.Errorf("unexpected translate %+v", operations[0])
+	}
+	if operations[1].Scale == nil || *operations[1].Scale != (mgl32.Vec3{ 2, 2, 2 }) {
+		t.Errorf("unexpected scale %+v", operations[1])
+	}
+	if rotate := operations[2].Rotate; rotate == nil || rotate.Angle != 90 || rotate.Unit != UNIT_DEGREES || rotate.Axis != (mgl32.Vec3{ 0, 1, 0 }) {
+		t.Errorf("unexpected rotate %+v", rotate)
+	}
+	if matrix := operations[3].Matrix; matrix == nil || *matrix != mgl32.Translate3D(5, 6, 7) {
+		t.Errorf("unexpected matrix %+v", matrix)
+	}
+}
+
+func TestParseReportsEveryField(t *testing.T) {
+	cases := []struct {
+		name    string
+		objects string
+		paths   []string
+	}{
+		{
+			"missing fields",
+			`{ "name": "House" }`,
+			[]string{ "objects[0].path", "objects[0].shader" },
+		},
+		{
+			"unknown field and wrong types",
+			`{ "name": "House", "path": 3, "shader": "basic", "colour": [1, 1, 1], "instances": 1.5 }`,
+			[]string{ "objects[0].colour", "objects[0].path", "objects[0].instances" },
+		},
+		{
+			"rotations need a unit",
+			`{ "name": "House", "path": "h.obj", "shader": "basic", "transform": [
+				{ "rotate": { "angle": 180, "axis": [1, 0, 0] } },
+				{ "rotate": { "angle": 180, "unit": "turns", "axis": [0, 0, 0] } }
+			] }`,
+			[]string{ "objects[0].transform[0].rotate.unit", "objects[0].transform[1].rotate.unit", "objects[0].transform[1].rotate.axis" },
+		},
+		{
+			"one step per operation",
+			`{ "name": "House", "path": "h.obj", "shader": "basic", "transform": [ { "translate": [0, 0, 0], "scale": [1, 1, 1] }, { "scale": [1, 0, 1] } ] }`,
+			[]string{ "objects[0].transform[0]", "objects[0].transform[1].scale" },
+		},
+		{
+			"the ground needs a translate",
+			`{ "name": "House", "path": "h.obj", "shader": "basic", "ground": { "offset": 1 }, "transform": [ { "scale": [1, 1, 1] } ] }`,
+			[]string{ "objects[0].transform" },
+		},
+		{
+			"the ground needs a terrain",
+			`{ "name": "House", "path": "h.obj", "shader": "basic", "ground": { "terrain": "Sea" }, "transform": [ { "translate": [0, 0, 0] } ] }`,
+			[]string{ "objects[0].ground.terrain" },
+		},
+		{
+			"the ground can't be scattered",
+			`{ "name": "House", "path": "h.obj", "shader": "basic", "scatter": [5, -1], "ground": {}, "transform": [ { "translate": [0, 0, 0] } ] }`,
+			[]string{ "objects[0].ground", "objects[0].scatter" },
+		},
+		{
+			"the parent comes first",
+			`{ "name": "Car", "path": "c.obj", "shader": "basic", "parent": "House" }, { "name": "House", "path": "h.obj", "shader": "basic" }`,
+			[]string{ "objects[0].parent" },
+		},
+		{
+			"the names are unique",
+			`{ "name": "House", "path": "h.obj", "shader": "basic" }, { "name": "House", "path": "h.obj", "shader": "basic" }`,
+			[]string{ "objects[1].name" },
+		},
+	}
+
+	for _, c := range cases {
+		_, err := parseScene("", c.objects)
+		if err == nil {
+			t.Errorf("%s: expected an error", c.name)
+			continue
+		}
+		t.Run(c.name, func(t *testing.T) { expectErrors(t, err, c.paths...) })
+	}
+
+	// A parent declared before is fine, even without the ground
+	if _, err := parseScene("", `{ "name": "House", "path": "h.obj", "shader": "basic" }, { "name": "Car", "path": "c.obj", "shader": "basic", "parent": "House" }`); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestParseCameraAndLights(t *testing.T) {
+	_, err := Parse([]byte(`{
+		"camera": { "eye": [1, 1, 1], "center": [1, 1, 1], "up": [0, 0, 0], "fieldOfView": 180, "near": 10, "far": 5 },
+		"lights": [ { "name": "Sun", "type": "directional", "position": [0, 0, 0] }, { "name": "Lamp", "type": "torch", "position": [0, 0, 0], "innerCone": 40 } ],
+		"objects": []
+	}`))
+	expectErrors(t, err, "camera.center", "camera.up", "camera.fieldOfView", "camera.far", "lights[0].direction", "lights[1].type", "lights[1].innerCone")
+
+	// The shaders only take so many lights
+	lights := []string{}
+	for i := 0; i <= models.MAX_LIGHTS; i++ {
+		lights = append(lights, fmt.Sprintf(`{ "name": "Light %d", "position": [0, 0, 0] }`, i))
+	}
+	_, err = Parse([]byte(`{ "camera": { "eye": [0, 0, 1], "center": [0, 0, 0] }, "lights": [` + strings.Join(lights, ", ") + `] }`))
+	expectErrors(t, err, "lights")
+
+	_, err = Parse([]byte(`{ "camera": { "eye": [0, 0, 1], "center": [0, 0, 0] }, "lights": [] }`))
+	expectErrors(t, err, "lights")
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	_, err := Parse([]byte("{\n\t\"camera\": {\n\t\t\"eye\": [0, 0,, 1]\n\t}\n}"))
+	if err == nil || !strings.Contains(err.Error(), "line 3, column 16") {
+		t.Errorf("expected the line and column of the error, found %v", err)
+	}
+
+	_, err = Parse([]byte(`{ "camera": { "eye": [0, 0, 1], "center": [0, 0, 0] }, "lights": [ { "name": "Light", "position": [0, 0, 0] } ] } {}`))
+	if err == nil || !strings.Contains(err.Error(), "unexpected data") {
+		t.Errorf("expected an error about the trailing data, found %v", err)
+	}
+
+	_, err = Parse([]byte(`[]`))
+	expectErrors(t, err, "")
+}
+
+func TestScatterOffsets(t *testing.T) {
+	object := &ObjectDescription{ Instances: 20, Scatter: mgl32.Vec2{ 50, 10 }, Seed: 4 }
+	offsets := scatterOffsets(object)
+
+	seen := map[mgl32.Vec3]bool{}
+	for i, offset := range offsets {
+		if offset.X() < 0 || offset.X() >= 50 || offset.Y() != 0 || offset.Z() < 0 || offset.Z() >= 10 {
+			t.Errorf("offset %d is outside of the area: %v", i, offset)
+		}
+		seen[offset] = true
+	}
+	if len(seen) != len(offsets) {
+		t.Errorf("expected %d different positions, found %d", len(offsets), len(seen))
+	}
+
+	// The same seed gives the same positions, so a saved scene looks the same
+	for i, offset := range scatterOffsets(object) {
+		if offset != offsets[i] {
+			t.Fatalf("offset %d changed from %v to %v", i, offsets[i], offset)
+		}
+	}
+
+	object.Scatter = mgl32.Vec2{}
+	for i, offset := range scatterOffsets(object) {
+		if offset != (mgl32.Vec3{}) {
+			t.Errorf("offset %d: expected no scatter, found %v", i, offset)
+		}
+	}
+}
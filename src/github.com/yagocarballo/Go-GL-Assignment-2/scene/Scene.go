@@ -0,0 +1,391 @@
+package scene
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/yagocarballo/Go-GL-Assignment-2/models"
+	"github.com/yagocarballo/Go-GL-Assignment-2/wrapper"
+)
+
+//
+// Scene
+// The models built from a scene description
+//
+type Scene struct {
+	Description *Description
+
+	View        *models.Camera
+	Lights      *models.LightManager // Uploads the lights to the shaders
+	Terrains    []*models.Terrain
+	Water       *models.Water // nil if the scene has no water
+	Objects     []*Object
+}
+
+//
+// Object
+// The instances of one object of the scene description
+//
+type Object struct {
+	Description *ObjectDescription
+	Instances   []*models.WavefrontObject
+}
+
+//
+// Load
+// Reads a scene file and builds the models
+//
+// @param path (string) the path to the scene file
+// @param shaderManager (*wrapper.ShaderManager) the shaders (they have to be loaded already)
+//
+// @return scene (*Scene) the scene
+// @return error (error) the error (an Errors list if the file is not valid)
+//
+func Load(path string, shaderManager *wrapper.ShaderManager) (*Scene, error) {
+	description, err := LoadDescription(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Build(description, shaderManager)
+}
+
+//
+// Build
+// Creates the models (and their buffers) of a scene description and applies their transforms
+//
+// @param description (*Description) the scene description
+// @param shaderManager (*wrapper.ShaderManager) the shaders (they have to be loaded already)
+//
+// @return scene (*Scene) the scene
+// @return error (error) an Errors list if a shader is missing or there are too many lights
+//
+func Build(description *Description, shaderManager *wrapper.ShaderManager) (*Scene, error) {
+	// The shaders can only be checked once they are loaded
+	errs := Errors{}
+	for i, terrain := range description.Terrains {
+		if _, ok := shaderManager.Shaders[terrain.Shader]; !ok {
+			errs = append(errs, &FieldError{ fmt.Sprintf("terrains[%d].shader", i), fmt.Sprintf("there is no shader called %q", terrain.Shader) })
+		}
+	}
+	for i, object := range description.Objects {
+		if _, ok := shaderManager.Shaders[object.Shader]; !ok {
+			errs = append(errs, &FieldError{ fmt.Sprintf("objects[%d].shader", i), fmt.Sprintf("there is no shader called %q", object.Shader) })
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	scene := &Scene{ description, nil, nil, nil, nil, nil }
+
+	// The models use the device the shaders were loaded with
+	device := shaderManager.Device
+
+	scene.View = models.NewCamera(description.Camera.Name, description.Camera.Eye, description.Camera.Center, description.Camera.Up)
+
+	scene.Lights = models.NewLightManager(device)
+	for _, lightDescription := range description.Lights {
+		light := models.NewLight(device, lightDescription.Name, lightTypes[lightDescription.Type], shaderManager)
+		light.Color, light.Intensity, light.Range = lightDescription.Color, lightDescription.Intensity, lightDescription.Range
+		light.Direction = lightDescription.Direction
+		light.InnerCone, light.OuterCone = lightDescription.InnerCone, lightDescription.OuterCone
+		light.MakeGizmoVBO(lightDescription.Latitude, lightDescription.Longitude)
+		if err := scene.Lights.Add(light); err != nil {
+			return nil, Errors{ &FieldError{ "lights", err.Error() } }
+		}
+	}
+	scene.Lights.BindShaders(shaderManager)
+
+	for _, terrainDescription := range description.Terrains {
+		terrain := models.NewTerrainWithSeed(device, terrainDescription.Seed, terrainDescription.Frequency, terrainDescription.Scale, terrainDescription.Tone)
+		terrain.Name = terrainDescription.Name
+		terrain.Node.Name = terrain.Name
+		terrain.CreateTerrain(terrainDescription.Points[0], terrainDescription.Points[1], terrainDescription.Size.X(), terrainDescription.Size.Y())
+		scene.Terrains = append(scene.Terrains, terrain)
+	}
+
+	if description.Water != nil {
+		scene.Water = models.NewWater(device, description.Water.Tone)
+		scene.Water.CreateWater(description.Water.Points[0], description.Water.Points[1], description.Water.Size.X(), description.Water.Size.Y())
+	}
+
+	for i := range description.Objects {
+		object := &Object{ &description.Objects[i], []*models.WavefrontObject{} }
+		for instance := 0; instance < object.Description.Instances; instance++ {
+			model := models.NewObjectLoader(device)
+			model.Name = object.Description.Name
+			if instance > 0 {
+				model.Name = fmt.Sprintf("%s %d", object.Description.Name, instance + 1)
+			}
+			model.Node.Name = model.Name
+			model.LoadObject(object.Description.Path)
+			model.CreateObject()
+			object.Instances = append(object.Instances, model)
+
+			// Moves with the first instance of its parent (it's always declared before)
+			if parent := scene.Object(object.Description.Parent); len(parent) > 0 {
+				parent[0].Node.AddChild(model.Node)
+			}
+		}
+		scene.Objects = append(scene.Objects, object)
+	}
+
+	scene.ApplyTransforms()
+
+	return scene, nil
+}
+
+//
+// ApplyTransforms
+// Resets every model to the transforms of the scene description
+//
+func (scene *Scene) ApplyTransforms() {
+	description := scene.Description
+
+	camera := description.Camera
+	scene.View.WorldUp = camera.Up.Normalize()
+	scene.View.FieldOfView, scene.View.Near, scene.View.Far = camera.FieldOfView, camera.Near, camera.Far
+	scene.View.LookAt(camera.Eye, camera.Center, camera.Up)
+	applyOperations(scene.View, camera.Transform)
+
+	for i, light := range scene.Lights.Lights {
+		light.ResetModel()
+		position := description.Lights[i].Position
+		light.Translate(position.X(), position.Y(), position.Z())
+	}
+
+	for i, terrain := range scene.Terrains {
+		terrain.ResetModel()
+		applyOperations(terrain, description.Terrains[i].Transform)
+	}
+
+	if scene.Water != nil {
+		scene.Water.ResetModel()
+		applyOperations(scene.Water, description.Water.Transform)
+	}
+
+	for _, object := range scene.Objects {
+		offsets := scatterOffsets(object.Description)
+		for i, instance := range object.Instances {
+			instance.ResetModel()
+
+			operations := object.Description.Transform
+			if ground := object.Description.Ground; ground != nil {
+				// Replaces the first translate (keeps it if the object is outside of the terrain)
+				start := operations[0].Translate
+				if terrain := scene.Terrain(ground.Terrain); terrain != nil && terrain.PlaceOnGround(instance, start.X(), start.Z(), ground.Offset, false) {
+					operations = operations[1:]
+				}
+			}
+
+			applyOperations(instance, operations)
+
+			// The scatter moves the whole instance, in the space of its parent
+			if offsets[i] != (mgl32.Vec3{}) {
+				instance.Node.SetMatrix(mgl32.Translate3D(offsets[i].X(), offsets[i].Y(), offsets[i].Z()).Mul4(instance.Node.Local()))
+			}
+		}
+	}
+}
+
+//
+// Draw
+// Draws the terrains and the objects with their shaders (the water and the lights are drawn apart)
+//
+// @param shaderManager (*wrapper.ShaderManager) the shaders
+//
+func (scene *Scene) Draw(shaderManager *wrapper.ShaderManager) {
+	for i, terrain := range scene.Terrains {
+		shader := scene.Description.Terrains[i].Shader
+		shaderManager.EnableShader(shader)
+		shaderManager.SetUniform4f(shader, "tone", terrain.ColorTone.X(), terrain.ColorTone.Y(), terrain.ColorTone.Z(), terrain.ColorTone.W())
+		terrain.DrawObject(shaderManager.CurrentShader())
+	}
+
+	for _, object := range scene.Objects {
+		shader, tone := object.Description.Shader, object.Description.Tone
+		shaderManager.EnableShader(shader)
+		shaderManager.SetUniform4f(shader, "tone", tone.X(), tone.Y(), tone.Z(), tone.W())
+		for _, instance := range object.Instances {
+			instance.DrawObject(shaderManager.CurrentShader())
+		}
+	}
+}
+
+//
+// Terrain
+// Finds a terrain by name
+//
+// @param name (string) the name of the terrain
+//
+// @return terrain (*models.Terrain) the terrain (nil if it doesn't exist)
+//
+func (scene *Scene) Terrain(name string) *models.Terrain {
+	for _, terrain := range scene.Terrains {
+		if terrain.Name == name {
+			return terrain
+		}
+	}
+
+	return nil
+}
+
+//
+// Light
+// Finds a light by name
+//
+// @param name (string) the name of the light
+//
+// @return light (*models.Light) the light (nil if it doesn't exist)
+//
+func (scene *Scene) Light(name string) *models.Light {
+	return scene.Lights.Light(name)
+}
+
+//
+// Object
+// Finds the instances of an object by name
+//
+// @param name (string) the name of the object
+//
+// @return instances ([]*models.WavefrontObject) the instances (empty if it doesn't exist)
+//
+func (scene *Scene) Object(name string) []*models.WavefrontObject {
+	for _, object := range scene.Objects {
+		if object.Description.Name == name {
+			return object.Instances
+		}
+	}
+
+	return []*models.WavefrontObject{}
+}
+
+//
+// Capture
+// Creates a scene description with the current state of the models.
+// The camera is stored as an eye and a center (the controllers never roll it), the other transforms
+// are stored as local matrices (relative to the parent), and the instances of an object take the transform of the first one
+// (without its scatter, so the others are spread the same way again)
+//
+// @return description (*Description) the scene description
+//
+func (scene *Scene) Capture() *Description {
+	original := scene.Description
+	eye := scene.View.Position()
+	description := &Description{
+		CameraDescription{
+			original.Camera.Name,
+			eye,
+			eye.Add(scene.View.Forward().Mul(original.Camera.Eye.Sub(original.Camera.Center).Len())),
+			scene.View.WorldUp,
+			scene.View.FieldOfView,
+			scene.View.Near,
+			scene.View.Far,
+			nil,
+		},
+		[]LightDescription{},
+		[]TerrainDescription{},
+		nil,
+		[]ObjectDescription{},
+	}
+
+	for i, light := range scene.Lights.Lights {
+		lightDescription := original.Lights[i]
+		lightDescription.Position = light.WorldPosition()
+		lightDescription.Color, lightDescription.Intensity, lightDescription.Range = light.Color, light.Intensity, light.Range
+		if light.Type != models.LIGHT_POINT {
+			// The light is only translated when it's loaded, so the turn of the node goes into the direction
+			lightDescription.Direction = light.WorldDirection()
+		}
+		description.Lights = append(description.Lights, lightDescription)
+	}
+
+	for i, terrain := range scene.Terrains {
+		terrainDescription := original.Terrains[i]
+		terrainDescription.Tone = terrain.ColorTone
+		terrainDescription.Transform = matrixOperation(terrain.Node.Local())
+		description.Terrains = append(description.Terrains, terrainDescription)
+	}
+
+	if scene.Water != nil {
+		water := *original.Water
+		water.Tone = scene.Water.ColorTone
+		water.Transform = matrixOperation(scene.Water.Node.Local())
+		description.Water = &water
+	}
+
+	for _, object := range scene.Objects {
+		objectDescription := *object.Description
+
+		// The matrix already has the ground height
+		objectDescription.Ground = nil
+		if len(object.Instances) > 0 {
+			offset := scatterOffsets(object.Description)[0]
+			objectDescription.Transform = matrixOperation(mgl32.Translate3D(-offset.X(), -offset.Y(), -offset.Z()).Mul4(object.Instances[0].Node.Local()))
+		}
+		description.Objects = append(description.Objects, objectDescription)
+	}
+
+	return description
+}
+
+//
+// Save
+// Writes the current state of the models to a scene file
+//
+// @param path (string) the path to the scene file
+//
+// @return error (error) the error (if any)
+//
+func (scene *Scene) Save(path string) error {
+	return SaveDescription(path, scene.Capture())
+}
+
+// The model light types of the scene file types
+var lightTypes = map[string]models.LightType{
+	LIGHT_TYPE_POINT:       models.LIGHT_POINT,
+	LIGHT_TYPE_DIRECTIONAL: models.LIGHT_DIRECTIONAL,
+	LIGHT_TYPE_SPOT:        models.LIGHT_SPOT,
+}
+
+// applyOperations applies the steps of a transform to a model (after ResetModel)
+func applyOperations(model models.Model, operations []Operation) {
+	for _, operation := range operations {
+		switch {
+		case operation.Translate != nil:
+			model.Translate(operation.Translate.X(), operation.Translate.Y(), operation.Translate.Z())
+		case operation.Scale != nil:
+			model.Scale(operation.Scale.X(), operation.Scale.Y(), operation.Scale.Z())
+		case operation.Rotate != nil:
+			if operation.Rotate.Unit == UNIT_DEGREES {
+				model.RotateDegrees(operation.Rotate.Angle, operation.Rotate.Axis)
+			} else {
+				model.RotateRadians(operation.Rotate.Angle, operation.Rotate.Axis)
+			}
+		case operation.Matrix != nil:
+			model.GetNode().SetMatrix(*operation.Matrix)
+		}
+	}
+}
+
+// scatterOffsets returns the move of every instance of an object, the same ones every time for a seed
+func scatterOffsets(object *ObjectDescription) []mgl32.Vec3 {
+	offsets := make([]mgl32.Vec3, object.Instances)
+	if object.Scatter == (mgl32.Vec2{}) {
+		return offsets
+	}
+
+	random := rand.New(rand.NewSource(object.Seed))
+	for i := range offsets {
+		offsets[i] = mgl32.Vec3{ random.Float32() * object.Scatter.X(), 0, random.Float32() * object.Scatter.Y() }
+	}
+
+	return offsets
+}
+
+func matrixOperation(matrix mgl32.Mat4) []Operation {
+	return []Operation{ { nil, nil, nil, &matrix } }
+}
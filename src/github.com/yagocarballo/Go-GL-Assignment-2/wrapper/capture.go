@@ -0,0 +1,407 @@
+package wrapper
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// Largest supersampling factor of the captures (the framebuffer grows with its square)
+const MAX_CAPTURE_SCALE = 4
+
+//
+// RecordOptions
+// How a frame sequence is recorded: numbered PNG files, an encoder that reads PNG images from its standard input, or both
+// (Example encoder: ffmpeg -y -f image2pipe -framerate 30 -i - clip.mp4)
+//
+type RecordOptions struct {
+	Directory string   // Folder of the numbered PNG files (frame-00000.png, ...), empty to skip the files
+	FrameRate int      // Frames per second of the sequence, the scene moves 1 / FrameRate seconds per frame
+	Scale     int      // Supersampling factor (1 captures at the size of the window)
+	Encoder   []string // Command and arguments of the encoder (nil to skip it)
+}
+
+//
+// recorder
+// The state of a recording: the fixed clock and the encoder
+//
+type recorder struct {
+	options    RecordOptions
+	frame      int
+
+	clock      *ManualClock // Moves 1 / FrameRate seconds per frame
+	savedClock Clock        // The clock before recording
+	savedCap   float64      // The frame cap before recording
+
+	encoder    *exec.Cmd
+	stdin      io.WriteCloser
+}
+
+//
+// captureWorker
+// Downsamples, encodes and writes the captured frames in order, away from the OpenGL thread
+//
+type captureWorker struct {
+	jobs    chan func() error
+	pending sync.WaitGroup
+	mutex   sync.Mutex
+	err     error // First error since the last wait
+}
+
+func newCaptureWorker() *captureWorker {
+	worker := &captureWorker{ make(chan func() error, 8), sync.WaitGroup{}, sync.Mutex{}, nil }
+	go worker.run()
+
+	return worker
+}
+
+// queue adds a job (it blocks while the queue is full, so a long recording doesn't fill the memory)
+func (worker *captureWorker) queue(job func() error) {
+	worker.pending.Add(1)
+	worker.jobs <- job
+}
+
+// run runs the jobs until the queue is closed
+func (worker *captureWorker) run() {
+	for job := range worker.jobs {
+		if err := job(); err != nil {
+			worker.mutex.Lock()
+			if worker.err == nil {
+				worker.err = err
+				log.Println("Capture failed:", err)
+			}
+			worker.mutex.Unlock()
+		}
+		worker.pending.Done()
+	}
+}
+
+// wait waits for the queued jobs and returns (and forgets) the first error
+func (worker *captureWorker) wait() error {
+	worker.pending.Wait()
+
+	worker.mutex.Lock()
+	defer worker.mutex.Unlock()
+	err := worker.err
+	worker.err = nil
+
+	return err
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////// Wrapper //////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// Screenshot
+// Captures the next frame to a PNG file. The file is written in the background (WaitCaptures waits for it)
+//
+// @param path (string) the path to the PNG file
+// @param scale (int) supersampling factor (the frame is drawn this many times larger and scaled down, 1 to MAX_CAPTURE_SCALE)
+//
+func (glw *Glw) Screenshot (path string, scale int) {
+	glw.screenshots = append(glw.screenshots, screenshotRequest{ path, clampScale(scale) })
+}
+
+//
+// StartRecording
+// Captures every frame until StopRecording. The scene moves a fixed time per frame (not the real time),
+// so the sequence plays smoothly at its frame rate however long the frames take to draw and write
+//
+// @param options (RecordOptions) where the frames go and the frame rate
+//
+// @return error (error) the error (if the options are not valid or the encoder doesn't start)
+//
+func (glw *Glw) StartRecording (options RecordOptions) error {
+	if glw.recorder != nil {
+		return errors.New("already recording")
+	}
+	if options.FrameRate <= 0 {
+		return fmt.Errorf("invalid frame rate %d", options.FrameRate)
+	}
+	if options.Directory == "" && len(options.Encoder) == 0 {
+		return errors.New("the recording needs a directory or an encoder")
+	}
+	options.Scale = clampScale(options.Scale)
+
+	if options.Directory != "" {
+		if err := os.MkdirAll(options.Directory, 0755); err != nil {
+			return err
+		}
+	}
+
+	recording := &recorder{ options, 0, &ManualClock{ glw.timing.Clock.Now() }, glw.timing.Clock, glw.timing.FrameCap, nil, nil }
+	if len(options.Encoder) > 0 {
+		recording.encoder = exec.Command(options.Encoder[0], options.Encoder[1:]...)
+		recording.encoder.Stdout, recording.encoder.Stderr = os.Stdout, os.Stderr
+
+		stdin, err := recording.encoder.StdinPipe()
+		if err != nil {
+			return err
+		}
+		if err := recording.encoder.Start(); err != nil {
+			return fmt.Errorf("couldn't start the encoder: %s", err)
+		}
+		recording.stdin = stdin
+	}
+
+	// The loop follows the fixed clock, and doesn't wait for the frame cap
+	glw.timing.Clock, glw.timing.FrameCap = recording.clock, 0
+	glw.recorder = recording
+
+	return nil
+}
+
+//
+// StopRecording
+// Stops capturing frames, waits for the queued frames and for the encoder to finish
+//
+// @return error (error) the first error of the recording (if any)
+//
+func (glw *Glw) StopRecording () error {
+	recording := glw.recorder
+	if recording == nil {
+		return errors.New("not recording")
+	}
+	glw.recorder = nil
+
+	// Goes back to the real time
+	glw.timing.Clock, glw.timing.FrameCap = recording.savedClock, recording.savedCap
+	glw.timing.Restart()
+
+	err := glw.WaitCaptures()
+	if recording.encoder != nil {
+		recording.stdin.Close()
+		if waitErr := recording.encoder.Wait(); err == nil && waitErr != nil {
+			err = fmt.Errorf("the encoder failed: %s", waitErr)
+		}
+	}
+
+	log.Printf("Recorded %d frames\n", recording.frame)
+
+	return err
+}
+
+//
+// IsRecording
+// Checks if the frames are being recorded
+//
+func (glw *Glw) IsRecording () bool {
+	return glw.recorder != nil
+}
+
+//
+// WaitCaptures
+// Waits until the screenshots and recorded frames are written
+//
+// @return error (error) the first error since the last wait (if any)
+//
+func (glw *Glw) WaitCaptures () error {
+	if glw.captures == nil {
+		return nil
+	}
+
+	return glw.captures.wait()
+}
+
+//
+// screenshotRequest
+// A screenshot waiting for the next frame
+//
+type screenshotRequest struct {
+	path  string
+	scale int
+}
+
+// captureScale returns the supersampling factor of the frame (0 if nothing captures it)
+func (glw *Glw) captureScale () int {
+	scale := 0
+	for _, request := range glw.screenshots {
+		if request.scale > scale {
+			scale = request.scale
+		}
+	}
+	if glw.recorder != nil && glw.recorder.options.Scale > scale {
+		scale = glw.recorder.options.Scale
+	}
+
+	return scale
+}
+
+//
+// renderFrame
+// Calls the render callback, and captures the frame if there is a screenshot or a recording.
+// The supersampled frames are drawn into a larger framebuffer, which is then scaled down into the window
+//
+// @param alpha (float64) the alpha of the render callback
+//
+func (glw *Glw) renderFrame (alpha float64) {
+	scale := glw.captureScale()
+	if scale == 0 {
+		glw.renderer(glw, alpha)
+		return
+	}
+
+	width, height := glw.GetFramebufferSize()
+	var pixels *image.RGBA
+	if scale == 1 {
+		glw.renderer(glw, alpha)
+		pixels = glw.ReadPixels()
+	} else {
+		pixels = glw.renderSupersampled(alpha, int32(width * scale), int32(height * scale))
+	}
+
+	// The large framebuffer may have failed, so the factor comes from the size of the frame
+	factor := 1
+	if width > 0 && pixels.Bounds().Dx() > width {
+		factor = pixels.Bounds().Dx() / width
+	}
+
+	if glw.captures == nil {
+		glw.captures = newCaptureWorker()
+	}
+
+	// The frame is drawn at the largest scale that was asked for, and every capture is scaled down to the size of the window
+	for _, request := range glw.screenshots {
+		path := request.path
+		glw.captures.queue(func() error {
+			if err := SavePNG(path, Downsample(pixels, factor)); err != nil {
+				return err
+			}
+			log.Printf("Saved the screenshot to %s\n", path)
+			return nil
+		})
+	}
+	glw.screenshots = nil
+
+	if recording := glw.recorder; recording != nil {
+		path := ""
+		if recording.options.Directory != "" {
+			path = filepath.Join(recording.options.Directory, fmt.Sprintf("frame-%05d.png", recording.frame))
+		}
+		stdin := recording.stdin
+		glw.captures.queue(func() error {
+			frame := Downsample(pixels, factor)
+			if path != "" {
+				if err := SavePNG(path, frame); err != nil {
+					return err
+				}
+			}
+			if stdin != nil {
+				return png.Encode(stdin, frame)
+			}
+			return nil
+		})
+
+		recording.frame++
+		recording.clock.Advance(1.0 / float64(recording.options.FrameRate))
+	}
+}
+
+//
+// renderSupersampled
+// Draws the frame into a larger framebuffer (it takes the place of the window), reads it back and scales it down into the window
+//
+// @param alpha (float64) the alpha of the render callback
+// @param width (int32) the width of the large framebuffer
+// @param height (int32) the height of the large framebuffer
+//
+// @return pixels (*image.RGBA) the large frame
+//
+func (glw *Glw) renderSupersampled (alpha float64, width, height int32) *image.RGBA {
+	if glw.captureTarget == nil || glw.captureTarget.Width != width || glw.captureTarget.Height != height {
+		if glw.captureTarget != nil {
+			glw.captureTarget.Delete()
+			glw.captureTarget = nil
+		}
+
+		target, err := NewFramebuffer(glw.Device, width, height)
+		if err != nil {
+			log.Println("Couldn't supersample the capture:", err)
+			glw.renderer(glw, alpha)
+			return glw.ReadPixels()
+		}
+		glw.captureTarget = target
+	}
+
+	// The passes go back to the large framebuffer instead of the window
+	window := screen
+	screen = screenTarget{ glw.captureTarget.FBO, width, height }
+	glw.captureTarget.Bind()
+
+	glw.renderer(glw, alpha)
+	pixels := glw.captureTarget.ReadPixels()
+
+	// Scales the frame down into the window
+	screen = window
+	windowWidth, windowHeight := glw.GetFramebufferSize()
+	glw.Device.BindFramebuffer(gl.READ_FRAMEBUFFER, glw.captureTarget.FBO)
+	glw.Device.BindFramebuffer(gl.DRAW_FRAMEBUFFER, screen.fbo)
+	glw.Device.BlitFramebuffer(0, 0, width, height, 0, 0, int32(windowWidth), int32(windowHeight), gl.COLOR_BUFFER_BIT, gl.LINEAR)
+	glw.Device.BindFramebuffer(gl.FRAMEBUFFER, screen.fbo)
+	glw.Device.Viewport(0, 0, int32(windowWidth), int32(windowHeight))
+
+	return pixels
+}
+
+//
+// Downsample
+// Scales an image down by averaging blocks of pixels (box filter)
+//
+// @param img (*image.RGBA) the image
+// @param factor (int) the size of the blocks (1 or less returns the same image)
+//
+// @return image (*image.RGBA) the smaller image
+//
+func Downsample (img *image.RGBA, factor int) *image.RGBA {
+	if factor <= 1 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx() / factor, bounds.Dy() / factor
+	small := image.NewRGBA(image.Rect(0, 0, width, height))
+	samples := uint32(factor * factor)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var sum [4]uint32
+			for sy := 0; sy < factor; sy++ {
+				offset := img.PixOffset(bounds.Min.X + x * factor, bounds.Min.Y + y * factor + sy)
+				for sx := 0; sx < factor; sx++ {
+					for channel := 0; channel < 4; channel++ {
+						sum[channel] += uint32(img.Pix[offset + sx * 4 + channel])
+					}
+				}
+			}
+
+			offset := small.PixOffset(x, y)
+			for channel := 0; channel < 4; channel++ {
+				small.Pix[offset + channel] = uint8((sum[channel] + samples / 2) / samples)
+			}
+		}
+	}
+
+	return small
+}
+
+// clampScale keeps a supersampling factor between 1 and MAX_CAPTURE_SCALE
+func clampScale (scale int) int {
+	if scale < 1 {
+		return 1
+	}
+	if scale > MAX_CAPTURE_SCALE {
+		return MAX_CAPTURE_SCALE
+	}
+
+	return scale
+}
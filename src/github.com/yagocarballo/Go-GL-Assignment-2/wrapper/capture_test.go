@@ -0,0 +1,105 @@
+package wrapper
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// filledImage returns an image with every pixel of the same color
+func filledImage(width, height int, fill color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, fill)
+		}
+	}
+
+	return img
+}
+
+func TestDownsampleFactorOne(t *testing.T) {
+	img := filledImage(3, 2, color.RGBA{ 1, 2, 3, 4 })
+	for _, factor := range []int{ 1, 0, -2 } {
+		if Downsample(img, factor) != img {
+			t.Errorf("factor %d: expected the same image", factor)
+		}
+	}
+}
+
+func TestDownsampleAveragesWithRounding(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, color.RGBA{ 10, 1, 0, 0 })
+	img.SetRGBA(1, 0, color.RGBA{ 11, 2, 0, 0 })
+	img.SetRGBA(0, 1, color.RGBA{ 11, 2, 0, 1 })
+	img.SetRGBA(1, 1, color.RGBA{ 11, 2, 1, 1 })
+
+	small := Downsample(img, 2)
+	if small.Bounds() != image.Rect(0, 0, 1, 1) {
+		t.Fatalf("expected a 1x1 image, found %v", small.Bounds())
+	}
+
+	// 43 / 4 = 10.75, 7 / 4 = 1.75, 1 / 4 = 0.25 and 2 / 4 = 0.5 (the halves round up)
+	if pixel := small.RGBAAt(0, 0); pixel != (color.RGBA{ 11, 2, 0, 1 }) {
+		t.Errorf("expected the rounded average (11, 2, 0, 1), found %v", pixel)
+	}
+}
+
+func TestDownsampleTruncatesOddSizes(t *testing.T) {
+	// The last column and row don't fill a block, so they are left out
+	img := filledImage(5, 3, color.RGBA{ 255, 255, 255, 255 })
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetRGBA(x, y, color.RGBA{ 20, 40, 60, 255 })
+		}
+	}
+
+	small := Downsample(img, 2)
+	if small.Bounds() != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("expected a 2x1 image, found %v", small.Bounds())
+	}
+	for x := 0; x < 2; x++ {
+		if pixel := small.RGBAAt(x, 0); pixel != (color.RGBA{ 20, 40, 60, 255 }) {
+			t.Errorf("pixel %d: the truncated pixels were averaged in (%v)", x, pixel)
+		}
+	}
+
+	// A sub image starts on its own corner
+	sub := img.SubImage(image.Rect(1, 1, 5, 3)).(*image.RGBA)
+	if pixel := Downsample(sub, 2).RGBAAt(1, 0); pixel != (color.RGBA{ 196, 201, 206, 255 }) {
+		t.Errorf("expected the block from (3, 1) to (4, 2), found %v", pixel)
+	}
+}
+
+func TestCaptureWorkerWait(t *testing.T) {
+	worker := newCaptureWorker()
+	defer close(worker.jobs)
+
+	first, second := errors.New("first"), errors.New("second")
+	order := []int{}
+	for index, err := range []error{ nil, first, second, nil } {
+		index, err := index, err
+		worker.queue(func() error {
+			order = append(order, index)
+			return err
+		})
+	}
+
+	if err := worker.wait(); err != first {
+		t.Errorf("expected the first error, found %v", err)
+	}
+	if len(order) != 4 || order[0] != 0 || order[1] != 1 || order[2] != 2 || order[3] != 3 {
+		t.Errorf("expected the jobs to run in order, found %v", order)
+	}
+
+	// The error is forgotten after the wait
+	if err := worker.wait(); err != nil {
+		t.Errorf("expected no error after the wait, found %v", err)
+	}
+
+	worker.queue(func() error { return second })
+	if err := worker.wait(); err != second {
+		t.Errorf("expected the error of the new job, found %v", err)
+	}
+}
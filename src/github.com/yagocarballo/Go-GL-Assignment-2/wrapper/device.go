@@ -0,0 +1,403 @@
+package wrapper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+//
+// Device
+// The OpenGL calls of the models, the shaders and the framebuffers. The app uses the go-gl device (NewGLDevice),
+// and the tests can use a FakeDevice, which records the calls without a GPU.
+// The data of the buffers and textures are slices of fixed size values ([]float32, []mgl32.Vec3, []uint16, ...,
+// nil for empty storage),
+// the offsets into the bound buffers are bytes, and the names of the uniforms and attributes don't end with \x00
+//
+type Device interface {
+	// Buffers
+	GenBuffer() uint32
+	DeleteBuffer(buffer uint32)
+	BindBuffer(target, buffer uint32)
+	BufferData(target uint32, size int, data interface{}, usage uint32)
+	BufferSubData(target uint32, offset, size int, data interface{})
+	BufferSize(target uint32) int32
+	BindBufferBase(target, index, buffer uint32)
+
+	// Vertex Attributes and Draws
+	VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset int)
+	EnableVertexAttribArray(index uint32)
+	DisableVertexAttribArray(index uint32)
+	DrawArrays(mode uint32, first, count int32)
+	DrawElements(mode uint32, count int32, xtype uint32, offset int)
+	GenVertexArray() uint32
+	BindVertexArray(array uint32)
+
+	// State
+	Enable(capability uint32)
+	Disable(capability uint32)
+	DepthFunc(function uint32)
+	BlendFunc(source, destination uint32)
+	PolygonMode(face, mode uint32)
+	PointSize(size float32)
+	Viewport(x, y, width, height int32)
+	ClearColor(red, green, blue, alpha float32)
+	Clear(mask uint32)
+	GetError() uint32
+
+	// Textures
+	GenTexture() uint32
+	DeleteTexture(texture uint32)
+	ActiveTexture(unit uint32)
+	BindTexture(target, texture uint32)
+	TexParameteri(target, name uint32, value int32)
+	TexImage2D(target uint32, level, internalFormat, width, height int32, format, xtype uint32, data interface{})
+
+	// Framebuffers
+	GenFramebuffer() uint32
+	DeleteFramebuffer(framebuffer uint32)
+	BindFramebuffer(target, framebuffer uint32)
+	FramebufferTexture2D(target, attachment, textureTarget, texture uint32, level int32)
+	CheckFramebufferStatus(target uint32) uint32
+	BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask, filter uint32)
+	ReadBuffer(source uint32)
+	ReadPixels(x, y, width, height int32, format, xtype uint32, pixels []uint8)
+
+	// Programs and Uniforms
+	CreateProgram(vertexSource, fragmentSource string) (uint32, error)
+	DeleteProgram(program uint32)
+	UseProgram(program uint32)
+	GetUniformLocation(program uint32, name string) int32
+	GetAttribLocation(program uint32, name string) int32
+	Uniform1i(location, value int32)
+	Uniform1ui(location int32, value uint32)
+	Uniform1f(location int32, value float32)
+	Uniform4f(location int32, v0, v1, v2, v3 float32)
+	Uniform4fv(location, count int32, value []float32)
+	UniformMatrix4fv(location, count int32, transpose bool, value []float32)
+	GetUniformBlockIndex(program uint32, name string) uint32
+	UniformBlockBinding(program, blockIndex, binding uint32)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////// GL Device /////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// glDevice
+// The Device of the go-gl bindings (it needs a context, so it's created after gl.Init)
+//
+type glDevice struct {}
+
+//
+// NewGLDevice
+// Creates the Device that calls OpenGL through go-gl
+//
+// @return device (Device) the device
+//
+func NewGLDevice () Device {
+	return glDevice{}
+}
+
+// pointer returns the address of the first value of a slice (nil for nil or empty slices, which gl.Ptr can't take)
+func pointer (data interface{}) unsafe.Pointer {
+	if data == nil {
+		return nil
+	}
+	if value := reflect.ValueOf(data); value.Kind() == reflect.Slice && value.Len() == 0 {
+		return nil
+	}
+
+	return gl.Ptr(data)
+}
+
+func (glDevice) GenBuffer () uint32 {
+	var buffer uint32
+	gl.GenBuffers(1, &buffer)
+	return buffer
+}
+
+func (glDevice) DeleteBuffer (buffer uint32) {
+	gl.DeleteBuffers(1, &buffer)
+}
+
+func (glDevice) BindBuffer (target, buffer uint32) {
+	gl.BindBuffer(target, buffer)
+}
+
+func (glDevice) BufferData (target uint32, size int, data interface{}, usage uint32) {
+	gl.BufferData(target, size, pointer(data), usage)
+}
+
+func (glDevice) BufferSubData (target uint32, offset, size int, data interface{}) {
+	gl.BufferSubData(target, offset, size, pointer(data))
+}
+
+func (glDevice) BufferSize (target uint32) int32 {
+	var size int32
+	gl.GetBufferParameteriv(target, gl.BUFFER_SIZE, &size)
+	return size
+}
+
+func (glDevice) BindBufferBase (target, index, buffer uint32) {
+	gl.BindBufferBase(target, index, buffer)
+}
+
+func (glDevice) VertexAttribPointer (index uint32, size int32, xtype uint32, normalized bool, stride int32, offset int) {
+	gl.VertexAttribPointer(index, size, xtype, normalized, stride, gl.PtrOffset(offset))
+}
+
+func (glDevice) EnableVertexAttribArray (index uint32) {
+	gl.EnableVertexAttribArray(index)
+}
+
+func (glDevice) DisableVertexAttribArray (index uint32) {
+	gl.DisableVertexAttribArray(index)
+}
+
+func (glDevice) DrawArrays (mode uint32, first, count int32) {
+	gl.DrawArrays(mode, first, count)
+}
+
+func (glDevice) DrawElements (mode uint32, count int32, xtype uint32, offset int) {
+	gl.DrawElements(mode, count, xtype, gl.PtrOffset(offset))
+}
+
+func (glDevice) GenVertexArray () uint32 {
+	var array uint32
+	gl.GenVertexArrays(1, &array)
+	return array
+}
+
+func (glDevice) BindVertexArray (array uint32) {
+	gl.BindVertexArray(array)
+}
+
+func (glDevice) Enable (capability uint32) {
+	gl.Enable(capability)
+}
+
+func (glDevice) Disable (capability uint32) {
+	gl.Disable(capability)
+}
+
+func (glDevice) DepthFunc (function uint32) {
+	gl.DepthFunc(function)
+}
+
+func (glDevice) BlendFunc (source, destination uint32) {
+	gl.BlendFunc(source, destination)
+}
+
+func (glDevice) PolygonMode (face, mode uint32) {
+	gl.PolygonMode(face, mode)
+}
+
+func (glDevice) PointSize (size float32) {
+	gl.PointSize(size)
+}
+
+func (glDevice) Viewport (x, y, width, height int32) {
+	gl.Viewport(x, y, width, height)
+}
+
+func (glDevice) ClearColor (red, green, blue, alpha float32) {
+	gl.ClearColor(red, green, blue, alpha)
+}
+
+func (glDevice) Clear (mask uint32) {
+	gl.Clear(mask)
+}
+
+func (glDevice) GetError () uint32 {
+	return gl.GetError()
+}
+
+func (glDevice) GenTexture () uint32 {
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	return texture
+}
+
+func (glDevice) DeleteTexture (texture uint32) {
+	gl.DeleteTextures(1, &texture)
+}
+
+func (glDevice) ActiveTexture (unit uint32) {
+	gl.ActiveTexture(unit)
+}
+
+func (glDevice) BindTexture (target, texture uint32) {
+	gl.BindTexture(target, texture)
+}
+
+func (glDevice) TexParameteri (target, name uint32, value int32) {
+	gl.TexParameteri(target, name, value)
+}
+
+func (glDevice) TexImage2D (target uint32, level, internalFormat, width, height int32, format, xtype uint32, data interface{}) {
+	gl.TexImage2D(target, level, internalFormat, width, height, 0, format, xtype, pointer(data))
+}
+
+func (glDevice) GenFramebuffer () uint32 {
+	var framebuffer uint32
+	gl.GenFramebuffers(1, &framebuffer)
+	return framebuffer
+}
+
+func (glDevice) DeleteFramebuffer (framebuffer uint32) {
+	gl.DeleteFramebuffers(1, &framebuffer)
+}
+
+func (glDevice) BindFramebuffer (target, framebuffer uint32) {
+	gl.BindFramebuffer(target, framebuffer)
+}
+
+func (glDevice) FramebufferTexture2D (target, attachment, textureTarget, texture uint32, level int32) {
+	gl.FramebufferTexture2D(target, attachment, textureTarget, texture, level)
+}
+
+func (glDevice) CheckFramebufferStatus (target uint32) uint32 {
+	return gl.CheckFramebufferStatus(target)
+}
+
+func (glDevice) BlitFramebuffer (srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask, filter uint32) {
+	gl.BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
+
+func (glDevice) ReadBuffer (source uint32) {
+	gl.ReadBuffer(source)
+}
+
+func (glDevice) ReadPixels (x, y, width, height int32, format, xtype uint32, pixels []uint8) {
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(x, y, width, height, format, xtype, pointer(pixels))
+}
+
+//
+// CreateProgram
+// Compiles the vertex and fragment shaders and links them into a program
+//
+// @param vertexSource (string) the source of the vertex shader
+// @param fragmentSource (string) the source of the fragment shader
+//
+// @return program (uint32) the program
+// @return error (error) the compile or link log (if any)
+//
+func (glDevice) CreateProgram (vertexSource, fragmentSource string) (uint32, error) {
+	// Compiles the Shaders
+	vertexShader, err := compileShader(vertexSource, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compile the vertex shader: %v", err)
+	}
+
+	fragmentShader, err := compileShader(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		gl.DeleteShader(vertexShader)
+		return 0, fmt.Errorf("failed to compile the fragment shader: %v", err)
+	}
+
+	// Creates the Program and links the Shaders
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vertexShader)
+	gl.AttachShader(program, fragmentShader)
+	gl.LinkProgram(program)
+
+	// The program keeps the compiled shaders
+	gl.DeleteShader(vertexShader)
+	gl.DeleteShader(fragmentShader)
+
+	// If there was any error, parse the C error and return it as a Go error
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength + 1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
+		gl.DeleteProgram(program)
+
+		return 0, fmt.Errorf("failed to link program: %v", log)
+	}
+
+	return program, nil
+}
+
+// compileShader creates and compiles a shader from its source
+func compileShader (source string, shaderType uint32) (uint32, error) {
+	shader := gl.CreateShader(shaderType)
+
+	// Converts the source into a valid C String
+	csources, free := gl.Strs(strings.TrimRight(source, "\x00") + "\x00")
+	gl.ShaderSource(shader, 1, csources, nil)
+	free()
+	gl.CompileShader(shader)
+
+	// If there was an error, parse the C Error into a Go Error and return it
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength + 1))
+		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+		gl.DeleteShader(shader)
+
+		return 0, fmt.Errorf("%v", log)
+	}
+
+	return shader, nil
+}
+
+func (glDevice) DeleteProgram (program uint32) {
+	gl.DeleteProgram(program)
+}
+
+func (glDevice) UseProgram (program uint32) {
+	gl.UseProgram(program)
+}
+
+func (glDevice) GetUniformLocation (program uint32, name string) int32 {
+	return gl.GetUniformLocation(program, gl.Str(name + "\x00"))
+}
+
+func (glDevice) GetAttribLocation (program uint32, name string) int32 {
+	return gl.GetAttribLocation(program, gl.Str(name + "\x00"))
+}
+
+func (glDevice) Uniform1i (location, value int32) {
+	gl.Uniform1i(location, value)
+}
+
+func (glDevice) Uniform1ui (location int32, value uint32) {
+	gl.Uniform1ui(location, value)
+}
+
+func (glDevice) Uniform1f (location int32, value float32) {
+	gl.Uniform1f(location, value)
+}
+
+func (glDevice) Uniform4f (location int32, v0, v1, v2, v3 float32) {
+	gl.Uniform4f(location, v0, v1, v2, v3)
+}
+
+func (glDevice) Uniform4fv (location, count int32, value []float32) {
+	gl.Uniform4fv(location, count, &value[0])
+}
+
+func (glDevice) UniformMatrix4fv (location, count int32, transpose bool, value []float32) {
+	gl.UniformMatrix4fv(location, count, transpose, &value[0])
+}
+
+func (glDevice) GetUniformBlockIndex (program uint32, name string) uint32 {
+	return gl.GetUniformBlockIndex(program, gl.Str(name + "\x00"))
+}
+
+func (glDevice) UniformBlockBinding (program, blockIndex, binding uint32) {
+	gl.UniformBlockBinding(program, blockIndex, binding)
+}
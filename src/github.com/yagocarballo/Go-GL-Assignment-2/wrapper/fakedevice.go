@@ -0,0 +1,585 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+//
+// Call
+// A call made on the FakeDevice
+//
+type Call struct {
+	Name string
+	Args []interface{}
+}
+
+func (call Call) String() string {
+	args := make([]string, len(call.Args))
+	for index, arg := range call.Args {
+		args[index] = fmt.Sprint(arg)
+	}
+
+	return fmt.Sprintf("%s(%s)", call.Name, strings.Join(args, ", "))
+}
+
+//
+// FakeProgram
+// A program of the FakeDevice, with the values its uniforms were given
+//
+type FakeProgram struct {
+	VertexSource   string
+	FragmentSource string
+
+	Locations      map[string]int32     // The location of every uniform and attribute that was asked for
+	Uniforms       map[string][]float32 // The last value of every uniform (the integers are stored as floats)
+	Blocks         map[string]uint32    // The binding point of every uniform block that was bound
+	names          []string             // The names by location
+	blocks         []string             // The names of the uniform blocks by index
+}
+
+//
+// FakeDevice
+// A Device that doesn't draw: it records the calls and keeps the state a test can check (the data of the buffers,
+// the bindings, the enabled capabilities and the values of the uniforms). It also notes the mistakes a GPU wouldn't
+// report, like uploading more bytes than the data has
+//
+type FakeDevice struct {
+	Calls             []Call
+	Errors            []error
+
+	Buffers           map[uint32][]byte       // The data of every buffer
+	Bindings          map[uint32]uint32       // The bound buffer, texture or framebuffer of every target
+	BufferBases       map[[2]uint32]uint32    // The buffer bound to every indexed binding point ({ target, index })
+	Capabilities      map[uint32]bool         // The enabled capabilities
+	Programs          map[uint32]*FakeProgram
+	ActiveProgram     uint32
+
+	FramebufferStatus uint32 // What CheckFramebufferStatus returns (FRAMEBUFFER_COMPLETE by default)
+	ProgramError      error  // What CreateProgram fails with (nil to succeed)
+	GLError           uint32 // What the next GetError returns (NO_ERROR by default)
+
+	lastName          uint32
+}
+
+//
+// NewFakeDevice
+// Creates an empty FakeDevice
+//
+// @return device (*FakeDevice) a pointer to the device
+//
+func NewFakeDevice () *FakeDevice {
+	return &FakeDevice{
+		nil,                             // Calls
+		nil,                             // Errors
+		make(map[uint32][]byte),         // Buffers
+		make(map[uint32]uint32),         // Bindings
+		make(map[[2]uint32]uint32),      // BufferBases
+		make(map[uint32]bool),           // Capabilities
+		make(map[uint32]*FakeProgram),   // Programs
+		0,                               // ActiveProgram
+		gl.FRAMEBUFFER_COMPLETE,         // FramebufferStatus
+		nil,                             // ProgramError
+		gl.NO_ERROR,                     // GLError
+		0,                               // lastName
+	}
+}
+
+// CallsNamed returns the calls of a method, in order
+func (device *FakeDevice) CallsNamed (name string) []Call {
+	calls := []Call{}
+	for _, call := range device.Calls {
+		if call.Name == name {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Reset forgets the recorded calls and errors (the state stays)
+func (device *FakeDevice) Reset () {
+	device.Calls, device.Errors = nil, nil
+}
+
+// BufferFloats returns the data of a buffer as float32 values
+func (device *FakeDevice) BufferFloats (buffer uint32) []float32 {
+	data := device.Buffers[buffer]
+	values := make([]float32, len(data) / 4)
+	for index := range values {
+		values[index] = math.Float32frombits(binary.LittleEndian.Uint32(data[index * 4:]))
+	}
+
+	return values
+}
+
+// BufferUint32s returns the data of a buffer as uint32 values
+func (device *FakeDevice) BufferUint32s (buffer uint32) []uint32 {
+	data := device.Buffers[buffer]
+	values := make([]uint32, len(data) / 4)
+	for index := range values {
+		values[index] = binary.LittleEndian.Uint32(data[index * 4:])
+	}
+
+	return values
+}
+
+// BufferUint16s returns the data of a buffer as uint16 values
+func (device *FakeDevice) BufferUint16s (buffer uint32) []uint16 {
+	data := device.Buffers[buffer]
+	values := make([]uint16, len(data) / 2)
+	for index := range values {
+		values[index] = binary.LittleEndian.Uint16(data[index * 2:])
+	}
+
+	return values
+}
+
+// Uniform returns the last value of a uniform of a program (nil if it was never set)
+func (device *FakeDevice) Uniform (program uint32, name string) []float32 {
+	if fake, ok := device.Programs[program]; ok {
+		return fake.Uniforms[name]
+	}
+
+	return nil
+}
+
+// record adds a call
+func (device *FakeDevice) record (name string, args ...interface{}) {
+	device.Calls = append(device.Calls, Call{ name, args })
+}
+
+// fail notes a mistake
+func (device *FakeDevice) fail (format string, args ...interface{}) {
+	device.Errors = append(device.Errors, fmt.Errorf(format, args...))
+}
+
+// gen returns a new name (the buffers, textures, framebuffers and programs share them, so they never clash)
+func (device *FakeDevice) gen () uint32 {
+	device.lastName++
+	return device.lastName
+}
+
+// toBytes returns the bytes of a slice of fixed size values (little endian, like the GPUs the app runs on)
+func toBytes (data interface{}) ([]byte, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var buffer bytes.Buffer
+	if err := binary.Write(&buffer, binary.LittleEndian, data); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////// Buffers //////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+func (device *FakeDevice) GenBuffer () uint32 {
+	buffer := device.gen()
+	device.Buffers[buffer] = nil
+	device.record("GenBuffer", buffer)
+
+	return buffer
+}
+
+func (device *FakeDevice) DeleteBuffer (buffer uint32) {
+	device.record("DeleteBuffer", buffer)
+	delete(device.Buffers, buffer)
+}
+
+func (device *FakeDevice) BindBuffer (target, buffer uint32) {
+	device.record("BindBuffer", target, buffer)
+	device.Bindings[target] = buffer
+}
+
+func (device *FakeDevice) BufferData (target uint32, size int, data interface{}, usage uint32) {
+	device.record("BufferData", target, size, usage)
+
+	buffer, ok := device.Bindings[target]
+	if !ok || buffer == 0 {
+		device.fail("BufferData: no buffer bound to 0x%x", target)
+		return
+	}
+
+	content, err := toBytes(data)
+	if err != nil {
+		device.fail("BufferData: %s", err)
+		return
+	}
+	if data != nil && size > len(content) {
+		device.fail("BufferData: %d bytes uploaded from %d bytes of data (buffer %d)", size, len(content), buffer)
+	}
+
+	device.Buffers[buffer] = make([]byte, size)
+	copy(device.Buffers[buffer], content)
+}
+
+func (device *FakeDevice) BufferSubData (target uint32, offset, size int, data interface{}) {
+	device.record("BufferSubData", target, offset, size)
+
+	buffer := device.Bindings[target]
+	content, err := toBytes(data)
+	if err != nil {
+		device.fail("BufferSubData: %s", err)
+		return
+	}
+	if size > len(content) {
+		device.fail("BufferSubData: %d bytes uploaded from %d bytes of data (buffer %d)", size, len(content), buffer)
+		size = len(content)
+	}
+	if offset < 0 || offset + size > len(device.Buffers[buffer]) {
+		device.fail("BufferSubData: bytes %d to %d are outside buffer %d (%d bytes)", offset, offset + size, buffer, len(device.Buffers[buffer]))
+		return
+	}
+
+	copy(device.Buffers[buffer][offset:], content[:size])
+}
+
+func (device *FakeDevice) BufferSize (target uint32) int32 {
+	device.record("BufferSize", target)
+	return int32(len(device.Buffers[device.Bindings[target]]))
+}
+
+func (device *FakeDevice) BindBufferBase (target, index, buffer uint32) {
+	device.record("BindBufferBase", target, index, buffer)
+	if _, ok := device.Buffers[buffer]; !ok && buffer != 0 {
+		device.fail("BindBufferBase: buffer %d doesn't exist", buffer)
+	}
+
+	// It also binds the buffer to the target, like glBindBufferBase
+	device.BufferBases[[2]uint32{ target, index }] = buffer
+	device.Bindings[target] = buffer
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////// Vertex Attributes and Draws ///////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+func (device *FakeDevice) VertexAttribPointer (index uint32, size int32, xtype uint32, normalized bool, stride int32, offset int) {
+	device.record("VertexAttribPointer", index, size, xtype, normalized, stride, offset)
+	if device.Bindings[gl.ARRAY_BUFFER] == 0 {
+		device.fail("VertexAttribPointer: no buffer bound for attribute %d", index)
+	}
+}
+
+func (device *FakeDevice) EnableVertexAttribArray (index uint32) {
+	device.record("EnableVertexAttribArray", index)
+}
+
+func (device *FakeDevice) DisableVertexAttribArray (index uint32) {
+	device.record("DisableVertexAttribArray", index)
+}
+
+func (device *FakeDevice) DrawArrays (mode uint32, first, count int32) {
+	device.record("DrawArrays", mode, first, count)
+}
+
+func (device *FakeDevice) DrawElements (mode uint32, count int32, xtype uint32, offset int) {
+	device.record("DrawElements", mode, count, xtype, offset)
+
+	size := 4
+	if xtype == gl.UNSIGNED_SHORT {
+		size = 2
+	}
+	buffer := device.Bindings[gl.ELEMENT_ARRAY_BUFFER]
+	if end := offset + int(count) * size; end > len(device.Buffers[buffer]) {
+		device.fail("DrawElements: reads up to byte %d of element buffer %d (%d bytes)", end, buffer, len(device.Buffers[buffer]))
+	}
+}
+
+func (device *FakeDevice) GenVertexArray () uint32 {
+	array := device.gen()
+	device.record("GenVertexArray", array)
+
+	return array
+}
+
+func (device *FakeDevice) BindVertexArray (array uint32) {
+	device.record("BindVertexArray", array)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+/////////////////////////////////////// State ///////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+func (device *FakeDevice) Enable (capability uint32) {
+	device.record("Enable", capability)
+	device.Capabilities[capability] = true
+}
+
+func (device *FakeDevice) Disable (capability uint32) {
+	device.record("Disable", capability)
+	device.Capabilities[capability] = false
+}
+
+func (device *FakeDevice) DepthFunc (function uint32) {
+	device.record("DepthFunc", function)
+}
+
+func (device *FakeDevice) BlendFunc (source, destination uint32) {
+	device.record("BlendFunc", source, destination)
+}
+
+func (device *FakeDevice) PolygonMode (face, mode uint32) {
+	device.record("PolygonMode", face, mode)
+}
+
+func (device *FakeDevice) PointSize (size float32) {
+	device.record("PointSize", size)
+}
+
+func (device *FakeDevice) Viewport (x, y, width, height int32) {
+	device.record("Viewport", x, y, width, height)
+}
+
+func (device *FakeDevice) ClearColor (red, green, blue, alpha float32) {
+	device.record("ClearColor", red, green, blue, alpha)
+}
+
+func (device *FakeDevice) Clear (mask uint32) {
+	device.record("Clear", mask)
+}
+
+func (device *FakeDevice) GetError () uint32 {
+	device.record("GetError")
+
+	code := device.GLError
+	device.GLError = gl.NO_ERROR
+	return code
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////// Textures and Framebuffers ////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+func (device *FakeDevice) GenTexture () uint32 {
+	texture := device.gen()
+	device.record("GenTexture", texture)
+
+	return texture
+}
+
+func (device *FakeDevice) DeleteTexture (texture uint32) {
+	device.record("DeleteTexture", texture)
+}
+
+func (device *FakeDevice) ActiveTexture (unit uint32) {
+	device.record("ActiveTexture", unit)
+}
+
+func (device *FakeDevice) BindTexture (target, texture uint32) {
+	device.record("BindTexture", target, texture)
+	device.Bindings[target] = texture
+}
+
+func (device *FakeDevice) TexParameteri (target, name uint32, value int32) {
+	device.record("TexParameteri", target, name, value)
+}
+
+func (device *FakeDevice) TexImage2D (target uint32, level, internalFormat, width, height int32, format, xtype uint32, data interface{}) {
+	device.record("TexImage2D", target, level, internalFormat, width, height, format, xtype)
+	if device.Bindings[target] == 0 {
+		device.fail("TexImage2D: no texture bound to 0x%x", target)
+	}
+}
+
+func (device *FakeDevice) GenFramebuffer () uint32 {
+	framebuffer := device.gen()
+	device.record("GenFramebuffer", framebuffer)
+
+	return framebuffer
+}
+
+func (device *FakeDevice) DeleteFramebuffer (framebuffer uint32) {
+	device.record("DeleteFramebuffer", framebuffer)
+}
+
+func (device *FakeDevice) BindFramebuffer (target, framebuffer uint32) {
+	device.record("BindFramebuffer", target, framebuffer)
+	device.Bindings[target] = framebuffer
+	if target == gl.FRAMEBUFFER {
+		device.Bindings[gl.READ_FRAMEBUFFER], device.Bindings[gl.DRAW_FRAMEBUFFER] = framebuffer, framebuffer
+	}
+}
+
+func (device *FakeDevice) FramebufferTexture2D (target, attachment, textureTarget, texture uint32, level int32) {
+	device.record("FramebufferTexture2D", target, attachment, textureTarget, texture, level)
+}
+
+func (device *FakeDevice) CheckFramebufferStatus (target uint32) uint32 {
+	device.record("CheckFramebufferStatus", target)
+	return device.FramebufferStatus
+}
+
+func (device *FakeDevice) BlitFramebuffer (srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask, filter uint32) {
+	device.record("BlitFramebuffer", srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
+
+func (device *FakeDevice) ReadBuffer (source uint32) {
+	device.record("ReadBuffer", source)
+}
+
+// ReadPixels leaves the pixels as they are (the fake doesn't draw anything)
+func (device *FakeDevice) ReadPixels (x, y, width, height int32, format, xtype uint32, pixels []uint8) {
+	device.record("ReadPixels", x, y, width, height, format, xtype)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+/////////////////////////////// Programs and Uniforms ///////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+func (device *FakeDevice) CreateProgram (vertexSource, fragmentSource string) (uint32, error) {
+	device.record("CreateProgram")
+	if device.ProgramError != nil {
+		return 0, device.ProgramError
+	}
+	if strings.TrimRight(vertexSource, "\x00") == "" || strings.TrimRight(fragmentSource, "\x00") == "" {
+		return 0, errors.New("failed to compile: empty shader source")
+	}
+
+	program := device.gen()
+	device.Programs[program] = &FakeProgram{ vertexSource, fragmentSource, make(map[string]int32), make(map[string][]float32), make(map[string]uint32), nil, nil }
+
+	return program, nil
+}
+
+func (device *FakeDevice) DeleteProgram (program uint32) {
+	device.record("DeleteProgram", program)
+	delete(device.Programs, program)
+}
+
+func (device *FakeDevice) UseProgram (program uint32) {
+	device.record("UseProgram", program)
+	device.ActiveProgram = program
+}
+
+func (device *FakeDevice) GetUniformLocation (program uint32, name string) int32 {
+	device.record("GetUniformLocation", program, name)
+	return device.location("GetUniformLocation", program, name)
+}
+
+func (device *FakeDevice) GetAttribLocation (program uint32, name string) int32 {
+	device.record("GetAttribLocation", program, name)
+	return device.location("GetAttribLocation", program, name)
+}
+
+// location gives every name of a program its own location, in the order they are asked for
+func (device *FakeDevice) location (method string, program uint32, name string) int32 {
+	fake, ok := device.Programs[program]
+	if !ok {
+		device.fail("%s: program %d doesn't exist", method, program)
+		return -1
+	}
+	if location, ok := fake.Locations[name]; ok {
+		return location
+	}
+
+	location := int32(len(fake.names))
+	fake.Locations[name] = location
+	fake.names = append(fake.names, name)
+
+	return location
+}
+
+// setUniform stores the value of a uniform of the active program
+func (device *FakeDevice) setUniform (location int32, values ...float32) {
+	if location < 0 {
+		return
+	}
+
+	fake, ok := device.Programs[device.ActiveProgram]
+	if !ok || int(location) >= len(fake.names) {
+		device.fail("Uniform: location %d isn't in the active program %d", location, device.ActiveProgram)
+		return
+	}
+
+	fake.Uniforms[fake.names[location]] = values
+}
+
+// setUniformArray stores a copy of the values of an array uniform
+func (device *FakeDevice) setUniformArray (name string, location int32, value []float32, length int) {
+	if len(value) < length {
+		device.fail("%s: %d values read from %d", name, length, len(value))
+		return
+	}
+
+	device.setUniform(location, append([]float32{}, value[:length]...)...)
+}
+
+func (device *FakeDevice) Uniform1i (location, value int32) {
+	device.record("Uniform1i", location, value)
+	device.setUniform(location, float32(value))
+}
+
+func (device *FakeDevice) Uniform1ui (location int32, value uint32) {
+	device.record("Uniform1ui", location, value)
+	device.setUniform(location, float32(value))
+}
+
+func (device *FakeDevice) Uniform1f (location int32, value float32) {
+	device.record("Uniform1f", location, value)
+	device.setUniform(location, value)
+}
+
+func (device *FakeDevice) Uniform4f (location int32, v0, v1, v2, v3 float32) {
+	device.record("Uniform4f", location, v0, v1, v2, v3)
+	device.setUniform(location, v0, v1, v2, v3)
+}
+
+func (device *FakeDevice) Uniform4fv (location, count int32, value []float32) {
+	device.record("Uniform4fv", location, count)
+	device.setUniformArray("Uniform4fv", location, value, int(count) * 4)
+}
+
+func (device *FakeDevice) UniformMatrix4fv (location, count int32, transpose bool, value []float32) {
+	device.record("UniformMatrix4fv", location, count, transpose)
+	device.setUniformArray("UniformMatrix4fv", location, value, int(count) * 16)
+}
+
+// GetUniformBlockIndex finds a block declared in the sources of the program (INVALID_INDEX if it isn't there)
+func (device *FakeDevice) GetUniformBlockIndex (program uint32, name string) uint32 {
+	device.record("GetUniformBlockIndex", program, name)
+
+	fake, ok := device.Programs[program]
+	if !ok {
+		device.fail("GetUniformBlockIndex: program %d doesn't exist", program)
+		return gl.INVALID_INDEX
+	}
+	for index, block := range fake.blocks {
+		if block == name {
+			return uint32(index)
+		}
+	}
+
+	// The block is declared as "uniform Name {" (the brace can be on the next line)
+	declaration := regexp.MustCompile(`\buniform\s+` + regexp.QuoteMeta(name) + `\s*\{`)
+	if declaration.MatchString(fake.VertexSource) || declaration.MatchString(fake.FragmentSource) {
+		fake.blocks = append(fake.blocks, name)
+		return uint32(len(fake.blocks) - 1)
+	}
+
+	return gl.INVALID_INDEX
+}
+
+func (device *FakeDevice) UniformBlockBinding (program, blockIndex, binding uint32) {
+	device.record("UniformBlockBinding", program, blockIndex, binding)
+
+	fake, ok := device.Programs[program]
+	if !ok || int(blockIndex) >= len(fake.blocks) {
+		device.fail("UniformBlockBinding: block %d isn't in program %d", blockIndex, program)
+		return
+	}
+
+	fake.Blocks[fake.blocks[blockIndex]] = binding
+}
@@ -0,0 +1,160 @@
+package wrapper
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+//
+// screenTarget
+// What the passes go back to when they finish: the window (0), or a framebuffer that takes its place
+// (the target of the offscreen mode, or the larger framebuffer of a supersampled screenshot)
+//
+type screenTarget struct {
+	fbo           uint32
+	width, height int32
+}
+
+var screen = screenTarget{ 0, 0, 0 }
+
+//
+// Framebuffer
+// An offscreen render target with a colour texture and a depth texture
+//
+type Framebuffer struct {
+	Device        Device
+	Width, Height int32
+
+	FBO           uint32
+	ColorTexture  uint32
+	DepthTexture  uint32
+}
+
+//
+// NewFramebuffer
+// Creates an offscreen framebuffer, the textures can be read by shaders after rendering into it
+//
+// @param device (Device) the device that creates it
+// @param width (int32) the width of the textures
+// @param height (int32) the height of the textures
+//
+// @return framebuffer (*Framebuffer) a pointer to the framebuffer
+// @return error (error) the error (if the framebuffer is not complete)
+//
+func NewFramebuffer (device Device, width, height int32) (*Framebuffer, error) {
+	framebuffer := &Framebuffer{ device, width, height, 0, 0, 0 }
+
+	framebuffer.FBO = device.GenFramebuffer()
+	device.BindFramebuffer(gl.FRAMEBUFFER, framebuffer.FBO)
+
+	framebuffer.ColorTexture = createAttachment(device, width, height, gl.RGBA8, gl.RGBA, gl.UNSIGNED_BYTE)
+	device.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, framebuffer.ColorTexture, 0)
+
+	framebuffer.DepthTexture = createAttachment(device, width, height, gl.DEPTH_COMPONENT24, gl.DEPTH_COMPONENT, gl.FLOAT)
+	device.FramebufferTexture2D(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.TEXTURE_2D, framebuffer.DepthTexture, 0)
+
+	status := device.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	device.BindFramebuffer(gl.FRAMEBUFFER, 0)
+
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		framebuffer.Delete()
+		return nil, fmt.Errorf("framebuffer (%dx%d) is not complete: 0x%x", width, height, status)
+	}
+
+	return framebuffer, nil
+}
+
+//
+// Bind
+// Renders into the framebuffer from now on (and sets the viewport to its size)
+//
+func (framebuffer *Framebuffer) Bind () {
+	framebuffer.Device.BindFramebuffer(gl.FRAMEBUFFER, framebuffer.FBO)
+	framebuffer.Device.Viewport(0, 0, framebuffer.Width, framebuffer.Height)
+}
+
+//
+// Unbind
+// Renders into the window again (or into the framebuffer that takes its place, with its own size)
+//
+// @param width (int32) the width of the window
+// @param height (int32) the height of the window
+//
+func (framebuffer *Framebuffer) Unbind (width, height int32) {
+	if screen.fbo != 0 {
+		width, height = screen.width, screen.height
+	}
+
+	framebuffer.Device.BindFramebuffer(gl.FRAMEBUFFER, screen.fbo)
+	framebuffer.Device.Viewport(0, 0, width, height)
+}
+
+//
+// ReadPixels
+// Reads the colour texture back from the GPU
+//
+// @return image (*image.RGBA) the pixels (the first row is the top of the image)
+//
+func (framebuffer *Framebuffer) ReadPixels () *image.RGBA {
+	framebuffer.Device.BindFramebuffer(gl.READ_FRAMEBUFFER, framebuffer.FBO)
+	framebuffer.Device.ReadBuffer(gl.COLOR_ATTACHMENT0)
+	pixels := readPixels(framebuffer.Device, framebuffer.Width, framebuffer.Height)
+	framebuffer.Device.BindFramebuffer(gl.READ_FRAMEBUFFER, screen.fbo)
+
+	return pixels
+}
+
+//
+// Delete
+// Deletes the framebuffer and its textures
+//
+func (framebuffer *Framebuffer) Delete () {
+	framebuffer.Device.DeleteTexture(framebuffer.ColorTexture)
+	framebuffer.Device.DeleteTexture(framebuffer.DepthTexture)
+	framebuffer.Device.DeleteFramebuffer(framebuffer.FBO)
+
+	framebuffer.FBO, framebuffer.ColorTexture, framebuffer.DepthTexture = 0, 0, 0
+}
+
+// readPixels reads the bound read framebuffer into an image (OpenGL starts at the bottom row, images at the top one)
+func readPixels (device Device, width, height int32) *image.RGBA {
+	pixels := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	if width <= 0 || height <= 0 {
+		return pixels
+	}
+
+	device.ReadPixels(0, 0, width, height, gl.RGBA, gl.UNSIGNED_BYTE, pixels.Pix)
+
+	// Flips the rows
+	row := make([]byte, pixels.Stride)
+	for top, bottom := 0, int(height) - 1; top < bottom; top, bottom = top + 1, bottom - 1 {
+		topRow := pixels.Pix[top * pixels.Stride : (top + 1) * pixels.Stride]
+		bottomRow := pixels.Pix[bottom * pixels.Stride : (bottom + 1) * pixels.Stride]
+		copy(row, topRow)
+		copy(topRow, bottomRow)
+		copy(bottomRow, row)
+	}
+
+	// The window is opaque, so the blended alpha is dropped
+	for alpha := 3; alpha < len(pixels.Pix); alpha += 4 {
+		pixels.Pix[alpha] = 255
+	}
+
+	return pixels
+}
+
+// createAttachment creates an empty texture to render into
+func createAttachment (device Device, width, height int32, internalFormat int32, format, xtype uint32) uint32 {
+	texture := device.GenTexture()
+	device.BindTexture(gl.TEXTURE_2D, texture)
+	device.TexImage2D(gl.TEXTURE_2D, 0, internalFormat, width, height, format, xtype, nil)
+	device.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	device.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	device.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	device.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	device.BindTexture(gl.TEXTURE_2D, 0)
+
+	return texture
+}
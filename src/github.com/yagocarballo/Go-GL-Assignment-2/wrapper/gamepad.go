@@ -0,0 +1,203 @@
+package wrapper
+
+import (
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+// Listeners of the gamepad events
+type GamepadListener func(glw *Glw, gamepad *Gamepad, connected bool)
+type GamepadButtonListener func(glw *Glw, gamepad *Gamepad, button int, action glfw.Action)
+
+// Default size of the dead zone of the axes (the sticks rarely rest at exactly 0)
+const DEFAULT_GAMEPAD_DEADZONE float32 = 0.2
+
+//
+// Gamepad
+// A connected joystick or gamepad, updated once per frame
+//
+type Gamepad struct {
+	Joystick glfw.Joystick
+	Name     string
+
+	Axes     []float32 // Position of the axes (-1 to 1) after removing the dead zone
+	RawAxes  []float32 // Position of the axes as GLFW reports them
+	Buttons  []bool    // Held buttons
+	Deadzone float32   // The axes read 0 under this value (and are rescaled above it)
+}
+
+//
+// ApplyDeadzone
+// Removes the dead zone of an axis and rescales the rest, so the value still goes from 0 to 1
+//
+// @param value (float32) the position of the axis (-1 to 1)
+// @param deadzone (float32) the size of the dead zone (0 to 1)
+//
+// @return value (float32) the position without the dead zone (-1 to 1)
+//
+func ApplyDeadzone (value, deadzone float32) float32 {
+	magnitude, sign := value, float32(1)
+	if value < 0 {
+		magnitude, sign = -value, -1
+	}
+
+	if magnitude <= deadzone || deadzone >= 1 {
+		return 0
+	}
+	if magnitude > 1 {
+		magnitude = 1
+	}
+
+	return sign * (magnitude - deadzone) / (1 - deadzone)
+}
+
+//
+// IsPressed
+// Checks if a button is held down
+//
+// @param button (int) the index of the button
+//
+// @return pressed (bool) true if the button is held down
+//
+func (gamepad *Gamepad) IsPressed (button int) bool {
+	return button >= 0 && button < len(gamepad.Buttons) && gamepad.Buttons[button]
+}
+
+//
+// Axis
+// Returns the position of an axis (0 if the gamepad doesn't have it)
+//
+// @param axis (int) the index of the axis
+//
+// @return value (float32) the position without the dead zone (-1 to 1)
+//
+func (gamepad *Gamepad) Axis (axis int) float32 {
+	if axis < 0 || axis >= len(gamepad.Axes) {
+		return 0
+	}
+
+	return gamepad.Axes[axis]
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////// Wrapper //////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// AddGamepadListener
+// Registers a function to be called when a gamepad is connected or disconnected
+//
+// @param listener (GamepadListener) the listener
+//
+func (glw *Glw) AddGamepadListener (listener GamepadListener) {
+	glw.gamepadListeners = append(glw.gamepadListeners, listener)
+}
+
+//
+// AddGamepadButtonListener
+// Registers a function to be called when a gamepad button is pressed or released
+//
+// @param listener (GamepadButtonListener) the listener
+//
+func (glw *Glw) AddGamepadButtonListener (listener GamepadButtonListener) {
+	glw.gamepadButtonListeners = append(glw.gamepadButtonListeners, listener)
+}
+
+//
+// SetGamepadDeadzone
+// Sets the dead zone of the axes of every gamepad
+//
+// @param deadzone (float32) the size of the dead zone (0 to 1)
+//
+func (glw *Glw) SetGamepadDeadzone (deadzone float32) {
+	glw.gamepadDeadzone = deadzone
+	for _, gamepad := range glw.GetGamepads() {
+		gamepad.Deadzone = deadzone
+	}
+}
+
+//
+// GetGamepadDeadzone
+// Gets the dead zone of the axes
+//
+func (glw *Glw) GetGamepadDeadzone () float32 {
+	return glw.gamepadDeadzone
+}
+
+//
+// GetGamepads
+// Returns the connected gamepads (in the order of the joysticks)
+//
+func (glw *Glw) GetGamepads () []*Gamepad {
+	gamepads := []*Gamepad{}
+	for _, gamepad := range glw.gamepads {
+		if gamepad != nil {
+			gamepads = append(gamepads, gamepad)
+		}
+	}
+
+	return gamepads
+}
+
+//
+// pollGamepads
+// Checks which joysticks are connected and reads their axes and buttons (called once per frame).
+// GLFW 3.1 has no joystick callbacks, so the connections are found by polling too
+//
+func (glw *Glw) pollGamepads () {
+	for joystick := glfw.Joystick1; joystick <= glfw.JoystickLast; joystick++ {
+		gamepad := glw.gamepads[joystick]
+		present := glfw.JoystickPresent(joystick)
+
+		switch {
+		case present && gamepad == nil:
+			gamepad = &Gamepad{ joystick, glfw.GetJoystickName(joystick), []float32{}, []float32{}, []bool{}, glw.gamepadDeadzone }
+			glw.gamepads[joystick] = gamepad
+			for _, listener := range glw.gamepadListeners {
+				listener(glw, gamepad, true)
+			}
+
+		case !present && gamepad != nil:
+			// Releases the held buttons, so nothing stays pressed
+			glw.updateButtons(gamepad, make([]byte, len(gamepad.Buttons)))
+			glw.gamepads[joystick] = nil
+			for _, listener := range glw.gamepadListeners {
+				listener(glw, gamepad, false)
+			}
+			continue
+
+		case !present:
+			continue
+		}
+
+		gamepad.RawAxes = glfw.GetJoystickAxes(joystick)
+		gamepad.Axes = make([]float32, len(gamepad.RawAxes))
+		for axis, value := range gamepad.RawAxes {
+			gamepad.Axes[axis] = ApplyDeadzone(value, gamepad.Deadzone)
+		}
+
+		glw.updateButtons(gamepad, glfw.GetJoystickButtons(joystick))
+	}
+}
+
+// updateButtons stores the state of the buttons and calls the listeners for the ones that changed
+func (glw *Glw) updateButtons (gamepad *Gamepad, states []byte) {
+	for len(gamepad.Buttons) < len(states) {
+		gamepad.Buttons = append(gamepad.Buttons, false)
+	}
+
+	for button, state := range states {
+		pressed := glfw.Action(state) == glfw.Press
+		if pressed == gamepad.Buttons[button] {
+			continue
+		}
+
+		gamepad.Buttons[button] = pressed
+		action := glfw.Release
+		if pressed {
+			action = glfw.Press
+		}
+		for _, listener := range glw.gamepadButtonListeners {
+			listener(glw, gamepad, button, action)
+		}
+	}
+}
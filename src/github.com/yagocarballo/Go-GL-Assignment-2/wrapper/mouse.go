@@ -0,0 +1,188 @@
+package wrapper
+
+import (
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+// Listeners of the mouse events (they get the wrapper, so they can read the mouse state)
+type MouseButtonListener func(glw *Glw, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey)
+type CursorListener func(glw *Glw, x, y float64)
+type ScrollListener func(glw *Glw, xoff, yoff float64)
+type CursorEnterListener func(glw *Glw, entered bool)
+
+//
+// Mouse
+// The state of the mouse. The positions are updated by the events, and the deltas
+// once per frame (the movement and scroll between the last two frames)
+//
+type Mouse struct {
+	X, Y             float64 // Cursor position in screen coordinates (from the top left of the window)
+	DeltaX, DeltaY   float64 // Cursor movement during the last frame
+	ScrollX, ScrollY float64 // Scroll during the last frame
+
+	Inside           bool    // The cursor is over the window
+	Captured         bool    // The cursor is hidden and locked to the window (for mouse look)
+
+	buttons          map[glfw.MouseButton]bool
+	lastX, lastY     float64 // Position at the last frame
+	scrollX, scrollY float64 // Scroll since the last frame
+	skip             bool    // The next delta is a jump (the cursor entered the window or got captured)
+}
+
+//
+// NewMouse
+// Creates the mouse state
+//
+// @return mouse (*Mouse) a pointer to the mouse state
+//
+func NewMouse () *Mouse {
+	return &Mouse{
+		0, 0,                               // X, Y
+		0, 0,                               // DeltaX, DeltaY
+		0, 0,                               // ScrollX, ScrollY
+		false,                              // Inside
+		false,                              // Captured
+		map[glfw.MouseButton]bool{},        // buttons
+		0, 0,                               // lastX, lastY
+		0, 0,                               // scrollX, scrollY
+		true,                               // skip
+	}
+}
+
+//
+// IsPressed
+// Checks if a mouse button is held down
+//
+// @param button (glfw.MouseButton) the button
+//
+// @return pressed (bool) true if the button is held down
+//
+func (mouse *Mouse) IsPressed (button glfw.MouseButton) bool {
+	return mouse.buttons[button]
+}
+
+//
+// update
+// Computes the deltas of the frame (called after polling the events)
+//
+func (mouse *Mouse) update () {
+	if mouse.skip {
+		mouse.DeltaX, mouse.DeltaY = 0, 0
+		mouse.skip = false
+	} else {
+		mouse.DeltaX, mouse.DeltaY = mouse.X - mouse.lastX, mouse.Y - mouse.lastY
+	}
+	mouse.lastX, mouse.lastY = mouse.X, mouse.Y
+
+	mouse.ScrollX, mouse.ScrollY = mouse.scrollX, mouse.scrollY
+	mouse.scrollX, mouse.scrollY = 0, 0
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////// Wrapper //////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+//
+// AddMouseButtonListener
+// Registers a function to be called when a mouse button is pressed or released
+//
+// @param listener (MouseButtonListener) the listener
+//
+func (glw *Glw) AddMouseButtonListener (listener MouseButtonListener) {
+	glw.mouseButtonListeners = append(glw.mouseButtonListeners, listener)
+}
+
+//
+// AddCursorListener
+// Registers a function to be called when the cursor moves
+//
+// @param listener (CursorListener) the listener
+//
+func (glw *Glw) AddCursorListener (listener CursorListener) {
+	glw.cursorListeners = append(glw.cursorListeners, listener)
+}
+
+//
+// AddScrollListener
+// Registers a function to be called when the mouse wheel (or touchpad) scrolls
+//
+// @param listener (ScrollListener) the listener
+//
+func (glw *Glw) AddScrollListener (listener ScrollListener) {
+	glw.scrollListeners = append(glw.scrollListeners, listener)
+}
+
+//
+// AddCursorEnterListener
+// Registers a function to be called when the cursor enters or leaves the window
+//
+// @param listener (CursorEnterListener) the listener
+//
+func (glw *Glw) AddCursorEnterListener (listener CursorEnterListener) {
+	glw.cursorEnterListeners = append(glw.cursorEnterListeners, listener)
+}
+
+//
+// SetCursorCaptured
+// Hides the cursor and locks it to the window (the deltas keep working), or releases it
+//
+// @param captured (bool) true to capture the cursor
+//
+func (glw *Glw) SetCursorCaptured (captured bool) {
+	if captured {
+		glw.Window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	} else {
+		glw.Window.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+	}
+
+	glw.Mouse.Captured = captured
+	glw.Mouse.skip = true
+}
+
+//
+// IsCursorCaptured
+// Checks if the cursor is captured
+//
+// @return captured (bool) true if the cursor is hidden and locked to the window
+//
+func (glw *Glw) IsCursorCaptured () bool {
+	return glw.Mouse.Captured
+}
+
+//
+// setMouseCallbacks
+// Sets the glfw mouse callbacks, they update the mouse state and call the listeners
+//
+func (glw *Glw) setMouseCallbacks () {
+	glw.Mouse.X, glw.Mouse.Y = glw.Window.GetCursorPos()
+
+	glw.Window.SetMouseButtonCallback(func (window *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		glw.Mouse.buttons[button] = action != glfw.Release
+		for _, listener := range glw.mouseButtonListeners {
+			listener(glw, button, action, mods)
+		}
+	})
+
+	glw.Window.SetCursorPosCallback(func (window *glfw.Window, x, y float64) {
+		glw.Mouse.X, glw.Mouse.Y = x, y
+		for _, listener := range glw.cursorListeners {
+			listener(glw, x, y)
+		}
+	})
+
+	glw.Window.SetScrollCallback(func (window *glfw.Window, xoff, yoff float64) {
+		glw.Mouse.scrollX += xoff
+		glw.Mouse.scrollY += yoff
+		for _, listener := range glw.scrollListeners {
+			listener(glw, xoff, yoff)
+		}
+	})
+
+	glw.Window.SetCursorEnterCallback(func (window *glfw.Window, entered bool) {
+		glw.Mouse.Inside = entered
+		glw.Mouse.skip = true
+		for _, listener := range glw.cursorEnterListeners {
+			listener(glw, entered)
+		}
+	})
+}
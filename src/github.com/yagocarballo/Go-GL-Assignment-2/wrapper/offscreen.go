@@ -0,0 +1,109 @@
+package wrapper
+
+import (
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+//
+// SetHeadless
+// Draws into an offscreen framebuffer of the size of the wrapper instead of a visible window (call it before CreateWindow).
+// GLFW still needs a display for the hidden window, on a machine without GPU Mesa's software rasterizer
+// works with a virtual display (Example: xvfb-run -a env LIBGL_ALWAYS_SOFTWARE=1 ./Go-GL-Assignment-2 -screenshot frame.png)
+//
+// @param headless (bool) true to draw offscreen
+//
+func (glw *Glw) SetHeadless (headless bool) {
+	if glw.Window != nil {
+		log.Println("The offscreen mode has to be set before creating the window")
+		return
+	}
+
+	glw.headless = headless
+}
+
+//
+// IsHeadless
+// Checks if the wrapper draws offscreen
+//
+func (glw *Glw) IsHeadless () bool {
+	return glw.headless
+}
+
+//
+// GetFramebufferSize
+// Gets the size in pixels of what the frames are drawn into (the offscreen target or the window)
+//
+// @return width (int) the width in pixels
+// @return height (int) the height in pixels
+//
+func (glw *Glw) GetFramebufferSize () (int, int) {
+	if glw.Target != nil {
+		return int(glw.Target.Width), int(glw.Target.Height)
+	}
+
+	return glw.Window.GetFramebufferSize()
+}
+
+//
+// ReadPixels
+// Reads the last drawn frame back from the GPU. Without the offscreen mode it reads the back buffer of the window,
+// so it has to be called from the render callback (after drawing, before the buffers are swapped)
+//
+// @return image (*image.RGBA) the frame (the first row is the top of the image)
+//
+func (glw *Glw) ReadPixels () *image.RGBA {
+	if glw.Target != nil {
+		return glw.Target.ReadPixels()
+	}
+
+	width, height := glw.Window.GetFramebufferSize()
+	glw.Device.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	glw.Device.ReadBuffer(gl.BACK)
+
+	return readPixels(glw.Device, int32(width), int32(height))
+}
+
+//
+// SavePNG
+// Writes an image to a PNG file (it creates the folder if it's missing)
+//
+// @param path (string) the path to the file
+// @param img (image.Image) the image
+//
+// @return error (error) the error (if any)
+//
+func SavePNG (path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// createTarget creates the offscreen framebuffer and makes it the screen of the passes
+func (glw *Glw) createTarget () {
+	target, err := NewFramebuffer(glw.Device, int32(glw.Width), int32(glw.Height))
+	if err != nil {
+		log.Fatalln("failed to create the offscreen target:", err)
+	}
+
+	glw.Target = target
+	screen = screenTarget{ target.FBO, target.Width, target.Height }
+	target.Bind()
+}
@@ -0,0 +1,295 @@
+package wrapper
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+//
+// Clock
+// A source of time in seconds (the loop uses GLFW, the tests can use a fake one)
+//
+type Clock interface {
+	Now() float64
+	Sleep(seconds float64)
+}
+
+// glfwClock reads the GLFW timer
+type glfwClock struct{}
+
+func (clock glfwClock) Now() float64 {
+	return glfw.GetTime()
+}
+
+func (clock glfwClock) Sleep(seconds float64) {
+	time.Sleep(time.Duration(seconds * float64(time.Second)))
+}
+
+//
+// ManualClock
+// A clock that only moves when it's told to (sleeping moves it too), so a run of the loop always gives the same frames
+//
+type ManualClock struct {
+	Time float64
+}
+
+func (clock *ManualClock) Now() float64 {
+	return clock.Time
+}
+
+func (clock *ManualClock) Sleep(seconds float64) {
+	clock.Advance(seconds)
+}
+
+// Advance moves the clock forward
+func (clock *ManualClock) Advance(seconds float64) {
+	if seconds > 0 {
+		clock.Time += seconds
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+/////////////////////////////////////// Timing //////////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+// Updates per second of a new loop
+const DEFAULT_UPDATE_RATE = 60
+
+//
+// Timing
+// The timing of a fixed step loop: every frame runs as many updates of Step seconds as the elapsed time allows,
+// and renders with alpha, how far it is between the last update and the next one (0 to 1)
+//
+type Timing struct {
+	Clock       Clock
+	Stats       *FrameStats
+
+	Step        float64 // Seconds per update
+	MaxUpdates  int     // Updates per frame at most (when the machine can't keep up, the rest of the time is dropped)
+	FrameCap    float64 // Seconds per frame at least (0 doesn't limit the frame rate)
+
+	accumulator float64 // Time that hasn't been updated yet
+	frameStart  float64
+	started     bool
+}
+
+//
+// NewTiming
+// Creates the timing of a loop
+//
+// @param clock (Clock) the source of time
+// @param updateRate (int) updates per second
+// @param fps (int) frames per second at most (0 or less doesn't limit the frame rate)
+//
+// @return timing (*Timing) a pointer to the timing
+//
+func NewTiming (clock Clock, updateRate, fps int) *Timing {
+	timing := &Timing{ clock, NewFrameStats(DEFAULT_STATS_FRAMES), 0, 5, 0, 0, 0, false }
+	timing.SetUpdateRate(updateRate)
+	timing.SetFrameRate(fps)
+
+	return timing
+}
+
+//
+// SetUpdateRate
+// Sets how many updates run per second
+//
+// @param updateRate (int) updates per second
+//
+func (timing *Timing) SetUpdateRate (updateRate int) {
+	if updateRate > 0 {
+		timing.Step = 1.0 / float64(updateRate)
+	}
+}
+
+//
+// SetFrameRate
+// Sets the frame cap
+//
+// @param fps (int) frames per second at most (0 or less doesn't limit the frame rate)
+//
+func (timing *Timing) SetFrameRate (fps int) {
+	timing.FrameCap = 0
+	if fps > 0 {
+		timing.FrameCap = 1.0 / float64(fps)
+	}
+}
+
+//
+// BeginFrame
+// Starts a frame: records the time of the last frame and finds how many updates to run
+//
+// @return updates (int) the number of updates of Step seconds to run before rendering
+// @return alpha (float64) how far the render is between the last update and the next one (0 to 1)
+//
+func (timing *Timing) BeginFrame () (int, float64) {
+	now := timing.Clock.Now()
+	if !timing.started {
+		timing.started = true
+		timing.frameStart = now
+		timing.accumulator = 0
+
+		// The first frame runs one update, so there is something to render
+		return 1, 0
+	}
+
+	elapsed := now - timing.frameStart
+	timing.frameStart = now
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	timing.Stats.Add(elapsed)
+
+	// The small margin stops the rounding errors from skipping an update when exactly one step has passed
+	timing.accumulator += elapsed
+	updates := int(timing.accumulator / timing.Step + 1e-6)
+	if updates > timing.MaxUpdates {
+		updates = timing.MaxUpdates
+		timing.accumulator = float64(updates) * timing.Step
+	}
+	timing.accumulator = math.Max(timing.accumulator - float64(updates) * timing.Step, 0)
+
+	return updates, timing.accumulator / timing.Step
+}
+
+//
+// Restart
+// Forgets the time of the last frame, so the next frame starts again like the first one (used when the clock changes)
+//
+func (timing *Timing) Restart () {
+	timing.started = false
+}
+
+//
+// EndFrame
+// Waits for the rest of the frame when there is a frame cap
+//
+func (timing *Timing) EndFrame () {
+	if timing.FrameCap <= 0 {
+		return
+	}
+
+	if remaining := timing.frameStart + timing.FrameCap - timing.Clock.Now(); remaining > 0 {
+		timing.Clock.Sleep(remaining)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////// Frame Stats ///////////////////////////////////
+/////////////////////////////////////////////////////////////////////////////////////
+
+// Number of frames in the rolling stats (two seconds at 60 FPS)
+const DEFAULT_STATS_FRAMES = 120
+
+//
+// FrameStats
+// Frame times of the last frames (a ring buffer)
+//
+type FrameStats struct {
+	samples []float64
+	next    int
+	count   int
+	last    float64
+}
+
+//
+// NewFrameStats
+// Creates the stats of a number of frames
+//
+// @param frames (int) the number of frames to keep
+//
+// @return stats (*FrameStats) a pointer to the stats
+//
+func NewFrameStats (frames int) *FrameStats {
+	if frames < 1 {
+		frames = 1
+	}
+
+	return &FrameStats{ make([]float64, frames), 0, 0, 0 }
+}
+
+//
+// Add
+// Records the time of a frame
+//
+// @param frameTime (float64) the time of the frame in seconds
+//
+func (stats *FrameStats) Add (frameTime float64) {
+	stats.samples[stats.next] = frameTime
+	stats.next = (stats.next + 1) % len(stats.samples)
+	if stats.count < len(stats.samples) {
+		stats.count++
+	}
+	stats.last = frameTime
+}
+
+// FrameTime returns the time of the last frame in seconds
+func (stats *FrameStats) FrameTime () float64 {
+	return stats.last
+}
+
+// AverageFrameTime returns the average time of the recorded frames in seconds
+func (stats *FrameStats) AverageFrameTime () float64 {
+	if stats.count == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, sample := range stats.samples[:stats.count] {
+		total += sample
+	}
+
+	return total / float64(stats.count)
+}
+
+// FPS returns the rolling average of frames per second
+func (stats *FrameStats) FPS () float64 {
+	average := stats.AverageFrameTime()
+	if average <= 0 {
+		return 0
+	}
+
+	return 1 / average
+}
+
+//
+// Percentile
+// Returns the frame time that a percentage of the recorded frames don't go over (nearest rank)
+//
+// @param percentile (float64) the percentage (0 to 100)
+//
+// @return frameTime (float64) the frame time in seconds
+//
+func (stats *FrameStats) Percentile (percentile float64) float64 {
+	if stats.count == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, stats.count)
+	copy(sorted, stats.samples[:stats.count])
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(percentile / 100 * float64(stats.count)))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > stats.count {
+		rank = stats.count
+	}
+
+	return sorted[rank - 1]
+}
+
+// P99 returns the 99th percentile of the frame time in seconds
+func (stats *FrameStats) P99 () float64 {
+	return stats.Percentile(99)
+}
+
+func (stats *FrameStats) String () string {
+	return fmt.Sprintf("%.1f FPS, %.2f ms (p99 %.2f ms)", stats.FPS(), stats.FrameTime() * 1000, stats.P99() * 1000)
+}
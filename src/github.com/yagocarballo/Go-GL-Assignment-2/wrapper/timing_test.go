@@ -0,0 +1,176 @@
+package wrapper
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// near compares two times
+func near(a, b float64) bool {
+	return math.Abs(a - b) < 1e-9
+}
+
+func TestTimingSteps(t *testing.T) {
+	clock := &ManualClock{ 10 }
+	timing := NewTiming(clock, 60, 0)
+	step := timing.Step
+
+	// The first frame runs one update, so there is something to render
+	if updates, alpha := timing.BeginFrame(); updates != 1 || alpha != 0 {
+		t.Fatalf("expected the first frame to run 1 update, found %d (alpha %f)", updates, alpha)
+	}
+
+	frames := []struct {
+		elapsed float64
+		updates int
+		alpha   float64
+	}{
+		{ 3 * step, 3, 0 },       // Exactly three steps (the rounding errors don't skip one)
+		{ 0.5 * step, 0, 0.5 },   // Half a step waits for the next frame
+		{ 0.75 * step, 1, 0.25 }, // The rest of the last frame counts
+		{ 2.25 * step, 2, 0.5 },
+		{ 0, 0, 0.5 },            // A frame without time only renders again
+	}
+
+	for i, frame := range frames {
+		clock.Advance(frame.elapsed)
+		updates, alpha := timing.BeginFrame()
+		if updates != frame.updates || !near(alpha, frame.alpha) {
+			t.Errorf("frame %d: expected %d updates (alpha %f), found %d (alpha %f)", i, frame.updates, frame.alpha, updates, alpha)
+		}
+	}
+
+	// Over many irregular frames the updates follow the time
+	random := rand.New(rand.NewSource(1))
+	start, total := clock.Now(), 0
+	for i := 0; i < 1000; i++ {
+		clock.Advance(random.Float64() * 3 * step)
+		updates, _ := timing.BeginFrame()
+		total += updates
+	}
+	elapsed := (clock.Now() - start) / step + 0.5
+	if expected := int(elapsed); total != expected && total != expected + 1 {
+		t.Errorf("expected about %d updates in %f seconds, found %d", expected, clock.Now() - start, total)
+	}
+}
+
+func TestTimingSpiralClamp(t *testing.T) {
+	clock := &ManualClock{}
+	timing := NewTiming(clock, 60, 0)
+	timing.BeginFrame()
+
+	// A long frame (a breakpoint, or a slow machine) runs MaxUpdates and drops the rest of the time
+	clock.Advance(1)
+	if updates, alpha := timing.BeginFrame(); updates != timing.MaxUpdates || alpha != 0 {
+		t.Errorf("expected %d updates (alpha 0), found %d (alpha %f)", timing.MaxUpdates, updates, alpha)
+	}
+
+	// The next frame doesn't try to catch up
+	clock.Advance(timing.Step)
+	if updates, _ := timing.BeginFrame(); updates != 1 {
+		t.Errorf("expected 1 update after the long frame, found %d", updates)
+	}
+
+	// The clamp follows MaxUpdates
+	timing.MaxUpdates = 2
+	clock.Advance(10 * timing.Step)
+	if updates, _ := timing.BeginFrame(); updates != 2 {
+		t.Errorf("expected 2 updates, found %d", updates)
+	}
+
+	// Restart starts again like the first frame, even if the clock went back
+	clock.Time = 0
+	timing.Restart()
+	if updates, alpha := timing.BeginFrame(); updates != 1 || alpha != 0 {
+		t.Errorf("expected 1 update after the restart, found %d (alpha %f)", updates, alpha)
+	}
+}
+
+func TestTimingAlphaRange(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+
+	for _, rate := range []int{ 30, 60, 144 } {
+		clock := &ManualClock{}
+		timing := NewTiming(clock, rate, 0)
+		timing.BeginFrame()
+
+		for i := 0; i < 2000; i++ {
+			clock.Advance(random.Float64() * 0.05)
+			if _, alpha := timing.BeginFrame(); alpha < 0 || alpha >= 1 {
+				t.Fatalf("%d updates per second, frame %d: alpha %f is out of [0, 1)", rate, i, alpha)
+			}
+		}
+	}
+}
+
+func TestTimingFrameCap(t *testing.T) {
+	clock := &ManualClock{}
+	timing := NewTiming(clock, 60, 50)
+	timing.BeginFrame()
+
+	// A fast frame sleeps until the frame time of the cap
+	clock.Advance(0.005)
+	timing.EndFrame()
+	if !near(clock.Now(), 0.02) {
+		t.Errorf("expected to sleep until 0.02, the clock is on %f", clock.Now())
+	}
+
+	// So the next frame takes exactly one cap
+	timing.BeginFrame()
+	if !near(timing.Stats.FrameTime(), 0.02) {
+		t.Errorf("expected a frame of 0.02 seconds, found %f", timing.Stats.FrameTime())
+	}
+
+	// A slow frame doesn't sleep
+	clock.Advance(0.03)
+	timing.EndFrame()
+	if !near(clock.Now(), 0.05) {
+		t.Errorf("a slow frame slept until %f", clock.Now())
+	}
+
+	// Without a cap it never sleeps
+	timing.SetFrameRate(0)
+	timing.BeginFrame()
+	timing.EndFrame()
+	if !near(clock.Now(), 0.05) || timing.FrameCap != 0 {
+		t.Errorf("slept without a frame cap until %f", clock.Now())
+	}
+}
+
+func TestFrameStats(t *testing.T) {
+	stats := NewFrameStats(100)
+	if stats.FPS() != 0 || stats.P99() != 0 || stats.AverageFrameTime() != 0 {
+		t.Error("the stats without frames are not 0")
+	}
+
+	// 1 to 100 ms, in a shuffled order
+	for _, index := range rand.New(rand.NewSource(3)).Perm(100) {
+		stats.Add(float64(index + 1) / 1000)
+	}
+
+	if average := stats.AverageFrameTime(); !near(average, 0.0505) {
+		t.Errorf("expected the average 50.5 ms, found %f", average * 1000)
+	}
+	if fps := stats.FPS(); math.Abs(fps - 1 / 0.0505) > 1e-6 {
+		t.Errorf("expected %f FPS, found %f", 1 / 0.0505, fps)
+	}
+	if p99 := stats.P99(); !near(p99, 0.099) {
+		t.Errorf("expected the p99 99 ms, found %f", p99 * 1000)
+	}
+	if median := stats.Percentile(50); !near(median, 0.05) {
+		t.Errorf("expected the median 50 ms, found %f", median * 1000)
+	}
+	if !near(stats.Percentile(0), 0.001) || !near(stats.Percentile(100), 0.1) {
+		t.Error("the percentiles 0 and 100 are not the fastest and the slowest frames")
+	}
+
+	// Only the last frames count
+	rolling := NewFrameStats(4)
+	for _, frameTime := range []float64{ 1, 0.01, 0.02, 0.03, 0.04 } {
+		rolling.Add(frameTime)
+	}
+	if !near(rolling.AverageFrameTime(), 0.025) || !near(rolling.P99(), 0.04) || rolling.FrameTime() != 0.04 {
+		t.Errorf("expected the stats of the last 4 frames, found %s", rolling)
+	}
+}
@@ -3,7 +3,6 @@ import (
 	"fmt"
 	"strings"
 	"io/ioutil"
-	"github.com/go-gl/gl/all-core/gl"
 	"github.com/kardianos/osext"
 )
 
@@ -20,6 +19,7 @@ type Shader struct {
 type ShaderManager struct {
 	Shaders         map[string]Shader
     ActiveShader    string
+    Device          Device
 }
 
 /* ---------------------------------------------------------------- */
@@ -76,104 +76,35 @@ func FileToString (path string) string {
 	return content
 }
 
-//
-// Build Shader
-// Creates and compiles a shader
-//
-// @param source (string) the path to the shader file
-// @param shaderType (uint32) the shader type
-//
-// @return shader (uint32) the pointer to the shader
-// @return error (error) the error (if any)
-//
-func BuildShader (source string, shaderType uint32) (uint32, error) {
-	// Creates the Shader Object
-	shader := gl.CreateShader(shaderType)
-
-	// Reads the File
-	fileContents := FileToString(source)
-
-	// Converts the file contents into a valid C String
-	csource := gl.Str(fileContents)
-
-	// Loads the Shader's Source
-	gl.ShaderSource(shader, 1, &csource, nil)
-
-	// Compiles the Shader
-	gl.CompileShader(shader)
-
-	// Gets any errors that happened when building the Shader
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-
-	// If there was an error, parse the C Error into a Go Error and return it
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
-
-		return 0, fmt.Errorf("failed to compile %v: %v", source, log)
-	}
-
-	// Returns the shader if everything is OK
-	return shader, nil
-}
-
 //
 // Load Shader
-// Load vertex and fragment shader and return the compiled program.
+// Reads the vertex and fragment shader files and returns the compiled program.
 //
+// @param device (Device) the device that compiles the program
 // @param vertexShaderSource (string) path to the vertex shader file
 // @param fragmentShaderSource (string) path to the fragment shader file
 //
 // @return program (uint32) a pointer to the shader program
 // @return error (error) the error (if any)
 //
-func LoadShader(vertexShaderSource, fragmentShaderSource string) (uint32, error) {
-	// Loads the Vertex shader file
-	vertexShader, err := BuildShader(vertexShaderSource, gl.VERTEX_SHADER)
+func LoadShader(device Device, vertexShaderSource, fragmentShaderSource string) (uint32, error) {
+	// Reads the Shader files
+	vertexContents, err := ReadFile(vertexShaderSource)
 	if err != nil {
 		return 0, err
 	}
 
-	// Loads the fragment shader file
-	fragmentShader, err := BuildShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
+	fragmentContents, err := ReadFile(fragmentShaderSource)
 	if err != nil {
 		return 0, err
 	}
 
-	// Creates the Program
-	program := gl.CreateProgram()
-
-	// Attaches the Shaders to the program
-	gl.AttachShader(program, vertexShader)
-	gl.AttachShader(program, fragmentShader)
-
-	// Links the program
-	gl.LinkProgram(program)
-
-	// Gets any error that happened when linking the program
-	var status int32
-	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
-
-	// If there was any error, parse the C error and return it as a Go error
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
-
-		return 0, fmt.Errorf("failed to link program: %v", log)
+	// Compiles and links the program
+	program, err := device.CreateProgram(vertexContents, fragmentContents)
+	if err != nil {
+		return 0, fmt.Errorf("%v (%v, %v)", err, vertexShaderSource, fragmentShaderSource)
 	}
 
-	// Deletes the shaders
-	gl.DeleteShader(vertexShader)
-	gl.DeleteShader(fragmentShader)
-
-	// returns the program
 	return program, nil
 }
 
@@ -181,9 +112,17 @@ func LoadShader(vertexShaderSource, fragmentShaderSource string) (uint32, error)
 /* ------------------------ Shader Manager ------------------------ */
 /* ---------------------------------------------------------------- */
 
-func NewShaderManager () *ShaderManager {
+//
+// NewShaderManager
+// Creates an empty shader manager
+//
+// @param device (Device) the device that compiles the shaders and sets the uniforms
+//
+// @return shaderManager (*ShaderManager) a pointer to the shader manager
+//
+func NewShaderManager (device Device) *ShaderManager {
     return &ShaderManager{
-       make(map[string]Shader), "",
+       make(map[string]Shader), "", device,
     }
 }
 
@@ -194,7 +133,7 @@ func NewShaderManager () *ShaderManager {
 // @param name (string) the name of the shader to enable
 //
 func (shaderManager *ShaderManager) EnableShader (name string) {
-    gl.UseProgram(shaderManager.Shaders[name].Shader)
+    shaderManager.Device.UseProgram(shaderManager.Shaders[name].Shader)
     shaderManager.ActiveShader = name
 }
 
@@ -203,7 +142,7 @@ func (shaderManager *ShaderManager) EnableShader (name string) {
 // Sets the Active Shader to 0 (none)
 //
 func (shaderManager *ShaderManager) DisableShader () {
-    gl.UseProgram(0)
+    shaderManager.Device.UseProgram(0)
     shaderManager.ActiveShader = ""
 }
 
@@ -219,7 +158,7 @@ func (shaderManager *ShaderManager) DisableShader () {
 //
 func (shaderManager *ShaderManager) LoadShader (name string, vertexShaderSource, fragmentShaderSource string) error {
     // Loads the Shader
-    shader, err := LoadShader(vertexShaderSource, fragmentShaderSource)
+    shader, err := LoadShader(shaderManager.Device, vertexShaderSource, fragmentShaderSource)
     if err != nil {
         return err
     }
@@ -248,7 +187,7 @@ func (shaderManager *ShaderManager) CurrentShader () uint32 {
 }
 
 func (shaderManager *ShaderManager) CreateUniform (shaderName, uniformName string) {
-    shaderManager.Shaders[shaderName].Uniforms[uniformName] = gl.GetUniformLocation(shaderManager.Shaders[shaderName].Shader, gl.Str(uniformName + "\x00"));
+    shaderManager.Shaders[shaderName].Uniforms[uniformName] = shaderManager.Device.GetUniformLocation(shaderManager.Shaders[shaderName].Shader, uniformName)
 }
 
 func (shaderManager *ShaderManager) GetUniform (shaderName, uniformName string) int32 {
@@ -256,17 +195,17 @@ func (shaderManager *ShaderManager) GetUniform (shaderName, uniformName string)
 }
 
 func (shaderManager *ShaderManager) SetUniform1ui (shaderName, uniformName string, value uint32) {
-    gl.Uniform1ui(shaderManager.GetUniform(shaderName, uniformName), value)
+    shaderManager.Device.Uniform1ui(shaderManager.GetUniform(shaderName, uniformName), value)
 }
 
-func (shaderManager *ShaderManager) SetUniformMatrix4fv (shaderName, uniformName string, count int32, transpose bool, value *float32) {
-    gl.UniformMatrix4fv(shaderManager.GetUniform(shaderName, uniformName), count, transpose, value)
+func (shaderManager *ShaderManager) SetUniformMatrix4fv (shaderName, uniformName string, count int32, transpose bool, value []float32) {
+    shaderManager.Device.UniformMatrix4fv(shaderManager.GetUniform(shaderName, uniformName), count, transpose, value)
 }
 
-func (shaderManager *ShaderManager) SetUniform4fv (shaderName, uniformName string, count int32, value *float32) {
-    gl.Uniform4fv(shaderManager.GetUniform(shaderName, uniformName), count, value)
+func (shaderManager *ShaderManager) SetUniform4fv (shaderName, uniformName string, count int32, value []float32) {
+    shaderManager.Device.Uniform4fv(shaderManager.GetUniform(shaderName, uniformName), count, value)
 }
 
 func (shaderManager *ShaderManager) SetUniform4f (shaderName, uniformName string, v0 float32, v1 float32, v2 float32, v3 float32) {
-    gl.Uniform4f(shaderManager.GetUniform(shaderName, uniformName), v0, v1, v2, v3)
+    shaderManager.Device.Uniform4f(shaderManager.GetUniform(shaderName, uniformName), v0, v1, v2, v3)
 }
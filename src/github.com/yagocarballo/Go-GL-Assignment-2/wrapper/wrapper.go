@@ -16,13 +16,38 @@ type Glw struct  {
 
 	// State
 	fps int
+	vsync bool
+	timing *Timing
 	running bool
+	headless bool
 	Window *glfw.Window
+	Device Device       // The OpenGL calls (created with the context)
+	Target *Framebuffer // Where the frames are drawn in the offscreen mode (nil when drawing to the window)
+	Mouse *Mouse
+	gamepads [glfw.JoystickLast + 1]*Gamepad // Indexed by joystick (nil if it's not connected)
+	gamepadDeadzone float32
+
+	// Captures
+	screenshots []screenshotRequest // Screenshots of the next frame
+	recorder *recorder              // The recording (nil if it's not recording)
+	captures *captureWorker         // Writes the captured frames in the background
+	captureTarget *Framebuffer      // Larger framebuffer of the supersampled captures
 
 	// Callbacks
-	renderer func(glw *Glw, elapsed float64)
+	updater func(glw *Glw, dt float64)
+	renderer func(glw *Glw, alpha float64)
 	keyCallBack glfw.KeyCallback
 	reshape glfw.FramebufferSizeCallback
+
+	// Mouse Listeners
+	mouseButtonListeners []MouseButtonListener
+	cursorListeners []CursorListener
+	scrollListeners []ScrollListener
+	cursorEnterListeners []CursorEnterListener
+
+	// Gamepad Listeners
+	gamepadListeners []GamepadListener
+	gamepadButtonListeners []GamepadButtonListener
 }
 
 // This function is called by go as soon as this library is imported
@@ -42,7 +67,14 @@ func init () {
 // @return wrapper (*Glw) a pointer to the wrapper.
 //
 func NewWrapper(width, height int, title string) *Glw {
-	return &Glw{ width, height, title, 60, true, nil, nil, nil, nil }
+	return &Glw{
+		width, height, title,
+		60, false, NewTiming(glfwClock{}, DEFAULT_UPDATE_RATE, 60), true, false, nil, nil, nil, NewMouse(), [glfw.JoystickLast + 1]*Gamepad{}, DEFAULT_GAMEPAD_DEADZONE,
+		nil, nil, nil, nil,
+		nil, nil, nil, nil,
+		nil, nil, nil, nil,
+		nil, nil,
+	}
 }
 
 // Public Functions
@@ -62,6 +94,11 @@ func (glw *Glw) CreateWindow () *glfw.Window {
 	// Sets the OpenGL Version
 	setOpenGlVersion()
 
+	// The offscreen mode still needs a context, so it opens a window but doesn't show it
+	if glw.headless {
+		glfw.WindowHint(glfw.Visible, glfw.False)
+	}
+
 	// Creates the Window
 	win, err := glfw.CreateWindow(glw.Width, glw.Height, glw.Title, nil, nil)
 	if err != nil {
@@ -74,50 +111,94 @@ func (glw *Glw) CreateWindow () *glfw.Window {
 	// Sets this context as the current context
 	win.MakeContextCurrent()
 
+	// Waits for the screen refresh to swap the buffers (or not)
+	if glw.vsync {
+		glfw.SwapInterval(1)
+	} else {
+		glfw.SwapInterval(0)
+	}
+
 	// Initiates GL
 	if err := gl.Init(); err != nil {
 		panic(err)
 	}
 
+	glw.Device = NewGLDevice()
+
 	// Enables Depth
-	gl.Enable(gl.DEPTH_TEST)
-	gl.DepthFunc(gl.LESS)
+	glw.Device.Enable(gl.DEPTH_TEST)
+	glw.Device.DepthFunc(gl.LESS)
 
 	win.SetInputMode(glfw.StickyKeysMode, 1)
 
 	// Sets the Window to the Wrapper
 	glw.SetWindow(win)
+
+	// Draws into a framebuffer instead of the hidden window
+	if glw.headless {
+		glw.createTarget()
+	}
+
+	// Tracks the Mouse and calls the Mouse Listeners
+	glw.setMouseCallbacks()
 	return win
 }
 
 //
 // Start Loop
-// this starts the event loop which runs until the program ends
+// this starts the event loop which runs until the program ends.
+// Every frame runs the update callback a fixed number of times (with the same dt, so the animations
+// don't depend on the machine), then the render callback once with how far it is to the next update
 //
 func (glw *Glw) StartLoop () {
-	optimalTime := 1000.0 / float64(glw.fps)
-	previousTime := glfw.GetTime()
-
 	// If the Window is open keep looping
 	for !glw.GetWindow().ShouldClose() {
-		// Update
-		time := glfw.GetTime()
-		elapsed := time - previousTime
-		delta	:= elapsed / optimalTime
-		previousTime = time
+		glw.frame()
+	}
 
-		// Calls the Render Callback
-		glw.renderer(glw, delta)
+	// Called at the end of the program, and terminates the window system
+	glw.Terminate()
+}
 
-		// Triggers window refresh
-		glw.GetWindow().SwapBuffers()
+//
+// RunFrames
+// Runs a number of frames of the loop (even if the window wants to close), and returns without terminating
+//
+// @param frames (int) the number of frames
+//
+func (glw *Glw) RunFrames (frames int) {
+	for frame := 0; frame < frames; frame++ {
+		glw.frame()
+	}
+}
 
-		// Triggers events
-		glfw.PollEvents()
+// frame runs the updates, the render and the events of one frame
+func (glw *Glw) frame () {
+	// Update
+	updates, alpha := glw.timing.BeginFrame()
+	for update := 0; update < updates; update++ {
+		if glw.updater != nil {
+			glw.updater(glw, glw.timing.Step)
+		}
 	}
 
-	// Called at the end of the program, and terminates the window system
-	glw.Terminate()
+	// Calls the Render Callback (and captures the frame if there is a screenshot or a recording)
+	glw.renderFrame(alpha)
+
+	// Triggers window refresh
+	glw.GetWindow().SwapBuffers()
+
+	// Triggers events
+	glfw.PollEvents()
+
+	// Computes the cursor movement and scroll of the frame
+	glw.Mouse.update()
+
+	// Reads the gamepads (and finds the connected and disconnected ones)
+	glw.pollGamepads()
+
+	// Waits for the rest of the frame (if the frame rate is capped)
+	glw.timing.EndFrame()
 }
 
 //
@@ -125,7 +206,28 @@ func (glw *Glw) StartLoop () {
 // When this is called, it destroys the window and terminates glfw
 //
 func (glw *Glw) Terminate () {
+	// Writes the captured frames
+	if glw.recorder != nil {
+		if err := glw.StopRecording(); err != nil {
+			log.Println("The recording failed:", err)
+		}
+	}
+	if glw.captures != nil {
+		glw.WaitCaptures()
+		close(glw.captures.jobs)
+		glw.captures = nil
+	}
+
 	// Clean up
+	if glw.captureTarget != nil {
+		glw.captureTarget.Delete()
+		glw.captureTarget = nil
+	}
+	if glw.Target != nil {
+		glw.Target.Delete()
+		glw.Target = nil
+		screen = screenTarget{ 0, 0, 0 }
+	}
 	glw.Window.Destroy()
 	glfw.Terminate()
 }
@@ -164,10 +266,11 @@ func printOpenGlVersionInfo() {
 
 //
 // SetFPS
-// Sets the FPS for the window
+// Sets the frame cap of the window (0 or less doesn't limit the frame rate)
 //
 func (glw *Glw) SetFPS (fps int) {
 	glw.fps = fps
+	glw.timing.SetFrameRate(fps)
 }
 
 //
@@ -178,6 +281,48 @@ func (glw *Glw) GetFPS () int {
 	return glw.fps
 }
 
+//
+// SetUpdateRate
+// Sets how many times per second the update callback runs
+//
+func (glw *Glw) SetUpdateRate (updateRate int) {
+	glw.timing.SetUpdateRate(updateRate)
+}
+
+//
+// SetVSync
+// Waits for the screen refresh to swap the buffers (it works with the frame cap, set the FPS to 0 to only use VSync)
+//
+func (glw *Glw) SetVSync (enabled bool) {
+	glw.vsync = enabled
+	if glw.Window == nil {
+		return
+	}
+
+	if enabled {
+		glfw.SwapInterval(1)
+	} else {
+		glfw.SwapInterval(0)
+	}
+}
+
+//
+// SetClock
+// Replaces the source of time of the loop
+//
+func (glw *Glw) SetClock (clock Clock) {
+	glw.timing.Clock = clock
+	glw.timing.Restart()
+}
+
+//
+// GetStats
+// Gets the frame time stats (frame time, rolling FPS and 99th percentile)
+//
+func (glw *Glw) GetStats () *FrameStats {
+	return glw.timing.Stats
+}
+
 //
 // SetWindow
 // Sets the Window Object
@@ -194,11 +339,19 @@ func (glw *Glw) GetWindow () *glfw.Window {
 	return glw.Window
 }
 
+//
+// SetUpdateCallback
+// Sets the Update Callback (called with a fixed dt in seconds)
+//
+func (glw *Glw) SetUpdateCallback (callback func(glw *Glw, dt float64)) {
+	glw.updater = callback
+}
+
 //
 // SetRenderCallback
-// Sets the Render Callback (loop)
+// Sets the Render Callback (called once per frame with alpha, from 0 at the last update to 1 at the next one)
 //
-func (glw *Glw) SetRenderCallback (callback func(glw *Glw, elapsed float64)) {
+func (glw *Glw) SetRenderCallback (callback func(glw *Glw, alpha float64)) {
 	glw.renderer = callback
 }
 